@@ -0,0 +1,111 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+// Package client is a small Go client for the dse.v1.EventService.
+//
+//   c, err := client.Dial("localhost:9300", client.WithToken("secret"))
+//   sub, err := c.Subscribe(ctx, `Name == "ProcessExecuted"`, 0)
+//   for {
+//       event, err := sub.Next()
+//       ...
+//   }
+package client
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	dsev1 "github.com/kris-nova/double-slit-experiment/proto/dse/v1"
+)
+
+type Client struct {
+	conn   *grpc.ClientConn
+	events dsev1.EventServiceClient
+}
+
+// Dial will connect to a dse gRPC server. The address is host:port,
+// or unix:///path for a Unix domain socket. Connections are insecure
+// unless transport credentials are passed in opts.
+func Dial(address string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{grpc.WithInsecure()}, opts...)
+	if strings.HasPrefix(address, "unix://") {
+		address = "unix:" + strings.TrimPrefix(address, "unix://")
+	}
+	conn, err := grpc.Dial(address, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		conn:   conn,
+		events: dsev1.NewEventServiceClient(conn),
+	}, nil
+}
+
+// WithToken will send a bearer token with every call.
+func WithToken(token string) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(bearerToken(token))
+}
+
+// Subscribe will start streaming events that match the filter.
+// Up to replay recent events are sent before any live events.
+func (c *Client) Subscribe(ctx context.Context, filter string, replay int) (*Subscription, error) {
+	stream, err := c.events.Subscribe(ctx, &dsev1.SubscribeRequest{
+		Filter: filter,
+		Replay: int32(replay),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Subscription{stream: stream}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+type Subscription struct {
+	stream dsev1.EventService_SubscribeClient
+}
+
+// Next will block until the next event. io.EOF is returned when
+// the server ends the stream.
+func (s *Subscription) Next() (*dsev1.Event, error) {
+	event, err := s.stream.Recv()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	return event, err
+}
+
+// bearerToken is a PerRPCCredentials that sends a bearer token.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return false
+}
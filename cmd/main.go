@@ -23,7 +23,12 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/kris-nova/double-slit-experiment/userspace"
 
@@ -38,6 +43,117 @@ var (
 
 	// verbosity toggles verbose mode
 	verbosity bool = true
+
+	// metricsAddress is the address to serve Prometheus metrics on
+	metricsAddress string
+
+	// outputs are the output specs to write events to
+	outputs = cli.NewStringSlice()
+
+	// apiAddress is the address to serve the live event API on
+	apiAddress string
+
+	// apiToken is the optional bearer token for the event API
+	apiToken string
+
+	// apiHistory is the number of recent events kept for the event API
+	apiHistory int
+
+	// grpcAddress is the address to serve the gRPC event service on
+	grpcAddress string
+
+	// criEndpoint is the CRI runtime service to enrich events from
+	criEndpoint string
+
+	// dockerEndpoint is the Docker or Podman API to enrich events from
+	dockerEndpoint string
+
+	// rulePaths are the rule files and directories to evaluate events against
+	rulePaths = cli.NewStringSlice()
+
+	// rateTracking toggles fork and exec rate tracking
+	rateTracking bool
+
+	// rateThresholds override the thresholds of the default rates
+	rateThresholds = cli.NewStringSlice()
+
+	// responsePaths are the response policy files and directories to act on
+	responsePaths = cli.NewStringSlice()
+
+	// responseDryRun will only write Response events, without acting
+	responseDryRun bool
+
+	// responseAllow is a filter of events that are never acted on
+	responseAllow string
+
+	// responseRate is the most actions taken in a window
+	responseRate string
+
+	// fileTracking toggles the file points
+	fileTracking bool
+
+	// filePrefixes replace the default prefixes of the file points
+	filePrefixes = cli.NewStringSlice()
+
+	// mountTracking toggles the mount point
+	mountTracking bool
+
+	// privilegeTracking toggles the privilege point
+	privilegeTracking bool
+
+	// loadTracking toggles the module and bpf points
+	loadTracking bool
+
+	// accessTracking toggles the process access point
+	accessTracking bool
+
+	// connectionTracking toggles the connect and accept points
+	connectionTracking bool
+
+	// udpTracking toggles the UDP flow and DNS point
+	udpTracking bool
+
+	// udpFlowInterval is how often the UDP flow counters are sent
+	udpFlowInterval time.Duration
+
+	// listenerTracking toggles the listener inventory point
+	listenerTracking bool
+
+	// tcpTracking toggles the TCP retransmit, reset and drop point
+	tcpTracking bool
+
+	// execDenyPrefixes are path prefixes the exec guard denies
+	execDenyPrefixes = cli.NewStringSlice()
+
+	// execDenyPaths are executables the exec guard denies by inode
+	execDenyPaths = cli.NewStringSlice()
+
+	// execDenyCgroups are cgroups the exec guard denies every exec in
+	execDenyCgroups = cli.NewStringSlice()
+
+	// execGuardAudit will report denied executions without denying them
+	execGuardAudit bool
+
+	// treeInterval is how often the process tree is redrawn
+	treeInterval time.Duration
+
+	// treeContainer limits the process tree to a single container
+	treeContainer string
+
+	// treeOnce prints a single process tree snapshot in this format and exits
+	treeOnce string
+
+	// topFilter is the initial event filter for the terminal UI
+	topFilter string
+
+	// topHistory is the number of recent events summarized by the terminal UI
+	topHistory int
+
+	// topInterval is how often the terminal UI is refreshed
+	topInterval time.Duration
+
+	// treeSnapshotDir is where process tree snapshots are written on SIGUSR1 and SIGUSR2
+	treeSnapshotDir string
 )
 
 func main() {
@@ -51,8 +167,9 @@ func main() {
 	}
 
 	app := &cli.App{
-		Usage: "Container runtime telemetry",
-		Name:  "The Double Slit Experiment",
+		Usage:   "Container runtime telemetry",
+		Name:    "The Double Slit Experiment",
+		Version: userspace.Version,
 		Action: func(context *cli.Context) error {
 			cli.ShowAppHelpAndExit(context, 0)
 			return nil
@@ -78,7 +195,7 @@ func main() {
 			{
 				Name:    "run",
 				Aliases: []string{"a"},
-				Usage:   "Run with the default profile, and print JSON events.",
+				Usage:   "Run with the default profile, and write JSON events to the outputs.",
 				Action: func(c *cli.Context) error {
 					return RunDSE() // X gonna give it to ya
 				},
@@ -90,6 +207,297 @@ func main() {
 						Destination: &verbosity,
 						Usage:       "Toggle the verbosity of the program.",
 					},
+					&cli.StringFlag{
+						Name:        "metrics",
+						Aliases:     []string{"m"},
+						Value:       "",
+						Destination: &metricsAddress,
+						Usage:       "Serve Prometheus metrics on this address (e.g. :9100).",
+					},
+					&cli.StringSliceFlag{
+						Name:        "output",
+						Aliases:     []string{"o"},
+						Destination: outputs,
+						Usage:       "Write events to this output, may be repeated (stdout, file:///path?max-size=100M&max-age=24h&max-backups=7&compress=true, unix:///path, syslog+udp://host:port, syslog+tcp://host:port, syslog+unix:///dev/log, journald, otlp+grpc://host:4317, otlp+http://host:4318). Add ?format=json|ecs|cef|leef to any output.",
+					},
+					&cli.StringFlag{
+						Name:        "api",
+						Value:       "",
+						Destination: &apiAddress,
+						Usage:       "Serve live events over SSE and WebSocket on this address (e.g. :8080).",
+					},
+					&cli.StringFlag{
+						Name:        "api-token",
+						Value:       "",
+						EnvVars:     []string{"DSE_API_TOKEN"},
+						Destination: &apiToken,
+						Usage:       "Require this bearer token for the event API and the gRPC event service.",
+					},
+					&cli.IntFlag{
+						Name:        "api-history",
+						Value:       userspace.DefaultEventServerHistory,
+						Destination: &apiHistory,
+						Usage:       "Number of recent events kept for event API clients that join late.",
+					},
+					&cli.StringFlag{
+						Name:        "grpc",
+						Value:       "",
+						Destination: &grpcAddress,
+						Usage:       "Serve the dse.v1.EventService on this address (e.g. :9300 or unix:///run/dse/grpc.sock).",
+					},
+					&cli.StringFlag{
+						Name:        "cri",
+						Value:       "",
+						Destination: &criEndpoint,
+						Usage:       "Add Kubernetes pod metadata from this CRI runtime socket (e.g. unix:///run/containerd/containerd.sock, or auto).",
+					},
+					&cli.StringFlag{
+						Name:        "docker",
+						Value:       "",
+						Destination: &dockerEndpoint,
+						Usage:       "Add container metadata from this Docker or Podman API socket (e.g. unix:///var/run/docker.sock, or auto).",
+					},
+					&cli.StringSliceFlag{
+						Name:        "rules",
+						Destination: rulePaths,
+						Usage:       "Emit Alert events for the rules in this file or directory, may be repeated. Use default for the starter rules.",
+					},
+					&cli.BoolFlag{
+						Name:        "rates",
+						Value:       false,
+						Destination: &rateTracking,
+						Usage:       "Emit RateExceeded events for fork bombs and exec storms, by container, parent process and UID.",
+					},
+					&cli.StringSliceFlag{
+						Name:        "rate",
+						Destination: rateThresholds,
+						Usage:       "Change the threshold of a rate, may be repeated (e.g. exec_per_container=500 or fork_per_uid=2000/5s). Implies --rates.",
+					},
+					&cli.StringSliceFlag{
+						Name:        "responses",
+						Destination: responsePaths,
+						Usage:       "Kill, stop or freeze the targets of events that match the response policies in this file or directory, may be repeated.",
+					},
+					&cli.BoolFlag{
+						Name:        "response-dry-run",
+						Value:       false,
+						Destination: &responseDryRun,
+						Usage:       "Write a Response event for every action, without taking the action.",
+					},
+					&cli.StringFlag{
+						Name:        "response-allow",
+						Value:       "",
+						Destination: &responseAllow,
+						Usage:       "Never act on events that match this filter (e.g. 'Comm == \"sshd\"').",
+					},
+					&cli.StringFlag{
+						Name:        "response-rate",
+						Value:       userspace.DefaultResponseRate,
+						Destination: &responseRate,
+						Usage:       "The most actions taken across every response policy in a window.",
+					},
+					&cli.BoolFlag{
+						Name:        "files",
+						Value:       false,
+						Destination: &fileTracking,
+						Usage:       "Observe opens and changes of sensitive files (shadow, sudoers, kubeconfigs, service account tokens).",
+					},
+					&cli.StringSliceFlag{
+						Name:        "file-prefix",
+						Destination: filePrefixes,
+						Usage:       "Observe files with this path prefix instead of the sensitive files, may be repeated. Implies --files.",
+					},
+					&cli.BoolFlag{
+						Name:        "mounts",
+						Value:       false,
+						Destination: &mountTracking,
+						Usage:       "Observe every mount and unmount, on the host and in containers.",
+					},
+					&cli.BoolFlag{
+						Name:        "privileges",
+						Value:       false,
+						Destination: &privilegeTracking,
+						Usage:       "Observe setuid, setgid, setgroups and capset, and exec of setuid binaries.",
+					},
+					&cli.BoolFlag{
+						Name:        "loads",
+						Value:       false,
+						Destination: &loadTracking,
+						Usage:       "Observe kernel modules and BPF programs that are loaded.",
+					},
+					&cli.BoolFlag{
+						Name:        "process-access",
+						Value:       false,
+						Destination: &accessTracking,
+						Usage:       "Observe ptrace, process_vm_readv, process_vm_writev and /proc/<pid>/mem.",
+					},
+					&cli.BoolFlag{
+						Name:        "connections",
+						Value:       false,
+						Destination: &connectionTracking,
+						Usage:       "Observe connect and accept of TCP, UDP and Unix sockets.",
+					},
+					&cli.BoolFlag{
+						Name:        "udp",
+						Value:       false,
+						Destination: &udpTracking,
+						Usage:       "Observe UDP flows by process, and decode DNS queries and responses.",
+					},
+					&cli.DurationFlag{
+						Name:        "udp-flow-interval",
+						Value:       userspace.DefaultUDPFlowInterval,
+						Destination: &udpFlowInterval,
+						Usage:       "Send the UDP flow counters this often.",
+					},
+					&cli.BoolFlag{
+						Name:        "listeners",
+						Value:       false,
+						Destination: &listenerTracking,
+						Usage:       "Keep an inventory of every TCP and UDP port listening, in every network namespace.",
+					},
+					&cli.BoolFlag{
+						Name:        "tcp",
+						Value:       false,
+						Destination: &tcpTracking,
+						Usage:       "Observe TCP retransmits, resets and dropped packets, by connection.",
+					},
+					&cli.StringSliceFlag{
+						Name:        "exec-deny-prefix",
+						Destination: execDenyPrefixes,
+						Usage:       "Deny exec of any executable whose resolved path has this prefix (e.g. /tmp/) with BPF LSM, may be repeated.",
+					},
+					&cli.StringSliceFlag{
+						Name:        "exec-deny-path",
+						Destination: execDenyPaths,
+						Usage:       "Deny exec of this file, and any hard link to it, with BPF LSM, may be repeated.",
+					},
+					&cli.StringSliceFlag{
+						Name:        "exec-deny-cgroup",
+						Destination: execDenyCgroups,
+						Usage:       "Deny every exec in this cgroup v2 path (e.g. /system.slice/nginx.service) and its descendants with BPF LSM, may be repeated.",
+					},
+					&cli.BoolFlag{
+						Name:        "exec-guard-audit",
+						Value:       false,
+						Destination: &execGuardAudit,
+						Usage:       "Write an ExecDenied event for every exec the guard matches, without denying it.",
+					},
+				},
+			},
+			{
+				Name:  "rules",
+				Usage: "Validate the rules, and print them as YAML.",
+				Action: func(c *cli.Context) error {
+					return RunRules()
+				},
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:        "rules",
+						Destination: rulePaths,
+						Usage:       "Load the rules in this file or directory, may be repeated. Defaults to the starter rules.",
+					},
+				},
+			},
+			{
+				Name:  "top",
+				Usage: "Interactive terminal UI for recent executables, connections, signals and containers.",
+				Action: func(c *cli.Context) error {
+					return RunTop()
+				},
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:        "verbose",
+						Aliases:     []string{"v"},
+						Value:       false,
+						Destination: &verbosity,
+						Usage:       "Toggle the verbosity of the program.",
+					},
+					&cli.StringFlag{
+						Name:        "filter",
+						Aliases:     []string{"f"},
+						Value:       "",
+						Destination: &topFilter,
+						Usage:       "Only summarize events matching this filter, press / to change it.",
+					},
+					&cli.IntFlag{
+						Name:        "history",
+						Value:       10000,
+						Destination: &topHistory,
+						Usage:       "Number of recent events to summarize.",
+					},
+					&cli.DurationFlag{
+						Name:        "interval",
+						Aliases:     []string{"i"},
+						Value:       time.Second,
+						Destination: &topInterval,
+						Usage:       "Refresh the UI this often.",
+					},
+					&cli.StringFlag{
+						Name:        "cri",
+						Value:       "",
+						Destination: &criEndpoint,
+						Usage:       "Add Kubernetes pod metadata from this CRI runtime socket (e.g. unix:///run/containerd/containerd.sock, or auto).",
+					},
+					&cli.StringFlag{
+						Name:        "docker",
+						Value:       "",
+						Destination: &dockerEndpoint,
+						Usage:       "Add container metadata from this Docker or Podman API socket (e.g. unix:///var/run/docker.sock, or auto).",
+					},
+				},
+			},
+			{
+				Name:  "tree",
+				Usage: "Show a live process tree for every container. Send SIGUSR1 for a JSON snapshot, SIGUSR2 for a Graphviz DOT snapshot.",
+				Action: func(c *cli.Context) error {
+					return RunTree()
+				},
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:        "verbose",
+						Aliases:     []string{"v"},
+						Value:       false,
+						Destination: &verbosity,
+						Usage:       "Toggle the verbosity of the program.",
+					},
+					&cli.DurationFlag{
+						Name:        "interval",
+						Aliases:     []string{"i"},
+						Value:       time.Second,
+						Destination: &treeInterval,
+						Usage:       "Redraw the tree at most this often.",
+					},
+					&cli.StringFlag{
+						Name:        "container",
+						Aliases:     []string{"c"},
+						Value:       "",
+						Destination: &treeContainer,
+						Usage:       "Only show the container with this ID prefix, name, pod name or compose project (or host).",
+					},
+					&cli.StringFlag{
+						Name:        "once",
+						Value:       "",
+						Destination: &treeOnce,
+						Usage:       "Print a single snapshot as text, json or dot and exit.",
+					},
+					&cli.StringFlag{
+						Name:        "snapshot-dir",
+						Value:       os.TempDir(),
+						Destination: &treeSnapshotDir,
+						Usage:       "Write snapshots taken with SIGUSR1 and SIGUSR2 to this directory.",
+					},
+					&cli.StringFlag{
+						Name:        "cri",
+						Value:       "",
+						Destination: &criEndpoint,
+						Usage:       "Add Kubernetes pod metadata from this CRI runtime socket (e.g. unix:///run/containerd/containerd.sock, or auto).",
+					},
+					&cli.StringFlag{
+						Name:        "docker",
+						Value:       "",
+						Destination: &dockerEndpoint,
+						Usage:       "Add container metadata from this Docker or Podman API socket (e.g. unix:///var/run/docker.sock, or auto).",
+					},
 				},
 			},
 		},
@@ -104,15 +512,344 @@ func main() {
 
 func RunDSE() error {
 	commandGlobalChecks()
-	observer := userspace.NewObserver(userspace.ProfileDefault())
-	err := observer.Start()
+	outputSpecs := outputs.Value()
+	if len(outputSpecs) == 0 {
+		outputSpecs = userspace.ProfileDefaultOutputs()
+	}
+	output, err := userspace.ParseOutputs(outputSpecs)
+	if err != nil {
+		return err
+	}
+	points := userspace.ProfileDefault()
+	var tracker *userspace.RateTracker
+	if rateTracking || len(rateThresholds.Value()) > 0 {
+		rates := userspace.ProfileDefaultRates()
+		for _, spec := range rateThresholds.Value() {
+			err := rates.Set(spec)
+			if err != nil {
+				return err
+			}
+		}
+		tracker, err = userspace.NewRateTracker(rates)
+		if err != nil {
+			return err
+		}
+		for name, point := range userspace.ProfileRates() {
+			points[name] = point
+		}
+	}
+	if fileTracking || len(filePrefixes.Value()) > 0 {
+		for name, point := range userspace.ProfileFiles(filePrefixes.Value()) {
+			points[name] = point
+		}
+	}
+	if mountTracking {
+		for name, point := range userspace.ProfileMounts() {
+			points[name] = point
+		}
+	}
+	if privilegeTracking {
+		for name, point := range userspace.ProfilePrivileges() {
+			points[name] = point
+		}
+	}
+	if loadTracking {
+		for name, point := range userspace.ProfileLoads() {
+			points[name] = point
+		}
+	}
+	if accessTracking {
+		for name, point := range userspace.ProfileProcessAccess() {
+			points[name] = point
+		}
+	}
+	if connectionTracking {
+		for name, point := range userspace.ProfileConnections() {
+			points[name] = point
+		}
+	}
+	if udpTracking {
+		for name, point := range userspace.ProfileUDP(udpFlowInterval) {
+			points[name] = point
+		}
+	}
+	if listenerTracking {
+		for name, point := range userspace.ProfileListeners() {
+			points[name] = point
+		}
+	}
+	if tcpTracking {
+		for name, point := range userspace.ProfileTCP() {
+			points[name] = point
+		}
+	}
+	var engine *userspace.RuleEngine
+	if len(rulePaths.Value()) > 0 {
+		rules, err := userspace.LoadRules(rulePaths.Value()...)
+		if err != nil {
+			return err
+		}
+		engine = userspace.NewRuleEngine(rules)
+		logger.Info("Evaluating %d rules", len(engine.Rules()))
+		for name, point := range userspace.ProfileRules(engine.Rules()) {
+			if _, ok := points[name]; ok {
+				continue
+			}
+			logger.Info("Observing %s for the rules", name)
+			points[name] = point
+		}
+	}
+	observer := userspace.NewObserver(points)
+	closeEnrichers, err := addEnrichers(observer)
+	if err != nil {
+		return err
+	}
+	defer closeEnrichers()
+	if tracker != nil {
+		observer.AddProducer(tracker)
+	}
+	if engine != nil {
+		observer.AddProducer(engine)
+	}
+	if len(responsePaths.Value()) > 0 {
+		policies, err := userspace.LoadResponsePolicies(responsePaths.Value()...)
+		if err != nil {
+			return err
+		}
+		responder, err := userspace.NewResponder(userspace.ResponderConfig{
+			DryRun: responseDryRun,
+			Allow:  responseAllow,
+			Rate:   responseRate,
+		}, policies)
+		if err != nil {
+			return err
+		}
+		logger.Info("Responding to %d response policies (dry run: %t)", len(responder.Policies()), responseDryRun)
+		observer.AddProducer(responder)
+	}
+	policy := userspace.ExecPolicy{
+		Prefixes: execDenyPrefixes.Value(),
+		Paths:    execDenyPaths.Value(),
+		Cgroups:  execDenyCgroups.Value(),
+		Audit:    execGuardAudit,
+	}
+	var guard *userspace.ExecGuard
+	if !policy.Empty() {
+		guard, err = userspace.NewExecGuard(policy)
+		if err != nil {
+			return err
+		}
+		defer guard.Close()
+		observer.AddProducer(guard)
+	}
+	if metricsAddress != "" {
+		metrics, err := userspace.NewMetrics(userspace.ProfileDefaultMetrics())
+		if err != nil {
+			return err
+		}
+		if tracker != nil {
+			err := metrics.Register(tracker)
+			if err != nil {
+				return err
+			}
+		}
+		observer.AddHandler(metrics)
+		go func() {
+			err := metrics.ListenAndServe(metricsAddress)
+			if err != nil {
+				logger.Critical("Unable to serve metrics: %v", err)
+			}
+		}()
+	}
+	if apiAddress != "" {
+		server := userspace.NewEventServer(userspace.EventServerConfig{
+			Address: apiAddress,
+			Token:   apiToken,
+			History: apiHistory,
+		})
+		observer.AddHandler(server)
+		go func() {
+			err := server.ListenAndServe()
+			if err != nil {
+				logger.Critical("Unable to serve event API: %v", err)
+			}
+		}()
+	}
+	if grpcAddress != "" {
+		server := userspace.NewGRPCServer(userspace.GRPCServerConfig{
+			Address: grpcAddress,
+			Token:   apiToken,
+			History: apiHistory,
+		})
+		observer.AddHandler(server)
+		go func() {
+			err := server.ListenAndServe()
+			if err != nil {
+				logger.Critical("Unable to serve gRPC: %v", err)
+			}
+		}()
+	}
+	err = observer.Start()
+	if err != nil {
+		return err
+	}
+	if guard != nil {
+		guard.Start(observer)
+	}
+	observer.WriteEvents(output)
+	return nil
+}
+
+// RunRules will load the rules, and print the enabled rules as YAML.
+func RunRules() error {
+	paths := rulePaths.Value()
+	if len(paths) == 0 {
+		paths = []string{userspace.RulesDefault}
+	}
+	rules, err := userspace.LoadRules(paths...)
+	if err != nil {
+		return err
+	}
+	b, err := rules.Enabled().YAML()
 	if err != nil {
 		return err
 	}
-	observer.PrintJSONEvents()
+	fmt.Print(string(b))
 	return nil
 }
 
+// RunTree will draw a live process tree until interrupted.
+func RunTree() error {
+	commandGlobalChecks()
+	switch treeOnce {
+	case "", "text", "json", "dot":
+	default:
+		return fmt.Errorf("unknown --once format %q, expected text, json or dot", treeOnce)
+	}
+	tree := userspace.NewProcessTree()
+	observer := userspace.NewObserver(userspace.ProfileProcessTree())
+	closeEnrichers, err := addEnrichers(observer)
+	if err != nil {
+		return err
+	}
+	defer closeEnrichers()
+	observer.AddHandler(tree)
+	err = observer.Start()
+	if err != nil {
+		return err
+	}
+
+	// Seed after starting, so no process started in between is missed.
+	err = tree.Seed()
+	if err != nil {
+		return err
+	}
+	go func() {
+		for range observer.EventStream() {
+		}
+	}()
+
+	var match func(group *userspace.ProcessTreeGroup) bool
+	if treeContainer != "" {
+		match = userspace.MatchProcessTreeGroup(treeContainer)
+	}
+	if treeOnce != "" {
+		time.Sleep(treeInterval)
+		return tree.Snapshot(match).Write(os.Stdout, treeOnce)
+	}
+
+	snapshotCh := make(chan os.Signal, 1)
+	signal.Notify(snapshotCh, syscall.SIGUSR1, syscall.SIGUSR2)
+	ticker := time.NewTicker(treeInterval)
+	defer ticker.Stop()
+	prune := time.NewTicker(10 * time.Second)
+	defer prune.Stop()
+	interactive := userspace.IsTerminal(os.Stdout)
+	var drawn uint64
+	for {
+		select {
+		case s := <-snapshotCh:
+			format := "json"
+			if s == syscall.SIGUSR2 {
+				format = "dot"
+			}
+			path, err := writeTreeSnapshot(tree.Snapshot(match), format)
+			if err != nil {
+				logger.Warning("Unable to write process tree snapshot: %v", err)
+				continue
+			}
+			logger.Info("Wrote process tree snapshot: %s", path)
+		case <-prune.C:
+			tree.Prune()
+		case <-ticker.C:
+			version := tree.Version()
+			if version == drawn {
+				continue
+			}
+			drawn = version
+			if interactive {
+				// Move the cursor home, and clear the screen
+				fmt.Print("\033[H\033[2J")
+			}
+			err := tree.Snapshot(match).WriteText(os.Stdout)
+			if err != nil {
+				return err
+			}
+			if !interactive {
+				fmt.Println()
+			}
+		}
+	}
+}
+
+// writeTreeSnapshot will write a snapshot to a new file in the snapshot directory.
+func writeTreeSnapshot(snapshot *userspace.ProcessTreeSnapshot, format string) (string, error) {
+	path := filepath.Join(treeSnapshotDir, fmt.Sprintf("dse-tree-%s.%s", snapshot.Time.Format("20060102T150405"), format))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	err = snapshot.Write(f, format)
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// addEnrichers will add the enrichers configured with --cri and --docker.
+// The returned function will close them.
+func addEnrichers(observer *userspace.Observer) (func(), error) {
+	var closers []func() error
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+	if criEndpoint != "" {
+		enricher, err := userspace.NewCRIEnricher(userspace.CRIEnricherConfig{
+			Endpoint: criEndpoint,
+		})
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, enricher.Close)
+		observer.AddEnricher(enricher)
+	}
+	if dockerEndpoint != "" {
+		enricher, err := userspace.NewDockerEnricher(userspace.DockerEnricherConfig{
+			Endpoint: dockerEndpoint,
+		})
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		closers = append(closers, enricher.Close)
+		observer.AddEnricher(enricher)
+	}
+	return closeAll, nil
+}
+
 // commandGlobalChecks is used to check the runtime constraints of the
 // system. This is just a collection of checks we use in many places.
 func commandGlobalChecks() {
@@ -0,0 +1,358 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kris-nova/logger"
+	"github.com/rivo/tview"
+	"golang.org/x/sys/unix"
+
+	"github.com/kris-nova/double-slit-experiment/userspace"
+)
+
+const (
+	// topRows is the number of rows kept in every pane
+	topRows = 100
+
+	topHelp = "[yellow]/[white] filter  [yellow]p[white] pause  [yellow]tab[white] next pane  [yellow]enter[white] container  [yellow]esc[white] back  [yellow]q[white] quit"
+)
+
+// top is the state of the `dse top` terminal UI.
+type top struct {
+	app         *tview.Application
+	pages       *tview.Pages
+	header      *tview.TextView
+	executables *tview.Table
+	talkers     *tview.Table
+	signals     *tview.Table
+	starts      *tview.Table
+	containers  *tview.Table
+	processes   *tview.Table
+	sockets     *tview.Table
+	input       *tview.InputField
+	log         *tview.TextView
+	panes       []tview.Primitive
+	broadcaster *userspace.EventBroadcaster
+
+	// Only touched from the UI goroutine
+	filter    *userspace.EventFilter
+	paused    bool
+	focus     int
+	container string
+	snapshot  *userspace.TopSnapshot
+	err       string
+}
+
+// RunTop will run the interactive terminal UI until the user quits.
+func RunTop() error {
+	commandGlobalChecks()
+	filter, err := userspace.ParseEventFilter(topFilter)
+	if err != nil {
+		return err
+	}
+	broadcaster := userspace.NewEventBroadcaster(topHistory)
+	observer := userspace.NewObserver(userspace.ProfileDefault())
+	closeEnrichers, err := addEnrichers(observer)
+	if err != nil {
+		return err
+	}
+	defer closeEnrichers()
+	observer.AddHandler(broadcaster)
+	err = observer.Start()
+	if err != nil {
+		return err
+	}
+	go func() {
+		for range observer.EventStream() {
+		}
+	}()
+
+	t := newTop(broadcaster, filter)
+
+	// Logs would draw over the UI, so they are written to their own pane.
+	logger.Writer = t.log
+	defer func() { logger.Writer = os.Stdout }()
+
+	go func() {
+		ticker := time.NewTicker(topInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			t.app.QueueUpdateDraw(t.refresh)
+		}
+	}()
+	t.refresh()
+	return t.app.Run()
+}
+
+func newTop(broadcaster *userspace.EventBroadcaster, filter *userspace.EventFilter) *top {
+	t := &top{
+		app:         tview.NewApplication(),
+		pages:       tview.NewPages(),
+		header:      tview.NewTextView().SetDynamicColors(true),
+		executables: topTable("Executables"),
+		talkers:     topTable("Talkers"),
+		signals:     topTable("Signals"),
+		starts:      topTable("Container starts"),
+		containers:  topTable("Containers"),
+		processes:   topTable("Processes"),
+		sockets:     topTable("Sockets"),
+		input:       tview.NewInputField().SetLabel("filter: "),
+		log:         tview.NewTextView().SetMaxLines(100),
+		broadcaster: broadcaster,
+		filter:      filter,
+	}
+	t.panes = []tview.Primitive{t.executables, t.talkers, t.signals, t.starts, t.containers}
+
+	overview := tview.NewGrid().
+		SetRows(0, 0, 0).
+		SetColumns(0, 0).
+		AddItem(t.executables, 0, 0, 1, 1, 0, 0, true).
+		AddItem(t.talkers, 0, 1, 1, 1, 0, 0, false).
+		AddItem(t.signals, 1, 0, 1, 1, 0, 0, false).
+		AddItem(t.starts, 1, 1, 1, 1, 0, 0, false).
+		AddItem(t.containers, 2, 0, 1, 2, 0, 0, false)
+	detail := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(t.processes, 0, 1, true).
+		AddItem(t.sockets, 0, 1, false)
+	t.pages.AddPage("overview", overview, true, true)
+	t.pages.AddPage("container", detail, true, false)
+
+	t.containers.SetSelectedFunc(func(row, _ int) {
+		t.drillDown(t.containers.GetCell(row, 0).GetReference())
+	})
+	t.starts.SetSelectedFunc(func(row, _ int) {
+		t.drillDown(t.starts.GetCell(row, 0).GetReference())
+	})
+
+	t.input.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			filter, err := userspace.ParseEventFilter(t.input.GetText())
+			if err != nil {
+				t.err = err.Error()
+			} else {
+				t.filter = filter
+				t.err = ""
+			}
+		}
+		t.input.SetText("")
+		t.setFocus()
+		t.refresh()
+	})
+	t.log.SetChangedFunc(func() { t.app.Draw() })
+
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(t.header, 2, 0, false).
+		AddItem(t.pages, 0, 1, true).
+		AddItem(t.input, 1, 0, false).
+		AddItem(t.log, 3, 0, false)
+	t.app.SetRoot(root, true).SetInputCapture(t.keys)
+	t.setFocus()
+	return t
+}
+
+func topTable(title string) *tview.Table {
+	table := tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	table.SetBorder(true).SetTitle(" " + title + " ")
+	return table
+}
+
+// keys handles every key that is not typed into the filter.
+func (t *top) keys(event *tcell.EventKey) *tcell.EventKey {
+	if t.input.HasFocus() {
+		return event
+	}
+	switch event.Key() {
+	case tcell.KeyTab:
+		t.focus = (t.focus + 1) % len(t.panes)
+		t.setFocus()
+		return nil
+	case tcell.KeyBacktab:
+		t.focus = (t.focus + len(t.panes) - 1) % len(t.panes)
+		t.setFocus()
+		return nil
+	case tcell.KeyEscape:
+		t.container = ""
+		t.pages.SwitchToPage("overview")
+		t.setFocus()
+		t.refresh()
+		return nil
+	case tcell.KeyCtrlC:
+		t.app.Stop()
+		return nil
+	}
+	switch event.Rune() {
+	case 'q':
+		t.app.Stop()
+		return nil
+	case 'p', ' ':
+		t.paused = !t.paused
+		t.refresh()
+		return nil
+	case '/':
+		if t.filter != nil {
+			t.input.SetText(t.filter.String())
+		}
+		t.app.SetFocus(t.input)
+		return nil
+	}
+	return event
+}
+
+func (t *top) setFocus() {
+	if t.container != "" {
+		t.app.SetFocus(t.processes)
+		return
+	}
+	t.app.SetFocus(t.panes[t.focus])
+}
+
+func (t *top) drillDown(reference interface{}) {
+	id, ok := reference.(string)
+	if !ok || id == "" {
+		return
+	}
+	t.container = id
+	t.pages.SwitchToPage("container")
+	t.setFocus()
+	t.refresh()
+}
+
+// refresh will summarize the recent events and redraw every pane.
+// It must be called from the UI goroutine.
+func (t *top) refresh() {
+	if !t.paused || t.snapshot == nil {
+		t.snapshot = userspace.NewTopSnapshot(t.broadcaster.Recent(nil, 0), t.filter, topRows)
+	}
+	s := t.snapshot
+
+	status := ""
+	if t.paused {
+		status = " [red::b]PAUSED[-::-]"
+	}
+	filter := "none"
+	if t.filter != nil {
+		filter = tview.Escape(t.filter.String())
+	}
+	header := fmt.Sprintf("[::b]dse top[::-]  %d events  filter: %s%s\n%s", s.Events, filter, status, topHelp)
+	if t.err != "" {
+		header = fmt.Sprintf("[::b]dse top[::-]  [red]%s[-]\n%s", tview.Escape(t.err), topHelp)
+	}
+	t.header.SetText(header)
+
+	topFill(t.executables, []string{"EXECS", "EXECUTABLE", "COMM"}, len(s.Executables), func(i int) ([]string, interface{}) {
+		c := s.Executables[i]
+		return []string{fmt.Sprint(c.Count), c.Key, c.Detail}, nil
+	})
+	topFill(t.talkers, []string{"CONNS", "PEER", "PORT"}, len(s.Talkers), func(i int) ([]string, interface{}) {
+		c := s.Talkers[i]
+		return []string{fmt.Sprint(c.Count), c.Key, c.Detail}, nil
+	})
+	topFill(t.signals, []string{"SIGNAL", "CODE", "ERRNO"}, len(s.Signals), func(i int) ([]string, interface{}) {
+		e := s.Signals[i]
+		return []string{e.SignalName, fmt.Sprint(e.Code), fmt.Sprint(e.Errno)}, nil
+	})
+	topFill(t.starts, []string{"CONTAINER", "PARENT", "CHILD", "FLAGS"}, len(s.ContainerStarts), func(i int) ([]string, interface{}) {
+		e := s.ContainerStarts[i]
+		parent := fmt.Sprint(e.ParentPid)
+		if e.ParentProc != nil {
+			parent += " " + e.ParentProc.Executable
+		}
+		return []string{topContainerName(&e.ContainerMetadata), parent, fmt.Sprint(e.ChildPid), strings.Join(e.CloneFlagsByName, "|")}, e.ContainerID
+	})
+	topFill(t.containers, []string{"CONTAINER", "IMAGE", "POD", "EVENTS", "PROCESSES"}, len(s.Containers), func(i int) ([]string, interface{}) {
+		c := s.Containers[i]
+		pod := ""
+		if c.PodName != "" {
+			pod = c.PodNamespace + "/" + c.PodName
+		}
+		return []string{topContainerName(&c.ContainerMetadata), c.ContainerImage, pod, fmt.Sprint(c.Events), fmt.Sprint(len(c.Processes))}, c.ContainerID
+	})
+
+	if t.container == "" {
+		return
+	}
+	container := s.Container(t.container)
+	if container == nil {
+		container = &userspace.TopContainer{ContainerMetadata: userspace.ContainerMetadata{ContainerID: t.container}}
+	}
+	t.processes.SetTitle(fmt.Sprintf(" Processes in %s ", topContainerName(&container.ContainerMetadata)))
+	t.sockets.SetTitle(fmt.Sprintf(" Sockets in %s ", topContainerName(&container.ContainerMetadata)))
+	topFill(t.processes, []string{"PID", "COMM", "EXECUTABLE", "EVENTS"}, len(container.Processes), func(i int) ([]string, interface{}) {
+		p := container.Processes[i]
+		return []string{fmt.Sprint(p.PID), p.Comm, p.Filename, fmt.Sprint(p.Events)}, nil
+	})
+	topFill(t.sockets, []string{"PID", "COMM", "LOCAL", "PEER", "STATE"}, len(container.Sockets), func(i int) ([]string, interface{}) {
+		e := container.Sockets[i]
+		local, peer := e.SourceAddr, e.DestAddr
+		if e.Family == uint(unix.AF_INET6) {
+			local, peer = e.SourceAddrV6, e.DestAddrV6
+		}
+		return []string{fmt.Sprint(e.PID), e.Comm, fmt.Sprintf("%s:%d", local, e.SourcePort), fmt.Sprintf("%s:%d", peer, e.DestPort), e.NewStateName}, nil
+	})
+}
+
+// topFill will replace the rows of a table, and keep the selected row.
+func topFill(table *tview.Table, headers []string, rows int, row func(i int) ([]string, interface{})) {
+	selected, _ := table.GetSelection()
+	table.Clear()
+	for column, header := range headers {
+		table.SetCell(0, column, tview.NewTableCell(header).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+	}
+	for i := 0; i < rows; i++ {
+		values, reference := row(i)
+		for column, value := range values {
+			cell := tview.NewTableCell(tview.Escape(value)).SetReference(reference)
+			if column == len(values)-1 {
+				cell.SetExpansion(1)
+			}
+			table.SetCell(i+1, column, cell)
+		}
+	}
+	if selected >= table.GetRowCount() {
+		selected = table.GetRowCount() - 1
+	}
+	if selected < 1 {
+		selected = 1
+	}
+	table.Select(selected, 0)
+}
+
+func topContainerName(m *userspace.ContainerMetadata) string {
+	if m.ContainerName != "" {
+		return m.ContainerName
+	}
+	if m.ContainerID == "" {
+		return "-"
+	}
+	id := m.ContainerID
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return id
+}
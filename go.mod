@@ -4,10 +4,20 @@ go 1.16
 
 require (
 	github.com/cilium/ebpf v0.6.1
+	github.com/gdamore/tcell/v2 v2.4.1-0.20210905002822-f057f0a857a1
+	github.com/gorilla/websocket v1.4.2
 	github.com/kris-nova/logger v0.2.2
 	github.com/martinlindhe/base36 v1.1.0 // indirect
-	github.com/mitchellh/go-ps v1.0.0 // indirect
+	github.com/mitchellh/go-ps v1.0.0
+	github.com/prometheus/client_golang v1.11.0
+	github.com/rivo/tview v0.0.0-20211202162923-2a6de950f73b
 	github.com/urfave/cli/v2 v2.3.0
-	golang.org/x/sys v0.0.0-20210124154548-22da62e12c0c
+	go.opentelemetry.io/proto/otlp v0.11.0
+	golang.org/x/net v0.0.0-20211209124913-491a49abca63
+	golang.org/x/sys v0.0.0-20210831042530-f4d43177bf5e
+	google.golang.org/grpc v1.43.0
+	google.golang.org/protobuf v1.27.1
+	gopkg.in/yaml.v2 v2.4.0
 	inet.af/netaddr v0.0.0-20210707202901-70468d781e6c // indirect
+	k8s.io/cri-api v0.23.1
 )
@@ -27,6 +27,7 @@
 #include "bpf.h"
 #include "string.h"
 #include <bpf/bpf_helpers.h>
+#include <bpf/bpf_core_read.h>
 
 struct {
     __uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
@@ -34,10 +35,28 @@ struct {
     __uint(value_size, sizeof(__u32));
 } events SEC(".maps");
 
+// current_ppid will return the thread group ID of the real parent of the current task.
+static __always_inline __u32 current_ppid() {
+    struct task_struct *task = (struct task_struct *)bpf_get_current_task();
+    struct task_struct *parent = 0;
+    __u32 ppid = 0;
+
+    bpf_probe_read_kernel(&parent, sizeof(parent), &task->real_parent);
+    bpf_probe_read_kernel(&ppid, sizeof(ppid), &parent->tgid);
+    return ppid;
+}
+
+// current_uid will return the real user ID of the current task.
+static __always_inline __u32 current_uid() {
+    return LAST_32_BITS(bpf_get_current_uid_gid());
+}
+
 
 // ----------------------------------------------------------------------------
 
 struct inet_sock_data_t {
+    __u32 type;
+    __u32 pid;
     int oldstate;
     int newstate;
     __u16 sport;
@@ -48,11 +67,28 @@ struct inet_sock_data_t {
     __u8 daddr[4];
     __u8 saddr_v6[16];
     __u8 daddr_v6[16];
+    __u8 comm[DATA_SIZE_32];
+};
+
+struct sock_owner_t {
+    __u32 pid;
+    __u8 comm[DATA_SIZE_32];
 };
 
+// sock_owners is the process that owns a socket, by the address of the socket.
+// Most state changes happen in softirq context where the current task is
+// unrelated, so the owner is saved when the process itself calls connect()
+// or listen().
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __uint(max_entries, 65536);
+    __type(key, __u64);
+    __type(value, struct sock_owner_t);
+} sock_owners SEC(".maps");
+
 struct inet_sock_entry_args_t {
     __u64 _unused;
-    __u64 _unused2;
+    __u64 skaddr;
     int oldstate;
     int newstate;
     __u16 sport;
@@ -92,7 +128,10 @@ print fmt: "family=%s protocol=%s sport=%hu dport=%hu saddr=%pI4 daddr=%pI4 sadd
 SEC("tracepoint/sock/inet_sock_set_state")
 int inet_sock_set_state(struct inet_sock_entry_args_t  *args){
     struct inet_sock_data_t data = {};
+    struct sock_owner_t *owner;
+    __u64 skaddr = args->skaddr;
 
+    data.type = EVENT_TYPE_SOCK;
     data.oldstate = args->oldstate;
     data.newstate = args->newstate;
     data.sport = args->sport;
@@ -104,6 +143,21 @@ int inet_sock_set_state(struct inet_sock_entry_args_t  *args){
     memcpy(data.saddr_v6, args->saddr_v6, sizeof(args->saddr_v6));
     memcpy(data.daddr_v6, args->daddr_v6, sizeof(args->daddr_v6));
 
+    if (args->newstate == TCP_SYN_SENT || args->newstate == TCP_LISTEN) {
+        struct sock_owner_t new_owner = {};
+
+        new_owner.pid = FIRST_32_BITS(bpf_get_current_pid_tgid());
+        bpf_get_current_comm(new_owner.comm, sizeof(new_owner.comm));
+        bpf_map_update_elem(&sock_owners, &skaddr, &new_owner, BPF_ANY);
+    }
+    owner = bpf_map_lookup_elem(&sock_owners, &skaddr);
+    if (owner) {
+        data.pid = owner->pid;
+        memcpy(data.comm, owner->comm, sizeof(data.comm));
+    }
+    if (args->newstate == TCP_CLOSE) {
+        bpf_map_delete_elem(&sock_owners, &skaddr);
+    }
 
     // Send out on the perf event map
     bpf_perf_event_output(args, &events, BPF_F_CURRENT_CPU, &data, sizeof(data));
@@ -113,6 +167,7 @@ int inet_sock_set_state(struct inet_sock_entry_args_t  *args){
 
 
 struct signal_deliver_data_t {
+    __u32 type;
     int signal;
     int errno;
     int code;
@@ -152,6 +207,7 @@ SEC("tracepoint/signal/signal_deliver")
 int signal_deliver(struct signal_deliver_entry_args_t  *args){
     struct signal_deliver_data_t signal_data = {};
 
+    signal_data.type = EVENT_TYPE_SIGNAL;
     signal_data.signal = args->signal;
     signal_data.errno = args->errno;
     signal_data.code = args->code;
@@ -167,8 +223,10 @@ int signal_deliver(struct signal_deliver_entry_args_t  *args){
 
 
 struct clone_data_t {
+    __u32 type;
     __u32 parent_tid;
     __u32 child_tid;
+    __u32 uid;
     __u64 clone_flags;
     __u64 tls;
 };
@@ -208,6 +266,8 @@ SEC("tracepoint/syscalls/sys_enter_clone")
 int enter_clone(struct clone_entry_args_t  *args){
     struct clone_data_t clone_data = {};
 
+    clone_data.type = EVENT_TYPE_CLONE;
+
     // Parent=0
     if (args->parent_tidptr == 0) {
         return 0;
@@ -218,6 +278,7 @@ int enter_clone(struct clone_entry_args_t  *args){
 
     bpf_probe_read_user(&clone_data.parent_tid, sizeof(clone_data.parent_tid), args->parent_tidptr);
     bpf_probe_read_user(&clone_data.child_tid, sizeof(clone_data.child_tid), args->child_tidptr);
+    clone_data.uid = current_uid();
     clone_data.clone_flags = args->clone_flags;
     clone_data.tls = args->tls;
 
@@ -228,7 +289,10 @@ int enter_clone(struct clone_entry_args_t  *args){
 }
 
 struct exec_data_t {
+    __u32 type;
     __u32 pid;
+    __u32 ppid;
+    __u32 uid;
     __u8 f_name[DATA_SIZE_32];
     __u8 comm[DATA_SIZE_32];
 };
@@ -270,7 +334,10 @@ int enter_execve(struct execve_entry_args_t *args){
     __u64 pid_tgid;
 
     pid_tgid = bpf_get_current_pid_tgid();
+    exec_data.type = EVENT_TYPE_EXECVE;
     exec_data.pid = LAST_32_BITS(pid_tgid);
+    exec_data.ppid = current_ppid();
+    exec_data.uid = current_uid();
 
     bpf_probe_read_user_str(exec_data.f_name, sizeof(exec_data.f_name), args->filename);
     bpf_get_current_comm(exec_data.comm, sizeof(exec_data.comm));
@@ -281,4 +348,3101 @@ int enter_execve(struct execve_entry_args_t *args){
     return 0;
 }
 
-char LICENSE[] SEC("license") = "GPL";
\ No newline at end of file
+// ----------------------------------------------------------------------------
+
+struct process_data_t {
+    __u32 type;
+    __u32 pid;
+    __u32 tid;
+    __u32 ppid;
+    __u32 child_pid;
+    __u32 uid;
+    int exit_code;
+    __u8 comm[DATA_SIZE_32];
+    __u8 filename[DATA_SIZE_128];
+};
+
+// For Rust libbpf-rs only
+struct process_data_t _pdt = {0};
+
+// CLONE_THREAD creates a thread in the thread group of the caller.
+#define CLONE_THREAD 0x00010000
+
+struct task_newtask_args_t {
+    __u64 _unused;
+
+    int pid;
+    char comm[16];
+    unsigned long clone_flags;
+    short oom_score_adj;
+};
+
+/**
+ *
+name: task_newtask
+ID: 130
+format:
+        field:unsigned short common_type;       offset:0;       size:2; signed:0;
+        field:unsigned char common_flags;       offset:2;       size:1; signed:0;
+        field:unsigned char common_preempt_count;       offset:3;       size:1; signed:0;
+        field:int common_pid;   offset:4;       size:4; signed:1;
+
+        field:pid_t pid;        offset:8;       size:4; signed:1;
+        field:char comm[16];    offset:12;      size:16;        signed:1;
+        field:unsigned long clone_flags;        offset:32;      size:8; signed:0;
+        field:short oom_score_adj;      offset:40;      size:2; signed:1;
+
+print fmt: "pid=%d comm=%s clone_flags=%lx oom_score_adj=%hd", REC->pid, REC->comm, REC->clone_flags, REC->oom_score_adj
+ */
+// task_newtask is sent for every new task once copy_process() can no
+// longer fail. The clone flags are known here, so new threads are
+// dropped in the kernel and every fork of a new process is sent, even
+// if the child exits before the event is read.
+SEC("tracepoint/task/task_newtask")
+int task_newtask(struct task_newtask_args_t *args){
+    struct process_data_t data = {};
+    __u64 pid_tgid;
+
+    if (args->clone_flags & CLONE_THREAD) {
+        return 0;
+    }
+    pid_tgid = bpf_get_current_pid_tgid();
+    data.type = EVENT_TYPE_FORK;
+    data.pid = FIRST_32_BITS(pid_tgid);
+    data.tid = LAST_32_BITS(pid_tgid);
+    data.ppid = current_ppid();
+    data.uid = current_uid();
+    data.child_pid = args->pid;
+
+    bpf_get_current_comm(data.comm, sizeof(data.comm));
+
+    // Send out on the perf event map
+    bpf_perf_event_output(args, &events, BPF_F_CURRENT_CPU, &data, sizeof(data));
+    if (DEBUG) bpf_printk("---tracepoint/task/task_newtask---");
+    return 0;
+}
+
+struct sched_process_exec_args_t {
+    __u64 _unused;
+
+    int __data_loc_filename;
+    int pid;
+    int old_pid;
+};
+
+/**
+ *
+name: sched_process_exec
+ID: 304
+format:
+        field:unsigned short common_type;       offset:0;       size:2; signed:0;
+        field:unsigned char common_flags;       offset:2;       size:1; signed:0;
+        field:unsigned char common_preempt_count;       offset:3;       size:1; signed:0;
+        field:int common_pid;   offset:4;       size:4; signed:1;
+
+        field:__data_loc char[] filename;       offset:8;       size:4; signed:1;
+        field:pid_t pid;        offset:12;      size:4; signed:1;
+        field:pid_t old_pid;    offset:16;      size:4; signed:1;
+
+print fmt: "filename=%s pid=%d old_pid=%d", __get_str(filename), REC->pid, REC->old_pid
+ */
+SEC("tracepoint/sched/sched_process_exec")
+int sched_process_exec(struct sched_process_exec_args_t *args){
+    struct process_data_t data = {};
+    __u64 pid_tgid;
+    unsigned short offset;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data.type = EVENT_TYPE_EXEC;
+    data.pid = FIRST_32_BITS(pid_tgid);
+    data.tid = LAST_32_BITS(pid_tgid);
+    data.ppid = current_ppid();
+    data.uid = current_uid();
+
+    // The lower 16 bits of a __data_loc field are the offset of the string in the record
+    offset = args->__data_loc_filename & 0xFFFF;
+    bpf_probe_read_kernel_str(data.filename, sizeof(data.filename), (void *)args + offset);
+    bpf_get_current_comm(data.comm, sizeof(data.comm));
+
+    // Send out on the perf event map
+    bpf_perf_event_output(args, &events, BPF_F_CURRENT_CPU, &data, sizeof(data));
+    if (DEBUG) bpf_printk("---tracepoint/sched/sched_process_exec---");
+    return 0;
+}
+
+struct sched_process_exit_args_t {
+    __u64 _unused;
+
+    char comm[16];
+    int pid;
+    int prio;
+};
+
+/**
+ *
+name: sched_process_exit
+ID: 305
+format:
+        field:unsigned short common_type;       offset:0;       size:2; signed:0;
+        field:unsigned char common_flags;       offset:2;       size:1; signed:0;
+        field:unsigned char common_preempt_count;       offset:3;       size:1; signed:0;
+        field:int common_pid;   offset:4;       size:4; signed:1;
+
+        field:char comm[16];    offset:8;       size:16;        signed:1;
+        field:pid_t pid;        offset:24;      size:4; signed:1;
+        field:int prio; offset:28;      size:4; signed:1;
+
+print fmt: "comm=%s pid=%d prio=%d", REC->comm, REC->pid, REC->prio
+ */
+SEC("tracepoint/sched/sched_process_exit")
+int sched_process_exit(struct sched_process_exit_args_t *args){
+    struct process_data_t data = {};
+    struct task_struct *task;
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data.type = EVENT_TYPE_EXIT;
+    data.pid = FIRST_32_BITS(pid_tgid);
+    data.tid = LAST_32_BITS(pid_tgid);
+
+    // Only the thread group leader exiting is the process exiting
+    if (data.pid != data.tid) {
+        return 0;
+    }
+    data.ppid = current_ppid();
+    data.uid = current_uid();
+
+    task = (struct task_struct *)bpf_get_current_task();
+    bpf_probe_read_kernel(&data.exit_code, sizeof(data.exit_code), &task->exit_code);
+    bpf_get_current_comm(data.comm, sizeof(data.comm));
+
+    // Send out on the perf event map
+    bpf_perf_event_output(args, &events, BPF_F_CURRENT_CPU, &data, sizeof(data));
+    if (DEBUG) bpf_printk("---tracepoint/sched/sched_process_exit---");
+    return 0;
+}
+
+// ----------------------------------------------------------------------------
+
+#define AT_FDCWD -100
+
+// FILE_PATH_DEPTH is the most directories walked to resolve a relative path.
+#define FILE_PATH_DEPTH 24
+#define FILE_PATH_MASK (DATA_SIZE_256 - 1)
+
+#ifndef container_of
+#define container_of(ptr, type, member) ((type *)((void *)(ptr) - __builtin_offsetof(type, member)))
+#endif
+
+// file_prefix_t is the key of the file_prefixes map. The prefixlen of a key is in bits.
+struct file_prefix_t {
+    __u32 prefixlen;
+    __u8 path[DATA_SIZE_256];
+};
+
+struct file_config_t {
+    __u32 filter;
+};
+
+struct file_open_data_t {
+    __u32 type;
+    __u32 pid;
+    __u32 ppid;
+    __u32 uid;
+    int dfd;
+    int flags;
+    __u32 mode;
+    int ret;
+    __u8 comm[DATA_SIZE_32];
+    __u8 filename[DATA_SIZE_256];
+};
+
+// For Rust libbpf-rs only
+struct file_open_data_t _fodt = {0};
+
+#define FILE_OP_UNLINK 1
+#define FILE_OP_RENAME 2
+#define FILE_OP_CHMOD 3
+#define FILE_OP_CHOWN 4
+#define FILE_OP_TRUNCATE 5
+
+struct file_change_data_t {
+    __u32 type;
+    __u32 op;
+    __u32 pid;
+    __u32 ppid;
+    __u32 uid;
+    __u32 mode;
+    __u32 owner;
+    __u32 group;
+    int flags;
+    int ret;
+    __s64 length;
+    __u8 comm[DATA_SIZE_32];
+    __u8 filename[DATA_SIZE_256];
+    __u8 newname[DATA_SIZE_256];
+};
+
+// For Rust libbpf-rs only
+struct file_change_data_t _fcdt = {0};
+
+#define MOUNT_OP_MOUNT 1
+#define MOUNT_OP_UMOUNT 2
+#define MOUNT_OP_FSOPEN 3
+#define MOUNT_OP_FSMOUNT 4
+#define MOUNT_OP_MOVE_MOUNT 5
+#define MOUNT_OP_OPEN_TREE 6
+
+struct mount_data_t {
+    __u32 type;
+    __u32 op;
+    __u32 pid;
+    __u32 ppid;
+    __u32 uid;
+    __u32 mnt_ns;
+    __u64 flags;
+    __u32 attr;
+    int ret;
+    __u8 comm[DATA_SIZE_32];
+    __u8 fstype[DATA_SIZE_32];
+    __u8 source[DATA_SIZE_256];
+    __u8 target[DATA_SIZE_256];
+};
+
+// For Rust libbpf-rs only
+struct mount_data_t _mdt = {0};
+
+// file_scratch_t is kept off the stack, which is limited to 512 bytes.
+// A directory is written backwards from the middle of buf, and the path
+// passed to the syscall is written forwards from the middle.
+struct file_scratch_t {
+    __u8 buf[DATA_SIZE_256 * 2];
+    struct file_prefix_t prefix;
+    struct file_open_data_t open;
+    struct file_change_data_t change;
+    struct mount_data_t mount;
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(max_entries, 1);
+    __type(key, __u32);
+    __type(value, struct file_scratch_t);
+} file_scratch SEC(".maps");
+
+// file_prefixes are the only paths sent to userspace, when the filter is on.
+struct {
+    __uint(type, BPF_MAP_TYPE_LPM_TRIE);
+    __uint(max_entries, 1024);
+    __uint(map_flags, BPF_F_NO_PREALLOC);
+    __type(key, struct file_prefix_t);
+    __type(value, __u8);
+} file_prefixes SEC(".maps");
+
+// file_config is set from userspace.
+struct {
+    __uint(type, BPF_MAP_TYPE_ARRAY);
+    __uint(max_entries, 1);
+    __type(key, __u32);
+    __type(value, struct file_config_t);
+} file_config SEC(".maps");
+
+// file_opens are every open, by pid_tgid, until the syscall returns and the file is filtered.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 10240);
+    __type(key, __u64);
+    __type(value, struct file_open_data_t);
+} file_opens SEC(".maps");
+
+// file_changes are the changes that passed the filter, by pid_tgid, until the syscall returns.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 10240);
+    __type(key, __u64);
+    __type(value, struct file_change_data_t);
+} file_changes SEC(".maps");
+
+// current_fd_file will return the file of a file descriptor of the current task.
+static __always_inline struct file *current_fd_file(int fd) {
+    struct task_struct *task = (struct task_struct *)bpf_get_current_task();
+    struct files_struct *files = 0;
+    struct fdtable *fdt = 0;
+    struct file **fds = 0;
+    struct file *file = 0;
+    unsigned int max_fds = 0;
+
+    if (fd < 0) {
+        return 0;
+    }
+    bpf_probe_read_kernel(&files, sizeof(files), &task->files);
+    bpf_probe_read_kernel(&fdt, sizeof(fdt), &files->fdt);
+    bpf_probe_read_kernel(&max_fds, sizeof(max_fds), &fdt->max_fds);
+    if (fd >= max_fds) {
+        return 0;
+    }
+    bpf_probe_read_kernel(&fds, sizeof(fds), &fdt->fd);
+    bpf_probe_read_kernel(&file, sizeof(file), &fds[fd]);
+    return file;
+}
+
+// file_path_at will read the path of the directory a relative path is
+// resolved against, which is the working directory for AT_FDCWD.
+static __always_inline int file_path_at(struct path *dir, int dfd) {
+    struct task_struct *task = (struct task_struct *)bpf_get_current_task();
+    struct fs_struct *fs = 0;
+    struct file *file = 0;
+
+    if (dfd == AT_FDCWD) {
+        bpf_probe_read_kernel(&fs, sizeof(fs), &task->fs);
+        return bpf_probe_read_kernel(dir, sizeof(*dir), &fs->pwd);
+    }
+    file = current_fd_file(dfd);
+    if (!file) {
+        return -1;
+    }
+    return bpf_probe_read_kernel(dir, sizeof(*dir), &file->f_path);
+}
+
+// file_path_dir will write the path of a directory backwards, ending at
+// the middle of buf, and return where it starts. The root directory is
+// empty. Mount points are crossed up to the root of the mount namespace.
+static __always_inline long file_path_dir(__u8 *buf, struct path *dir) {
+    struct dentry *dentry = dir->dentry;
+    struct dentry *parent = 0;
+    struct dentry *mnt_root = 0;
+    struct vfsmount *vfsmnt = dir->mnt;
+    struct mount *mnt = container_of(vfsmnt, struct mount, mnt);
+    struct mount *mnt_parent = 0;
+    struct qstr d_name = {};
+    long pos = DATA_SIZE_256;
+    __u32 len;
+
+#pragma unroll
+    for (int i = 0; i < FILE_PATH_DEPTH; i++) {
+        bpf_probe_read_kernel(&mnt_root, sizeof(mnt_root), &vfsmnt->mnt_root);
+        bpf_probe_read_kernel(&parent, sizeof(parent), &dentry->d_parent);
+        if (dentry == mnt_root || dentry == parent) {
+            bpf_probe_read_kernel(&mnt_parent, sizeof(mnt_parent), &mnt->mnt_parent);
+            if (dentry != mnt_root || mnt == mnt_parent) {
+                break;
+            }
+            // Continue from the directory the mount is on
+            bpf_probe_read_kernel(&dentry, sizeof(dentry), &mnt->mnt_mountpoint);
+            mnt = mnt_parent;
+            vfsmnt = &mnt->mnt;
+            continue;
+        }
+        bpf_probe_read_kernel(&d_name, sizeof(d_name), &dentry->d_name);
+        len = d_name.len & FILE_PATH_MASK;
+        if (len + 1 > pos) {
+            break;
+        }
+        pos -= len + 1;
+        bpf_probe_read_kernel(&buf[(pos + 1) & FILE_PATH_MASK], len, d_name.name);
+        buf[pos & FILE_PATH_MASK] = '/';
+        dentry = parent;
+    }
+    return pos;
+}
+
+// resolve_path will write the path of filename to dst, resolved against dfd
+// when it is relative, and return its length with the NUL. Symlinks, "." and
+// ".." are not resolved.
+static __always_inline long resolve_path(struct file_scratch_t *scratch, int dfd, const char *filename, __u8 *dst) {
+    struct path dir = {};
+    long pos = DATA_SIZE_256;
+    __u8 first = 0;
+
+    bpf_probe_read_user(&first, sizeof(first), filename);
+    if (first != '/' && file_path_at(&dir, dfd) == 0) {
+        pos = file_path_dir(scratch->buf, &dir);
+    }
+    if (first == '/' || pos != DATA_SIZE_256) {
+        // Absolute, or relative to a directory below the root
+        if (first != '/') {
+            scratch->buf[DATA_SIZE_256] = '/';
+            bpf_probe_read_user_str(&scratch->buf[DATA_SIZE_256 + 1], DATA_SIZE_256 - 1, filename);
+        } else {
+            bpf_probe_read_user_str(&scratch->buf[DATA_SIZE_256], DATA_SIZE_256, filename);
+        }
+    } else {
+        // Relative to the root, or unresolved
+        pos = DATA_SIZE_256 - 1;
+        scratch->buf[pos] = '/';
+        bpf_probe_read_user_str(&scratch->buf[DATA_SIZE_256], DATA_SIZE_256, filename);
+    }
+    return bpf_probe_read_kernel_str(dst, DATA_SIZE_256, &scratch->buf[pos & (DATA_SIZE_256 * 2 - 1)]);
+}
+
+// file_filter will return 1 if a path should be sent to userspace.
+static __always_inline int file_filter(struct file_scratch_t *scratch, __u8 *path, long len) {
+    struct file_config_t *config;
+    __u32 zero = 0;
+
+    config = bpf_map_lookup_elem(&file_config, &zero);
+    if (!config || !config->filter) {
+        return 1;
+    }
+    if (len <= 1) {
+        return 0;
+    }
+    // Match every byte of the path, without the NUL
+    scratch->prefix.prefixlen = (len - 1) * 8;
+    bpf_probe_read_kernel(scratch->prefix.path, sizeof(scratch->prefix.path), path);
+    return bpf_map_lookup_elem(&file_prefixes, &scratch->prefix) != 0;
+}
+
+static __always_inline int enter_open(int dfd, const char *filename, int flags, __u32 mode) {
+    struct file_scratch_t *scratch;
+    struct file_open_data_t *data;
+    __u64 pid_tgid;
+    __u32 zero = 0;
+    long len;
+
+    scratch = bpf_map_lookup_elem(&file_scratch, &zero);
+    if (!scratch) {
+        return 0;
+    }
+    data = &scratch->open;
+    // Replaced by the path of the opened file when the syscall returns
+    resolve_path(scratch, dfd, filename, data->filename);
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data->type = EVENT_TYPE_FILE_OPEN;
+    data->pid = FIRST_32_BITS(pid_tgid);
+    data->ppid = current_ppid();
+    data->uid = current_uid();
+    data->dfd = dfd;
+    data->flags = flags;
+    data->mode = mode;
+    data->ret = 0;
+    bpf_get_current_comm(data->comm, sizeof(data->comm));
+
+    // Sent when the syscall returns
+    bpf_map_update_elem(&file_opens, &pid_tgid, data, BPF_ANY);
+    return 0;
+}
+
+// exit_open will filter an open by the path of the file that was opened, so
+// symlinks, "." and ".." can not avoid a prefix. A failed open is filtered by
+// the path passed to the syscall.
+static __always_inline int exit_open(void *ctx, long ret) {
+    struct file_scratch_t *scratch;
+    struct file_open_data_t *data;
+    struct file *file = 0;
+    struct path path = {};
+    __u64 pid_tgid;
+    __u32 zero = 0;
+    long pos;
+    long len;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data = bpf_map_lookup_elem(&file_opens, &pid_tgid);
+    if (!data) {
+        return 0;
+    }
+    scratch = bpf_map_lookup_elem(&file_scratch, &zero);
+    if (!scratch) {
+        bpf_map_delete_elem(&file_opens, &pid_tgid);
+        return 0;
+    }
+    data->ret = ret;
+    file = current_fd_file(ret);
+    if (file && bpf_probe_read_kernel(&path, sizeof(path), &file->f_path) == 0) {
+        pos = file_path_dir(scratch->buf, &path);
+        if (pos == DATA_SIZE_256) {
+            // The root directory
+            pos = DATA_SIZE_256 - 1;
+            scratch->buf[pos] = '/';
+        }
+        scratch->buf[DATA_SIZE_256] = 0;
+        len = bpf_probe_read_kernel_str(data->filename, DATA_SIZE_256, &scratch->buf[pos & (DATA_SIZE_256 * 2 - 1)]);
+    } else {
+        len = bpf_probe_read_kernel_str(scratch->buf, DATA_SIZE_256, data->filename);
+    }
+    if (file_filter(scratch, data->filename, len)) {
+        // Send out on the perf event map
+        bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, data, sizeof(*data));
+    }
+    bpf_map_delete_elem(&file_opens, &pid_tgid);
+    if (DEBUG) bpf_printk("---tracepoint/syscalls/sys_exit_openat---");
+    return 0;
+}
+
+struct sys_enter_openat_args_t {
+    __u64 _unused;
+    __u64 _unused2;
+
+    __s64 dfd;
+    const char *filename;
+    __s64 flags;
+    __u64 mode;
+};
+
+/**
+ *
+name: sys_enter_openat
+format:
+        field:unsigned short common_type;       offset:0;       size:2; signed:0;
+        field:unsigned char common_flags;       offset:2;       size:1; signed:0;
+        field:unsigned char common_preempt_count;       offset:3;       size:1; signed:0;
+        field:int common_pid;   offset:4;       size:4; signed:1;
+
+        field:int __syscall_nr; offset:8;       size:4; signed:1;
+        field:int dfd;  offset:16;      size:8; signed:0;
+        field:const char __attribute__((user)) * filename;      offset:24;      size:8; signed:0;
+        field:int flags;        offset:32;      size:8; signed:0;
+        field:umode_t mode;     offset:40;      size:8; signed:0;
+
+print fmt: "dfd: 0x%08lx, filename: 0x%08lx, flags: 0x%08lx, mode: 0x%08lx", ((unsigned long)(REC->dfd)), ((unsigned long)(REC->filename)), ((unsigned long)(REC->flags)), ((unsigned long)(REC->mode))
+ */
+SEC("tracepoint/syscalls/sys_enter_openat")
+int enter_openat(struct sys_enter_openat_args_t *args){
+    return enter_open(args->dfd, args->filename, args->flags, args->mode);
+}
+
+struct sys_enter_openat2_args_t {
+    __u64 _unused;
+    __u64 _unused2;
+
+    __s64 dfd;
+    const char *filename;
+    struct open_how *how;
+    __u64 usize;
+};
+
+/**
+ *
+name: sys_enter_openat2
+format:
+        field:unsigned short common_type;       offset:0;       size:2; signed:0;
+        field:unsigned char common_flags;       offset:2;       size:1; signed:0;
+        field:unsigned char common_preempt_count;       offset:3;       size:1; signed:0;
+        field:int common_pid;   offset:4;       size:4; signed:1;
+
+        field:int __syscall_nr; offset:8;       size:4; signed:1;
+        field:int dfd;  offset:16;      size:8; signed:0;
+        field:const char __attribute__((user)) * filename;      offset:24;      size:8; signed:0;
+        field:struct open_how __attribute__((user)) * how;      offset:32;      size:8; signed:0;
+        field:size_t usize;     offset:40;      size:8; signed:0;
+
+print fmt: "dfd: 0x%08lx, filename: 0x%08lx, how: 0x%08lx, usize: 0x%08lx", ((unsigned long)(REC->dfd)), ((unsigned long)(REC->filename)), ((unsigned long)(REC->how)), ((unsigned long)(REC->usize))
+ */
+SEC("tracepoint/syscalls/sys_enter_openat2")
+int enter_openat2(struct sys_enter_openat2_args_t *args){
+    struct open_how how = {};
+
+    bpf_probe_read_user(&how, sizeof(how), args->how);
+    return enter_open(args->dfd, args->filename, how.flags, how.mode);
+}
+
+struct sys_exit_args_t {
+    __u64 _unused;
+    __u64 _unused2;
+
+    __s64 ret;
+};
+
+/**
+ *
+name: sys_exit_openat
+format:
+        field:unsigned short common_type;       offset:0;       size:2; signed:0;
+        field:unsigned char common_flags;       offset:2;       size:1; signed:0;
+        field:unsigned char common_preempt_count;       offset:3;       size:1; signed:0;
+        field:int common_pid;   offset:4;       size:4; signed:1;
+
+        field:int __syscall_nr; offset:8;       size:4; signed:1;
+        field:long ret; offset:16;      size:8; signed:1;
+
+print fmt: "0x%lx", REC->ret
+ */
+SEC("tracepoint/syscalls/sys_exit_openat")
+int exit_openat(struct sys_exit_args_t *args){
+    return exit_open(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_openat2")
+int exit_openat2(struct sys_exit_args_t *args){
+    return exit_open(args, args->ret);
+}
+
+// ----------------------------------------------------------------------------
+
+// enter_file_change will save a change to a path that passed the filter. Both
+// paths of a rename are resolved, and either one can pass the filter.
+//
+// Unlike an open, there is no file to read the path from when the syscall
+// returns, so the filter is on the path passed to the syscall. Symlinks, "."
+// and ".." are not resolved, and /tmp/../etc/shadow, or a symlink to it, does
+// not match the /etc/ prefix.
+static __always_inline int enter_file_change(__u32 op, int dfd, const char *filename, int newdfd, const char *newname,
+                                             __u32 mode, __u32 owner, __u32 group, int flags, __s64 length) {
+    struct file_scratch_t *scratch;
+    struct file_change_data_t *data;
+    __u64 pid_tgid;
+    __u32 zero = 0;
+    long len;
+    int match;
+
+    scratch = bpf_map_lookup_elem(&file_scratch, &zero);
+    if (!scratch) {
+        return 0;
+    }
+    data = &scratch->change;
+    len = resolve_path(scratch, dfd, filename, data->filename);
+    match = file_filter(scratch, data->filename, len);
+    data->newname[0] = 0;
+    if (newname) {
+        len = resolve_path(scratch, newdfd, newname, data->newname);
+        match = match || file_filter(scratch, data->newname, len);
+    }
+    if (!match) {
+        return 0;
+    }
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data->type = EVENT_TYPE_FILE_CHANGE;
+    data->op = op;
+    data->pid = FIRST_32_BITS(pid_tgid);
+    data->ppid = current_ppid();
+    data->uid = current_uid();
+    data->mode = mode;
+    data->owner = owner;
+    data->group = group;
+    data->flags = flags;
+    data->length = length;
+    data->ret = 0;
+    bpf_get_current_comm(data->comm, sizeof(data->comm));
+
+    // Sent when the syscall returns
+    bpf_map_update_elem(&file_changes, &pid_tgid, data, BPF_ANY);
+    return 0;
+}
+
+static __always_inline int exit_file_change(void *ctx, long ret) {
+    struct file_change_data_t *data;
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data = bpf_map_lookup_elem(&file_changes, &pid_tgid);
+    if (!data) {
+        return 0;
+    }
+    data->ret = ret;
+
+    // Send out on the perf event map
+    bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, data, sizeof(*data));
+    bpf_map_delete_elem(&file_changes, &pid_tgid);
+    if (DEBUG) bpf_printk("---tracepoint/syscalls/sys_exit_file_change---");
+    return 0;
+}
+
+// sys_enter_args_t is the format of every sys_enter tracepoint. Every
+// argument is 8 bytes, starting at offset 16.
+struct sys_enter_args_t {
+    __u64 _unused;
+    __u64 _unused2;
+
+    __u64 args[6];
+};
+
+// unlink(const char *pathname)
+SEC("tracepoint/syscalls/sys_enter_unlink")
+int enter_unlink(struct sys_enter_args_t *args){
+    return enter_file_change(FILE_OP_UNLINK, AT_FDCWD, (const char *)args->args[0], 0, 0, 0, 0, 0, 0, 0);
+}
+
+// unlinkat(int dfd, const char *pathname, int flag)
+SEC("tracepoint/syscalls/sys_enter_unlinkat")
+int enter_unlinkat(struct sys_enter_args_t *args){
+    return enter_file_change(FILE_OP_UNLINK, args->args[0], (const char *)args->args[1], 0, 0, 0, 0, 0, args->args[2], 0);
+}
+
+// rename(const char *oldname, const char *newname)
+SEC("tracepoint/syscalls/sys_enter_rename")
+int enter_rename(struct sys_enter_args_t *args){
+    return enter_file_change(FILE_OP_RENAME, AT_FDCWD, (const char *)args->args[0], AT_FDCWD, (const char *)args->args[1], 0, 0, 0, 0, 0);
+}
+
+// renameat(int olddfd, const char *oldname, int newdfd, const char *newname)
+SEC("tracepoint/syscalls/sys_enter_renameat")
+int enter_renameat(struct sys_enter_args_t *args){
+    return enter_file_change(FILE_OP_RENAME, args->args[0], (const char *)args->args[1], args->args[2], (const char *)args->args[3], 0, 0, 0, 0, 0);
+}
+
+// renameat2(int olddfd, const char *oldname, int newdfd, const char *newname, unsigned int flags)
+SEC("tracepoint/syscalls/sys_enter_renameat2")
+int enter_renameat2(struct sys_enter_args_t *args){
+    return enter_file_change(FILE_OP_RENAME, args->args[0], (const char *)args->args[1], args->args[2], (const char *)args->args[3], 0, 0, 0, args->args[4], 0);
+}
+
+// chmod(const char *filename, umode_t mode)
+SEC("tracepoint/syscalls/sys_enter_chmod")
+int enter_chmod(struct sys_enter_args_t *args){
+    return enter_file_change(FILE_OP_CHMOD, AT_FDCWD, (const char *)args->args[0], 0, 0, args->args[1], 0, 0, 0, 0);
+}
+
+// fchmodat(int dfd, const char *filename, umode_t mode)
+SEC("tracepoint/syscalls/sys_enter_fchmodat")
+int enter_fchmodat(struct sys_enter_args_t *args){
+    return enter_file_change(FILE_OP_CHMOD, args->args[0], (const char *)args->args[1], 0, 0, args->args[2], 0, 0, 0, 0);
+}
+
+// chown(const char *filename, uid_t user, gid_t group)
+SEC("tracepoint/syscalls/sys_enter_chown")
+int enter_chown(struct sys_enter_args_t *args){
+    return enter_file_change(FILE_OP_CHOWN, AT_FDCWD, (const char *)args->args[0], 0, 0, 0, args->args[1], args->args[2], 0, 0);
+}
+
+// fchownat(int dfd, const char *filename, uid_t user, gid_t group, int flag)
+SEC("tracepoint/syscalls/sys_enter_fchownat")
+int enter_fchownat(struct sys_enter_args_t *args){
+    return enter_file_change(FILE_OP_CHOWN, args->args[0], (const char *)args->args[1], 0, 0, 0, args->args[2], args->args[3], args->args[4], 0);
+}
+
+// truncate(const char *path, long length)
+SEC("tracepoint/syscalls/sys_enter_truncate")
+int enter_truncate(struct sys_enter_args_t *args){
+    return enter_file_change(FILE_OP_TRUNCATE, AT_FDCWD, (const char *)args->args[0], 0, 0, 0, 0, 0, 0, args->args[1]);
+}
+
+SEC("tracepoint/syscalls/sys_exit_unlink")
+int exit_unlink(struct sys_exit_args_t *args){
+    return exit_file_change(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_unlinkat")
+int exit_unlinkat(struct sys_exit_args_t *args){
+    return exit_file_change(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_rename")
+int exit_rename(struct sys_exit_args_t *args){
+    return exit_file_change(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_renameat")
+int exit_renameat(struct sys_exit_args_t *args){
+    return exit_file_change(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_renameat2")
+int exit_renameat2(struct sys_exit_args_t *args){
+    return exit_file_change(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_chmod")
+int exit_chmod(struct sys_exit_args_t *args){
+    return exit_file_change(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_fchmodat")
+int exit_fchmodat(struct sys_exit_args_t *args){
+    return exit_file_change(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_chown")
+int exit_chown(struct sys_exit_args_t *args){
+    return exit_file_change(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_fchownat")
+int exit_fchownat(struct sys_exit_args_t *args){
+    return exit_file_change(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_truncate")
+int exit_truncate(struct sys_exit_args_t *args){
+    return exit_file_change(args, args->ret);
+}
+
+// ----------------------------------------------------------------------------
+
+// mounts are the mount syscalls, by pid_tgid, until the syscall returns.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 1024);
+    __type(key, __u64);
+    __type(value, struct mount_data_t);
+} mounts SEC(".maps");
+
+// current_mnt_ns will return the inode of the mount namespace of the current task.
+static __always_inline __u32 current_mnt_ns() {
+    struct task_struct *task = (struct task_struct *)bpf_get_current_task();
+    struct nsproxy *nsproxy = 0;
+    struct mnt_namespace *mnt_ns = 0;
+    __u32 inum = 0;
+
+    bpf_probe_read_kernel(&nsproxy, sizeof(nsproxy), &task->nsproxy);
+    bpf_probe_read_kernel(&mnt_ns, sizeof(mnt_ns), &nsproxy->mnt_ns);
+    bpf_probe_read_kernel(&inum, sizeof(inum), &mnt_ns->ns.inum);
+    return inum;
+}
+
+// enter_mount_syscall will save a mount syscall. The source is a path for open_tree()
+// and move_mount(), and is otherwise read as is, as it is often not a path
+// (proc, tmpfs, overlay). The target is always a path.
+static __always_inline int enter_mount_syscall(__u32 op, int sdfd, const char *source, int tdfd, const char *target,
+                                               const char *fstype, __u64 flags, __u32 attr) {
+    struct file_scratch_t *scratch;
+    struct mount_data_t *data;
+    __u64 pid_tgid;
+    __u32 zero = 0;
+
+    scratch = bpf_map_lookup_elem(&file_scratch, &zero);
+    if (!scratch) {
+        return 0;
+    }
+    data = &scratch->mount;
+    data->source[0] = 0;
+    data->target[0] = 0;
+    data->fstype[0] = 0;
+    if (source && (op == MOUNT_OP_OPEN_TREE || op == MOUNT_OP_MOVE_MOUNT)) {
+        resolve_path(scratch, sdfd, source, data->source);
+    } else if (source) {
+        bpf_probe_read_user_str(data->source, sizeof(data->source), source);
+    }
+    if (target) {
+        resolve_path(scratch, tdfd, target, data->target);
+    }
+    if (fstype) {
+        bpf_probe_read_user_str(data->fstype, sizeof(data->fstype), fstype);
+    }
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data->type = EVENT_TYPE_MOUNT;
+    data->op = op;
+    data->pid = FIRST_32_BITS(pid_tgid);
+    data->ppid = current_ppid();
+    data->uid = current_uid();
+    data->mnt_ns = current_mnt_ns();
+    data->flags = flags;
+    data->attr = attr;
+    data->ret = 0;
+    bpf_get_current_comm(data->comm, sizeof(data->comm));
+
+    // Sent when the syscall returns
+    bpf_map_update_elem(&mounts, &pid_tgid, data, BPF_ANY);
+    return 0;
+}
+
+static __always_inline int exit_mount_syscall(void *ctx, long ret) {
+    struct mount_data_t *data;
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data = bpf_map_lookup_elem(&mounts, &pid_tgid);
+    if (!data) {
+        return 0;
+    }
+    data->ret = ret;
+
+    // Send out on the perf event map
+    bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, data, sizeof(*data));
+    bpf_map_delete_elem(&mounts, &pid_tgid);
+    if (DEBUG) bpf_printk("---tracepoint/syscalls/sys_exit_mount---");
+    return 0;
+}
+
+// mount(char *dev_name, char *dir_name, char *type, unsigned long flags, void *data)
+SEC("tracepoint/syscalls/sys_enter_mount")
+int enter_mount(struct sys_enter_args_t *args){
+    return enter_mount_syscall(MOUNT_OP_MOUNT, AT_FDCWD, (const char *)args->args[0], AT_FDCWD, (const char *)args->args[1],
+                               (const char *)args->args[2], args->args[3], 0);
+}
+
+// umount2(char *name, int flags)
+SEC("tracepoint/syscalls/sys_enter_umount")
+int enter_umount(struct sys_enter_args_t *args){
+    return enter_mount_syscall(MOUNT_OP_UMOUNT, 0, 0, AT_FDCWD, (const char *)args->args[0], 0, args->args[1], 0);
+}
+
+// fsopen(const char *fs_name, unsigned int flags)
+SEC("tracepoint/syscalls/sys_enter_fsopen")
+int enter_fsopen(struct sys_enter_args_t *args){
+    return enter_mount_syscall(MOUNT_OP_FSOPEN, 0, 0, 0, 0, (const char *)args->args[0], args->args[1], 0);
+}
+
+// fsmount(int fs_fd, unsigned int flags, unsigned int attr_flags)
+SEC("tracepoint/syscalls/sys_enter_fsmount")
+int enter_fsmount(struct sys_enter_args_t *args){
+    return enter_mount_syscall(MOUNT_OP_FSMOUNT, 0, 0, 0, 0, 0, args->args[1], args->args[2]);
+}
+
+// move_mount(int from_dfd, const char *from_pathname, int to_dfd, const char *to_pathname, unsigned int flags)
+SEC("tracepoint/syscalls/sys_enter_move_mount")
+int enter_move_mount(struct sys_enter_args_t *args){
+    return enter_mount_syscall(MOUNT_OP_MOVE_MOUNT, args->args[0], (const char *)args->args[1], args->args[2], (const char *)args->args[3],
+                               0, args->args[4], 0);
+}
+
+// open_tree(int dfd, const char *filename, unsigned flags)
+SEC("tracepoint/syscalls/sys_enter_open_tree")
+int enter_open_tree(struct sys_enter_args_t *args){
+    return enter_mount_syscall(MOUNT_OP_OPEN_TREE, args->args[0], (const char *)args->args[1], 0, 0, 0, args->args[2], 0);
+}
+
+SEC("tracepoint/syscalls/sys_exit_mount")
+int exit_mount(struct sys_exit_args_t *args){
+    return exit_mount_syscall(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_umount")
+int exit_umount(struct sys_exit_args_t *args){
+    return exit_mount_syscall(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_fsopen")
+int exit_fsopen(struct sys_exit_args_t *args){
+    return exit_mount_syscall(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_fsmount")
+int exit_fsmount(struct sys_exit_args_t *args){
+    return exit_mount_syscall(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_move_mount")
+int exit_move_mount(struct sys_exit_args_t *args){
+    return exit_mount_syscall(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_open_tree")
+int exit_open_tree(struct sys_exit_args_t *args){
+    return exit_mount_syscall(args, args->ret);
+}
+
+// ----------------------------------------------------------------------------
+
+#define PRIV_OP_SETUID 1
+#define PRIV_OP_SETREUID 2
+#define PRIV_OP_SETRESUID 3
+#define PRIV_OP_SETFSUID 4
+#define PRIV_OP_SETGID 5
+#define PRIV_OP_SETREGID 6
+#define PRIV_OP_SETRESGID 7
+#define PRIV_OP_SETFSGID 8
+#define PRIV_OP_SETGROUPS 9
+#define PRIV_OP_CAPSET 10
+#define PRIV_OP_EXEC 11
+#define PRIV_OP_UNSHARE 12
+#define PRIV_OP_SETNS 13
+#define PRIV_OP_CLONE 14
+#define PRIV_OP_CLONE3 15
+
+// The CLONE_NEW* flags of clone(), clone3() and unshare()
+#define PRIV_CLONE_NEWNS 0x00020000
+#define PRIV_CLONE_NEWCGROUP 0x02000000
+#define PRIV_CLONE_NEWUTS 0x04000000
+#define PRIV_CLONE_NEWIPC 0x08000000
+#define PRIV_CLONE_NEWUSER 0x10000000
+#define PRIV_CLONE_NEWPID 0x20000000
+#define PRIV_CLONE_NEWNET 0x40000000
+#define PRIV_CLONE_NEWTIME 0x00000080
+#define PRIV_CLONE_NEW_MASK (PRIV_CLONE_NEWNS | PRIV_CLONE_NEWCGROUP | PRIV_CLONE_NEWUTS | PRIV_CLONE_NEWIPC | \
+                             PRIV_CLONE_NEWUSER | PRIV_CLONE_NEWPID | PRIV_CLONE_NEWNET | PRIV_CLONE_NEWTIME)
+
+#define PRIV_GROUPS 16
+
+struct cred_data_t {
+    __u32 uid;
+    __u32 gid;
+    __u32 euid;
+    __u32 egid;
+    __u32 suid;
+    __u32 sgid;
+    __u32 fsuid;
+    __u32 fsgid;
+    __u64 cap_inheritable;
+    __u64 cap_permitted;
+    __u64 cap_effective;
+};
+
+struct priv_data_t {
+    __u32 type;
+    __u32 op;
+    __u32 pid;
+    __u32 ppid;
+    int ret;
+    __u32 ngroups;
+    __u64 args[3];
+    struct cred_data_t old;
+    struct cred_data_t new;
+    __u32 groups[PRIV_GROUPS];
+    __u8 comm[DATA_SIZE_32];
+    __u8 filename[DATA_SIZE_128];
+};
+
+// For Rust libbpf-rs only
+struct priv_data_t _prdt = {0};
+
+// Kept off the stack, which is limited to 512 bytes.
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(max_entries, 1);
+    __type(key, __u32);
+    __type(value, struct priv_data_t);
+} priv_scratch SEC(".maps");
+
+// privs are the credential syscalls, by pid_tgid, until the syscall returns.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 10240);
+    __type(key, __u64);
+    __type(value, struct priv_data_t);
+} privs SEC(".maps");
+
+// exec_creds are the credentials of a task before exec(), by pid_tgid. A
+// failed exec() is never removed, so the oldest entries are evicted.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __uint(max_entries, 10240);
+    __type(key, __u64);
+    __type(value, struct cred_data_t);
+} exec_creds SEC(".maps");
+
+// current_creds will read the credentials of the current task. kernel_cap_t
+// is 64 bits, as either __u32 cap[2] or __u64 val, depending on the kernel.
+static __always_inline void current_creds(struct cred_data_t *data) {
+    struct task_struct *task = (struct task_struct *)bpf_get_current_task();
+    const struct cred *cred = 0;
+
+    bpf_probe_read_kernel(&cred, sizeof(cred), &task->cred);
+    bpf_probe_read_kernel(&data->uid, sizeof(data->uid), &cred->uid);
+    bpf_probe_read_kernel(&data->gid, sizeof(data->gid), &cred->gid);
+    bpf_probe_read_kernel(&data->euid, sizeof(data->euid), &cred->euid);
+    bpf_probe_read_kernel(&data->egid, sizeof(data->egid), &cred->egid);
+    bpf_probe_read_kernel(&data->suid, sizeof(data->suid), &cred->suid);
+    bpf_probe_read_kernel(&data->sgid, sizeof(data->sgid), &cred->sgid);
+    bpf_probe_read_kernel(&data->fsuid, sizeof(data->fsuid), &cred->fsuid);
+    bpf_probe_read_kernel(&data->fsgid, sizeof(data->fsgid), &cred->fsgid);
+    bpf_probe_read_kernel(&data->cap_inheritable, sizeof(data->cap_inheritable), &cred->cap_inheritable);
+    bpf_probe_read_kernel(&data->cap_permitted, sizeof(data->cap_permitted), &cred->cap_permitted);
+    bpf_probe_read_kernel(&data->cap_effective, sizeof(data->cap_effective), &cred->cap_effective);
+}
+
+static __always_inline int enter_priv(__u32 op, __u64 arg0, __u64 arg1, __u64 arg2) {
+    struct priv_data_t *data;
+    __u64 pid_tgid;
+    __u32 zero = 0;
+
+    data = bpf_map_lookup_elem(&priv_scratch, &zero);
+    if (!data) {
+        return 0;
+    }
+    pid_tgid = bpf_get_current_pid_tgid();
+    data->type = EVENT_TYPE_PRIVILEGE;
+    data->op = op;
+    data->pid = FIRST_32_BITS(pid_tgid);
+    data->ppid = current_ppid();
+    data->ret = 0;
+    data->ngroups = 0;
+    data->args[0] = arg0;
+    data->args[1] = arg1;
+    data->args[2] = arg2;
+    data->filename[0] = 0;
+    current_creds(&data->old);
+    if (op == PRIV_OP_SETGROUPS) {
+        // setgroups(int gidsetsize, gid_t *grouplist)
+        data->ngroups = arg0;
+        bpf_probe_read_user(data->groups, sizeof(data->groups), (void *)arg1);
+    }
+    bpf_get_current_comm(data->comm, sizeof(data->comm));
+
+    // Sent when the syscall returns
+    bpf_map_update_elem(&privs, &pid_tgid, data, BPF_ANY);
+    return 0;
+}
+
+static __always_inline int exit_priv(void *ctx, long ret) {
+    struct priv_data_t *data;
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data = bpf_map_lookup_elem(&privs, &pid_tgid);
+    if (!data) {
+        return 0;
+    }
+    data->ret = ret;
+    current_creds(&data->new);
+
+    // Send out on the perf event map
+    bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, data, sizeof(*data));
+    bpf_map_delete_elem(&privs, &pid_tgid);
+    if (DEBUG) bpf_printk("---tracepoint/syscalls/sys_exit_priv---");
+    return 0;
+}
+
+// setuid(uid_t uid)
+SEC("tracepoint/syscalls/sys_enter_setuid")
+int enter_setuid(struct sys_enter_args_t *args){
+    return enter_priv(PRIV_OP_SETUID, args->args[0], 0, 0);
+}
+
+// setreuid(uid_t ruid, uid_t euid)
+SEC("tracepoint/syscalls/sys_enter_setreuid")
+int enter_setreuid(struct sys_enter_args_t *args){
+    return enter_priv(PRIV_OP_SETREUID, args->args[0], args->args[1], 0);
+}
+
+// setresuid(uid_t ruid, uid_t euid, uid_t suid)
+SEC("tracepoint/syscalls/sys_enter_setresuid")
+int enter_setresuid(struct sys_enter_args_t *args){
+    return enter_priv(PRIV_OP_SETRESUID, args->args[0], args->args[1], args->args[2]);
+}
+
+// setfsuid(uid_t uid)
+SEC("tracepoint/syscalls/sys_enter_setfsuid")
+int enter_setfsuid(struct sys_enter_args_t *args){
+    return enter_priv(PRIV_OP_SETFSUID, args->args[0], 0, 0);
+}
+
+// setgid(gid_t gid)
+SEC("tracepoint/syscalls/sys_enter_setgid")
+int enter_setgid(struct sys_enter_args_t *args){
+    return enter_priv(PRIV_OP_SETGID, args->args[0], 0, 0);
+}
+
+// setregid(gid_t rgid, gid_t egid)
+SEC("tracepoint/syscalls/sys_enter_setregid")
+int enter_setregid(struct sys_enter_args_t *args){
+    return enter_priv(PRIV_OP_SETREGID, args->args[0], args->args[1], 0);
+}
+
+// setresgid(gid_t rgid, gid_t egid, gid_t sgid)
+SEC("tracepoint/syscalls/sys_enter_setresgid")
+int enter_setresgid(struct sys_enter_args_t *args){
+    return enter_priv(PRIV_OP_SETRESGID, args->args[0], args->args[1], args->args[2]);
+}
+
+// setfsgid(gid_t gid)
+SEC("tracepoint/syscalls/sys_enter_setfsgid")
+int enter_setfsgid(struct sys_enter_args_t *args){
+    return enter_priv(PRIV_OP_SETFSGID, args->args[0], 0, 0);
+}
+
+// setgroups(int gidsetsize, gid_t *grouplist)
+SEC("tracepoint/syscalls/sys_enter_setgroups")
+int enter_setgroups(struct sys_enter_args_t *args){
+    return enter_priv(PRIV_OP_SETGROUPS, args->args[0], args->args[1], 0);
+}
+
+// capset(cap_user_header_t header, const cap_user_data_t data)
+SEC("tracepoint/syscalls/sys_enter_capset")
+int enter_capset(struct sys_enter_args_t *args){
+    return enter_priv(PRIV_OP_CAPSET, 0, 0, 0);
+}
+
+SEC("tracepoint/syscalls/sys_exit_setuid")
+int exit_setuid(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_setreuid")
+int exit_setreuid(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_setresuid")
+int exit_setresuid(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_setfsuid")
+int exit_setfsuid(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_setgid")
+int exit_setgid(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_setregid")
+int exit_setregid(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_setresgid")
+int exit_setresgid(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_setfsgid")
+int exit_setfsgid(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_setgroups")
+int exit_setgroups(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_capset")
+int exit_capset(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+// unshare(int flags)
+SEC("tracepoint/syscalls/sys_enter_unshare")
+int enter_unshare(struct sys_enter_args_t *args){
+    return enter_priv(PRIV_OP_UNSHARE, args->args[0], 0, 0);
+}
+
+// setns(int fd, int nstype)
+SEC("tracepoint/syscalls/sys_enter_setns")
+int enter_setns(struct sys_enter_args_t *args){
+    return enter_priv(PRIV_OP_SETNS, args->args[0], args->args[1], 0);
+}
+
+// clone(unsigned long flags, ...) is only followed with a CLONE_NEW* flag.
+// The flags are the first argument on every architecture.
+SEC("tracepoint/syscalls/sys_enter_clone")
+int enter_clone_ns(struct sys_enter_args_t *args){
+    if ((args->args[0] & PRIV_CLONE_NEW_MASK) == 0) {
+        return 0;
+    }
+    return enter_priv(PRIV_OP_CLONE, args->args[0], 0, 0);
+}
+
+// clone3(struct clone_args *cl_args, size_t size)
+SEC("tracepoint/syscalls/sys_enter_clone3")
+int enter_clone3_ns(struct sys_enter_args_t *args){
+    __u64 flags = 0;
+
+    // flags is the first field of struct clone_args
+    bpf_probe_read_user(&flags, sizeof(flags), (void *)args->args[0]);
+    if ((flags & PRIV_CLONE_NEW_MASK) == 0) {
+        return 0;
+    }
+    return enter_priv(PRIV_OP_CLONE3, flags, 0, 0);
+}
+
+SEC("tracepoint/syscalls/sys_exit_unshare")
+int exit_unshare(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_setns")
+int exit_setns(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+// The child also returns from clone(), but only the parent is in privs.
+SEC("tracepoint/syscalls/sys_exit_clone")
+int exit_clone_ns(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_clone3")
+int exit_clone3_ns(struct sys_exit_args_t *args){
+    return exit_priv(args, args->ret);
+}
+
+// save_exec_creds will save the credentials of the current task before exec().
+static __always_inline int save_exec_creds() {
+    struct cred_data_t creds = {};
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    current_creds(&creds);
+    bpf_map_update_elem(&exec_creds, &pid_tgid, &creds, BPF_ANY);
+    return 0;
+}
+
+SEC("tracepoint/syscalls/sys_enter_execve")
+int enter_execve_creds(struct sys_enter_args_t *args){
+    return save_exec_creds();
+}
+
+SEC("tracepoint/syscalls/sys_enter_execveat")
+int enter_execveat_creds(struct sys_enter_args_t *args){
+    return save_exec_creds();
+}
+
+// exec_creds_changed will send the credentials before and after exec(), if
+// the effective IDs changed or capabilities were gained, such as a setuid or
+// setgid binary, or a binary with file capabilities.
+SEC("tracepoint/sched/sched_process_exec")
+int exec_creds_changed(struct sched_process_exec_args_t *args){
+    struct priv_data_t *data;
+    struct cred_data_t *old;
+    unsigned short offset;
+    __u64 pid_tgid;
+    __u64 key;
+    __u32 zero = 0;
+
+    // A thread that calls exec() takes the PID of the thread group leader
+    pid_tgid = bpf_get_current_pid_tgid();
+    key = (pid_tgid & 0xFFFFFFFF00000000) | (__u32)args->old_pid;
+    old = bpf_map_lookup_elem(&exec_creds, &key);
+    if (!old) {
+        return 0;
+    }
+    data = bpf_map_lookup_elem(&priv_scratch, &zero);
+    if (!data) {
+        return 0;
+    }
+    data->old = *old;
+    bpf_map_delete_elem(&exec_creds, &key);
+    current_creds(&data->new);
+    if (data->old.euid == data->new.euid &&
+        data->old.egid == data->new.egid &&
+        (data->new.cap_permitted & ~data->old.cap_permitted) == 0 &&
+        (data->new.cap_effective & ~data->old.cap_effective) == 0) {
+        return 0;
+    }
+
+    data->type = EVENT_TYPE_PRIVILEGE;
+    data->op = PRIV_OP_EXEC;
+    data->pid = FIRST_32_BITS(pid_tgid);
+    data->ppid = current_ppid();
+    data->ret = 0;
+    data->ngroups = 0;
+    data->args[0] = 0;
+    data->args[1] = 0;
+    data->args[2] = 0;
+
+    // The lower 16 bits of a __data_loc field are the offset of the string in the record
+    offset = args->__data_loc_filename & 0xFFFF;
+    bpf_probe_read_kernel_str(data->filename, sizeof(data->filename), (void *)args + offset);
+    bpf_get_current_comm(data->comm, sizeof(data->comm));
+
+    // Send out on the perf event map
+    bpf_perf_event_output(args, &events, BPF_F_CURRENT_CPU, data, sizeof(*data));
+    if (DEBUG) bpf_printk("---tracepoint/sched/sched_process_exec/creds---");
+    return 0;
+}
+
+// ----------------------------------------------------------------------------
+
+#define MODULE_OP_INIT 1
+#define MODULE_OP_FINIT 2
+#define MODULE_OP_DELETE 3
+
+struct module_data_t {
+    __u32 type;
+    __u32 op;
+    __u32 pid;
+    __u32 ppid;
+    __u32 uid;
+    int ret;
+    __u32 flags;
+    __u32 taints;
+    __u64 len;
+    __u8 comm[DATA_SIZE_32];
+    __u8 name[DATA_SIZE_64];
+    __u8 params[DATA_SIZE_128];
+    __u8 filename[DATA_SIZE_256];
+};
+
+// For Rust libbpf-rs only
+struct module_data_t _mdt = {0};
+
+struct module_scratch_t {
+    __u8 buf[DATA_SIZE_256 * 2];
+    struct module_data_t module;
+};
+
+// Kept off the stack, which is limited to 512 bytes.
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(max_entries, 1);
+    __type(key, __u32);
+    __type(value, struct module_scratch_t);
+} module_scratch SEC(".maps");
+
+// modules are the module syscalls, by pid_tgid, until the syscall returns.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 1024);
+    __type(key, __u64);
+    __type(value, struct module_data_t);
+} modules SEC(".maps");
+
+struct load_config_t {
+    __u32 self;
+};
+
+// load_config is set from userspace. Modules and BPF programs loaded
+// by the thread group in self are not sent.
+struct {
+    __uint(type, BPF_MAP_TYPE_ARRAY);
+    __uint(max_entries, 1);
+    __type(key, __u32);
+    __type(value, struct load_config_t);
+} load_config SEC(".maps");
+
+// load_excluded will return 1 if the current task is dse itself.
+static __always_inline int load_excluded(__u64 pid_tgid) {
+    struct load_config_t *config;
+    __u32 zero = 0;
+
+    config = bpf_map_lookup_elem(&load_config, &zero);
+    if (!config || !config->self) {
+        return 0;
+    }
+    return config->self == FIRST_32_BITS(pid_tgid);
+}
+
+// enter_module will save a module syscall. The name of a loaded module is
+// inside the ELF image, so it is only known when module_load fires.
+static __always_inline int enter_module(__u32 op, int fd, const char *name, const char *params, __u64 len, __u32 flags) {
+    struct module_scratch_t *scratch;
+    struct module_data_t *data;
+    struct path path = {};
+    __u64 pid_tgid;
+    __u32 zero = 0;
+    long pos;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    if (load_excluded(pid_tgid)) {
+        return 0;
+    }
+    scratch = bpf_map_lookup_elem(&module_scratch, &zero);
+    if (!scratch) {
+        return 0;
+    }
+    data = &scratch->module;
+    data->name[0] = 0;
+    data->params[0] = 0;
+    data->filename[0] = 0;
+    if (name) {
+        bpf_probe_read_user_str(data->name, sizeof(data->name), name);
+    }
+    if (params) {
+        bpf_probe_read_user_str(data->params, sizeof(data->params), params);
+    }
+    if (op == MODULE_OP_FINIT && file_path_at(&path, fd) == 0) {
+        // The path of the file ends at the middle of buf
+        scratch->buf[DATA_SIZE_256] = 0;
+        pos = file_path_dir(scratch->buf, &path);
+        bpf_probe_read_kernel_str(data->filename, sizeof(data->filename), &scratch->buf[pos & (DATA_SIZE_256 * 2 - 1)]);
+    }
+
+    data->type = EVENT_TYPE_MODULE;
+    data->op = op;
+    data->pid = FIRST_32_BITS(pid_tgid);
+    data->ppid = current_ppid();
+    data->uid = current_uid();
+    data->ret = 0;
+    data->flags = flags;
+    data->taints = 0;
+    data->len = len;
+    bpf_get_current_comm(data->comm, sizeof(data->comm));
+
+    // Sent when the syscall returns
+    bpf_map_update_elem(&modules, &pid_tgid, data, BPF_ANY);
+    return 0;
+}
+
+static __always_inline int exit_module(void *ctx, long ret) {
+    struct module_data_t *data;
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data = bpf_map_lookup_elem(&modules, &pid_tgid);
+    if (!data) {
+        return 0;
+    }
+    data->ret = ret;
+
+    // Send out on the perf event map
+    bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, data, sizeof(*data));
+    bpf_map_delete_elem(&modules, &pid_tgid);
+    if (DEBUG) bpf_printk("---tracepoint/syscalls/sys_exit_module---");
+    return 0;
+}
+
+// init_module(void *umod, unsigned long len, const char *uargs)
+SEC("tracepoint/syscalls/sys_enter_init_module")
+int enter_init_module(struct sys_enter_args_t *args){
+    return enter_module(MODULE_OP_INIT, -1, 0, (const char *)args->args[2], args->args[1], 0);
+}
+
+// finit_module(int fd, const char *uargs, int flags)
+SEC("tracepoint/syscalls/sys_enter_finit_module")
+int enter_finit_module(struct sys_enter_args_t *args){
+    return enter_module(MODULE_OP_FINIT, args->args[0], 0, (const char *)args->args[1], 0, args->args[2]);
+}
+
+// delete_module(const char *name_user, unsigned int flags)
+SEC("tracepoint/syscalls/sys_enter_delete_module")
+int enter_delete_module(struct sys_enter_args_t *args){
+    return enter_module(MODULE_OP_DELETE, -1, (const char *)args->args[0], 0, 0, args->args[1]);
+}
+
+SEC("tracepoint/syscalls/sys_exit_init_module")
+int exit_init_module(struct sys_exit_args_t *args){
+    return exit_module(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_finit_module")
+int exit_finit_module(struct sys_exit_args_t *args){
+    return exit_module(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_delete_module")
+int exit_delete_module(struct sys_exit_args_t *args){
+    return exit_module(args, args->ret);
+}
+
+// /sys/kernel/debug/tracing/events/module/module_load/format
+struct module_load_args_t {
+    __u64 _unused;
+
+    unsigned int taints;
+    int __data_loc_name;
+};
+
+// module_load will name the module of a pending init_module() or finit_module().
+SEC("tracepoint/module/module_load")
+int module_load(struct module_load_args_t *args){
+    struct module_data_t *data;
+    unsigned short offset;
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data = bpf_map_lookup_elem(&modules, &pid_tgid);
+    if (!data) {
+        return 0;
+    }
+    data->taints = args->taints;
+
+    // The lower 16 bits of a __data_loc field are the offset of the string in the record
+    offset = args->__data_loc_name & 0xFFFF;
+    bpf_probe_read_kernel_str(data->name, sizeof(data->name), (void *)args + offset);
+    return 0;
+}
+
+// ----------------------------------------------------------------------------
+
+#define BPF_CMD_PROG_LOAD 5
+#define BPF_CMD_PROG_ATTACH 8
+#define BPF_CMD_PROG_DETACH 9
+#define BPF_CMD_RAW_TRACEPOINT_OPEN 17
+#define BPF_CMD_LINK_CREATE 28
+
+#define BPF_PROG_NAME_LEN 16
+
+struct bpf_data_t {
+    __u32 type;
+    __u32 pid;
+    __u32 ppid;
+    __u32 uid;
+    int ret;
+    __u32 cmd;
+    __u32 prog_type;
+    __u32 attach_type;
+    __u32 insn_cnt;
+    __u32 prog_fd;
+    __u32 target_fd;
+    __u32 prog_flags;
+    __u8 comm[DATA_SIZE_32];
+    __u8 prog_name[BPF_PROG_NAME_LEN];
+    __u8 tracepoint[DATA_SIZE_64];
+};
+
+// For Rust libbpf-rs only
+struct bpf_data_t _bdt = {0};
+
+// bpf_calls are the bpf() syscalls that load or attach a program, by
+// pid_tgid, until the syscall returns.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 1024);
+    __type(key, __u64);
+    __type(value, struct bpf_data_t);
+} bpf_calls SEC(".maps");
+
+// bpf(int cmd, union bpf_attr *attr, unsigned int size)
+//
+// Only the commands that load, attach or detach a program are saved. Map
+// lookups and updates are far too frequent, and not interesting.
+SEC("tracepoint/syscalls/sys_enter_bpf")
+int enter_bpf(struct sys_enter_args_t *args){
+    struct bpf_data_t data = {};
+    union bpf_attr *attr = (union bpf_attr *)args->args[1];
+    __u64 name = 0;
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    if (load_excluded(pid_tgid)) {
+        return 0;
+    }
+    data.cmd = args->args[0];
+    switch (data.cmd) {
+    case BPF_CMD_PROG_LOAD:
+        bpf_probe_read_user(&data.prog_type, sizeof(data.prog_type), &attr->prog_type);
+        bpf_probe_read_user(&data.insn_cnt, sizeof(data.insn_cnt), &attr->insn_cnt);
+        bpf_probe_read_user(&data.prog_flags, sizeof(data.prog_flags), &attr->prog_flags);
+        bpf_probe_read_user(&data.attach_type, sizeof(data.attach_type), &attr->expected_attach_type);
+        bpf_probe_read_user(data.prog_name, sizeof(data.prog_name), attr->prog_name);
+        break;
+    case BPF_CMD_PROG_ATTACH:
+    case BPF_CMD_PROG_DETACH:
+        bpf_probe_read_user(&data.target_fd, sizeof(data.target_fd), &attr->target_fd);
+        bpf_probe_read_user(&data.prog_fd, sizeof(data.prog_fd), &attr->attach_bpf_fd);
+        bpf_probe_read_user(&data.attach_type, sizeof(data.attach_type), &attr->attach_type);
+        break;
+    case BPF_CMD_RAW_TRACEPOINT_OPEN:
+        bpf_probe_read_user(&name, sizeof(name), &attr->raw_tracepoint.name);
+        bpf_probe_read_user(&data.prog_fd, sizeof(data.prog_fd), &attr->raw_tracepoint.prog_fd);
+        if (name) {
+            bpf_probe_read_user_str(data.tracepoint, sizeof(data.tracepoint), (void *)name);
+        }
+        break;
+    case BPF_CMD_LINK_CREATE:
+        bpf_probe_read_user(&data.prog_fd, sizeof(data.prog_fd), &attr->link_create.prog_fd);
+        bpf_probe_read_user(&data.target_fd, sizeof(data.target_fd), &attr->link_create.target_fd);
+        bpf_probe_read_user(&data.attach_type, sizeof(data.attach_type), &attr->link_create.attach_type);
+        break;
+    default:
+        return 0;
+    }
+
+    data.type = EVENT_TYPE_BPF;
+    data.pid = FIRST_32_BITS(pid_tgid);
+    data.ppid = current_ppid();
+    data.uid = current_uid();
+    bpf_get_current_comm(data.comm, sizeof(data.comm));
+
+    // Sent when the syscall returns
+    bpf_map_update_elem(&bpf_calls, &pid_tgid, &data, BPF_ANY);
+    return 0;
+}
+
+SEC("tracepoint/syscalls/sys_exit_bpf")
+int exit_bpf(struct sys_exit_args_t *args){
+    struct bpf_data_t *data;
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data = bpf_map_lookup_elem(&bpf_calls, &pid_tgid);
+    if (!data) {
+        return 0;
+    }
+    data->ret = args->ret;
+
+    // Send out on the perf event map
+    bpf_perf_event_output(args, &events, BPF_F_CURRENT_CPU, data, sizeof(*data));
+    bpf_map_delete_elem(&bpf_calls, &pid_tgid);
+    if (DEBUG) bpf_printk("---tracepoint/syscalls/sys_exit_bpf---");
+    return 0;
+}
+
+// ----------------------------------------------------------------------------
+
+#define ACCESS_OP_PTRACE 1
+#define ACCESS_OP_VM_READV 2
+#define ACCESS_OP_VM_WRITEV 3
+#define ACCESS_OP_PROC_MEM 4
+
+// Requests of ptrace() that are sent. The rest, such as PTRACE_PEEKDATA
+// and PTRACE_SYSCALL, are sent thousands of times by every debugger.
+#define PTRACE_TRACEME 0
+#define PTRACE_POKETEXT 4
+#define PTRACE_POKEDATA 5
+#define PTRACE_POKEUSR 6
+#define PTRACE_SETREGS 13
+#define PTRACE_ATTACH 16
+#define PTRACE_SEIZE 0x4206
+
+// The most digits of a PID in /proc/<pid>/mem
+#define PROC_PID_DIGITS 10
+
+struct process_access_data_t {
+    __u32 type;
+    __u32 op;
+    __u32 pid;
+    __u32 ppid;
+    __u32 uid;
+    int target;
+    __s64 ret;
+    __u64 request;
+    __u64 addr;
+    __u32 flags;
+    __u32 _pad;
+    __u8 comm[DATA_SIZE_32];
+    __u8 filename[DATA_SIZE_64];
+};
+
+// For Rust libbpf-rs only
+struct process_access_data_t _padt = {0};
+
+// process_accesses are the syscalls that access another process, by
+// pid_tgid, until the syscall returns.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 1024);
+    __type(key, __u64);
+    __type(value, struct process_access_data_t);
+} process_accesses SEC(".maps");
+
+static __always_inline int enter_process_access(struct process_access_data_t *data, __u32 op, int target, __u64 request,
+                                                __u64 addr, __u32 flags) {
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data->type = EVENT_TYPE_PROCESS_ACCESS;
+    data->op = op;
+    data->pid = FIRST_32_BITS(pid_tgid);
+    data->ppid = current_ppid();
+    data->uid = current_uid();
+    data->target = target;
+    data->ret = 0;
+    data->request = request;
+    data->addr = addr;
+    data->flags = flags;
+    bpf_get_current_comm(data->comm, sizeof(data->comm));
+
+    // Sent when the syscall returns
+    bpf_map_update_elem(&process_accesses, &pid_tgid, data, BPF_ANY);
+    return 0;
+}
+
+static __always_inline int exit_process_access(void *ctx, long ret) {
+    struct process_access_data_t *data;
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data = bpf_map_lookup_elem(&process_accesses, &pid_tgid);
+    if (!data) {
+        return 0;
+    }
+    data->ret = ret;
+
+    // Send out on the perf event map
+    bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, data, sizeof(*data));
+    bpf_map_delete_elem(&process_accesses, &pid_tgid);
+    if (DEBUG) bpf_printk("---tracepoint/syscalls/sys_exit_process_access---");
+    return 0;
+}
+
+// proc_mem_pid will return the PID of an absolute /proc/<pid>/mem path,
+// or 0 for any other path, including /proc/self/mem.
+static __always_inline int proc_mem_pid(__u8 *path) {
+    int pid = 0;
+    __u8 c;
+
+    if (path[0] != '/' || path[1] != 'p' || path[2] != 'r' || path[3] != 'o' || path[4] != 'c' || path[5] != '/') {
+        return 0;
+    }
+#pragma unroll
+    for (int i = 6; i < 6 + PROC_PID_DIGITS; i++) {
+        c = path[i];
+        if (c >= '0' && c <= '9') {
+            pid = pid * 10 + (c - '0');
+            continue;
+        }
+        if (i > 6 && c == '/' && path[i + 1] == 'm' && path[i + 2] == 'e' && path[i + 3] == 'm' && path[i + 4] == 0) {
+            return pid;
+        }
+        return 0;
+    }
+    return 0;
+}
+
+static __always_inline int enter_proc_mem(const char *filename, __u32 flags) {
+    struct process_access_data_t data = {};
+    int pid;
+
+    bpf_probe_read_user_str(data.filename, sizeof(data.filename), filename);
+    pid = proc_mem_pid(data.filename);
+    if (!pid) {
+        return 0;
+    }
+    return enter_process_access(&data, ACCESS_OP_PROC_MEM, pid, 0, 0, flags);
+}
+
+// ptrace(long request, long pid, unsigned long addr, unsigned long data)
+SEC("tracepoint/syscalls/sys_enter_ptrace")
+int enter_ptrace(struct sys_enter_args_t *args){
+    struct process_access_data_t data = {};
+
+    switch (args->args[0]) {
+    case PTRACE_TRACEME:
+    case PTRACE_POKETEXT:
+    case PTRACE_POKEDATA:
+    case PTRACE_POKEUSR:
+    case PTRACE_SETREGS:
+    case PTRACE_ATTACH:
+    case PTRACE_SEIZE:
+        break;
+    default:
+        return 0;
+    }
+    return enter_process_access(&data, ACCESS_OP_PTRACE, args->args[1], args->args[0], args->args[2], 0);
+}
+
+// process_vm_readv(pid_t pid, const struct iovec *lvec, unsigned long liovcnt,
+//                  const struct iovec *rvec, unsigned long riovcnt, unsigned long flags)
+SEC("tracepoint/syscalls/sys_enter_process_vm_readv")
+int enter_process_vm_readv(struct sys_enter_args_t *args){
+    struct process_access_data_t data = {};
+    return enter_process_access(&data, ACCESS_OP_VM_READV, args->args[0], 0, 0, args->args[5]);
+}
+
+// process_vm_writev() has the same arguments as process_vm_readv()
+SEC("tracepoint/syscalls/sys_enter_process_vm_writev")
+int enter_process_vm_writev(struct sys_enter_args_t *args){
+    struct process_access_data_t data = {};
+    return enter_process_access(&data, ACCESS_OP_VM_WRITEV, args->args[0], 0, 0, args->args[5]);
+}
+
+// openat(int dfd, const char *filename, int flags, umode_t mode)
+SEC("tracepoint/syscalls/sys_enter_openat")
+int enter_openat_proc_mem(struct sys_enter_args_t *args){
+    return enter_proc_mem((const char *)args->args[1], args->args[2]);
+}
+
+// openat2(int dfd, const char *filename, struct open_how *how, size_t usize)
+SEC("tracepoint/syscalls/sys_enter_openat2")
+int enter_openat2_proc_mem(struct sys_enter_args_t *args){
+    struct open_how *how = (struct open_how *)args->args[2];
+    __u64 flags = 0;
+
+    bpf_probe_read_user(&flags, sizeof(flags), &how->flags);
+    return enter_proc_mem((const char *)args->args[1], flags);
+}
+
+SEC("tracepoint/syscalls/sys_exit_ptrace")
+int exit_ptrace(struct sys_exit_args_t *args){
+    return exit_process_access(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_process_vm_readv")
+int exit_process_vm_readv(struct sys_exit_args_t *args){
+    return exit_process_access(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_process_vm_writev")
+int exit_process_vm_writev(struct sys_exit_args_t *args){
+    return exit_process_access(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_openat")
+int exit_openat_proc_mem(struct sys_exit_args_t *args){
+    return exit_process_access(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_openat2")
+int exit_openat2_proc_mem(struct sys_exit_args_t *args){
+    return exit_process_access(args, args->ret);
+}
+
+// ----------------------------------------------------------------------------
+
+#define SOCK_OP_CONNECT 1
+#define SOCK_OP_ACCEPT 2
+#define SOCK_OP_ACCEPT4 3
+
+#define AF_UNIX 1
+#define AF_INET 2
+#define AF_INET6 10
+
+#define EBADF 9
+#define EAGAIN 11
+#define ENOTSOCK 88
+#define EINPROGRESS 115
+
+#define UNIX_PATH_MAX 108
+
+// Ports are in network byte order in the kernel, and this probe is little endian.
+#define NTOHS(x) __builtin_bswap16(x)
+
+struct sock_call_data_t {
+    __u32 type;
+    __u32 op;
+    __u32 pid;
+    __u32 ppid;
+    __u32 uid;
+    int fd;
+    int ret;
+    __u32 flags;
+    __u16 family;
+    __u16 sock_type;
+    __u16 lport;
+    __u16 rport;
+    __u32 peer_pid;
+    __u32 netns;
+    __u8 laddr[16];
+    __u8 raddr[16];
+    __u8 comm[DATA_SIZE_32];
+    __u8 path[UNIX_PATH_MAX];
+};
+
+// For Rust libbpf-rs only
+struct sock_call_data_t _scdt = {0};
+
+// sock_calls are the connect(), accept(), bind() and listen() syscalls, by pid_tgid,
+// until the syscall returns.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 10240);
+    __type(key, __u64);
+    __type(value, struct sock_call_data_t);
+} sock_calls SEC(".maps");
+
+// sock_netns will return the inode of the network namespace of a socket.
+static __always_inline __u32 sock_netns(struct sock *sk) {
+    struct net *net = 0;
+    __u32 inum = 0;
+
+    bpf_probe_read_kernel(&net, sizeof(net), &sk->__sk_common.skc_net.net);
+    if (net) {
+        bpf_probe_read_kernel(&inum, sizeof(inum), &net->ns.inum);
+    }
+    return inum;
+}
+
+// sock_call_read will read the addresses of the socket of a file descriptor. The
+// peer of connect() is read from its arguments, so only the local address is read.
+static __always_inline int sock_call_read(struct sock_call_data_t *data, int fd, int peer) {
+    struct file *file = current_fd_file(fd);
+    struct socket *sock = 0;
+    struct sock *sk = 0;
+    struct pid *peer_pid = 0;
+    struct unix_address *addr = 0;
+    short type = 0;
+
+    if (!file) {
+        return -1;
+    }
+    bpf_probe_read_kernel(&sock, sizeof(sock), &file->private_data);
+    if (!sock) {
+        return -1;
+    }
+    bpf_probe_read_kernel(&type, sizeof(type), &sock->type);
+    bpf_probe_read_kernel(&sk, sizeof(sk), &sock->sk);
+    if (!sk) {
+        return -1;
+    }
+    data->sock_type = type;
+    data->netns = sock_netns(sk);
+    bpf_probe_read_kernel(&data->family, sizeof(data->family), &sk->__sk_common.skc_family);
+    switch (data->family) {
+    case AF_INET:
+        bpf_probe_read_kernel(data->laddr, 4, &sk->__sk_common.skc_rcv_saddr);
+        bpf_probe_read_kernel(&data->lport, sizeof(data->lport), &sk->__sk_common.skc_num);
+        if (peer) {
+            bpf_probe_read_kernel(data->raddr, 4, &sk->__sk_common.skc_daddr);
+            bpf_probe_read_kernel(&data->rport, sizeof(data->rport), &sk->__sk_common.skc_dport);
+            data->rport = NTOHS(data->rport);
+        }
+        break;
+    case AF_INET6:
+        bpf_probe_read_kernel(data->laddr, 16, &sk->__sk_common.skc_v6_rcv_saddr);
+        bpf_probe_read_kernel(&data->lport, sizeof(data->lport), &sk->__sk_common.skc_num);
+        if (peer) {
+            bpf_probe_read_kernel(data->raddr, 16, &sk->__sk_common.skc_v6_daddr);
+            bpf_probe_read_kernel(&data->rport, sizeof(data->rport), &sk->__sk_common.skc_dport);
+            data->rport = NTOHS(data->rport);
+        }
+        break;
+    case AF_UNIX:
+        // The path of an accepted socket is the path of the listener
+        if (peer) {
+            bpf_probe_read_kernel(&addr, sizeof(addr), &((struct unix_sock *)sk)->addr);
+            if (addr) {
+                bpf_probe_read_kernel(data->path, sizeof(data->path), ((struct sockaddr_un *)&addr->name)->sun_path);
+            }
+        }
+        bpf_probe_read_kernel(&peer_pid, sizeof(peer_pid), &sk->sk_peer_pid);
+        if (peer_pid) {
+            bpf_probe_read_kernel(&data->peer_pid, sizeof(data->peer_pid), &peer_pid->numbers[0].nr);
+        }
+        break;
+    default:
+        return -1;
+    }
+    return 0;
+}
+
+// connect(int fd, struct sockaddr *uservaddr, int addrlen)
+SEC("tracepoint/syscalls/sys_enter_connect")
+int enter_connect(struct sys_enter_args_t *args){
+    struct sock_call_data_t data = {};
+    void *uservaddr = (void *)args->args[1];
+    __u64 pid_tgid;
+
+    bpf_probe_read_user(&data.family, sizeof(data.family), uservaddr);
+    switch (data.family) {
+    case AF_INET:
+        // struct sockaddr_in { sa_family_t; __be16 sin_port; struct in_addr sin_addr; }
+        bpf_probe_read_user(&data.rport, sizeof(data.rport), uservaddr + 2);
+        bpf_probe_read_user(data.raddr, 4, uservaddr + 4);
+        data.rport = NTOHS(data.rport);
+        break;
+    case AF_INET6:
+        // struct sockaddr_in6 { sa_family_t; __be16 sin6_port; __be32 sin6_flowinfo; struct in6_addr sin6_addr; }
+        bpf_probe_read_user(&data.rport, sizeof(data.rport), uservaddr + 2);
+        bpf_probe_read_user(data.raddr, 16, uservaddr + 8);
+        data.rport = NTOHS(data.rport);
+        break;
+    case AF_UNIX:
+        // struct sockaddr_un { sa_family_t; char sun_path[108]; }
+        bpf_probe_read_user(data.path, sizeof(data.path), uservaddr + 2);
+        break;
+    default:
+        return 0;
+    }
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data.type = EVENT_TYPE_CONNECT;
+    data.op = SOCK_OP_CONNECT;
+    data.pid = FIRST_32_BITS(pid_tgid);
+    data.ppid = current_ppid();
+    data.uid = current_uid();
+    data.fd = args->args[0];
+    bpf_get_current_comm(data.comm, sizeof(data.comm));
+
+    // Sent when the syscall returns
+    bpf_map_update_elem(&sock_calls, &pid_tgid, &data, BPF_ANY);
+    return 0;
+}
+
+SEC("tracepoint/syscalls/sys_exit_connect")
+int exit_connect(struct sys_exit_args_t *args){
+    struct sock_call_data_t *data;
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data = bpf_map_lookup_elem(&sock_calls, &pid_tgid);
+    if (!data) {
+        return 0;
+    }
+    data->ret = args->ret;
+    if (data->ret != -EBADF && data->ret != -ENOTSOCK) {
+        // The local address is bound by connect(), even when it is still in progress
+        sock_call_read(data, data->fd, 0);
+    }
+
+    // Send out on the perf event map
+    bpf_perf_event_output(args, &events, BPF_F_CURRENT_CPU, data, sizeof(*data));
+    bpf_map_delete_elem(&sock_calls, &pid_tgid);
+    if (DEBUG) bpf_printk("---tracepoint/syscalls/sys_exit_connect---");
+    return 0;
+}
+
+static __always_inline int enter_accept(__u32 op, int fd, __u32 flags) {
+    struct sock_call_data_t data = {};
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data.type = EVENT_TYPE_ACCEPT;
+    data.op = op;
+    data.pid = FIRST_32_BITS(pid_tgid);
+    data.ppid = current_ppid();
+    data.uid = current_uid();
+    data.fd = fd;
+    data.flags = flags;
+    bpf_get_current_comm(data.comm, sizeof(data.comm));
+
+    // Sent when the syscall returns
+    bpf_map_update_elem(&sock_calls, &pid_tgid, &data, BPF_ANY);
+    return 0;
+}
+
+static __always_inline int exit_accept(void *ctx, long ret) {
+    struct sock_call_data_t *data;
+    __u64 pid_tgid;
+    int err;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data = bpf_map_lookup_elem(&sock_calls, &pid_tgid);
+    if (!data) {
+        return 0;
+    }
+    data->ret = ret;
+    if (ret >= 0) {
+        err = sock_call_read(data, ret, 1);
+    } else if (ret != -EAGAIN) {
+        // The listener, for the family and local address
+        err = sock_call_read(data, data->fd, 0);
+    } else {
+        // A non-blocking accept() with nothing to accept
+        err = -1;
+    }
+    if (err == 0) {
+        // Send out on the perf event map
+        bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, data, sizeof(*data));
+        if (DEBUG) bpf_printk("---tracepoint/syscalls/sys_exit_accept---");
+    }
+    bpf_map_delete_elem(&sock_calls, &pid_tgid);
+    return 0;
+}
+
+// accept(int fd, struct sockaddr *upeer_sockaddr, int *upeer_addrlen)
+SEC("tracepoint/syscalls/sys_enter_accept")
+int enter_accept_syscall(struct sys_enter_args_t *args){
+    return enter_accept(SOCK_OP_ACCEPT, args->args[0], 0);
+}
+
+// accept4(int fd, struct sockaddr *upeer_sockaddr, int *upeer_addrlen, int flags)
+SEC("tracepoint/syscalls/sys_enter_accept4")
+int enter_accept4(struct sys_enter_args_t *args){
+    return enter_accept(SOCK_OP_ACCEPT4, args->args[0], args->args[3]);
+}
+
+SEC("tracepoint/syscalls/sys_exit_accept")
+int exit_accept_syscall(struct sys_exit_args_t *args){
+    return exit_accept(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_accept4")
+int exit_accept4(struct sys_exit_args_t *args){
+    return exit_accept(args, args->ret);
+}
+
+// ----------------------------------------------------------------------------
+
+// Every op from UDP_OP_RECVFROM is a receive
+#define UDP_OP_SENDTO 1
+#define UDP_OP_SENDMSG 2
+#define UDP_OP_SENDMMSG 3
+#define UDP_OP_WRITE 4
+#define UDP_OP_RECVFROM 5
+#define UDP_OP_RECVMSG 6
+#define UDP_OP_RECVMMSG 7
+#define UDP_OP_READ 8
+
+#define S_IFMT 00170000
+#define S_IFSOCK 0140000
+
+#define UDP_SEND 1
+#define UDP_RECV 2
+
+#define DNS_PORT 53
+#define DNS_PAYLOAD_SIZE 512
+
+// The most messages of one sendmmsg() or recvmmsg() that are counted
+#define UDP_MMSG_MAX 4
+
+struct udp_flow_key_t {
+    __u32 pid;
+    __u16 family;
+    __u16 lport;
+    __u16 rport;
+    __u16 _pad;
+    __u8 laddr[16];
+    __u8 raddr[16];
+};
+
+struct udp_flow_t {
+    __u64 tx_bytes;
+    __u64 tx_packets;
+    __u64 rx_bytes;
+    __u64 rx_packets;
+    __u64 last;
+    __u8 comm[DATA_SIZE_32];
+};
+
+struct dns_data_t {
+    __u32 type;
+    __u32 pid;
+    __u32 ppid;
+    __u32 uid;
+    __u32 direction;
+    __u32 len;
+    __u16 family;
+    __u16 lport;
+    __u16 rport;
+    __u16 _pad;
+    __u8 laddr[16];
+    __u8 raddr[16];
+    __u8 comm[DATA_SIZE_32];
+    __u8 payload[DNS_PAYLOAD_SIZE];
+};
+
+// For Rust libbpf-rs only
+struct dns_data_t _dnsdt = {0};
+
+struct udp_call_t {
+    __u32 op;
+    int fd;
+    __u64 buf;
+    __u64 addr;
+};
+
+struct udp_scratch_t {
+    struct udp_flow_key_t sock;
+    struct udp_flow_key_t flow;
+    struct dns_data_t dns;
+};
+
+// Kept off the stack, which is limited to 512 bytes.
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(max_entries, 1);
+    __type(key, __u32);
+    __type(value, struct udp_scratch_t);
+} udp_scratch SEC(".maps");
+
+// udp_calls are the send and receive syscalls, by pid_tgid, until the syscall returns.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 10240);
+    __type(key, __u64);
+    __type(value, struct udp_call_t);
+} udp_calls SEC(".maps");
+
+// udp_flows are the counters of every UDP flow, by process and address. They
+// are read and removed from userspace.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 10240);
+    __type(key, struct udp_flow_key_t);
+    __type(value, struct udp_flow_t);
+} udp_flows SEC(".maps");
+
+// udp_sock_read will read the addresses of a UDP socket. The peer is only
+// set for a connected socket. Any other socket is not read.
+static __always_inline int udp_sock_read(struct udp_flow_key_t *key, int fd) {
+    struct file *file = current_fd_file(fd);
+    struct inode *f_inode = 0;
+    struct socket *sock = 0;
+    struct sock *sk = 0;
+    umode_t mode = 0;
+    short type = 0;
+
+    __builtin_memset(key, 0, sizeof(*key));
+    if (!file) {
+        return -1;
+    }
+    // read() and write() are on any fd, and only a socket has a struct socket
+    bpf_probe_read_kernel(&f_inode, sizeof(f_inode), &file->f_inode);
+    bpf_probe_read_kernel(&mode, sizeof(mode), &f_inode->i_mode);
+    if ((mode & S_IFMT) != S_IFSOCK) {
+        return -1;
+    }
+    bpf_probe_read_kernel(&sock, sizeof(sock), &file->private_data);
+    if (!sock) {
+        return -1;
+    }
+    bpf_probe_read_kernel(&type, sizeof(type), &sock->type);
+    bpf_probe_read_kernel(&sk, sizeof(sk), &sock->sk);
+    if (type != SOCK_DGRAM || !sk) {
+        return -1;
+    }
+    bpf_probe_read_kernel(&key->family, sizeof(key->family), &sk->__sk_common.skc_family);
+    switch (key->family) {
+    case AF_INET:
+        bpf_probe_read_kernel(key->laddr, 4, &sk->__sk_common.skc_rcv_saddr);
+        bpf_probe_read_kernel(key->raddr, 4, &sk->__sk_common.skc_daddr);
+        break;
+    case AF_INET6:
+        bpf_probe_read_kernel(key->laddr, 16, &sk->__sk_common.skc_v6_rcv_saddr);
+        bpf_probe_read_kernel(key->raddr, 16, &sk->__sk_common.skc_v6_daddr);
+        break;
+    default:
+        return -1;
+    }
+    bpf_probe_read_kernel(&key->lport, sizeof(key->lport), &sk->__sk_common.skc_num);
+    bpf_probe_read_kernel(&key->rport, sizeof(key->rport), &sk->__sk_common.skc_dport);
+    key->rport = NTOHS(key->rport);
+    return 0;
+}
+
+// udp_message will count one message of a flow, and send it to userspace if it
+// is DNS. The peer is addr when it is set, or the peer of a connected socket.
+static __always_inline int udp_message(void *ctx, struct udp_scratch_t *scratch, __u32 direction, void *addr, void *buf, long len) {
+    struct udp_flow_t new_flow = {};
+    struct udp_flow_t *flow;
+    struct dns_data_t *dns;
+    __u64 pid_tgid;
+    __u16 family = 0;
+    __u32 size;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    scratch->flow = scratch->sock;
+    scratch->flow.pid = FIRST_32_BITS(pid_tgid);
+    if (addr) {
+        bpf_probe_read_user(&family, sizeof(family), addr);
+    }
+    if (family == AF_INET) {
+        __builtin_memset(scratch->flow.raddr, 0, sizeof(scratch->flow.raddr));
+        bpf_probe_read_user(&scratch->flow.rport, sizeof(scratch->flow.rport), addr + 2);
+        bpf_probe_read_user(scratch->flow.raddr, 4, addr + 4);
+        scratch->flow.rport = NTOHS(scratch->flow.rport);
+    } else if (family == AF_INET6) {
+        bpf_probe_read_user(&scratch->flow.rport, sizeof(scratch->flow.rport), addr + 2);
+        bpf_probe_read_user(scratch->flow.raddr, 16, addr + 8);
+        scratch->flow.rport = NTOHS(scratch->flow.rport);
+    }
+
+    flow = bpf_map_lookup_elem(&udp_flows, &scratch->flow);
+    if (!flow) {
+        bpf_get_current_comm(new_flow.comm, sizeof(new_flow.comm));
+        bpf_map_update_elem(&udp_flows, &scratch->flow, &new_flow, BPF_NOEXIST);
+        flow = bpf_map_lookup_elem(&udp_flows, &scratch->flow);
+        if (!flow) {
+            return 0;
+        }
+    }
+    if (direction == UDP_SEND) {
+        __sync_fetch_and_add(&flow->tx_bytes, len);
+        __sync_fetch_and_add(&flow->tx_packets, 1);
+    } else {
+        __sync_fetch_and_add(&flow->rx_bytes, len);
+        __sync_fetch_and_add(&flow->rx_packets, 1);
+    }
+    flow->last = bpf_ktime_get_ns();
+
+    if (scratch->flow.rport != DNS_PORT && scratch->flow.lport != DNS_PORT) {
+        return 0;
+    }
+    dns = &scratch->dns;
+    dns->type = EVENT_TYPE_DNS;
+    dns->pid = scratch->flow.pid;
+    dns->ppid = current_ppid();
+    dns->uid = current_uid();
+    dns->direction = direction;
+    dns->len = len;
+    dns->family = scratch->flow.family;
+    dns->lport = scratch->flow.lport;
+    dns->rport = scratch->flow.rport;
+    __builtin_memcpy(dns->laddr, scratch->flow.laddr, sizeof(dns->laddr));
+    __builtin_memcpy(dns->raddr, scratch->flow.raddr, sizeof(dns->raddr));
+    bpf_get_current_comm(dns->comm, sizeof(dns->comm));
+    size = len;
+    if (size > DNS_PAYLOAD_SIZE) {
+        size = DNS_PAYLOAD_SIZE;
+    }
+    bpf_probe_read_user(dns->payload, size, buf);
+
+    // Send out on the perf event map
+    bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, dns, sizeof(*dns));
+    if (DEBUG) bpf_printk("---tracepoint/syscalls/sys_exit_udp/dns---");
+    return 0;
+}
+
+// udp_msghdr will count a message of sendmsg() or recvmsg(). Only the first
+// iovec is read for DNS.
+static __always_inline int udp_msghdr(void *ctx, struct udp_scratch_t *scratch, __u32 direction, struct user_msghdr *msg, long len) {
+    struct iovec *iov = 0;
+    void *name = 0;
+    void *base = 0;
+
+    bpf_probe_read_user(&name, sizeof(name), &msg->msg_name);
+    bpf_probe_read_user(&iov, sizeof(iov), &msg->msg_iov);
+    if (iov) {
+        bpf_probe_read_user(&base, sizeof(base), &iov->iov_base);
+    }
+    return udp_message(ctx, scratch, direction, name, base, len);
+}
+
+static __always_inline int enter_udp(__u32 op, int fd, __u64 buf, __u64 addr) {
+    struct udp_call_t call = {};
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    call.op = op;
+    call.fd = fd;
+    call.buf = buf;
+    call.addr = addr;
+
+    // Counted when the syscall returns
+    bpf_map_update_elem(&udp_calls, &pid_tgid, &call, BPF_ANY);
+    return 0;
+}
+
+static __always_inline int exit_udp(void *ctx, long ret) {
+    struct udp_scratch_t *scratch;
+    struct udp_call_t *found;
+    struct udp_call_t call;
+    struct mmsghdr *vec;
+    __u32 direction = UDP_SEND;
+    __u32 msg_len;
+    __u64 pid_tgid;
+    __u32 zero = 0;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    found = bpf_map_lookup_elem(&udp_calls, &pid_tgid);
+    if (!found) {
+        return 0;
+    }
+    call = *found;
+    bpf_map_delete_elem(&udp_calls, &pid_tgid);
+    if (ret <= 0) {
+        return 0;
+    }
+    scratch = bpf_map_lookup_elem(&udp_scratch, &zero);
+    if (!scratch) {
+        return 0;
+    }
+    if (udp_sock_read(&scratch->sock, call.fd) != 0) {
+        return 0;
+    }
+    if (call.op >= UDP_OP_RECVFROM) {
+        direction = UDP_RECV;
+    }
+    switch (call.op) {
+    case UDP_OP_SENDTO:
+    case UDP_OP_RECVFROM:
+    case UDP_OP_WRITE:
+    case UDP_OP_READ:
+        return udp_message(ctx, scratch, direction, (void *)call.addr, (void *)call.buf, ret);
+    case UDP_OP_SENDMSG:
+    case UDP_OP_RECVMSG:
+        return udp_msghdr(ctx, scratch, direction, (struct user_msghdr *)call.buf, ret);
+    }
+
+    // sendmmsg() and recvmmsg() return the number of messages
+#pragma unroll
+    for (int i = 0; i < UDP_MMSG_MAX; i++) {
+        if (i >= ret) {
+            break;
+        }
+        vec = (struct mmsghdr *)call.buf + i;
+        msg_len = 0;
+        bpf_probe_read_user(&msg_len, sizeof(msg_len), &vec->msg_len);
+        udp_msghdr(ctx, scratch, direction, &vec->msg_hdr, msg_len);
+    }
+    return 0;
+}
+
+// sendto(int fd, void *buff, size_t len, unsigned int flags, struct sockaddr *addr, int addr_len)
+SEC("tracepoint/syscalls/sys_enter_sendto")
+int enter_sendto(struct sys_enter_args_t *args){
+    return enter_udp(UDP_OP_SENDTO, args->args[0], args->args[1], args->args[4]);
+}
+
+// recvfrom(int fd, void *ubuf, size_t size, unsigned int flags, struct sockaddr *addr, int *addr_len)
+SEC("tracepoint/syscalls/sys_enter_recvfrom")
+int enter_recvfrom(struct sys_enter_args_t *args){
+    return enter_udp(UDP_OP_RECVFROM, args->args[0], args->args[1], args->args[4]);
+}
+
+// sendmsg(int fd, struct user_msghdr *msg, unsigned int flags)
+SEC("tracepoint/syscalls/sys_enter_sendmsg")
+int enter_sendmsg(struct sys_enter_args_t *args){
+    return enter_udp(UDP_OP_SENDMSG, args->args[0], args->args[1], 0);
+}
+
+// recvmsg(int fd, struct user_msghdr *msg, unsigned int flags)
+SEC("tracepoint/syscalls/sys_enter_recvmsg")
+int enter_recvmsg(struct sys_enter_args_t *args){
+    return enter_udp(UDP_OP_RECVMSG, args->args[0], args->args[1], 0);
+}
+
+// sendmmsg(int fd, struct mmsghdr *mmsg, unsigned int vlen, unsigned int flags)
+SEC("tracepoint/syscalls/sys_enter_sendmmsg")
+int enter_sendmmsg(struct sys_enter_args_t *args){
+    return enter_udp(UDP_OP_SENDMMSG, args->args[0], args->args[1], 0);
+}
+
+// recvmmsg(int fd, struct mmsghdr *mmsg, unsigned int vlen, unsigned int flags, struct __kernel_timespec *timeout)
+SEC("tracepoint/syscalls/sys_enter_recvmmsg")
+int enter_recvmmsg(struct sys_enter_args_t *args){
+    return enter_udp(UDP_OP_RECVMMSG, args->args[0], args->args[1], 0);
+}
+
+// read(unsigned int fd, char *buf, size_t count) and write(unsigned int fd, const char *buf, size_t count)
+//
+// Only a read() or write() on a UDP socket is counted, which is always connected,
+// such as the Go resolver or a connected send(). The fd is checked at enter, so
+// the far more common read() and write() of files are not saved.
+static __always_inline int enter_udp_rw(__u32 op, int fd, __u64 buf) {
+    struct udp_scratch_t *scratch;
+    __u32 zero = 0;
+
+    scratch = bpf_map_lookup_elem(&udp_scratch, &zero);
+    if (!scratch) {
+        return 0;
+    }
+    if (udp_sock_read(&scratch->sock, fd) != 0) {
+        return 0;
+    }
+    return enter_udp(op, fd, buf, 0);
+}
+
+SEC("tracepoint/syscalls/sys_enter_write")
+int enter_write(struct sys_enter_args_t *args){
+    return enter_udp_rw(UDP_OP_WRITE, args->args[0], args->args[1]);
+}
+
+SEC("tracepoint/syscalls/sys_enter_read")
+int enter_read(struct sys_enter_args_t *args){
+    return enter_udp_rw(UDP_OP_READ, args->args[0], args->args[1]);
+}
+
+SEC("tracepoint/syscalls/sys_exit_sendto")
+int exit_sendto(struct sys_exit_args_t *args){
+    return exit_udp(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_recvfrom")
+int exit_recvfrom(struct sys_exit_args_t *args){
+    return exit_udp(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_sendmsg")
+int exit_sendmsg(struct sys_exit_args_t *args){
+    return exit_udp(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_recvmsg")
+int exit_recvmsg(struct sys_exit_args_t *args){
+    return exit_udp(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_sendmmsg")
+int exit_sendmmsg(struct sys_exit_args_t *args){
+    return exit_udp(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_recvmmsg")
+int exit_recvmmsg(struct sys_exit_args_t *args){
+    return exit_udp(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_write")
+int exit_write(struct sys_exit_args_t *args){
+    return exit_udp(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_read")
+int exit_read(struct sys_exit_args_t *args){
+    return exit_udp(args, args->ret);
+}
+
+// ----------------------------------------------------------------------------
+
+#define SOCK_OP_BIND 4
+#define SOCK_OP_LISTEN 5
+#define SOCK_OP_CLOSE 6
+
+// bind(int fd, struct sockaddr *umyaddr, int addrlen)
+//
+// Only a bind() to a port is a listener, and the socket type is only known
+// when the syscall returns. A TCP socket is a listener when listen() returns.
+SEC("tracepoint/syscalls/sys_enter_bind")
+int enter_bind(struct sys_enter_args_t *args){
+    struct sock_call_data_t data = {};
+    void *umyaddr = (void *)args->args[1];
+    __u64 pid_tgid;
+
+    bpf_probe_read_user(&data.family, sizeof(data.family), umyaddr);
+    if (data.family != AF_INET && data.family != AF_INET6) {
+        return 0;
+    }
+    // The port is at the same offset of sockaddr_in and sockaddr_in6
+    bpf_probe_read_user(&data.lport, sizeof(data.lport), umyaddr + 2);
+    if (data.lport == 0) {
+        return 0;
+    }
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data.type = EVENT_TYPE_LISTEN;
+    data.op = SOCK_OP_BIND;
+    data.pid = FIRST_32_BITS(pid_tgid);
+    data.ppid = current_ppid();
+    data.uid = current_uid();
+    data.fd = args->args[0];
+    bpf_get_current_comm(data.comm, sizeof(data.comm));
+
+    // Sent when the syscall returns
+    bpf_map_update_elem(&sock_calls, &pid_tgid, &data, BPF_ANY);
+    return 0;
+}
+
+// listen(int fd, int backlog)
+SEC("tracepoint/syscalls/sys_enter_listen")
+int enter_listen(struct sys_enter_args_t *args){
+    struct sock_call_data_t data = {};
+    __u64 pid_tgid;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data.type = EVENT_TYPE_LISTEN;
+    data.op = SOCK_OP_LISTEN;
+    data.pid = FIRST_32_BITS(pid_tgid);
+    data.ppid = current_ppid();
+    data.uid = current_uid();
+    data.fd = args->args[0];
+    data.flags = args->args[1];
+    bpf_get_current_comm(data.comm, sizeof(data.comm));
+
+    // Sent when the syscall returns
+    bpf_map_update_elem(&sock_calls, &pid_tgid, &data, BPF_ANY);
+    return 0;
+}
+
+static __always_inline int exit_listen(void *ctx, long ret) {
+    struct sock_call_data_t *data;
+    __u64 pid_tgid;
+    __u16 lport;
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data = bpf_map_lookup_elem(&sock_calls, &pid_tgid);
+    if (!data) {
+        return 0;
+    }
+    data->ret = ret;
+    lport = data->lport;
+    if (sock_call_read(data, data->fd, 0) != 0 || (data->family != AF_INET && data->family != AF_INET6)) {
+        bpf_map_delete_elem(&sock_calls, &pid_tgid);
+        return 0;
+    }
+    if (data->op == SOCK_OP_BIND) {
+        if (data->sock_type != SOCK_DGRAM) {
+            bpf_map_delete_elem(&sock_calls, &pid_tgid);
+            return 0;
+        }
+        if (ret != 0) {
+            // The port that could not be bound
+            data->lport = NTOHS(lport);
+        }
+    }
+
+    // Send out on the perf event map
+    bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, data, sizeof(*data));
+    bpf_map_delete_elem(&sock_calls, &pid_tgid);
+    if (DEBUG) bpf_printk("---tracepoint/syscalls/sys_exit_listen---");
+    return 0;
+}
+
+SEC("tracepoint/syscalls/sys_exit_bind")
+int exit_bind(struct sys_exit_args_t *args){
+    return exit_listen(args, args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_listen")
+int exit_listen_syscall(struct sys_exit_args_t *args){
+    return exit_listen(args, args->ret);
+}
+
+// A TCP listener is closed by the process that owns it, with close() or
+// when it exits, so the current process is the owner.
+SEC("tracepoint/sock/inet_sock_set_state")
+int inet_sock_listen_close(struct inet_sock_entry_args_t *args){
+    struct sock_call_data_t data = {};
+    __u64 pid_tgid;
+
+    if (args->oldstate != TCP_LISTEN || args->newstate != TCP_CLOSE || args->protocol != IPPROTO_TCP) {
+        return 0;
+    }
+
+    pid_tgid = bpf_get_current_pid_tgid();
+    data.type = EVENT_TYPE_LISTEN;
+    data.op = SOCK_OP_CLOSE;
+    data.pid = FIRST_32_BITS(pid_tgid);
+    data.ppid = current_ppid();
+    data.uid = current_uid();
+    data.fd = -1;
+    data.family = args->family;
+    data.sock_type = SOCK_STREAM;
+    data.lport = args->sport;
+    data.netns = sock_netns((struct sock *)args->skaddr);
+    if (args->family == AF_INET6) {
+        memcpy(data.laddr, args->saddr_v6, sizeof(args->saddr_v6));
+    } else {
+        memcpy(data.laddr, args->saddr, sizeof(args->saddr));
+    }
+    bpf_get_current_comm(data.comm, sizeof(data.comm));
+
+    // Send out on the perf event map
+    bpf_perf_event_output(args, &events, BPF_F_CURRENT_CPU, &data, sizeof(data));
+    if (DEBUG) bpf_printk("---tracepoint/sock/inet_sock_listen_close---");
+    return 0;
+}
+
+// ----------------------------------------------------------------------------
+
+#define TCP_OP_RETRANSMIT 1
+#define TCP_OP_SEND_RESET 2
+#define TCP_OP_RECEIVE_RESET 3
+#define TCP_OP_DROP 4
+
+#define ETH_P_IP 0x0800
+#define ETH_P_IPV6 0x86DD
+
+// network_header and transport_header are ~0 when they are not set
+#define SKB_HEADER_UNSET 0xFFFF
+
+struct tcp_data_t {
+    __u32 type;
+    __u32 op;
+    __u32 pid;
+    int state;
+    __u32 reason;
+    __u32 netns;
+    __u32 drops;
+    __u16 family;
+    __u16 sport;
+    __u16 dport;
+    __u16 _pad;
+    __u8 saddr[16];
+    __u8 daddr[16];
+    __u8 comm[DATA_SIZE_32];
+};
+
+// For Rust libbpf-rs only
+struct tcp_data_t _tcpdt = {0};
+
+// TCP_DROP_INTERVAL_NS is how long the drops of a connection are counted
+// in the kernel before the next TCPPacketDropped of the connection is sent.
+#define TCP_DROP_INTERVAL_NS 1000000000ULL
+
+// tcp_drop_key_t is the connection of a dropped packet. Packets without a
+// socket have no ports in the key, so a port scan from one source is counted
+// as one connection instead of sending an event for every port.
+struct tcp_drop_key_t {
+    __u8 saddr[16];
+    __u8 daddr[16];
+    __u16 family;
+    __u16 sport;
+    __u16 dport;
+    __u16 _pad;
+};
+
+struct tcp_drop_count_t {
+    __u64 sent;
+    __u32 drops;
+    __u32 _pad;
+};
+
+// tcp_drops are the drops of every connection since its last event.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __uint(max_entries, 16384);
+    __type(key, struct tcp_drop_key_t);
+    __type(value, struct tcp_drop_count_t);
+} tcp_drops SEC(".maps");
+
+// tcp_retransmit_skb and tcp_send_reset
+struct tcp_event_sk_skb_args_t {
+    __u64 _unused;
+    __u64 skbaddr;
+    __u64 skaddr;
+    int state;
+    __u16 sport;
+    __u16 dport;
+    __u16 family;
+    __u8 saddr[4];
+    __u8 daddr[4];
+    __u8 saddr_v6[16];
+    __u8 daddr_v6[16];
+};
+
+// tcp_receive_reset
+struct tcp_event_sk_args_t {
+    __u64 _unused;
+    __u64 skaddr;
+    __u16 sport;
+    __u16 dport;
+    __u16 family;
+    __u8 saddr[4];
+    __u8 daddr[4];
+    __u8 saddr_v6[16];
+    __u8 daddr_v6[16];
+};
+
+// The fields of kfree_skb move between kernels, so they are relocated with
+// CO-RE. The drop reason was added in Linux 5.17.
+struct trace_event_raw_kfree_skb___dse {
+    void *skbaddr;
+    void *location;
+    __u16 protocol;
+    int reason;
+} __attribute__((preserve_access_index));
+
+// tcp_owner will set the process that owns a socket, and its network namespace.
+// TCP sends and receives in softirq context, where the current task is unrelated.
+static __always_inline void tcp_owner(struct tcp_data_t *data, __u64 skaddr) {
+    struct sock_owner_t *owner;
+
+    if (!skaddr) {
+        return;
+    }
+    data->netns = sock_netns((struct sock *)skaddr);
+    owner = bpf_map_lookup_elem(&sock_owners, &skaddr);
+    if (owner) {
+        data->pid = owner->pid;
+        memcpy(data->comm, owner->comm, sizeof(data->comm));
+    }
+}
+
+static __always_inline int tcp_event_sk_skb(struct tcp_event_sk_skb_args_t *args, __u32 op) {
+    struct tcp_data_t data = {};
+
+    data.type = EVENT_TYPE_TCP;
+    data.op = op;
+    data.state = args->state;
+    data.family = args->family;
+    data.sport = args->sport;
+    data.dport = args->dport;
+    if (args->family == AF_INET6) {
+        memcpy(data.saddr, args->saddr_v6, sizeof(args->saddr_v6));
+        memcpy(data.daddr, args->daddr_v6, sizeof(args->daddr_v6));
+    } else {
+        memcpy(data.saddr, args->saddr, sizeof(args->saddr));
+        memcpy(data.daddr, args->daddr, sizeof(args->daddr));
+    }
+    tcp_owner(&data, args->skaddr);
+
+    // Send out on the perf event map
+    bpf_perf_event_output(args, &events, BPF_F_CURRENT_CPU, &data, sizeof(data));
+    return 0;
+}
+
+SEC("tracepoint/tcp/tcp_retransmit_skb")
+int tcp_retransmit_skb(struct tcp_event_sk_skb_args_t *args){
+    if (DEBUG) bpf_printk("---tracepoint/tcp/tcp_retransmit_skb---");
+    return tcp_event_sk_skb(args, TCP_OP_RETRANSMIT);
+}
+
+SEC("tracepoint/tcp/tcp_send_reset")
+int tcp_send_reset(struct tcp_event_sk_skb_args_t *args){
+    if (DEBUG) bpf_printk("---tracepoint/tcp/tcp_send_reset---");
+    return tcp_event_sk_skb(args, TCP_OP_SEND_RESET);
+}
+
+SEC("tracepoint/tcp/tcp_receive_reset")
+int tcp_receive_reset(struct tcp_event_sk_args_t *args){
+    struct tcp_data_t data = {};
+    struct sock *sk = (struct sock *)args->skaddr;
+    __u8 state = 0;
+
+    data.type = EVENT_TYPE_TCP;
+    data.op = TCP_OP_RECEIVE_RESET;
+    bpf_probe_read_kernel(&state, sizeof(state), &sk->__sk_common.skc_state);
+    data.state = state;
+    data.family = args->family;
+    data.sport = args->sport;
+    data.dport = args->dport;
+    if (args->family == AF_INET6) {
+        memcpy(data.saddr, args->saddr_v6, sizeof(args->saddr_v6));
+        memcpy(data.daddr, args->daddr_v6, sizeof(args->daddr_v6));
+    } else {
+        memcpy(data.saddr, args->saddr, sizeof(args->saddr));
+        memcpy(data.daddr, args->daddr, sizeof(args->daddr));
+    }
+    tcp_owner(&data, args->skaddr);
+
+    // Send out on the perf event map
+    bpf_perf_event_output(args, &events, BPF_F_CURRENT_CPU, &data, sizeof(data));
+    if (DEBUG) bpf_printk("---tracepoint/tcp/tcp_receive_reset---");
+    return 0;
+}
+
+// tcp_drop_sock will read a dropped packet from the TCP socket it belongs to.
+static __always_inline int tcp_drop_sock(struct tcp_data_t *data, struct sock *sk) {
+    __u16 protocol = 0;
+    __u8 state = 0;
+
+    bpf_probe_read_kernel(&protocol, sizeof(protocol), &sk->sk_protocol);
+    if (protocol != IPPROTO_TCP) {
+        return -1;
+    }
+    bpf_probe_read_kernel(&data->family, sizeof(data->family), &sk->__sk_common.skc_family);
+    switch (data->family) {
+    case AF_INET:
+        bpf_probe_read_kernel(data->saddr, 4, &sk->__sk_common.skc_rcv_saddr);
+        bpf_probe_read_kernel(data->daddr, 4, &sk->__sk_common.skc_daddr);
+        break;
+    case AF_INET6:
+        bpf_probe_read_kernel(data->saddr, 16, &sk->__sk_common.skc_v6_rcv_saddr);
+        bpf_probe_read_kernel(data->daddr, 16, &sk->__sk_common.skc_v6_daddr);
+        break;
+    default:
+        return -1;
+    }
+    bpf_probe_read_kernel(&data->sport, sizeof(data->sport), &sk->__sk_common.skc_num);
+    bpf_probe_read_kernel(&data->dport, sizeof(data->dport), &sk->__sk_common.skc_dport);
+    data->dport = NTOHS(data->dport);
+    bpf_probe_read_kernel(&state, sizeof(state), &sk->__sk_common.skc_state);
+    data->state = state;
+    tcp_owner(data, (__u64)sk);
+    return 0;
+}
+
+// tcp_drop_packet will read a dropped packet without a socket from its headers.
+static __always_inline int tcp_drop_packet(struct tcp_data_t *data, struct sk_buff *skb, __u16 eth_protocol) {
+    unsigned char *head = 0;
+    __u16 network_header = 0;
+    __u8 ip_protocol = 0;
+    __u8 ihl = 0;
+    void *ip;
+    void *tcp;
+
+    bpf_probe_read_kernel(&head, sizeof(head), &skb->head);
+    bpf_probe_read_kernel(&network_header, sizeof(network_header), &skb->network_header);
+    if (!head || network_header == SKB_HEADER_UNSET) {
+        return -1;
+    }
+    ip = head + network_header;
+    switch (eth_protocol) {
+    case ETH_P_IP:
+        // struct iphdr { __u8 ihl:4, version:4; ... __u8 protocol; __sum16 check; __be32 saddr; __be32 daddr; }
+        bpf_probe_read_kernel(&ihl, sizeof(ihl), ip);
+        bpf_probe_read_kernel(&ip_protocol, sizeof(ip_protocol), ip + 9);
+        bpf_probe_read_kernel(data->saddr, 4, ip + 12);
+        bpf_probe_read_kernel(data->daddr, 4, ip + 16);
+        data->family = AF_INET;
+        tcp = ip + (ihl & 0x0F) * 4;
+        break;
+    case ETH_P_IPV6:
+        // struct ipv6hdr { ... __u8 nexthdr; __u8 hop_limit; struct in6_addr saddr; struct in6_addr daddr; }
+        // Extension headers are not followed.
+        bpf_probe_read_kernel(&ip_protocol, sizeof(ip_protocol), ip + 6);
+        bpf_probe_read_kernel(data->saddr, 16, ip + 8);
+        bpf_probe_read_kernel(data->daddr, 16, ip + 24);
+        data->family = AF_INET6;
+        tcp = ip + 40;
+        break;
+    default:
+        return -1;
+    }
+    if (ip_protocol != IPPROTO_TCP) {
+        return -1;
+    }
+    // struct tcphdr { __be16 source; __be16 dest; ... }
+    bpf_probe_read_kernel(&data->sport, sizeof(data->sport), tcp);
+    bpf_probe_read_kernel(&data->dport, sizeof(data->dport), tcp + 2);
+    data->sport = NTOHS(data->sport);
+    data->dport = NTOHS(data->dport);
+    return 0;
+}
+
+// tcp_drop_count will count a drop of the connection of data, and return the
+// number of drops to send, or 0 when the connection already sent an event in
+// the last TCP_DROP_INTERVAL_NS. Drops are only sent with the next drop of the
+// connection after the interval.
+static __always_inline __u32 tcp_drop_count(struct tcp_data_t *data, struct sock *sk) {
+    struct tcp_drop_key_t key = {};
+    struct tcp_drop_count_t *count;
+    __u64 now = bpf_ktime_get_ns();
+    __u32 drops;
+
+    key.family = data->family;
+    memcpy(key.saddr, data->saddr, sizeof(key.saddr));
+    memcpy(key.daddr, data->daddr, sizeof(key.daddr));
+    if (sk) {
+        key.sport = data->sport;
+        key.dport = data->dport;
+    }
+    count = bpf_map_lookup_elem(&tcp_drops, &key);
+    if (!count) {
+        struct tcp_drop_count_t first = {};
+
+        first.sent = now;
+        bpf_map_update_elem(&tcp_drops, &key, &first, BPF_ANY);
+        return 1;
+    }
+    if (now - count->sent < TCP_DROP_INTERVAL_NS) {
+        __sync_fetch_and_add(&count->drops, 1);
+        return 0;
+    }
+    // A drop on another CPU between the read and the reset is lost.
+    drops = count->drops + 1;
+    count->drops = 0;
+    count->sent = now;
+    return drops;
+}
+
+// Only TCP packets are sent. A packet with a socket is from the socket, and
+// a packet without one, such as a packet for a closed port, is read as sent.
+// Drops are counted by connection in tcp_drops, and every connection sends
+// at most one event every TCP_DROP_INTERVAL_NS with the number of drops.
+SEC("tracepoint/skb/kfree_skb")
+int kfree_skb(struct trace_event_raw_kfree_skb___dse *args){
+    struct tcp_data_t data = {};
+    struct sk_buff *skb = args->skbaddr;
+    struct sock *sk = 0;
+    int err;
+
+    bpf_probe_read_kernel(&sk, sizeof(sk), &skb->sk);
+    if (sk) {
+        err = tcp_drop_sock(&data, sk);
+    } else {
+        err = tcp_drop_packet(&data, skb, NTOHS(args->protocol));
+    }
+    if (err != 0) {
+        return 0;
+    }
+    data.drops = tcp_drop_count(&data, sk);
+    if (data.drops == 0) {
+        return 0;
+    }
+
+    data.type = EVENT_TYPE_TCP;
+    data.op = TCP_OP_DROP;
+    if (bpf_core_field_exists(args->reason)) {
+        data.reason = args->reason;
+    }
+
+    // Send out on the perf event map
+    bpf_perf_event_output(args, &events, BPF_F_CURRENT_CPU, &data, sizeof(data));
+    if (DEBUG) bpf_printk("---tracepoint/skb/kfree_skb---");
+    return 0;
+}
+
+// tcp_sock_owner will save the process that owns a socket, like inet_sock_set_state,
+// without sending every state change.
+SEC("tracepoint/sock/inet_sock_set_state")
+int tcp_sock_owner(struct inet_sock_entry_args_t *args){
+    __u64 skaddr = args->skaddr;
+
+    if (args->newstate == TCP_SYN_SENT || args->newstate == TCP_LISTEN) {
+        struct sock_owner_t owner = {};
+
+        owner.pid = FIRST_32_BITS(bpf_get_current_pid_tgid());
+        bpf_get_current_comm(owner.comm, sizeof(owner.comm));
+        bpf_map_update_elem(&sock_owners, &skaddr, &owner, BPF_ANY);
+    }
+    if (args->newstate == TCP_CLOSE) {
+        bpf_map_delete_elem(&sock_owners, &skaddr);
+    }
+    return 0;
+}
+
+// accept_owner will save the process that accepted a socket. The state of an
+// accepted socket changes in softirq context, before it is accepted.
+static __always_inline int accept_owner(long ret) {
+    struct sock_owner_t owner = {};
+    struct file *file;
+    struct socket *sock = 0;
+    struct sock *sk = 0;
+    __u16 family = 0;
+    __u64 skaddr;
+
+    if (ret < 0) {
+        return 0;
+    }
+    file = current_fd_file(ret);
+    if (!file) {
+        return 0;
+    }
+    bpf_probe_read_kernel(&sock, sizeof(sock), &file->private_data);
+    if (!sock) {
+        return 0;
+    }
+    bpf_probe_read_kernel(&sk, sizeof(sk), &sock->sk);
+    if (!sk) {
+        return 0;
+    }
+    bpf_probe_read_kernel(&family, sizeof(family), &sk->__sk_common.skc_family);
+    if (family != AF_INET && family != AF_INET6) {
+        return 0;
+    }
+    skaddr = (__u64)sk;
+    owner.pid = FIRST_32_BITS(bpf_get_current_pid_tgid());
+    bpf_get_current_comm(owner.comm, sizeof(owner.comm));
+    bpf_map_update_elem(&sock_owners, &skaddr, &owner, BPF_ANY);
+    return 0;
+}
+
+SEC("tracepoint/syscalls/sys_exit_accept")
+int tcp_accept_owner(struct sys_exit_args_t *args){
+    return accept_owner(args->ret);
+}
+
+SEC("tracepoint/syscalls/sys_exit_accept4")
+int tcp_accept4_owner(struct sys_exit_args_t *args){
+    return accept_owner(args->ret);
+}
+
+char LICENSE[] SEC("license") = "GPL";
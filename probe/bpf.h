@@ -29,6 +29,31 @@
 #define FIRST_32_BITS(x) x >> 32
 #define DATA_SIZE_32 32
 #define DATA_SIZE_64 64
+#define DATA_SIZE_128 128
+#define DATA_SIZE_256 256
+
+// The first field of every record sent on the events map is
+// one of these types, so userspace can tell the records apart.
+#define EVENT_TYPE_SOCK 1
+#define EVENT_TYPE_SIGNAL 2
+#define EVENT_TYPE_CLONE 3
+#define EVENT_TYPE_EXECVE 4
+#define EVENT_TYPE_FORK 5
+#define EVENT_TYPE_EXEC 6
+#define EVENT_TYPE_EXIT 7
+#define EVENT_TYPE_EXEC_DENIED 8
+#define EVENT_TYPE_FILE_OPEN 9
+#define EVENT_TYPE_FILE_CHANGE 10
+#define EVENT_TYPE_MOUNT 11
+#define EVENT_TYPE_PRIVILEGE 12
+#define EVENT_TYPE_MODULE 13
+#define EVENT_TYPE_BPF 14
+#define EVENT_TYPE_PROCESS_ACCESS 15
+#define EVENT_TYPE_CONNECT 16
+#define EVENT_TYPE_ACCEPT 17
+#define EVENT_TYPE_DNS 18
+#define EVENT_TYPE_LISTEN 19
+#define EVENT_TYPE_TCP 20
 
 #define DEBUG 1
 
@@ -0,0 +1,251 @@
+/*
+ * This file is part of The Double Slit Experiment (https://github.com/kris-nova/doubleslitexperiment).
+ * Copyright (c) 2021 Kris Nóva <kris@nivenly.com>.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, version 3.
+ *
+ * This program is distributed in the hope that it will be useful, but
+ * WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU
+ * General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ *
+ *     ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+ *     ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+ *     ██╔██╗ ██║██║██╔██║██║   ██║███████║
+ *     ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+ *     ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+ *     ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+ */
+
+
+// The exec guard is a separate object from bpf.c, so the tracepoints
+// still load on kernels without BPF LSM (CONFIG_BPF_LSM and "bpf" in
+// the lsm= boot parameter).
+
+#include "vmlinux.h"
+#include "bpf.h"
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_tracing.h>
+
+#define EPERM 1
+
+#define EXEC_DENIED_PREFIX 1
+#define EXEC_DENIED_INODE 2
+#define EXEC_DENIED_CGROUP 3
+#define EXEC_DENIED_UNRESOLVED 4
+
+// EXEC_PATH_MAX is the longest path of an executable resolved with bpf_d_path.
+#define EXEC_PATH_MAX 4096
+
+// EXEC_CGROUP_DEPTH is the most cgroup ancestors checked for a denied cgroup.
+#define EXEC_CGROUP_DEPTH 16
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
+    __uint(key_size, sizeof(__u32));
+    __uint(value_size, sizeof(__u32));
+} lsm_events SEC(".maps");
+
+// ----------------------------------------------------------------------------
+
+struct exec_prefix_t {
+    __u32 prefixlen;
+    __u8 path[DATA_SIZE_128];
+};
+
+struct exec_inode_t {
+    __u64 ino;
+    __u32 dev;
+    __u32 _pad;
+};
+
+struct exec_guard_config_t {
+    __u32 enforce;
+    __u32 prefixes;
+};
+
+// exec_deny_prefixes are path prefixes, matched against the absolute path of the
+// executable, with symlinks resolved. The prefixlen of a key is in bits.
+struct {
+    __uint(type, BPF_MAP_TYPE_LPM_TRIE);
+    __uint(max_entries, 1024);
+    __uint(map_flags, BPF_F_NO_PREALLOC);
+    __type(key, struct exec_prefix_t);
+    __type(value, __u8);
+} exec_deny_prefixes SEC(".maps");
+
+// exec_deny_inodes are executables, by inode and device.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 1024);
+    __type(key, struct exec_inode_t);
+    __type(value, __u8);
+} exec_deny_inodes SEC(".maps");
+
+// exec_deny_cgroups are cgroup v2 IDs where every exec() is denied, including in
+// every descendant cgroup.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 1024);
+    __type(key, __u64);
+    __type(value, __u8);
+} exec_deny_cgroups SEC(".maps");
+
+// exec_guard_config is set from userspace. When enforce is 0, denied
+// executions are reported, but allowed. prefixes is the number of keys in
+// exec_deny_prefixes.
+struct {
+    __uint(type, BPF_MAP_TYPE_ARRAY);
+    __uint(max_entries, 1);
+    __type(key, __u32);
+    __type(value, struct exec_guard_config_t);
+} exec_guard_config SEC(".maps");
+
+struct exec_denied_data_t {
+    __u32 type;
+    __u32 pid;
+    __u32 uid;
+    __u32 reason;
+    __u32 enforced;
+    __u32 dev;
+    __u64 ino;
+    __u64 cgroup_id;
+    __u8 comm[DATA_SIZE_32];
+    __u8 filename[DATA_SIZE_128];
+};
+
+// For Rust libbpf-rs only
+struct exec_denied_data_t _eddt = {0};
+
+// exec_scratch_t is kept off the stack, which is limited to 512 bytes.
+struct exec_scratch_t {
+    struct exec_prefix_t prefix;
+    __u8 path[EXEC_PATH_MAX];
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(max_entries, 1);
+    __type(key, __u32);
+    __type(value, struct exec_scratch_t);
+} exec_scratch SEC(".maps");
+
+// denied_cgroup will return the ID of the cgroup v2 of the current task, or of
+// its closest ancestor, that is in exec_deny_cgroups, or 0.
+static __always_inline __u64 denied_cgroup() {
+    struct task_struct *task = (struct task_struct *)bpf_get_current_task();
+    struct cgroup_subsys_state *parent = 0;
+    struct css_set *cgroups = 0;
+    struct cgroup *cgrp = 0;
+    struct kernfs_node *kn = 0;
+    __u64 id = 0;
+
+    bpf_probe_read_kernel(&cgroups, sizeof(cgroups), &task->cgroups);
+    bpf_probe_read_kernel(&cgrp, sizeof(cgrp), &cgroups->dfl_cgrp);
+    for (int i = 0; i < EXEC_CGROUP_DEPTH; i++) {
+        if (!cgrp) {
+            return 0;
+        }
+        // The ID of a cgroup is the inode of its kernfs directory
+        bpf_probe_read_kernel(&kn, sizeof(kn), &cgrp->kn);
+        bpf_probe_read_kernel(&id, sizeof(id), &kn->id);
+        if (bpf_map_lookup_elem(&exec_deny_cgroups, &id)) {
+            return id;
+        }
+        // self is the first field of struct cgroup, so the parent
+        // css is the parent cgroup
+        bpf_probe_read_kernel(&parent, sizeof(parent), &cgrp->self.parent);
+        cgrp = (struct cgroup *)parent;
+    }
+    return 0;
+}
+
+SEC("lsm/bprm_check_security")
+int BPF_PROG(bprm_check_security, struct linux_binprm *bprm, int ret){
+    struct exec_denied_data_t data = {};
+    struct exec_guard_config_t *config;
+    struct exec_scratch_t *scratch;
+    struct exec_inode_t inode = {};
+    struct file *file = 0;
+    struct inode *f_inode = 0;
+    struct super_block *sb = 0;
+    const char *filename = 0;
+    __u64 cgroup_id;
+    __u32 zero = 0;
+    long len;
+
+    // Another LSM has already denied the exec
+    if (ret != 0) {
+        return ret;
+    }
+
+    scratch = bpf_map_lookup_elem(&exec_scratch, &zero);
+    if (!scratch) {
+        return 0;
+    }
+    config = bpf_map_lookup_elem(&exec_guard_config, &zero);
+
+    // The path of the file, instead of bprm->filename, so a relative path,
+    // a symlink or /proc/self/fd/N can not avoid a prefix
+    len = bpf_d_path(&bprm->file->f_path, (char *)scratch->path, sizeof(scratch->path));
+    if (len > 1) {
+        // Every prefix fits in the key, so only the first bytes of a longer
+        // path are matched, without the NUL
+        if (len > DATA_SIZE_128 + 1) {
+            len = DATA_SIZE_128 + 1;
+        }
+        scratch->prefix.prefixlen = (len - 1) * 8;
+        bpf_probe_read_kernel(scratch->prefix.path, sizeof(scratch->prefix.path), scratch->path);
+    } else {
+        // The path is longer than EXEC_PATH_MAX, or unreachable. bprm->filename
+        // is only reported, never matched, as it can avoid a prefix.
+        scratch->prefix.prefixlen = 0;
+        bpf_probe_read_kernel(&filename, sizeof(filename), &bprm->filename);
+        bpf_probe_read_kernel_str(scratch->path, DATA_SIZE_128, filename);
+    }
+
+    bpf_probe_read_kernel(&file, sizeof(file), &bprm->file);
+    bpf_probe_read_kernel(&f_inode, sizeof(f_inode), &file->f_inode);
+    bpf_probe_read_kernel(&inode.ino, sizeof(inode.ino), &f_inode->i_ino);
+    bpf_probe_read_kernel(&sb, sizeof(sb), &f_inode->i_sb);
+    bpf_probe_read_kernel(&inode.dev, sizeof(inode.dev), &sb->s_dev);
+
+    data.cgroup_id = bpf_get_current_cgroup_id();
+    if (scratch->prefix.prefixlen == 0 && config && config->prefixes) {
+        // Fail closed, the path can not be checked against the prefixes
+        data.reason = EXEC_DENIED_UNRESOLVED;
+    } else if (scratch->prefix.prefixlen && bpf_map_lookup_elem(&exec_deny_prefixes, &scratch->prefix)) {
+        data.reason = EXEC_DENIED_PREFIX;
+    } else if (bpf_map_lookup_elem(&exec_deny_inodes, &inode)) {
+        data.reason = EXEC_DENIED_INODE;
+    } else if ((cgroup_id = denied_cgroup()) != 0) {
+        data.reason = EXEC_DENIED_CGROUP;
+        data.cgroup_id = cgroup_id;
+    } else {
+        return 0;
+    }
+
+    data.type = EVENT_TYPE_EXEC_DENIED;
+    data.pid = FIRST_32_BITS(bpf_get_current_pid_tgid());
+    data.uid = LAST_32_BITS(bpf_get_current_uid_gid());
+    data.enforced = config && config->enforce;
+    data.dev = inode.dev;
+    data.ino = inode.ino;
+    bpf_get_current_comm(data.comm, sizeof(data.comm));
+    bpf_probe_read_kernel_str(data.filename, sizeof(data.filename), scratch->path);
+
+    // Send out on the perf event map
+    bpf_perf_event_output(ctx, &lsm_events, BPF_F_CURRENT_CPU, &data, sizeof(data));
+    if (DEBUG) bpf_printk("---lsm/bprm_check_security---");
+    if (data.enforced) {
+        return -EPERM;
+    }
+    return 0;
+}
+
+char LICENSE[] SEC("license") = "GPL";
@@ -0,0 +1,116 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// CgroupRoot is where the unified (v2) cgroup hierarchy is mounted.
+var CgroupRoot = "/sys/fs/cgroup"
+
+// containerIDRegex will match the 64 character hex container ID
+// that container runtimes (docker, containerd, cri-o, podman) use
+// when naming the cgroup for a container.
+//
+//   /docker/<id>
+//   /system.slice/docker-<id>.scope
+//   /kubepods/besteffort/pod<uid>/<id>
+//   /kubepods.slice/.../cri-containerd-<id>.scope
+//   /machine.slice/libpod-<id>.scope
+var containerIDRegex = regexp.MustCompile(`([0-9a-f]{64})(\.scope)?$`)
+
+// ProcCgroup will return the cgroup path of a process.
+//
+// For the unified (v2) hierarchy this is the "0::" entry. For the
+// legacy (v1) hierarchy we return the first path that is not the
+// root cgroup, which is enough to resolve a container ID.
+func ProcCgroup(pid int) (string, error) {
+	raw, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+	var fallback string
+	for _, line := range strings.Split(string(raw), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			return parts[2], nil
+		}
+		if fallback == "" && parts[2] != "/" {
+			fallback = parts[2]
+		}
+	}
+	return fallback, nil
+}
+
+// ContainerIDFromCgroup will parse a container ID out of a cgroup path.
+// An empty string is returned if the cgroup does not belong to a container.
+func ContainerIDFromCgroup(cgroup string) string {
+	matches := containerIDRegex.FindStringSubmatch(cgroup)
+	if len(matches) < 2 {
+		return ""
+	}
+	return matches[1]
+}
+
+// ProcContainerID will return the container ID of a process,
+// or an empty string if the process is not running in a container.
+func ProcContainerID(pid int) (string, error) {
+	cgroup, err := ProcCgroup(pid)
+	if err != nil {
+		return "", err
+	}
+	return ContainerIDFromCgroup(cgroup), nil
+}
+
+// FreezeCgroup will freeze every process in a cgroup with the
+// cgroup v2 freezer. The legacy (v1) freezer is not supported.
+func FreezeCgroup(cgroup string) error {
+	return writeCgroupFreeze(cgroup, "1")
+}
+
+// ThawCgroup will thaw a cgroup frozen with FreezeCgroup().
+func ThawCgroup(cgroup string) error {
+	return writeCgroupFreeze(cgroup, "0")
+}
+
+func writeCgroupFreeze(cgroup, value string) error {
+	cgroup = filepath.Clean("/" + cgroup)
+	if cgroup == "/" {
+		return fmt.Errorf("refusing to freeze the root cgroup")
+	}
+	path := filepath.Join(CgroupRoot, cgroup, "cgroup.freeze")
+	err := ioutil.WriteFile(path, []byte(value), 0644)
+	if err != nil {
+		return fmt.Errorf("unable to write %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package system
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// TCP states in /proc/net/tcp
+// More:
+//   include/net/tcp_states.h
+const (
+	procNetTCPClose  = 0x07
+	procNetTCPListen = 0x0A
+)
+
+var procNetFiles = []struct {
+	name     string
+	protocol string
+	ipv6     bool
+}{
+	{"tcp", "tcp", false},
+	{"tcp6", "tcp", true},
+	{"udp", "udp", false},
+	{"udp6", "udp", true},
+}
+
+// Socket is a TCP or UDP socket from /proc/$pid/net. State is the
+// TCP state, which for UDP is TCP_ESTABLISHED if it is connected, and
+// TCP_CLOSE if it is not.
+type Socket struct {
+	Protocol   string
+	IPv6       bool
+	LocalAddr  net.IP
+	LocalPort  int
+	RemoteAddr net.IP
+	RemotePort int
+	State      int
+	UID        int
+	Inode      uint64
+}
+
+// Listening is true for a TCP socket in TCP_LISTEN, and for a UDP
+// socket that is bound to a port, and not connected.
+func (s *Socket) Listening() bool {
+	if s.Protocol == "tcp" {
+		return s.State == procNetTCPListen
+	}
+	return s.State == procNetTCPClose && s.LocalPort != 0
+}
+
+// ProcNetSockets will return the TCP and UDP sockets of the network
+// namespace of a process from /proc/$pid/net/{tcp,tcp6,udp,udp6}.
+// The files of a protocol that is not loaded are skipped.
+func ProcNetSockets(pid int) ([]*Socket, error) {
+	var sockets []*Socket
+	for _, file := range procNetFiles {
+		path := fmt.Sprintf("/proc/%d/net/%s", pid, file.name)
+		found, err := readProcNet(path, file.protocol, file.ipv6)
+		if os.IsNotExist(err) && file.ipv6 {
+			// IPv6 is disabled
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sockets = append(sockets, found...)
+	}
+	return sockets, nil
+}
+
+// readProcNet will read the sockets of one /proc/net file.
+//
+//   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+//    0: 0100007F:0277 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21035 1 ...
+func readProcNet(path, protocol string, ipv6 bool) ([]*Socket, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var sockets []*Socket
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // Header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		s := &Socket{
+			Protocol: protocol,
+			IPv6:     ipv6,
+		}
+		s.LocalAddr, s.LocalPort, err = procNetAddr(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		s.RemoteAddr, s.RemotePort, err = procNetAddr(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		state, err := strconv.ParseUint(fields[3], 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		s.State = int(state)
+		s.UID, err = strconv.Atoi(fields[7])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		s.Inode, err = strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		sockets = append(sockets, s)
+	}
+	return sockets, scanner.Err()
+}
+
+// procNetAddr will parse an address and port of /proc/net. The address
+// is hex of 32 bit words in host byte order, and the port is hex.
+func procNetAddr(field string) (net.IP, int, error) {
+	parts := strings.Split(field, ":")
+	if len(parts) != 2 {
+		return nil, 0, fmt.Errorf("invalid address %s", field)
+	}
+	raw, err := hex.DecodeString(parts[0])
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid address %s", field)
+	}
+
+	// Deliberate design: Like the probe, we assume a little endian host.
+	for i := 0; i < len(raw); i += 4 {
+		raw[i], raw[i+1], raw[i+2], raw[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid port %s", field)
+	}
+	return net.IP(raw), int(port), nil
+}
+
+// ProcNetNamespaces will return a process in every network namespace,
+// by the inode of the namespace.
+func ProcNetNamespaces() (map[uint64]int, error) {
+	procs, err := ProcList()
+	if err != nil {
+		return nil, err
+	}
+	namespaces := make(map[uint64]int)
+	for _, proc := range procs {
+		ns, err := ProcNamespace(proc.Pid, "net")
+		if err != nil {
+			// Kernel threads and processes that have terminated
+			continue
+		}
+		if _, ok := namespaces[ns]; !ok {
+			namespaces[ns] = proc.Pid
+		}
+	}
+	return namespaces, nil
+}
+
+// ProcSocketOwners will return the process that has each socket open,
+// by inode, from the /proc/$pid/fd links. A socket open in more than
+// one process, such as after a fork(), is owned by the lowest PID.
+func ProcSocketOwners() (map[uint64]int, error) {
+	procs, err := ProcList()
+	if err != nil {
+		return nil, err
+	}
+	owners := make(map[uint64]int)
+	for _, proc := range procs {
+		fds, err := ioutil.ReadDir(fmt.Sprintf("/proc/%d/fd", proc.Pid))
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(fmt.Sprintf("/proc/%d/fd/%s", proc.Pid, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			// socket:[21035]
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"), 10, 64)
+			if err != nil {
+				continue
+			}
+			if owner, ok := owners[inode]; !ok || proc.Pid < owner {
+				owners[inode] = proc.Pid
+			}
+		}
+	}
+	return owners, nil
+}
@@ -18,12 +18,22 @@
 package system
 
 import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/mitchellh/go-ps"
 )
 
+// clockTicks is USER_HZ, the unit of the times in /proc/$pid/stat.
+// It is 100 on every architecture Linux supports.
+const clockTicks = 100
+
 type Process struct {
 	Executable string
 	ParentPid  int
@@ -61,3 +71,200 @@ func ProcPIDLookup(pid int) (*Process, error) {
 	p.Process = proc
 	return p, nil
 }
+
+// ProcList will list every process in the /proc filesystem.
+func ProcList() ([]*Process, error) {
+	procs, err := ps.Processes()
+	if err != nil {
+		return nil, err
+	}
+	var processes []*Process
+	for _, procp := range procs {
+		processes = append(processes, &Process{
+			Executable: procp.Executable(),
+			ParentPid:  procp.PPid(),
+			Pid:        procp.Pid(),
+		})
+	}
+	return processes, nil
+}
+
+// ProcTgid will return the thread group ID of a task
+// from /proc/$pid/status. Threads have a thread group ID
+// that is different from their own ID.
+func ProcTgid(pid int) (int, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Tgid:") {
+			continue
+		}
+		return strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "Tgid:")))
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no Tgid in /proc/%d/status", pid)
+}
+
+// ProcExecutablePath will return the path of the executable
+// of a process from /proc/$pid/exe.
+func ProcExecutablePath(pid int) (string, error) {
+	return os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+}
+
+// ProcComm will return the command name of a process
+// from /proc/$pid/comm.
+func ProcComm(pid int) (string, error) {
+	raw, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
+
+// ProcStartTime will return the time a process started from the
+// starttime field of /proc/$pid/stat. The boot time is truncated
+// to the second, so the start time is never later than the actual
+// start time, and up to a second earlier.
+func ProcStartTime(pid int) (time.Time, error) {
+	raw, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// The comm in (parentheses) may have spaces and parentheses
+	stat := string(raw)
+	end := strings.LastIndex(stat, ")")
+	if end < 0 {
+		return time.Time{}, fmt.Errorf("invalid /proc/%d/stat", pid)
+	}
+	// Fields start at the state, which is field 3
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 20 {
+		return time.Time{}, fmt.Errorf("invalid /proc/%d/stat", pid)
+	}
+	ticks, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid starttime in /proc/%d/stat: %v", pid, err)
+	}
+	boot, err := BootTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return boot.Add(time.Duration(ticks) * time.Second / clockTicks), nil
+}
+
+// BootTime will return the time the system booted from the btime
+// line of /proc/stat.
+func BootTime() (time.Time, error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "btime ") {
+			continue
+		}
+		btime, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "btime ")), 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid btime in /proc/stat: %v", err)
+		}
+		return time.Unix(btime, 0), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return time.Time{}, err
+	}
+	return time.Time{}, fmt.Errorf("no btime in /proc/stat")
+}
+
+// ProcNamespace will return the inode of a namespace of a process
+// from /proc/$pid/ns/$namespace, such as "mnt" or "pid".
+func ProcNamespace(pid int, namespace string) (uint64, error) {
+	link, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/%s", pid, namespace))
+	if err != nil {
+		return 0, err
+	}
+	// mnt:[4026531840]
+	start, end := strings.Index(link, "["), strings.Index(link, "]")
+	if start < 0 || end < start {
+		return 0, fmt.Errorf("invalid namespace link %s", link)
+	}
+	return strconv.ParseUint(link[start+1:end], 10, 64)
+}
+
+// ProcNamespacePIDs will return the PIDs of a process in every
+// PID namespace it is in, from the host to its own, from the NSpid
+// line of /proc/$pid/status.
+func ProcNamespacePIDs(pid int) ([]int, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "NSpid:") {
+			continue
+		}
+		var pids []int
+		for _, field := range strings.Fields(strings.TrimPrefix(line, "NSpid:")) {
+			nsPid, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, err
+			}
+			pids = append(pids, nsPid)
+		}
+		return pids, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("no NSpid in /proc/%d/status", pid)
+}
+
+// ProcFindNamespacePID will return the PID on the host of the process
+// that has nsPid in the PID namespace of another process. This is how
+// a PID passed to a syscall by a process in a container is found.
+func ProcFindNamespacePID(pid, nsPid int) (int, error) {
+	pidNs, err := ProcNamespace(pid, "pid")
+	if err != nil {
+		return 0, err
+	}
+	pids, err := ProcNamespacePIDs(pid)
+	if err != nil {
+		return 0, err
+	}
+	level := len(pids) - 1
+	if level <= 0 {
+		// On the host, the PID is the same
+		return nsPid, nil
+	}
+	procs, err := ProcList()
+	if err != nil {
+		return 0, err
+	}
+	for _, proc := range procs {
+		candidate, err := ProcNamespacePIDs(proc.Pid)
+		if err != nil || len(candidate) <= level || candidate[level] != nsPid {
+			continue
+		}
+
+		// A PID is only unique in its own namespace
+		ns, err := ProcNamespace(proc.Pid, "pid")
+		if err != nil || ns != pidNs {
+			continue
+		}
+		return proc.Pid, nil
+	}
+	return 0, fmt.Errorf("no process %d in the pid namespace of %d", nsPid, pid)
+}
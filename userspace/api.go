@@ -0,0 +1,293 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/kris-nova/logger"
+)
+
+const (
+	// DefaultEventServerHistory is the number of recent events
+	// kept for clients that join late.
+	DefaultEventServerHistory = 1000
+
+	// DefaultEventServerBuffer is the number of events buffered for
+	// each client before events are dropped for that client.
+	DefaultEventServerBuffer = 1024
+
+	// eventServerKeepAlive is how often an idle stream is pinged
+	eventServerKeepAlive = 15 * time.Second
+)
+
+type EventServerConfig struct {
+	Address string
+
+	// Token is an optional bearer token. If set, every request must
+	// send "Authorization: Bearer <token>" or ?access_token=<token>
+	Token string
+
+	History int
+	Buffer  int
+}
+
+// EventServer is an EventHandler that will serve live events over HTTP.
+//
+//   GET /events/sse?filter=<expr>&replay=<n>     Server-Sent Events
+//   GET /events/ws?filter=<expr>&replay=<n>      WebSocket
+//   GET /events/recent?filter=<expr>&limit=<n>   JSON array of recent events
+//
+// Filters use the EventFilter syntax. A WebSocket client can replace
+// its filter at any time by sending the new expression as a text message.
+type EventServer struct {
+	config      EventServerConfig
+	broadcaster *EventBroadcaster
+	upgrader    websocket.Upgrader
+}
+
+func NewEventServer(config EventServerConfig) *EventServer {
+	if config.History <= 0 {
+		config.History = DefaultEventServerHistory
+	}
+	if config.Buffer <= 0 {
+		config.Buffer = DefaultEventServerBuffer
+	}
+	s := &EventServer{
+		config:      config,
+		broadcaster: NewEventBroadcaster(config.History),
+	}
+	if config.Token != "" {
+		// Browsers can't set headers on a WebSocket, so with a
+		// token configured any origin is allowed to connect.
+		s.upgrader.CheckOrigin = func(r *http.Request) bool { return true }
+	}
+	return s
+}
+
+// Handle will broadcast the Event to every connected client.
+func (s *EventServer) Handle(event Event) {
+	s.broadcaster.Handle(event)
+}
+
+// Broadcaster will return the EventBroadcaster backing the server.
+func (s *EventServer) Broadcaster() *EventBroadcaster {
+	return s.broadcaster
+}
+
+func (s *EventServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events/sse", s.authorize(s.serveSSE))
+	mux.HandleFunc("/events/ws", s.authorize(s.serveWebSocket))
+	mux.HandleFunc("/events/recent", s.authorize(s.serveRecent))
+	return mux
+}
+
+// ListenAndServe will serve the API on the configured address.
+// This will block.
+func (s *EventServer) ListenAndServe() error {
+	logger.Info("Serving events: %s", s.config.Address)
+	return http.ListenAndServe(s.config.Address, s.Handler())
+}
+
+func (s *EventServer) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.config.Token != "" {
+			token := r.URL.Query().Get("access_token")
+			if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+				token = strings.TrimPrefix(auth, "Bearer ")
+			}
+			if subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="dse"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// requestFilter will parse the filter and an integer parameter
+// (replay or limit) from the query string.
+func requestFilter(r *http.Request, param string) (*EventFilter, int, error) {
+	filter, err := ParseEventFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		return nil, 0, err
+	}
+	var n int
+	if v := r.URL.Query().Get(param); v != "" {
+		n, err = strconv.Atoi(v)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid %s: %v", param, err)
+		}
+	}
+	return filter, n, nil
+}
+
+func (s *EventServer) serveRecent(w http.ResponseWriter, r *http.Request) {
+	filter, limit, err := requestFilter(r, "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	events := []json.RawMessage{}
+	for _, event := range s.broadcaster.Recent(filter, limit) {
+		b, err := event.JSON()
+		if err != nil {
+			continue
+		}
+		events = append(events, b)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+func (s *EventServer) serveSSE(w http.ResponseWriter, r *http.Request) {
+	filter, replay, err := requestFilter(r, "replay")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	sub := s.broadcaster.Subscribe(filter, s.config.Buffer, replay)
+	defer sub.Close()
+	keepAlive := time.NewTicker(eventServerKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			_, err := fmt.Fprintf(w, ": keepalive\n\n")
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			b, err := event.JSON()
+			if err != nil {
+				continue
+			}
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Name(), b)
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *EventServer) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	filter, replay, err := requestFilter(r, "replay")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade() has already written the error
+		return
+	}
+	defer conn.Close()
+
+	sub := s.broadcaster.Subscribe(filter, s.config.Buffer, replay)
+	filters := make(chan string)
+	closed := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(closed)
+		for {
+			kind, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if kind != websocket.TextMessage {
+				continue
+			}
+			select {
+			case filters <- string(msg):
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	keepAlive := time.NewTicker(eventServerKeepAlive)
+	defer keepAlive.Stop()
+	defer func() {
+		sub.Close()
+	}()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-keepAlive.C:
+			err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventServerKeepAlive))
+			if err != nil {
+				return
+			}
+		case expression := <-filters:
+			filter, err := ParseEventFilter(expression)
+			if err != nil {
+				conn.WriteJSON(map[string]string{"Error": err.Error()})
+				continue
+			}
+			sub.Close()
+			sub = s.broadcaster.Subscribe(filter, s.config.Buffer, 0)
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			b, err := event.JSON()
+			if err != nil {
+				continue
+			}
+			err = conn.WriteMessage(websocket.TextMessage, b)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
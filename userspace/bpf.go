@@ -18,11 +18,17 @@
 package userspace
 
 //go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target bpfel -cc clang gen_probe ../probe/bpf.c ../probe/bpf.h -- -I/usr/include/bpf -I.
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target bpfel -cc clang gen_lsm ../probe/lsm.c ../probe/bpf.h -- -I/usr/include/bpf -I.
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/cilium/ebpf/perf"
+
 	"inet.af/netaddr"
 
 	"golang.org/x/sys/unix"
@@ -32,8 +38,63 @@ const (
 	BPFGroupSyscalls = "syscalls"
 	BPFGroupSignal   = "signal"
 	BPFGroupSock     = "sock"
+	BPFGroupSched    = "sched"
+	BPFGroupModule   = "module"
+	BPFGroupTCP      = "tcp"
+	BPFGroupSkb      = "skb"
+	BPFGroupTask     = "task"
+)
+
+// Event types are the first field of every record the probe sends on
+// the events map. These must match the EVENT_TYPE_* definitions in probe/bpf.h.
+const (
+	EventTypeSock uint32 = iota + 1
+	EventTypeSignal
+	EventTypeClone
+	EventTypeExecve
+	EventTypeFork
+	EventTypeExec
+	EventTypeExit
+	EventTypeExecDenied
+	EventTypeFileOpen
+	EventTypeFileChange
+	EventTypeMount
+	EventTypePrivilege
+	EventTypeModule
+	EventTypeBPF
+	EventTypeProcessAccess
+	EventTypeConnect
+	EventTypeAccept
+	EventTypeDNS
+	EventTypeListen
+	EventTypeTCP
 )
 
+// ErrEventType is returned when a record is decoded as the wrong event type.
+// Every ObservationPoint sees every record, so this is expected.
+var ErrEventType = errors.New("record is a different event type")
+
+// EventType will return the event type of a record.
+func EventType(record perf.Record) (uint32, error) {
+	if len(record.RawSample) < 4 {
+		return 0, fmt.Errorf("record too short: %d bytes", len(record.RawSample))
+	}
+	return binary.LittleEndian.Uint32(record.RawSample), nil
+}
+
+// decodeEvent will decode a record into data if the record is of the
+// given event type, or return ErrEventType.
+func decodeEvent(record perf.Record, eventType uint32, data interface{}) error {
+	actual, err := EventType(record)
+	if err != nil {
+		return err
+	}
+	if actual != eventType {
+		return ErrEventType
+	}
+	return binary.Read(bytes.NewBuffer(record.RawSample), binary.LittleEndian, data)
+}
+
 // IsPrivileged will check for UID 0
 func IsPrivileged() bool {
 	uid := os.Getuid()
@@ -43,6 +104,12 @@ func IsPrivileged() bool {
 	return true
 }
 
+// IsTerminal will check if a file is a terminal
+func IsTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), unix.TCGETS)
+	return err == nil
+}
+
 // SetRLimitInfinity will set the resource limit in the kernel
 // to RLIM_INFINITY
 // More:
@@ -71,6 +138,14 @@ func BytesToString32(bytes [32]byte) string {
 	return str
 }
 
+// BytesToString converts a NUL terminated byte array to a string
+func BytesToString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
 func IPV4(bytes [4]byte) string {
 	var ip string
 	for _, oct := range bytes {
@@ -88,3 +163,14 @@ func IPV6(bytes [16]byte) string {
 	i := netaddr.IPv6Raw(bytes)
 	return i.String()
 }
+
+// inetAddr will return the address of an AF_INET or AF_INET6 socket,
+// where an IPv4 address is the first 4 bytes.
+func inetAddr(family uint16, addr [16]byte) string {
+	if family == unix.AF_INET {
+		var ip [4]byte
+		copy(ip[:], addr[:4])
+		return IPV4(ip)
+	}
+	return IPV6(addr)
+}
@@ -0,0 +1,57 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// EventProcessAccess will decode a record from the sys_exit tracepoint
+// of ptrace(), process_vm_readv(), process_vm_writev(), or an open of
+// /proc/<pid>/mem.
+func EventProcessAccess(event perf.Record) (*process_access_data_t, error) {
+	var data process_access_data_t
+	err := decodeEvent(event, EventTypeProcessAccess, &data)
+	if err != nil {
+		return nil, fmt.Errorf("process access kernel event perf: %w", err)
+	}
+	return &data, nil
+}
+
+type process_access_data_t struct {
+	Type     uint32
+	Op       uint32
+	Pid      uint32
+	Ppid     uint32
+	Uid      uint32
+	Target   int32
+	Ret      int64
+	Request  uint64
+	Addr     uint64
+	Flags    uint32
+	_        uint32
+	Comm     [32]byte
+	Filename [64]byte
+}
@@ -18,26 +18,25 @@
 package userspace
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
 
 	"github.com/cilium/ebpf/perf"
 )
 
 func EventClone(event perf.Record) (*clone_data_t, error) {
-	buffer := bytes.NewBuffer(event.RawSample)
 	var data clone_data_t
-	err := binary.Read(buffer, binary.LittleEndian, &data)
+	err := decodeEvent(event, EventTypeClone, &data)
 	if err != nil {
-		return nil, fmt.Errorf("clone() kernel event perf: %v", err)
+		return nil, fmt.Errorf("clone() kernel event perf: %w", err)
 	}
 	return &data, nil
 }
 
 type clone_data_t struct {
+	Type        uint32
 	Parent_tid  uint32
 	Child_tid   uint32
+	Uid         uint32
 	Clone_flags uint64
 	TLS         uint64
 }
@@ -0,0 +1,71 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// EventConnect will decode a record from the sys_exit_connect tracepoint.
+func EventConnect(event perf.Record) (*sock_call_data_t, error) {
+	var data sock_call_data_t
+	err := decodeEvent(event, EventTypeConnect, &data)
+	if err != nil {
+		return nil, fmt.Errorf("connect kernel event perf: %w", err)
+	}
+	return &data, nil
+}
+
+// EventAccept will decode a record from the sys_exit tracepoint
+// of accept() or accept4().
+func EventAccept(event perf.Record) (*sock_call_data_t, error) {
+	var data sock_call_data_t
+	err := decodeEvent(event, EventTypeAccept, &data)
+	if err != nil {
+		return nil, fmt.Errorf("accept kernel event perf: %w", err)
+	}
+	return &data, nil
+}
+
+type sock_call_data_t struct {
+	Type      uint32
+	Op        uint32
+	Pid       uint32
+	Ppid      uint32
+	Uid       uint32
+	Fd        int32
+	Ret       int32
+	Flags     uint32
+	Family    uint16
+	Sock_type uint16
+	Lport     uint16
+	Rport     uint16
+	Peer_pid  uint32
+	Netns     uint32
+	Laddr     [16]byte
+	Raddr     [16]byte
+	Comm      [32]byte
+	Path      [108]byte
+}
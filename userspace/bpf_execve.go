@@ -18,25 +18,25 @@
 package userspace
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
 
 	"github.com/cilium/ebpf/perf"
 )
 
 func EventExecve(event perf.Record) (*execve_data_t, error) {
-	buffer := bytes.NewBuffer(event.RawSample)
 	var data execve_data_t
-	err := binary.Read(buffer, binary.LittleEndian, &data)
+	err := decodeEvent(event, EventTypeExecve, &data)
 	if err != nil {
-		return nil, fmt.Errorf("execve() kernel event perf: %v", err)
+		return nil, fmt.Errorf("execve() kernel event perf: %w", err)
 	}
 	return &data, nil
 }
 
 type execve_data_t struct {
+	Type     uint32
 	Pid      uint32
+	Ppid     uint32
+	Uid      uint32
 	Filename [32]byte
 	Comm     [32]byte
 }
@@ -0,0 +1,87 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// EventFileOpen will decode a record from the sys_exit_openat
+// and sys_exit_openat2 tracepoints.
+func EventFileOpen(event perf.Record) (*file_open_data_t, error) {
+	var data file_open_data_t
+	err := decodeEvent(event, EventTypeFileOpen, &data)
+	if err != nil {
+		return nil, fmt.Errorf("openat kernel event perf: %w", err)
+	}
+	return &data, nil
+}
+
+// EventFileChange will decode a record from the sys_exit tracepoint
+// of any of the file changes.
+func EventFileChange(event perf.Record) (*file_change_data_t, error) {
+	var data file_change_data_t
+	err := decodeEvent(event, EventTypeFileChange, &data)
+	if err != nil {
+		return nil, fmt.Errorf("file change kernel event perf: %w", err)
+	}
+	return &data, nil
+}
+
+// file_prefix_t is the key of the file_prefixes map.
+type file_prefix_t struct {
+	Prefixlen uint32
+	Path      [256]byte
+}
+
+type file_open_data_t struct {
+	Type     uint32
+	Pid      uint32
+	Ppid     uint32
+	Uid      uint32
+	Dfd      int32
+	Flags    int32
+	Mode     uint32
+	Ret      int32
+	Comm     [32]byte
+	Filename [256]byte
+}
+
+type file_change_data_t struct {
+	Type     uint32
+	Op       uint32
+	Pid      uint32
+	Ppid     uint32
+	Uid      uint32
+	Mode     uint32
+	Owner    uint32
+	Group    uint32
+	Flags    int32
+	Ret      int32
+	Length   int64
+	Comm     [32]byte
+	Filename [256]byte
+	Newname  [256]byte
+}
@@ -0,0 +1,62 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// EventLifecycle will decode a record from any of the
+// task_newtask, sched_process_exec or sched_process_exit
+// tracepoints.
+func EventLifecycle(event perf.Record) (*process_data_t, error) {
+	eventType, err := EventType(event)
+	if err != nil {
+		return nil, fmt.Errorf("sched_process kernel event perf: %w", err)
+	}
+	switch eventType {
+	case EventTypeFork, EventTypeExec, EventTypeExit:
+	default:
+		return nil, ErrEventType
+	}
+	var data process_data_t
+	err = decodeEvent(event, eventType, &data)
+	if err != nil {
+		return nil, fmt.Errorf("sched_process kernel event perf: %w", err)
+	}
+	return &data, nil
+}
+
+type process_data_t struct {
+	Type      uint32
+	Pid       uint32
+	Tid       uint32
+	Ppid      uint32
+	Child_pid uint32
+	Uid       uint32
+	Exit_code int32
+	Comm      [32]byte
+	Filename  [128]byte
+}
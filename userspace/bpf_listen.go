@@ -0,0 +1,40 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// EventListen will decode a record from the sys_exit tracepoint of
+// bind() or listen(), or a TCP listener closed in inet_sock_set_state.
+func EventListen(event perf.Record) (*sock_call_data_t, error) {
+	var data sock_call_data_t
+	err := decodeEvent(event, EventTypeListen, &data)
+	if err != nil {
+		return nil, fmt.Errorf("listen kernel event perf: %w", err)
+	}
+	return &data, nil
+}
@@ -0,0 +1,84 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// EventModule will decode a record from the sys_exit tracepoint
+// of init_module(), finit_module() or delete_module().
+func EventModule(event perf.Record) (*module_data_t, error) {
+	var data module_data_t
+	err := decodeEvent(event, EventTypeModule, &data)
+	if err != nil {
+		return nil, fmt.Errorf("module kernel event perf: %w", err)
+	}
+	return &data, nil
+}
+
+// EventBPF will decode a record from the sys_exit_bpf tracepoint.
+func EventBPF(event perf.Record) (*bpf_data_t, error) {
+	var data bpf_data_t
+	err := decodeEvent(event, EventTypeBPF, &data)
+	if err != nil {
+		return nil, fmt.Errorf("bpf kernel event perf: %w", err)
+	}
+	return &data, nil
+}
+
+type module_data_t struct {
+	Type     uint32
+	Op       uint32
+	Pid      uint32
+	Ppid     uint32
+	Uid      uint32
+	Ret      int32
+	Flags    uint32
+	Taints   uint32
+	Len      uint64
+	Comm     [32]byte
+	Name     [64]byte
+	Params   [128]byte
+	Filename [256]byte
+}
+
+type bpf_data_t struct {
+	Type        uint32
+	Pid         uint32
+	Ppid        uint32
+	Uid         uint32
+	Ret         int32
+	Cmd         uint32
+	Prog_type   uint32
+	Attach_type uint32
+	Insn_cnt    uint32
+	Prog_fd     uint32
+	Target_fd   uint32
+	Prog_flags  uint32
+	Comm        [32]byte
+	Prog_name   [16]byte
+	Tracepoint  [64]byte
+}
@@ -0,0 +1,53 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// EventExecDenied will decode a record from the
+// lsm/bprm_check_security program.
+func EventExecDenied(event perf.Record) (*exec_denied_data_t, error) {
+	var data exec_denied_data_t
+	err := decodeEvent(event, EventTypeExecDenied, &data)
+	if err != nil {
+		return nil, fmt.Errorf("bprm_check_security kernel event perf: %w", err)
+	}
+	return &data, nil
+}
+
+type exec_denied_data_t struct {
+	Type      uint32
+	Pid       uint32
+	Uid       uint32
+	Reason    uint32
+	Enforced  uint32
+	Dev       uint32
+	Ino       uint64
+	Cgroup_id uint64
+	Comm      [32]byte
+	Filename  [128]byte
+}
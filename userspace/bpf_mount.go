@@ -0,0 +1,56 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// EventMount will decode a record from the sys_exit tracepoint of
+// any of the mount syscalls.
+func EventMount(event perf.Record) (*mount_data_t, error) {
+	var data mount_data_t
+	err := decodeEvent(event, EventTypeMount, &data)
+	if err != nil {
+		return nil, fmt.Errorf("mount kernel event perf: %w", err)
+	}
+	return &data, nil
+}
+
+type mount_data_t struct {
+	Type   uint32
+	Op     uint32
+	Pid    uint32
+	Ppid   uint32
+	Uid    uint32
+	Mnt_ns uint32
+	Flags  uint64
+	Attr   uint32
+	Ret    int32
+	Comm   [32]byte
+	Fstype [32]byte
+	Source [256]byte
+	Target [256]byte
+}
@@ -0,0 +1,69 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// EventPrivilege will decode a record from the sys_exit tracepoint
+// of any of the credential syscalls, or from sched_process_exec.
+func EventPrivilege(event perf.Record) (*priv_data_t, error) {
+	var data priv_data_t
+	err := decodeEvent(event, EventTypePrivilege, &data)
+	if err != nil {
+		return nil, fmt.Errorf("privilege kernel event perf: %w", err)
+	}
+	return &data, nil
+}
+
+type cred_data_t struct {
+	Uid             uint32
+	Gid             uint32
+	Euid            uint32
+	Egid            uint32
+	Suid            uint32
+	Sgid            uint32
+	Fsuid           uint32
+	Fsgid           uint32
+	Cap_inheritable uint64
+	Cap_permitted   uint64
+	Cap_effective   uint64
+}
+
+type priv_data_t struct {
+	Type     uint32
+	Op       uint32
+	Pid      uint32
+	Ppid     uint32
+	Ret      int32
+	Ngroups  uint32
+	Args     [3]uint64
+	Old      cred_data_t
+	New      cred_data_t
+	Groups   [16]uint32
+	Comm     [32]byte
+	Filename [128]byte
+}
@@ -18,24 +18,22 @@
 package userspace
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
 
 	"github.com/cilium/ebpf/perf"
 )
 
 func EventSignal(event perf.Record) (*signal_data_t, error) {
-	buffer := bytes.NewBuffer(event.RawSample)
 	var data signal_data_t
-	err := binary.Read(buffer, binary.LittleEndian, &data)
+	err := decodeEvent(event, EventTypeSignal, &data)
 	if err != nil {
-		return nil, fmt.Errorf("signal_delivered() kernel event perf: %v", err)
+		return nil, fmt.Errorf("signal_delivered() kernel event perf: %w", err)
 	}
 	return &data, nil
 }
 
 type signal_data_t struct {
+	Type          uint32
 	Signal        int32
 	Errno         int32
 	Code          int32
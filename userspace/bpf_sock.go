@@ -18,24 +18,23 @@
 package userspace
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
 
 	"github.com/cilium/ebpf/perf"
 )
 
 func EventSock(event perf.Record) (*inet_sock_data_t, error) {
-	buffer := bytes.NewBuffer(event.RawSample)
 	var data inet_sock_data_t
-	err := binary.Read(buffer, binary.LittleEndian, &data)
+	err := decodeEvent(event, EventTypeSock, &data)
 	if err != nil {
-		return nil, fmt.Errorf("inet_sock kernel event perf: %v", err)
+		return nil, fmt.Errorf("inet_sock kernel event perf: %w", err)
 	}
 	return &data, nil
 }
 
 type inet_sock_data_t struct {
+	Type     uint32
+	Pid      uint32
 	OldState int32
 	NewState int32
 	Sport    uint16
@@ -46,4 +45,5 @@ type inet_sock_data_t struct {
 	Daddr    [4]byte
 	Saddr_v6 [16]byte
 	Daddr_v6 [16]byte
+	Comm     [32]byte
 }
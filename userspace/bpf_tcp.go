@@ -0,0 +1,57 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// EventTCP will decode a record from the tcp tracepoints, or from
+// the kfree_skb tracepoint.
+func EventTCP(event perf.Record) (*tcp_data_t, error) {
+	var data tcp_data_t
+	err := decodeEvent(event, EventTypeTCP, &data)
+	if err != nil {
+		return nil, fmt.Errorf("tcp kernel event perf: %w", err)
+	}
+	return &data, nil
+}
+
+type tcp_data_t struct {
+	Type   uint32
+	Op     uint32
+	Pid    uint32
+	State  int32
+	Reason uint32
+	Netns  uint32
+	Drops  uint32
+	Family uint16
+	Sport  uint16
+	Dport  uint16
+	_      uint16
+	Saddr  [16]byte
+	Daddr  [16]byte
+	Comm   [32]byte
+}
@@ -0,0 +1,67 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// TestEventErrEventType will decode a record of another event type
+// with every decoder. eventLoop ignores the records of other event
+// types with errors.Is(err, ErrEventType).
+func TestEventErrEventType(t *testing.T) {
+	decoders := map[string]func(perf.Record) error{
+		"EventClone":         func(r perf.Record) error { _, err := EventClone(r); return err },
+		"EventFileOpen":      func(r perf.Record) error { _, err := EventFileOpen(r); return err },
+		"EventFileChange":    func(r perf.Record) error { _, err := EventFileChange(r); return err },
+		"EventMount":         func(r perf.Record) error { _, err := EventMount(r); return err },
+		"EventPrivilege":     func(r perf.Record) error { _, err := EventPrivilege(r); return err },
+		"EventModule":        func(r perf.Record) error { _, err := EventModule(r); return err },
+		"EventBPF":           func(r perf.Record) error { _, err := EventBPF(r); return err },
+		"EventProcessAccess": func(r perf.Record) error { _, err := EventProcessAccess(r); return err },
+		"EventConnect":       func(r perf.Record) error { _, err := EventConnect(r); return err },
+		"EventAccept":        func(r perf.Record) error { _, err := EventAccept(r); return err },
+		"EventDNS":           func(r perf.Record) error { _, err := EventDNS(r); return err },
+		"EventListen":        func(r perf.Record) error { _, err := EventListen(r); return err },
+		"EventTCP":           func(r perf.Record) error { _, err := EventTCP(r); return err },
+		"EventExecve":        func(r perf.Record) error { _, err := EventExecve(r); return err },
+		"EventLifecycle":     func(r perf.Record) error { _, err := EventLifecycle(r); return err },
+		"EventExecDenied":    func(r perf.Record) error { _, err := EventExecDenied(r); return err },
+		"EventSignal":        func(r perf.Record) error { _, err := EventSignal(r); return err },
+		"EventSock":          func(r perf.Record) error { _, err := EventSock(r); return err },
+	}
+	// No event has type 0
+	record := perf.Record{RawSample: make([]byte, 4096)}
+	binary.LittleEndian.PutUint32(record.RawSample, 0)
+	for name, decode := range decoders {
+		t.Run(name, func(t *testing.T) {
+			if err := decode(record); !errors.Is(err, ErrEventType) {
+				t.Errorf("expected ErrEventType, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// EventDNS will decode a record of a UDP message on port 53, from
+// the sys_exit tracepoint of any of the send or receive syscalls.
+func EventDNS(event perf.Record) (*dns_data_t, error) {
+	var data dns_data_t
+	err := decodeEvent(event, EventTypeDNS, &data)
+	if err != nil {
+		return nil, fmt.Errorf("dns kernel event perf: %w", err)
+	}
+	return &data, nil
+}
+
+type udp_flow_key_t struct {
+	Pid    uint32
+	Family uint16
+	Lport  uint16
+	Rport  uint16
+	_      uint16
+	Laddr  [16]byte
+	Raddr  [16]byte
+}
+
+type udp_flow_t struct {
+	Tx_bytes   uint64
+	Tx_packets uint64
+	Rx_bytes   uint64
+	Rx_packets uint64
+	Last       uint64
+	Comm       [32]byte
+}
+
+type dns_data_t struct {
+	Type      uint32
+	Pid       uint32
+	Ppid      uint32
+	Uid       uint32
+	Direction uint32
+	Len       uint32
+	Family    uint16
+	Lport     uint16
+	Rport     uint16
+	_         uint16
+	Laddr     [16]byte
+	Raddr     [16]byte
+	Comm      [32]byte
+	Payload   [512]byte
+}
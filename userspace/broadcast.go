@@ -0,0 +1,171 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// EventBroadcaster is an EventHandler that will fan the Observer's
+// events out to any number of subscribers, and keep a ring buffer
+// of recent events for subscribers that join late.
+//
+// Subscribers never block the Observer. Events are dropped for a
+// subscriber that is not keeping up.
+type EventBroadcaster struct {
+	mtx         sync.RWMutex
+	subscribers map[*EventSubscription]bool
+	history     []Event
+	next        int
+	full        bool
+}
+
+// EventSubscription is a single subscriber to an EventBroadcaster.
+type EventSubscription struct {
+	C           chan Event
+	filter      *EventFilter
+	dropped     uint64
+	broadcaster *EventBroadcaster
+	once        sync.Once
+}
+
+// NewEventBroadcaster will create a broadcaster that keeps the
+// last history events.
+func NewEventBroadcaster(history int) *EventBroadcaster {
+	return &EventBroadcaster{
+		subscribers: map[*EventSubscription]bool{},
+		history:     make([]Event, history),
+	}
+}
+
+// Handle will record the Event in the history, and send the Event
+// to every subscriber whose filter matches.
+func (b *EventBroadcaster) Handle(event Event) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if len(b.history) > 0 {
+		b.history[b.next] = event
+		b.next = (b.next + 1) % len(b.history)
+		if b.next == 0 {
+			b.full = true
+		}
+	}
+	for s := range b.subscribers {
+		s.send(event)
+	}
+}
+
+// Recent will return up to limit of the most recent events matching
+// the filter, oldest first. A limit <= 0 returns the entire history.
+func (b *EventBroadcaster) Recent(filter *EventFilter, limit int) []Event {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	return b.recent(filter, limit)
+}
+
+func (b *EventBroadcaster) recent(filter *EventFilter, limit int) []Event {
+	var ordered []Event
+	if b.full {
+		ordered = append(ordered, b.history[b.next:]...)
+	}
+	ordered = append(ordered, b.history[:b.next]...)
+	var events []Event
+	for _, event := range ordered {
+		if filter.Match(event) {
+			events = append(events, event)
+		}
+	}
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	return events
+}
+
+// Subscribe will create a new subscription with room for buffer events.
+// If replay > 0, up to replay recent events matching the filter are
+// delivered before any new events.
+func (b *EventBroadcaster) Subscribe(filter *EventFilter, buffer, replay int) *EventSubscription {
+	s := &EventSubscription{
+		filter:      filter,
+		broadcaster: b,
+	}
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	var recent []Event
+	if replay > 0 {
+		recent = b.recent(filter, replay)
+	}
+	s.C = make(chan Event, buffer+len(recent))
+	for _, event := range recent {
+		s.C <- event
+	}
+	b.subscribers[s] = true
+	return s
+}
+
+// Unsubscribe will stop delivering events to the subscription and
+// close its channel.
+func (b *EventBroadcaster) Unsubscribe(s *EventSubscription) {
+	s.once.Do(func() {
+		b.mtx.Lock()
+		delete(b.subscribers, s)
+		b.mtx.Unlock()
+		close(s.C)
+	})
+}
+
+// Close will unsubscribe every subscriber.
+func (b *EventBroadcaster) Close() {
+	b.mtx.RLock()
+	var subscribers []*EventSubscription
+	for s := range b.subscribers {
+		subscribers = append(subscribers, s)
+	}
+	b.mtx.RUnlock()
+	for _, s := range subscribers {
+		b.Unsubscribe(s)
+	}
+}
+
+func (s *EventSubscription) send(event Event) {
+	if !s.filter.Match(event) {
+		return
+	}
+	select {
+	case s.C <- event:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+// Dropped is the number of events dropped because the
+// subscriber was not keeping up.
+func (s *EventSubscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close is the same as Unsubscribe()
+func (s *EventSubscription) Close() {
+	s.broadcaster.Unsubscribe(s)
+}
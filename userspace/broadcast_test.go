@@ -0,0 +1,128 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"testing"
+)
+
+func TestEventBroadcasterRecent(t *testing.T) {
+	tests := []struct {
+		name    string
+		history int
+		events  []uint
+		filter  string
+		limit   int
+		recent  []uint
+	}{
+		{
+			name:    "partial history",
+			history: 5,
+			events:  []uint{1, 2, 3},
+			recent:  []uint{1, 2, 3},
+		},
+		{
+			name:    "wrapped history",
+			history: 3,
+			events:  []uint{1, 2, 3, 4, 5},
+			recent:  []uint{3, 4, 5},
+		},
+		{
+			name:    "limit",
+			history: 5,
+			events:  []uint{1, 2, 3, 4},
+			limit:   2,
+			recent:  []uint{3, 4},
+		},
+		{
+			name:    "filter",
+			history: 5,
+			events:  []uint{1, 2, 3, 4, 5},
+			filter:  `PID >= 3`,
+			limit:   2,
+			recent:  []uint{4, 5},
+		},
+		{
+			name:    "no history",
+			history: 0,
+			events:  []uint{1, 2},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := NewEventBroadcaster(test.history)
+			for _, pid := range test.events {
+				b.Handle(&ProcessEvent{PID: pid})
+			}
+			recent := b.Recent(MustParseEventFilter(test.filter), test.limit)
+			assertPIDs(t, recent, test.recent)
+		})
+	}
+}
+
+func TestEventBroadcasterSubscribe(t *testing.T) {
+	b := NewEventBroadcaster(10)
+	b.Handle(&ProcessEvent{PID: 1})
+	b.Handle(&ProcessEvent{PID: 2})
+
+	all := b.Subscribe(nil, 10, 0)
+	replay := b.Subscribe(MustParseEventFilter(`PID != 3`), 10, 5)
+	slow := b.Subscribe(nil, 1, 0)
+
+	for _, pid := range []uint{3, 4, 5} {
+		b.Handle(&ProcessEvent{PID: pid})
+	}
+	b.Close()
+
+	assertPIDs(t, drain(all), []uint{3, 4, 5})
+	assertPIDs(t, drain(replay), []uint{1, 2, 4, 5})
+	assertPIDs(t, drain(slow), []uint{3})
+	if slow.Dropped() != 2 {
+		t.Errorf("expected 2 dropped events, got %d", slow.Dropped())
+	}
+
+	// Unsubscribe is safe to call more than once
+	all.Close()
+	b.Unsubscribe(all)
+	b.Handle(&ProcessEvent{PID: 6})
+}
+
+func drain(s *EventSubscription) []Event {
+	var events []Event
+	for event := range s.C {
+		events = append(events, event)
+	}
+	return events
+}
+
+func assertPIDs(t *testing.T, events []Event, expected []uint) {
+	t.Helper()
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d", len(expected), len(events))
+	}
+	for i, event := range events {
+		if pid := event.(*ProcessEvent).PID; pid != expected[i] {
+			t.Errorf("event %d: expected PID %d, got %d", i, expected[i], pid)
+		}
+	}
+}
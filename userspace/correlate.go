@@ -0,0 +1,149 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kris-nova/logger"
+)
+
+const (
+	// DefaultRuleMaxSequences is the number of sequences a Rule
+	// will follow at once. Once reached, new sequences are ignored
+	// until the oldest expire.
+	DefaultRuleMaxSequences = 10000
+)
+
+// ruleSequences are the partial matches of a single sequence Rule,
+// by the values of its "by" fields.
+type ruleSequences struct {
+	partials map[string]*rulePartial
+	pruned   time.Time
+}
+
+// rulePartial is a sequence that has matched the first next
+// conditions, starting at start.
+type rulePartial struct {
+	start  time.Time
+	next   int
+	events []Event
+}
+
+// correlate will follow the sequence Rule with the Event, and return
+// every event of the sequence once the Event completes it.
+func (e *RuleEngine) correlate(rule *Rule, event Event) []Event {
+	key, ok := ruleSequenceKey(rule, event)
+	if !ok {
+		return nil
+	}
+	now := EventTimestamp(event)
+
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	sequences, ok := e.sequences[rule.ID]
+	if !ok {
+		sequences = &ruleSequences{
+			partials: map[string]*rulePartial{},
+			pruned:   now,
+		}
+		e.sequences[rule.ID] = sequences
+	}
+	if now.Sub(sequences.pruned) > rule.within {
+		sequences.prune(now, rule.within)
+	}
+
+	// Advance a sequence that is in progress
+	partial, ok := sequences.partials[key]
+	if ok && now.Sub(partial.start) > rule.within {
+		delete(sequences.partials, key)
+		ok = false
+	}
+	if ok && rule.sequence[partial.next].Match(event) {
+		partial.events = append(partial.events, event)
+		partial.next++
+		if partial.next < len(rule.sequence) {
+			return nil
+		}
+		delete(sequences.partials, key)
+		return partial.events
+	}
+
+	// Or start a new sequence, replacing one in progress
+	if !rule.sequence[0].Match(event) {
+		return nil
+	}
+	if !ok && len(sequences.partials) >= DefaultRuleMaxSequences {
+		sequences.prune(now, rule.within)
+		if len(sequences.partials) >= DefaultRuleMaxSequences {
+			logger.Debug("Rule %s is following %d sequences, ignoring new sequences", rule.ID, len(sequences.partials))
+			return nil
+		}
+	}
+	sequences.partials[key] = &rulePartial{
+		start:  now,
+		next:   1,
+		events: []Event{event},
+	}
+	return nil
+}
+
+// prune will forget every sequence that started before the window.
+func (s *ruleSequences) prune(now time.Time, within time.Duration) {
+	for key, partial := range s.partials {
+		if now.Sub(partial.start) > within {
+			delete(s.partials, key)
+		}
+	}
+	s.pruned = now
+}
+
+// ruleSequenceKey will return the values of the "by" fields of a
+// Rule for the Event. Events that do not have every field set can
+// not be correlated, except for ContainerID, which is empty on the
+// host.
+func ruleSequenceKey(rule *Rule, event Event) (string, bool) {
+	values := make([]string, len(rule.by))
+	for i, field := range rule.by {
+		value := ruleSequenceValue(event, field)
+		if value == "" && field != "ContainerID" {
+			return "", false
+		}
+		values[i] = value
+	}
+	return strings.Join(values, "\x00"), true
+}
+
+// ruleSequenceValue will return the value of the first alternative
+// of a "by" field that is set on the Event, such as the ChildPID of
+// a clone() for ChildPID|PID, and the PID of every other event.
+func ruleSequenceValue(event Event, field string) string {
+	for _, alternative := range strings.Split(field, "|") {
+		value := EventFieldString(event, strings.TrimSpace(alternative))
+		if value != "" && value != "0" {
+			return value
+		}
+	}
+	return ""
+}
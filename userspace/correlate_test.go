@@ -0,0 +1,159 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"testing"
+	"time"
+)
+
+// sequenceEvent is a ProcessEvent at a time, for correlation.
+func sequenceEvent(name string, pid uint, containerID string, at time.Time) *ProcessEvent {
+	e := &ProcessEvent{EventName: name, PID: pid, ContainerMetadata: ContainerMetadata{ContainerID: containerID}}
+	e.setTimestamp(at)
+	return e
+}
+
+func TestCorrelate(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	tests := []struct {
+		name   string
+		by     []string
+		events []*ProcessEvent
+		alert  bool
+	}{
+		{
+			name: "default by",
+			events: []*ProcessEvent{
+				sequenceEvent("A", 42, "abc123", start),
+				sequenceEvent("B", 42, "abc123", start.Add(time.Second)),
+			},
+			alert: true,
+		},
+		{
+			name: "default by on the host",
+			events: []*ProcessEvent{
+				sequenceEvent("A", 42, "", start),
+				sequenceEvent("B", 42, "", start.Add(time.Second)),
+			},
+			alert: true,
+		},
+		{
+			name: "default by in another container",
+			events: []*ProcessEvent{
+				sequenceEvent("A", 42, "abc123", start),
+				sequenceEvent("B", 42, "def456", start.Add(time.Second)),
+			},
+		},
+		{
+			name: "default by in another process",
+			events: []*ProcessEvent{
+				sequenceEvent("A", 42, "abc123", start),
+				sequenceEvent("B", 43, "abc123", start.Add(time.Second)),
+			},
+		},
+		{
+			name: "out of order",
+			events: []*ProcessEvent{
+				sequenceEvent("B", 42, "", start),
+				sequenceEvent("A", 42, "", start.Add(time.Second)),
+			},
+		},
+		{
+			name: "outside the window",
+			events: []*ProcessEvent{
+				sequenceEvent("A", 42, "", start),
+				sequenceEvent("B", 42, "", start.Add(11*time.Second)),
+			},
+		},
+		{
+			name: "restarted inside the window",
+			events: []*ProcessEvent{
+				sequenceEvent("A", 42, "", start),
+				sequenceEvent("A", 42, "", start.Add(9*time.Second)),
+				sequenceEvent("B", 42, "", start.Add(11*time.Second)),
+			},
+			alert: true,
+		},
+		{
+			name: "unset by field",
+			by:   []string{"PPID"},
+			events: []*ProcessEvent{
+				sequenceEvent("A", 42, "", start),
+				sequenceEvent("B", 42, "", start.Add(time.Second)),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine := NewRuleEngine(MustCompileRules(Rules{{
+				ID:       "sequence",
+				Severity: "low",
+				Sequence: []string{`Name == "A"`, `Name == "B"`},
+				By:       test.by,
+			}}))
+			var alerts []Event
+			for _, event := range test.events {
+				alerts = append(alerts, engine.Produce(event)...)
+			}
+			if (len(alerts) == 1) != test.alert {
+				t.Fatalf("expected alert=%v, got %d alerts", test.alert, len(alerts))
+			}
+			if test.alert && len(alerts[0].(*AlertEvent).Events) != 2 {
+				t.Errorf("expected 2 events in the alert, got %d", len(alerts[0].(*AlertEvent).Events))
+			}
+		})
+	}
+}
+
+func TestRuleSequenceKey(t *testing.T) {
+	clone := &PrivilegeEvent{EventName: EventNameNamespaceChanged, Syscall: "clone", PID: 42, ChildPID: 43}
+	child := &PrivilegeEvent{EventName: EventNameNamespaceChanged, Syscall: "setns", PID: 43}
+	tests := []struct {
+		name  string
+		by    []string
+		event Event
+		key   string
+		ok    bool
+	}{
+		{"default", nil, sequenceEvent("A", 42, "abc123", time.Time{}), "42\x00abc123", true},
+		{"host", nil, sequenceEvent("A", 42, "", time.Time{}), "42\x00", true},
+		{"unset", []string{"PPID"}, sequenceEvent("A", 42, "", time.Time{}), "", false},
+		{"first alternative", []string{"ChildPID|PID"}, clone, "43", true},
+		{"second alternative", []string{"ChildPID|PID"}, child, "43", true},
+		{"missing alternative", []string{"Missing|PID"}, child, "43", true},
+		{"no alternative", []string{"ChildPID|PPID"}, child, "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule := &Rule{ID: "x", Severity: "low", Sequence: []string{"PID", "PID"}, By: test.by}
+			if err := rule.Compile(); err != nil {
+				t.Fatal(err)
+			}
+			key, ok := ruleSequenceKey(rule, test.event)
+			if key != test.key || ok != test.ok {
+				t.Errorf("expected %q %v, got %q %v", test.key, test.ok, key, ok)
+			}
+		})
+	}
+}
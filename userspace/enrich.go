@@ -0,0 +1,187 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kris-nova/logger"
+
+	"github.com/kris-nova/double-slit-experiment/system"
+)
+
+// Enricher will add metadata to an Event after it leaves an
+// ObservationPoint, and before it reaches any EventHandler.
+//
+// Enrich is called from the Observer's single dispatch goroutine,
+// so a slow Enricher will slow down every Event behind it.
+type Enricher interface {
+	Enrich(event Event)
+}
+
+// ContainerMetadata is embedded in every Event that can be attributed
+// to a container. The ContainerID is resolved from the cgroup of the
+// process, and the remaining fields are filled in by an Enricher.
+//
+// The fields are flattened into the Event, so they can be used directly
+// in filters, rules and metric labels.
+//
+//   PodNamespace == "kube-system" && ContainerName != "kube-proxy"
+type ContainerMetadata struct {
+	ContainerID      string            `json:"ContainerID"`
+	ContainerName    string            `json:"ContainerName,omitempty"`
+	ContainerImage   string            `json:"ContainerImage,omitempty"`
+	ContainerImageID string            `json:"ContainerImageID,omitempty"`
+	ContainerLabels  map[string]string `json:"ContainerLabels,omitempty"`
+	PodName          string            `json:"PodName,omitempty"`
+	PodNamespace     string            `json:"PodNamespace,omitempty"`
+	PodUID           string            `json:"PodUID,omitempty"`
+	PodLabels        map[string]string `json:"PodLabels,omitempty"`
+	ComposeProject   string            `json:"ComposeProject,omitempty"`
+	ComposeService   string            `json:"ComposeService,omitempty"`
+}
+
+// Container will return the ContainerMetadata embedded in an Event.
+func (m *ContainerMetadata) Container() *ContainerMetadata {
+	return m
+}
+
+// containerMetadataForPID will return the ContainerMetadata of a
+// process, with the ContainerID resolved from its cgroup. PID 0 is
+// not a process, such as a packet handled in softirq.
+//
+// Deliberate design: We ignore errors if we can't lookup the cgroup.
+// There is a non-zero chance the process has terminated.
+func containerMetadataForPID(pid int) ContainerMetadata {
+	if pid == 0 {
+		return ContainerMetadata{}
+	}
+	containerID, err := system.ProcContainerID(pid)
+	if err != nil {
+		logger.Debug(err.Error())
+	}
+	return ContainerMetadata{
+		ContainerID: containerID,
+	}
+}
+
+// ContainerEnrichable is implemented by every Event that embeds
+// ContainerMetadata.
+type ContainerEnrichable interface {
+	Event
+	Container() *ContainerMetadata
+}
+
+// Merge will copy every field that is set in from, except for the ContainerID.
+func (m *ContainerMetadata) Merge(from *ContainerMetadata) {
+	if from == nil {
+		return
+	}
+	if from.ContainerName != "" {
+		m.ContainerName = from.ContainerName
+	}
+	if from.ContainerImage != "" {
+		m.ContainerImage = from.ContainerImage
+	}
+	if from.ContainerImageID != "" {
+		m.ContainerImageID = from.ContainerImageID
+	}
+	if len(from.ContainerLabels) > 0 {
+		m.ContainerLabels = from.ContainerLabels
+	}
+	if from.PodName != "" {
+		m.PodName = from.PodName
+	}
+	if from.PodNamespace != "" {
+		m.PodNamespace = from.PodNamespace
+	}
+	if from.PodUID != "" {
+		m.PodUID = from.PodUID
+	}
+	if len(from.PodLabels) > 0 {
+		m.PodLabels = from.PodLabels
+	}
+	if from.ComposeProject != "" {
+		m.ComposeProject = from.ComposeProject
+	}
+	if from.ComposeService != "" {
+		m.ComposeService = from.ComposeService
+	}
+}
+
+// containerCache is a cache of ContainerMetadata by ID that is shared by
+// the enrichers. A nil entry records that the runtime does not know the ID.
+type containerCache struct {
+	mu      sync.Mutex
+	entries map[string]*containerCacheEntry
+}
+
+type containerCacheEntry struct {
+	metadata *ContainerMetadata
+	expires  time.Time
+}
+
+func newContainerCache() *containerCache {
+	return &containerCache{
+		entries: make(map[string]*containerCacheEntry),
+	}
+}
+
+// get will return the cached metadata for an ID, and if the entry was found
+// and has not expired.
+func (c *containerCache) get(id string) (*ContainerMetadata, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[id]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.metadata, true
+}
+
+func (c *containerCache) set(id string, metadata *ContainerMetadata, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[id] = &containerCacheEntry{metadata: metadata, expires: time.Now().Add(ttl)}
+}
+
+// delete will drop an ID from the cache, along with any expired entries.
+func (c *containerCache) delete(id string) {
+	c.mu.Lock()
+	delete(c.entries, id)
+	c.mu.Unlock()
+	c.prune()
+}
+
+// prune will drop every expired entry.
+func (c *containerCache) prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
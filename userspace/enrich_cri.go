@@ -0,0 +1,571 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kris-nova/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+	criv1alpha2 "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+)
+
+const (
+	DefaultCRITimeout     = 2 * time.Second
+	DefaultCRICacheTTL    = 5 * time.Minute
+	DefaultCRINegativeTTL = 10 * time.Second
+	DefaultCRIResync      = time.Minute
+
+	// criLookupQueueSize is the number of container IDs that can
+	// wait to be looked up before new IDs are dropped.
+	criLookupQueueSize = 256
+
+	// CRIEndpointAuto will use the first of CRIEndpoints that exists.
+	CRIEndpointAuto = "auto"
+
+	// Labels set by the kubelet on every container.
+	CRILabelContainerName = "io.kubernetes.container.name"
+	CRILabelPodName       = "io.kubernetes.pod.name"
+	CRILabelPodNamespace  = "io.kubernetes.pod.namespace"
+	CRILabelPodUID        = "io.kubernetes.pod.uid"
+)
+
+// CRIEndpoints are the well known CRI runtime service sockets.
+var CRIEndpoints = []string{
+	"unix:///run/containerd/containerd.sock",
+	"unix:///run/crio/crio.sock",
+	"unix:///var/run/crio/crio.sock",
+	"unix:///run/k3s/containerd/containerd.sock",
+}
+
+type CRIEnricherConfig struct {
+
+	// Endpoint is the CRI runtime service, either a unix:// address
+	// or CRIEndpointAuto.
+	Endpoint string
+
+	// Timeout for every call to the runtime.
+	Timeout time.Duration
+
+	// CacheTTL is how long a container is cached before
+	// it is looked up again.
+	CacheTTL time.Duration
+
+	// NegativeTTL is how long a container ID the runtime does
+	// not know about is cached.
+	NegativeTTL time.Duration
+
+	// Resync is how often every container and pod sandbox is
+	// listed from the runtime to warm the cache.
+	Resync time.Duration
+}
+
+// CRIEnricher will add Kubernetes pod metadata to events from
+// containers managed by a CRI runtime such as containerd or CRI-O.
+//
+// The cache is warmed by listing every container and pod sandbox
+// from the runtime, and a container that is not cached is looked up
+// in the background. Enrich never waits on the runtime, so an event
+// from a container that has not been looked up yet is not enriched.
+// Every ContainerEvent will refresh the cache for the container it
+// started in, so that containers the runtime did not know about yet
+// are looked up again.
+type CRIEnricher struct {
+	config  CRIEnricherConfig
+	conn    *grpc.ClientConn
+	runtime criRuntime
+
+	containers *containerCache
+	pods       *containerCache
+
+	queue   chan string
+	mtx     sync.Mutex
+	pending map[string]bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// criRuntime hides the differences between the v1 and v1alpha2 CRI APIs.
+type criRuntime interface {
+	version(ctx context.Context) (string, error)
+	container(ctx context.Context, id string) (*ContainerMetadata, error)
+	podSandbox(ctx context.Context, id string) (*ContainerMetadata, error)
+	pod(ctx context.Context, uid string) (*ContainerMetadata, error)
+	list(ctx context.Context) (map[string]*ContainerMetadata, error)
+}
+
+func NewCRIEnricher(config CRIEnricherConfig) (*CRIEnricher, error) {
+	if config.Timeout == 0 {
+		config.Timeout = DefaultCRITimeout
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = DefaultCRICacheTTL
+	}
+	if config.NegativeTTL == 0 {
+		config.NegativeTTL = DefaultCRINegativeTTL
+	}
+	if config.Resync == 0 {
+		config.Resync = DefaultCRIResync
+	}
+	endpoint, err := criEndpoint(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	config.Endpoint = endpoint
+	conn, err := grpc.Dial(endpoint, grpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("unable to dial CRI runtime %s: %v", endpoint, err)
+	}
+	e := &CRIEnricher{
+		config:     config,
+		conn:       conn,
+		containers: newContainerCache(),
+		pods:       newContainerCache(),
+		queue:      make(chan string, criLookupQueueSize),
+		pending:    map[string]bool{},
+	}
+
+	// Newer runtimes only serve v1, older runtimes only serve v1alpha2.
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+	for _, runtime := range []criRuntime{
+		&criRuntimeV1{client: criv1.NewRuntimeServiceClient(conn)},
+		&criRuntimeV1alpha2{client: criv1alpha2.NewRuntimeServiceClient(conn)},
+	} {
+		version, err := runtime.version(ctx)
+		if status.Code(err) == codes.Unimplemented {
+			continue
+		}
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("unable to connect to CRI runtime %s: %v", endpoint, err)
+		}
+		logger.Info("CRI runtime %s: %s", endpoint, version)
+		e.runtime = runtime
+		e.start()
+		return e, nil
+	}
+	conn.Close()
+	return nil, fmt.Errorf("CRI runtime %s does not serve a supported CRI version", endpoint)
+}
+
+// criEndpoint will resolve CRIEndpointAuto, and add the unix://
+// scheme to a plain path.
+func criEndpoint(endpoint string) (string, error) {
+	if endpoint == "" || endpoint == CRIEndpointAuto {
+		for _, candidate := range CRIEndpoints {
+			if _, err := os.Stat(strings.TrimPrefix(candidate, "unix://")); err == nil {
+				return candidate, nil
+			}
+		}
+		return "", fmt.Errorf("unable to find a CRI runtime socket in %s", strings.Join(CRIEndpoints, ", "))
+	}
+	if strings.HasPrefix(endpoint, "/") {
+		return "unix://" + endpoint, nil
+	}
+	return endpoint, nil
+}
+
+func (e *CRIEnricher) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.wg.Add(2)
+	go e.resync(ctx)
+	go e.work(ctx)
+}
+
+// Enrich will add pod metadata to any ContainerEnrichable event.
+func (e *CRIEnricher) Enrich(event Event) {
+	enrichable, ok := event.(ContainerEnrichable)
+	if !ok {
+		return
+	}
+	metadata := enrichable.Container()
+	if metadata.ContainerID == "" {
+		return
+	}
+	cached, ok := e.containers.get(metadata.ContainerID)
+	if _, refresh := event.(*ContainerEvent); refresh || !ok {
+		e.enqueue(metadata.ContainerID)
+	}
+	metadata.Merge(cached)
+}
+
+// enqueue will look up a container ID in the background. IDs that
+// are already waiting are ignored, and IDs are dropped if the queue
+// is full.
+func (e *CRIEnricher) enqueue(id string) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	if e.pending[id] {
+		return
+	}
+	select {
+	case e.queue <- id:
+		e.pending[id] = true
+	default:
+		logger.Debug("CRI lookup queue full, dropping container %s", id)
+	}
+}
+
+// work will look up queued container IDs one at a time.
+func (e *CRIEnricher) work(ctx context.Context) {
+	defer e.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-e.queue:
+			e.lookup(ctx, id)
+			e.mtx.Lock()
+			delete(e.pending, id)
+			e.mtx.Unlock()
+		}
+	}
+}
+
+// resync will warm the cache with every container and pod sandbox
+// the runtime knows about.
+func (e *CRIEnricher) resync(ctx context.Context) {
+	defer e.wg.Done()
+	ticker := time.NewTicker(e.config.Resync)
+	defer ticker.Stop()
+	for {
+		e.warm(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *CRIEnricher) warm(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancel()
+	containers, err := e.runtime.list(ctx)
+	if err != nil {
+		logger.Debug("Unable to list CRI containers: %v", err)
+		return
+	}
+	for id, metadata := range containers {
+		e.containers.set(id, metadata, e.config.CacheTTL)
+		if metadata.PodUID != "" && len(metadata.PodLabels) > 0 {
+			e.pods.set(metadata.PodUID, &ContainerMetadata{
+				PodName:      metadata.PodName,
+				PodNamespace: metadata.PodNamespace,
+				PodUID:       metadata.PodUID,
+				PodLabels:    metadata.PodLabels,
+			}, e.config.CacheTTL)
+		}
+	}
+	e.containers.prune()
+	e.pods.prune()
+}
+
+// lookup will look up a container ID from the runtime and cache the
+// result. A nil result means the runtime does not know about the container.
+func (e *CRIEnricher) lookup(ctx context.Context, id string) *ContainerMetadata {
+	ctx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancel()
+	metadata, err := e.runtime.container(ctx, id)
+	if status.Code(err) == codes.NotFound {
+		// The pause container of a pod is a sandbox, not a container.
+		metadata, err = e.runtime.podSandbox(ctx, id)
+	}
+	if err != nil && status.Code(err) != codes.NotFound {
+		logger.Debug("Unable to look up container %s: %v", id, err)
+	}
+	if metadata != nil && metadata.PodUID != "" && len(metadata.PodLabels) == 0 {
+		pod := e.lookupPod(ctx, metadata.PodUID)
+		if pod != nil {
+			metadata.PodLabels = pod.PodLabels
+		}
+	}
+
+	ttl := e.config.CacheTTL
+	if metadata == nil {
+		ttl = e.config.NegativeTTL
+	}
+	e.containers.set(id, metadata, ttl)
+	return metadata
+}
+
+func (e *CRIEnricher) lookupPod(ctx context.Context, uid string) *ContainerMetadata {
+	if pod, ok := e.pods.get(uid); ok {
+		return pod
+	}
+	pod, err := e.runtime.pod(ctx, uid)
+	if err != nil {
+		logger.Debug("Unable to look up pod %s: %v", uid, err)
+	}
+	ttl := e.config.CacheTTL
+	if pod == nil {
+		ttl = e.config.NegativeTTL
+	}
+	e.pods.set(uid, pod, ttl)
+	return pod
+}
+
+func (e *CRIEnricher) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+	}
+	return e.conn.Close()
+}
+
+// criContainerMetadata will build ContainerMetadata from the labels
+// the kubelet sets on every container.
+func criContainerMetadata(name, image, imageID string, labels map[string]string) *ContainerMetadata {
+	if labels[CRILabelContainerName] != "" {
+		name = labels[CRILabelContainerName]
+	}
+	return &ContainerMetadata{
+		ContainerName:    name,
+		ContainerImage:   image,
+		ContainerImageID: imageID,
+		ContainerLabels:  labels,
+		PodName:          labels[CRILabelPodName],
+		PodNamespace:     labels[CRILabelPodNamespace],
+		PodUID:           labels[CRILabelPodUID],
+	}
+}
+
+// criSandboxMetadata will build the ContainerMetadata of a pod sandbox.
+func criSandboxMetadata(name, namespace, uid string, labels map[string]string) *ContainerMetadata {
+	return &ContainerMetadata{
+		PodName:      name,
+		PodNamespace: namespace,
+		PodUID:       uid,
+		PodLabels:    labels,
+	}
+}
+
+// criListMetadata will add the labels of each pod to its containers,
+// and return the metadata of every container and sandbox by ID.
+func criListMetadata(sandboxes, containers map[string]*ContainerMetadata) map[string]*ContainerMetadata {
+	pods := map[string]*ContainerMetadata{}
+	for _, sandbox := range sandboxes {
+		pods[sandbox.PodUID] = sandbox
+	}
+	for _, container := range containers {
+		if pod, ok := pods[container.PodUID]; ok && container.PodUID != "" {
+			container.PodLabels = pod.PodLabels
+		}
+	}
+	for id, sandbox := range sandboxes {
+		containers[id] = sandbox
+	}
+	return containers
+}
+
+type criRuntimeV1 struct {
+	client criv1.RuntimeServiceClient
+}
+
+func (r *criRuntimeV1) version(ctx context.Context) (string, error) {
+	response, err := r.client.Version(ctx, &criv1.VersionRequest{})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s (CRI %s)", response.RuntimeName, response.RuntimeVersion, response.RuntimeApiVersion), nil
+}
+
+func (r *criRuntimeV1) container(ctx context.Context, id string) (*ContainerMetadata, error) {
+	response, err := r.client.ContainerStatus(ctx, &criv1.ContainerStatusRequest{ContainerId: id})
+	if err != nil {
+		return nil, err
+	}
+	s := response.Status
+	if s == nil {
+		return nil, status.Error(codes.NotFound, id)
+	}
+	var name, image string
+	if s.Metadata != nil {
+		name = s.Metadata.Name
+	}
+	if s.Image != nil {
+		image = s.Image.Image
+	}
+	return criContainerMetadata(name, image, s.ImageRef, s.Labels), nil
+}
+
+func (r *criRuntimeV1) podSandbox(ctx context.Context, id string) (*ContainerMetadata, error) {
+	response, err := r.client.PodSandboxStatus(ctx, &criv1.PodSandboxStatusRequest{PodSandboxId: id})
+	if err != nil {
+		return nil, err
+	}
+	s := response.Status
+	if s == nil || s.Metadata == nil {
+		return nil, status.Error(codes.NotFound, id)
+	}
+	return criSandboxMetadata(s.Metadata.Name, s.Metadata.Namespace, s.Metadata.Uid, s.Labels), nil
+}
+
+func (r *criRuntimeV1) pod(ctx context.Context, uid string) (*ContainerMetadata, error) {
+	response, err := r.client.ListPodSandbox(ctx, &criv1.ListPodSandboxRequest{
+		Filter: &criv1.PodSandboxFilter{
+			LabelSelector: map[string]string{CRILabelPodUID: uid},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, sandbox := range response.Items {
+		if sandbox.Metadata == nil {
+			continue
+		}
+		return criSandboxMetadata(sandbox.Metadata.Name, sandbox.Metadata.Namespace, sandbox.Metadata.Uid, sandbox.Labels), nil
+	}
+	return nil, nil
+}
+
+func (r *criRuntimeV1) list(ctx context.Context) (map[string]*ContainerMetadata, error) {
+	sandboxResponse, err := r.client.ListPodSandbox(ctx, &criv1.ListPodSandboxRequest{})
+	if err != nil {
+		return nil, err
+	}
+	sandboxes := map[string]*ContainerMetadata{}
+	for _, sandbox := range sandboxResponse.Items {
+		if sandbox.Metadata == nil {
+			continue
+		}
+		sandboxes[sandbox.Id] = criSandboxMetadata(sandbox.Metadata.Name, sandbox.Metadata.Namespace, sandbox.Metadata.Uid, sandbox.Labels)
+	}
+	containerResponse, err := r.client.ListContainers(ctx, &criv1.ListContainersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	containers := map[string]*ContainerMetadata{}
+	for _, c := range containerResponse.Containers {
+		var name, image string
+		if c.Metadata != nil {
+			name = c.Metadata.Name
+		}
+		if c.Image != nil {
+			image = c.Image.Image
+		}
+		containers[c.Id] = criContainerMetadata(name, image, c.ImageRef, c.Labels)
+	}
+	return criListMetadata(sandboxes, containers), nil
+}
+
+type criRuntimeV1alpha2 struct {
+	client criv1alpha2.RuntimeServiceClient
+}
+
+func (r *criRuntimeV1alpha2) version(ctx context.Context) (string, error) {
+	response, err := r.client.Version(ctx, &criv1alpha2.VersionRequest{})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s (CRI %s)", response.RuntimeName, response.RuntimeVersion, response.RuntimeApiVersion), nil
+}
+
+func (r *criRuntimeV1alpha2) container(ctx context.Context, id string) (*ContainerMetadata, error) {
+	response, err := r.client.ContainerStatus(ctx, &criv1alpha2.ContainerStatusRequest{ContainerId: id})
+	if err != nil {
+		return nil, err
+	}
+	s := response.Status
+	if s == nil {
+		return nil, status.Error(codes.NotFound, id)
+	}
+	var name, image string
+	if s.Metadata != nil {
+		name = s.Metadata.Name
+	}
+	if s.Image != nil {
+		image = s.Image.Image
+	}
+	return criContainerMetadata(name, image, s.ImageRef, s.Labels), nil
+}
+
+func (r *criRuntimeV1alpha2) podSandbox(ctx context.Context, id string) (*ContainerMetadata, error) {
+	response, err := r.client.PodSandboxStatus(ctx, &criv1alpha2.PodSandboxStatusRequest{PodSandboxId: id})
+	if err != nil {
+		return nil, err
+	}
+	s := response.Status
+	if s == nil || s.Metadata == nil {
+		return nil, status.Error(codes.NotFound, id)
+	}
+	return criSandboxMetadata(s.Metadata.Name, s.Metadata.Namespace, s.Metadata.Uid, s.Labels), nil
+}
+
+func (r *criRuntimeV1alpha2) pod(ctx context.Context, uid string) (*ContainerMetadata, error) {
+	response, err := r.client.ListPodSandbox(ctx, &criv1alpha2.ListPodSandboxRequest{
+		Filter: &criv1alpha2.PodSandboxFilter{
+			LabelSelector: map[string]string{CRILabelPodUID: uid},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, sandbox := range response.Items {
+		if sandbox.Metadata == nil {
+			continue
+		}
+		return criSandboxMetadata(sandbox.Metadata.Name, sandbox.Metadata.Namespace, sandbox.Metadata.Uid, sandbox.Labels), nil
+	}
+	return nil, nil
+}
+
+func (r *criRuntimeV1alpha2) list(ctx context.Context) (map[string]*ContainerMetadata, error) {
+	sandboxResponse, err := r.client.ListPodSandbox(ctx, &criv1alpha2.ListPodSandboxRequest{})
+	if err != nil {
+		return nil, err
+	}
+	sandboxes := map[string]*ContainerMetadata{}
+	for _, sandbox := range sandboxResponse.Items {
+		if sandbox.Metadata == nil {
+			continue
+		}
+		sandboxes[sandbox.Id] = criSandboxMetadata(sandbox.Metadata.Name, sandbox.Metadata.Namespace, sandbox.Metadata.Uid, sandbox.Labels)
+	}
+	containerResponse, err := r.client.ListContainers(ctx, &criv1alpha2.ListContainersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	containers := map[string]*ContainerMetadata{}
+	for _, c := range containerResponse.Containers {
+		var name, image string
+		if c.Metadata != nil {
+			name = c.Metadata.Name
+		}
+		if c.Image != nil {
+			image = c.Image.Image
+		}
+		containers[c.Id] = criContainerMetadata(name, image, c.ImageRef, c.Labels)
+	}
+	return criListMetadata(sandboxes, containers), nil
+}
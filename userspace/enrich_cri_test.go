@@ -0,0 +1,339 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+	criv1alpha2 "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+)
+
+var (
+	testCRIContainerLabels = map[string]string{
+		CRILabelContainerName: "nginx",
+		CRILabelPodName:       "web-0",
+		CRILabelPodNamespace:  "default",
+		CRILabelPodUID:        "pod-uid",
+		"team":                "platform",
+	}
+	testCRIPodLabels = map[string]string{
+		"app": "web",
+	}
+)
+
+// fakeCRIServer is a v1 runtime service with one pod.
+type fakeCRIServer struct {
+	criv1.UnimplementedRuntimeServiceServer
+	mtx        sync.Mutex
+	containers map[string]*criv1.Container
+	sandboxes  map[string]*criv1.PodSandbox
+	lookups    int
+	lists      int
+}
+
+func newFakeCRIServer() *fakeCRIServer {
+	return &fakeCRIServer{
+		containers: map[string]*criv1.Container{},
+		sandboxes:  map[string]*criv1.PodSandbox{},
+	}
+}
+
+func (s *fakeCRIServer) addContainer(id string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.containers[id] = &criv1.Container{
+		Id:       id,
+		Metadata: &criv1.ContainerMetadata{Name: "runtime-name"},
+		Image:    &criv1.ImageSpec{Image: "nginx:1.21"},
+		ImageRef: "sha256:abc",
+		Labels:   testCRIContainerLabels,
+	}
+}
+
+func (s *fakeCRIServer) addSandbox(id string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.sandboxes[id] = &criv1.PodSandbox{
+		Id:       id,
+		Metadata: &criv1.PodSandboxMetadata{Name: "web-0", Namespace: "default", Uid: "pod-uid"},
+		Labels:   testCRIPodLabels,
+	}
+}
+
+func (s *fakeCRIServer) lookupCount() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.lookups
+}
+
+// waitForList will wait for the enricher to warm its cache.
+func (s *fakeCRIServer) waitForList(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mtx.Lock()
+		lists := s.lists
+		s.mtx.Unlock()
+		if lists > 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("containers were never listed")
+}
+
+func (s *fakeCRIServer) Version(ctx context.Context, req *criv1.VersionRequest) (*criv1.VersionResponse, error) {
+	return &criv1.VersionResponse{RuntimeName: "fake", RuntimeVersion: "v1", RuntimeApiVersion: "v1"}, nil
+}
+
+func (s *fakeCRIServer) ContainerStatus(ctx context.Context, req *criv1.ContainerStatusRequest) (*criv1.ContainerStatusResponse, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.lookups++
+	c, ok := s.containers[req.ContainerId]
+	if !ok {
+		return nil, status.Error(codes.NotFound, req.ContainerId)
+	}
+	return &criv1.ContainerStatusResponse{Status: &criv1.ContainerStatus{
+		Id:       c.Id,
+		Metadata: c.Metadata,
+		Image:    c.Image,
+		ImageRef: c.ImageRef,
+		Labels:   c.Labels,
+	}}, nil
+}
+
+func (s *fakeCRIServer) PodSandboxStatus(ctx context.Context, req *criv1.PodSandboxStatusRequest) (*criv1.PodSandboxStatusResponse, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	sandbox, ok := s.sandboxes[req.PodSandboxId]
+	if !ok {
+		return nil, status.Error(codes.NotFound, req.PodSandboxId)
+	}
+	return &criv1.PodSandboxStatusResponse{Status: &criv1.PodSandboxStatus{
+		Id:       sandbox.Id,
+		Metadata: sandbox.Metadata,
+		Labels:   sandbox.Labels,
+	}}, nil
+}
+
+func (s *fakeCRIServer) ListPodSandbox(ctx context.Context, req *criv1.ListPodSandboxRequest) (*criv1.ListPodSandboxResponse, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	response := &criv1.ListPodSandboxResponse{}
+	for _, sandbox := range s.sandboxes {
+		if uid := req.GetFilter().GetLabelSelector()[CRILabelPodUID]; uid != "" && uid != sandbox.Metadata.Uid {
+			continue
+		}
+		response.Items = append(response.Items, sandbox)
+	}
+	return response, nil
+}
+
+func (s *fakeCRIServer) ListContainers(ctx context.Context, req *criv1.ListContainersRequest) (*criv1.ListContainersResponse, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.lists++
+	response := &criv1.ListContainersResponse{}
+	for _, c := range s.containers {
+		response.Containers = append(response.Containers, c)
+	}
+	return response, nil
+}
+
+// fakeCRIServerV1alpha2 is an older runtime that only serves v1alpha2.
+type fakeCRIServerV1alpha2 struct {
+	criv1alpha2.UnimplementedRuntimeServiceServer
+}
+
+func (s *fakeCRIServerV1alpha2) Version(ctx context.Context, req *criv1alpha2.VersionRequest) (*criv1alpha2.VersionResponse, error) {
+	return &criv1alpha2.VersionResponse{RuntimeName: "fake", RuntimeVersion: "v1alpha2", RuntimeApiVersion: "v1alpha2"}, nil
+}
+
+func (s *fakeCRIServerV1alpha2) ListPodSandbox(ctx context.Context, req *criv1alpha2.ListPodSandboxRequest) (*criv1alpha2.ListPodSandboxResponse, error) {
+	return &criv1alpha2.ListPodSandboxResponse{Items: []*criv1alpha2.PodSandbox{{
+		Id:       "sandbox",
+		Metadata: &criv1alpha2.PodSandboxMetadata{Name: "web-0", Namespace: "default", Uid: "pod-uid"},
+		Labels:   testCRIPodLabels,
+	}}}, nil
+}
+
+func (s *fakeCRIServerV1alpha2) ListContainers(ctx context.Context, req *criv1alpha2.ListContainersRequest) (*criv1alpha2.ListContainersResponse, error) {
+	return &criv1alpha2.ListContainersResponse{Containers: []*criv1alpha2.Container{{
+		Id:       "container",
+		Metadata: &criv1alpha2.ContainerMetadata{Name: "runtime-name"},
+		Image:    &criv1alpha2.ImageSpec{Image: "nginx:1.21"},
+		ImageRef: "sha256:abc",
+		Labels:   testCRIContainerLabels,
+	}}}, nil
+}
+
+// newTestCRIEnricher will serve the registered runtime on a temporary
+// unix socket, and connect a CRIEnricher to it.
+func newTestCRIEnricher(t *testing.T, register func(server *grpc.Server)) *CRIEnricher {
+	path := filepath.Join(t.TempDir(), "cri.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	e, err := NewCRIEnricher(CRIEnricherConfig{
+		Endpoint: path,
+		Resync:   time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { e.Close() })
+	return e
+}
+
+// waitForCRI will wait for a container ID to be cached.
+func waitForCRI(t *testing.T, e *CRIEnricher, id string, known bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		metadata, ok := e.containers.get(id)
+		if ok && (metadata != nil) == known {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("container %s was not cached", id)
+}
+
+func enrichedCRIEvent(e *CRIEnricher, event ContainerEnrichable) *ContainerMetadata {
+	e.Enrich(event)
+	return event.Container()
+}
+
+func assertCRIContainer(t *testing.T, metadata *ContainerMetadata) {
+	t.Helper()
+	if metadata.ContainerName != "nginx" || metadata.ContainerImage != "nginx:1.21" || metadata.ContainerImageID != "sha256:abc" {
+		t.Errorf("unexpected container: %+v", metadata)
+	}
+	if metadata.ContainerLabels["team"] != "platform" {
+		t.Errorf("missing container labels: %v", metadata.ContainerLabels)
+	}
+	assertCRIPod(t, metadata)
+}
+
+func assertCRIPod(t *testing.T, metadata *ContainerMetadata) {
+	t.Helper()
+	if metadata.PodName != "web-0" || metadata.PodNamespace != "default" || metadata.PodUID != "pod-uid" {
+		t.Errorf("unexpected pod: %+v", metadata)
+	}
+	if metadata.PodLabels["app"] != "web" {
+		t.Errorf("missing pod labels: %v", metadata.PodLabels)
+	}
+}
+
+func TestCRIEnricherWarm(t *testing.T) {
+	server := newFakeCRIServer()
+	server.addSandbox("sandbox")
+	server.addContainer("container")
+	e := newTestCRIEnricher(t, func(s *grpc.Server) { criv1.RegisterRuntimeServiceServer(s, server) })
+	waitForCRI(t, e, "container", true)
+	waitForCRI(t, e, "sandbox", true)
+
+	assertCRIContainer(t, enrichedCRIEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}}))
+	assertCRIPod(t, enrichedCRIEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: "sandbox"}}))
+	if n := server.lookupCount(); n != 0 {
+		t.Errorf("expected warm cache, got %d lookups", n)
+	}
+}
+
+func TestCRIEnricherLookup(t *testing.T) {
+	server := newFakeCRIServer()
+	e := newTestCRIEnricher(t, func(s *grpc.Server) { criv1.RegisterRuntimeServiceServer(s, server) })
+	server.waitForList(t)
+	server.addSandbox("sandbox")
+	server.addContainer("container")
+
+	tests := []struct {
+		id     string
+		assert func(t *testing.T, metadata *ContainerMetadata)
+	}{
+		{id: "container", assert: assertCRIContainer},
+		{id: "sandbox", assert: assertCRIPod},
+	}
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			// The first event is never held up by the runtime
+			metadata := enrichedCRIEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: test.id}})
+			if metadata.PodName != "" {
+				t.Errorf("expected lookup in the background, got %+v", metadata)
+			}
+			waitForCRI(t, e, test.id, true)
+			test.assert(t, enrichedCRIEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: test.id}}))
+		})
+	}
+}
+
+func TestCRIEnricherNegativeCache(t *testing.T) {
+	server := newFakeCRIServer()
+	e := newTestCRIEnricher(t, func(s *grpc.Server) { criv1.RegisterRuntimeServiceServer(s, server) })
+	server.waitForList(t)
+	enrichedCRIEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}})
+	waitForCRI(t, e, "container", false)
+	lookups := server.lookupCount()
+
+	// The runtime learns about the container, but the negative
+	// result is cached until a ContainerEvent
+	server.addSandbox("sandbox")
+	server.addContainer("container")
+	metadata := enrichedCRIEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}})
+	if metadata.PodName != "" {
+		t.Errorf("expected negative cache, got %+v", metadata)
+	}
+	if n := server.lookupCount(); n != lookups {
+		t.Errorf("expected %d lookups, got %d", lookups, n)
+	}
+
+	enrichedCRIEvent(e, &ContainerEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}})
+	waitForCRI(t, e, "container", true)
+	assertCRIContainer(t, enrichedCRIEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}}))
+}
+
+func TestCRIEnricherV1alpha2(t *testing.T) {
+	e := newTestCRIEnricher(t, func(s *grpc.Server) {
+		criv1alpha2.RegisterRuntimeServiceServer(s, &fakeCRIServerV1alpha2{})
+	})
+	if _, ok := e.runtime.(*criRuntimeV1alpha2); !ok {
+		t.Fatalf("expected v1alpha2 runtime, got %T", e.runtime)
+	}
+	waitForCRI(t, e, "container", true)
+	assertCRIContainer(t, enrichedCRIEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}}))
+}
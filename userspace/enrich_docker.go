@@ -0,0 +1,436 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kris-nova/logger"
+)
+
+const (
+	DefaultDockerTimeout     = 2 * time.Second
+	DefaultDockerCacheTTL    = 5 * time.Minute
+	DefaultDockerNegativeTTL = 10 * time.Second
+
+	// dockerLookupQueueSize is the number of container IDs that can
+	// wait to be inspected before new IDs are dropped.
+	dockerLookupQueueSize = 256
+
+	// DockerEndpointAuto will use the first of DockerEndpoints that exists.
+	DockerEndpointAuto = "auto"
+
+	// Labels set by docker compose on every container.
+	DockerLabelComposeProject = "com.docker.compose.project"
+	DockerLabelComposeService = "com.docker.compose.service"
+)
+
+// DockerEndpoints are the well known Docker Engine API sockets. Podman
+// serves the same API on its own socket.
+var DockerEndpoints = []string{
+	"unix:///var/run/docker.sock",
+	"unix:///run/docker.sock",
+	"unix:///run/podman/podman.sock",
+}
+
+type DockerEnricherConfig struct {
+
+	// Endpoint is the Docker Engine API, either a unix:// address
+	// or DockerEndpointAuto.
+	Endpoint string
+
+	// Timeout for every request to the daemon, except for the
+	// event stream.
+	Timeout time.Duration
+
+	// CacheTTL is how long a container is cached before
+	// it is inspected again.
+	CacheTTL time.Duration
+
+	// NegativeTTL is how long a container ID the daemon does
+	// not know about is cached.
+	NegativeTTL time.Duration
+}
+
+// DockerEnricher will add container metadata to events from containers
+// managed by Docker or Podman.
+//
+// Lookups are cached by container ID. The cache is warmed by listing
+// every container, and the daemon's event stream is watched so that new
+// containers are inspected as soon as they are created, usually ahead
+// of the first ContainerEvent. A container that is not cached is
+// inspected in the background. Enrich never waits on the daemon, so an
+// event from a container that has not been inspected yet is not
+// enriched.
+type DockerEnricher struct {
+	config     DockerEnricherConfig
+	client     *http.Client
+	containers *containerCache
+
+	queue   chan string
+	mtx     sync.Mutex
+	pending map[string]bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// dockerContainer is the subset of the container inspect response we use.
+type dockerContainer struct {
+	ID     string `json:"Id"`
+	Name   string `json:"Name"`
+	Image  string `json:"Image"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// dockerListContainer is the subset of a container in the container
+// list response we use.
+type dockerListContainer struct {
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Image   string            `json:"Image"`
+	ImageID string            `json:"ImageID"`
+	Labels  map[string]string `json:"Labels"`
+}
+
+// dockerEvent is the subset of an event from the event stream we use.
+type dockerEvent struct {
+	Type   string `json:"Type"`
+	Action string `json:"Action"`
+	Actor  struct {
+		ID string `json:"ID"`
+	} `json:"Actor"`
+}
+
+func NewDockerEnricher(config DockerEnricherConfig) (*DockerEnricher, error) {
+	if config.Timeout == 0 {
+		config.Timeout = DefaultDockerTimeout
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = DefaultDockerCacheTTL
+	}
+	if config.NegativeTTL == 0 {
+		config.NegativeTTL = DefaultDockerNegativeTTL
+	}
+	endpoint, err := dockerEndpoint(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	config.Endpoint = endpoint
+	path := strings.TrimPrefix(endpoint, "unix://")
+	e := &DockerEnricher{
+		config: config,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", path)
+				},
+			},
+		},
+		containers: newContainerCache(),
+		queue:      make(chan string, dockerLookupQueueSize),
+		pending:    map[string]bool{},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+	var version struct {
+		Version    string `json:"Version"`
+		APIVersion string `json:"ApiVersion"`
+		Components []struct {
+			Name string `json:"Name"`
+		} `json:"Components"`
+	}
+	err = e.get(ctx, "/version", &version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to container daemon %s: %v", endpoint, err)
+	}
+	runtime := "Docker"
+	for _, component := range version.Components {
+		if strings.HasPrefix(component.Name, "Podman") {
+			runtime = "Podman"
+		}
+	}
+	logger.Info("Container daemon %s: %s %s (API %s)", endpoint, runtime, version.Version, version.APIVersion)
+
+	watchCtx, watchCancel := context.WithCancel(context.Background())
+	e.cancel = watchCancel
+	e.wg.Add(2)
+	go e.watch(watchCtx)
+	go e.work(watchCtx)
+	return e, nil
+}
+
+// dockerEndpoint will resolve DockerEndpointAuto, and add the unix://
+// scheme to a plain path.
+func dockerEndpoint(endpoint string) (string, error) {
+	if endpoint == "" || endpoint == DockerEndpointAuto {
+		for _, candidate := range DockerEndpoints {
+			if _, err := os.Stat(strings.TrimPrefix(candidate, "unix://")); err == nil {
+				return candidate, nil
+			}
+		}
+		return "", fmt.Errorf("unable to find a container daemon socket in %s", strings.Join(DockerEndpoints, ", "))
+	}
+	if strings.HasPrefix(endpoint, "/") {
+		return "unix://" + endpoint, nil
+	}
+	if !strings.HasPrefix(endpoint, "unix://") {
+		return "", fmt.Errorf("unsupported container daemon endpoint %s, expected unix:///path", endpoint)
+	}
+	return endpoint, nil
+}
+
+// Enrich will add container metadata to any ContainerEnrichable event.
+func (e *DockerEnricher) Enrich(event Event) {
+	enrichable, ok := event.(ContainerEnrichable)
+	if !ok {
+		return
+	}
+	metadata := enrichable.Container()
+	if metadata.ContainerID == "" {
+		return
+	}
+	cached, ok := e.containers.get(metadata.ContainerID)
+	if _, refresh := event.(*ContainerEvent); refresh && cached == nil {
+		// Only look up containers the daemon did not know about again,
+		// the event stream keeps known containers fresh.
+		ok = false
+	}
+	if !ok {
+		e.enqueue(metadata.ContainerID)
+	}
+	metadata.Merge(cached)
+}
+
+// enqueue will inspect a container ID in the background. IDs that
+// are already waiting are ignored, and IDs are dropped if the queue
+// is full.
+func (e *DockerEnricher) enqueue(id string) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	if e.pending[id] {
+		return
+	}
+	select {
+	case e.queue <- id:
+		e.pending[id] = true
+	default:
+		logger.Debug("Container daemon lookup queue full, dropping container %s", id)
+	}
+}
+
+// work will inspect queued container IDs one at a time.
+func (e *DockerEnricher) work(ctx context.Context) {
+	defer e.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-e.queue:
+			e.lookup(ctx, id)
+			e.mtx.Lock()
+			delete(e.pending, id)
+			e.mtx.Unlock()
+		}
+	}
+}
+
+// lookup will inspect a container ID and cache the result. A nil
+// result means the daemon does not know about the container.
+func (e *DockerEnricher) lookup(ctx context.Context, id string) *ContainerMetadata {
+	ctx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancel()
+	metadata, err := e.inspect(ctx, id)
+	if err != nil {
+		logger.Debug("Unable to inspect container %s: %v", id, err)
+	}
+	ttl := e.config.CacheTTL
+	if metadata == nil {
+		ttl = e.config.NegativeTTL
+	}
+	e.containers.set(id, metadata, ttl)
+	return metadata
+}
+
+// inspect will return the metadata for a container, or nil if the
+// daemon does not know about it.
+func (e *DockerEnricher) inspect(ctx context.Context, id string) (*ContainerMetadata, error) {
+	var container dockerContainer
+	err := e.get(ctx, "/containers/"+url.PathEscape(id)+"/json", &container)
+	if err == errDockerNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	image := container.Config.Image
+	if image == "" {
+		image = container.Image
+	}
+	return dockerContainerMetadata(container.Name, image, container.Image, container.Config.Labels), nil
+}
+
+// warm will cache every container the daemon knows about.
+func (e *DockerEnricher) warm(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancel()
+	var containers []dockerListContainer
+	err := e.get(ctx, "/containers/json?all=true", &containers)
+	if err != nil {
+		logger.Debug("Unable to list containers: %v", err)
+		return
+	}
+	for _, container := range containers {
+		var name string
+		if len(container.Names) > 0 {
+			name = container.Names[0]
+		}
+		e.containers.set(container.ID, dockerContainerMetadata(name, container.Image, container.ImageID, container.Labels), e.config.CacheTTL)
+	}
+	e.containers.prune()
+}
+
+// dockerContainerMetadata will build ContainerMetadata from a container
+// and the labels docker compose sets on it.
+func dockerContainerMetadata(name, image, imageID string, labels map[string]string) *ContainerMetadata {
+	return &ContainerMetadata{
+		ContainerName:    strings.TrimPrefix(name, "/"),
+		ContainerImage:   image,
+		ContainerImageID: imageID,
+		ContainerLabels:  labels,
+		ComposeProject:   labels[DockerLabelComposeProject],
+		ComposeService:   labels[DockerLabelComposeService],
+	}
+}
+
+var errDockerNotFound = fmt.Errorf("not found")
+
+func (e *DockerEnricher) get(ctx context.Context, path string, v interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker"+path, nil)
+	if err != nil {
+		return err
+	}
+	response, err := e.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return errDockerNotFound
+	}
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("%s: %s: %s", path, response.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(response.Body).Decode(v)
+}
+
+// watch will follow the daemon's event stream until the context is
+// cancelled, reconnecting with a backoff if the stream ends. The cache
+// is warmed every time the stream connects, to catch up on the
+// containers created while it was down.
+func (e *DockerEnricher) watch(ctx context.Context) {
+	defer e.wg.Done()
+	backoff := time.Second
+	for {
+		e.warm(ctx)
+		err := e.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warning("Container daemon event stream ended, reconnecting in %s: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
+
+// stream will read container events and keep the cache up to date.
+func (e *DockerEnricher) stream(ctx context.Context) error {
+	filters := url.QueryEscape(`{"type":["container"]}`)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker/events?filters="+filters, nil)
+	if err != nil {
+		return err
+	}
+	response, err := e.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("events: %s", response.Status)
+	}
+	decoder := json.NewDecoder(response.Body)
+	for {
+		var event dockerEvent
+		err := decoder.Decode(&event)
+		if err != nil {
+			return err
+		}
+		id := event.Actor.ID
+		if event.Type != "container" || id == "" {
+			continue
+		}
+		switch event.Action {
+		case "create", "start", "rename", "update":
+			inspectCtx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+			metadata, err := e.inspect(inspectCtx, id)
+			cancel()
+			if err != nil {
+				logger.Debug("Unable to inspect container %s: %v", id, err)
+				continue
+			}
+			if metadata != nil {
+				e.containers.set(id, metadata, e.config.CacheTTL)
+			}
+		case "destroy":
+			e.containers.delete(id)
+		}
+	}
+}
+
+// Close will stop watching the event stream.
+func (e *DockerEnricher) Close() error {
+	e.cancel()
+	e.wg.Wait()
+	return nil
+}
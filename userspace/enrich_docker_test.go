@@ -0,0 +1,300 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testDockerLabels = map[string]string{
+	DockerLabelComposeProject: "shop",
+	DockerLabelComposeService: "web",
+}
+
+// fakeDockerServer is a Docker Engine API with the containers that
+// have been added. Inspect requests wait while the server is held.
+type fakeDockerServer struct {
+	mtx        sync.Mutex
+	containers map[string]bool
+	inspects   int
+	lists      int
+	hold       chan struct{}
+	events     chan dockerEvent
+}
+
+func newFakeDockerServer() *fakeDockerServer {
+	hold := make(chan struct{})
+	close(hold)
+	return &fakeDockerServer{
+		containers: map[string]bool{},
+		hold:       hold,
+		events:     make(chan dockerEvent, 16),
+	}
+}
+
+func (s *fakeDockerServer) addContainer(id string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.containers[id] = true
+}
+
+func (s *fakeDockerServer) inspectCount() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.inspects
+}
+
+// waitForList will wait for the enricher to warm its cache.
+func (s *fakeDockerServer) waitForList(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mtx.Lock()
+		lists := s.lists
+		s.mtx.Unlock()
+		if lists > 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("containers were never listed")
+}
+
+func (s *fakeDockerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/version":
+		json.NewEncoder(w).Encode(map[string]string{"Version": "20.10.12", "ApiVersion": "1.41"})
+	case r.URL.Path == "/containers/json":
+		s.mtx.Lock()
+		s.lists++
+		var containers []dockerListContainer
+		for id := range s.containers {
+			containers = append(containers, dockerListContainer{ID: id, Names: []string{"/shop_web_1"}, Image: "nginx:1.21", ImageID: "sha256:abc", Labels: testDockerLabels})
+		}
+		s.mtx.Unlock()
+		json.NewEncoder(w).Encode(containers)
+	case strings.HasPrefix(r.URL.Path, "/containers/"):
+		s.mtx.Lock()
+		s.inspects++
+		hold := s.hold
+		s.mtx.Unlock()
+		<-hold
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/containers/"), "/json")
+		s.mtx.Lock()
+		known := s.containers[id]
+		s.mtx.Unlock()
+		if !known {
+			http.Error(w, "no such container", http.StatusNotFound)
+			return
+		}
+		var container dockerContainer
+		container.ID = id
+		container.Name = "/shop_web_1"
+		container.Image = "sha256:abc"
+		container.Config.Image = "nginx:1.21"
+		container.Config.Labels = testDockerLabels
+		json.NewEncoder(w).Encode(container)
+	case r.URL.Path == "/events":
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-s.events:
+				json.NewEncoder(w).Encode(event)
+				w.(http.Flusher).Flush()
+			}
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// newTestDockerEnricher will serve the fake API on a temporary unix
+// socket, and connect a DockerEnricher to it.
+func newTestDockerEnricher(t *testing.T, server *fakeDockerServer) *DockerEnricher {
+	path := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpServer := &http.Server{Handler: server}
+	go httpServer.Serve(listener)
+	t.Cleanup(func() { httpServer.Close() })
+	e, err := NewDockerEnricher(DockerEnricherConfig{Endpoint: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { e.Close() })
+	return e
+}
+
+// waitForDocker will wait for a container ID to be cached.
+func waitForDocker(t *testing.T, e *DockerEnricher, id string, known bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		metadata, ok := e.containers.get(id)
+		if ok && (metadata != nil) == known {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("container %s was not cached", id)
+}
+
+func enrichedDockerEvent(e *DockerEnricher, event ContainerEnrichable) *ContainerMetadata {
+	e.Enrich(event)
+	return event.Container()
+}
+
+func assertDockerContainer(t *testing.T, metadata *ContainerMetadata) {
+	t.Helper()
+	if metadata.ContainerName != "shop_web_1" || metadata.ContainerImage != "nginx:1.21" || metadata.ContainerImageID != "sha256:abc" {
+		t.Errorf("unexpected container: %+v", metadata)
+	}
+	if metadata.ComposeProject != "shop" || metadata.ComposeService != "web" {
+		t.Errorf("unexpected compose project: %+v", metadata)
+	}
+}
+
+func TestDockerEnricherWarm(t *testing.T) {
+	server := newFakeDockerServer()
+	server.addContainer("container")
+	e := newTestDockerEnricher(t, server)
+	waitForDocker(t, e, "container", true)
+	assertDockerContainer(t, enrichedDockerEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}}))
+	if n := server.inspectCount(); n != 0 {
+		t.Errorf("expected warm cache, got %d inspects", n)
+	}
+}
+
+func TestDockerEnricherLookup(t *testing.T) {
+	server := newFakeDockerServer()
+	e := newTestDockerEnricher(t, server)
+	server.waitForList(t)
+	server.addContainer("container")
+
+	// Hold the inspect, the event must not wait on the daemon
+	hold := make(chan struct{})
+	server.mtx.Lock()
+	server.hold = hold
+	server.mtx.Unlock()
+	done := make(chan *ContainerMetadata)
+	go func() {
+		done <- enrichedDockerEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}})
+	}()
+	select {
+	case metadata := <-done:
+		if metadata.ContainerName != "" {
+			t.Errorf("expected lookup in the background, got %+v", metadata)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Enrich waited on the daemon")
+	}
+
+	// A second event does not queue the container again
+	enrichedDockerEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}})
+	close(hold)
+	waitForDocker(t, e, "container", true)
+	assertDockerContainer(t, enrichedDockerEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}}))
+	if n := server.inspectCount(); n != 1 {
+		t.Errorf("expected 1 inspect, got %d", n)
+	}
+}
+
+func TestDockerEnricherNegativeCache(t *testing.T) {
+	server := newFakeDockerServer()
+	e := newTestDockerEnricher(t, server)
+	server.waitForList(t)
+	enrichedDockerEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}})
+	waitForDocker(t, e, "container", false)
+	inspects := server.inspectCount()
+
+	// The daemon learns about the container, but the negative
+	// result is cached until a ContainerEvent
+	server.addContainer("container")
+	metadata := enrichedDockerEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}})
+	if metadata.ContainerName != "" {
+		t.Errorf("expected negative cache, got %+v", metadata)
+	}
+	if n := server.inspectCount(); n != inspects {
+		t.Errorf("expected %d inspects, got %d", inspects, n)
+	}
+
+	enrichedDockerEvent(e, &ContainerEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}})
+	waitForDocker(t, e, "container", true)
+	assertDockerContainer(t, enrichedDockerEvent(e, &ProcessEvent{ContainerMetadata: ContainerMetadata{ContainerID: "container"}}))
+}
+
+func TestDockerEnricherEventStream(t *testing.T) {
+	server := newFakeDockerServer()
+	e := newTestDockerEnricher(t, server)
+	server.waitForList(t)
+	server.addContainer("container")
+
+	var event dockerEvent
+	event.Type = "container"
+	event.Action = "start"
+	event.Actor.ID = "container"
+	server.events <- event
+	waitForDocker(t, e, "container", true)
+
+	event.Action = "destroy"
+	server.events <- event
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := e.containers.get("container"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("destroyed container is still cached")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDockerEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint, expected string
+		valid              bool
+	}{
+		{"/run/docker.sock", "unix:///run/docker.sock", true},
+		{"unix:///run/podman/podman.sock", "unix:///run/podman/podman.sock", true},
+		{"tcp://127.0.0.1:2375", "", false},
+	}
+	for _, test := range tests {
+		endpoint, err := dockerEndpoint(test.endpoint)
+		if (err == nil) != test.valid || endpoint != test.expected {
+			t.Errorf("%s: expected %q, got %q (%v)", test.endpoint, test.expected, endpoint, err)
+		}
+	}
+}
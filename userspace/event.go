@@ -17,6 +17,13 @@
 
 package userspace
 
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
 // Event is a generic event for all
 // ObservationPoint systems.
 type Event interface {
@@ -24,3 +31,165 @@ type Event interface {
 	String() string
 	Name() string
 }
+
+// EventTime is embedded in an Event, and is set to the time the
+// Observer dispatched the Event.
+type EventTime struct {
+	time time.Time
+}
+
+// Timestamp is the time the Event was observed.
+func (t *EventTime) Timestamp() time.Time {
+	return t.time
+}
+
+// setTimestamp will set the time of an Event that has not
+// already been observed.
+func (t *EventTime) setTimestamp(now time.Time) {
+	if t.time.IsZero() {
+		t.time = now
+	}
+}
+
+// EventTimestamp will return the time an Event was observed, or
+// the current time for an Event that has not been dispatched.
+func EventTimestamp(event Event) time.Time {
+	if e, ok := event.(interface{ Timestamp() time.Time }); ok {
+		if t := e.Timestamp(); !t.IsZero() {
+			return t
+		}
+	}
+	return time.Now()
+}
+
+// EventHandler is called by the Observer for every Event
+// before the Event is delivered on the EventStream.
+type EventHandler interface {
+	Handle(event Event)
+}
+
+// EventProducer is called by the Observer for every Event after
+// the EventHandlers. The events it returns, such as alerts, are
+// dispatched the same as events from an ObservationPoint.
+type EventProducer interface {
+	Produce(event Event) []Event
+}
+
+// EventHandlerFunc is a plain function that can be used as an EventHandler.
+type EventHandlerFunc func(event Event)
+
+func (f EventHandlerFunc) Handle(event Event) {
+	f(event)
+}
+
+// EventField will look up the value of a field on an Event by the
+// name the field is given in the Event's JSON. Nested fields are
+// separated with a period.
+//
+//   EventField(event, "Filename")
+//   EventField(event, "ParentProc.Executable")
+func EventField(event Event, path string) (interface{}, bool) {
+	value := reflect.ValueOf(event)
+	for _, name := range strings.Split(path, ".") {
+		for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				return nil, false
+			}
+			value = value.Elem()
+		}
+		switch value.Kind() {
+		case reflect.Struct:
+			field, ok := structFieldByJSONName(value, name)
+			if !ok {
+				return nil, false
+			}
+			value = field
+		case reflect.Map:
+			if value.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			field := value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key()))
+			if !field.IsValid() {
+				return nil, false
+			}
+			value = field
+		default:
+			return nil, false
+		}
+	}
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, false
+		}
+		value = value.Elem()
+	}
+	return value.Interface(), true
+}
+
+// EventFieldString will return EventField() formatted as a string,
+// or an empty string if the field does not exist.
+func EventFieldString(event Event, path string) string {
+	value, ok := EventField(event, path)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// EventFieldFloat will return EventField() as a float64 for
+// numeric fields.
+func EventFieldFloat(event Event, path string) (float64, bool) {
+	value, ok := EventField(event, path)
+	if !ok {
+		return 0, false
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// structFieldByJSONName will find an exported field by its json tag,
+// or by its Go name if the field has no tag. Embedded structs are
+// searched the same way encoding/json flattens them.
+func structFieldByJSONName(value reflect.Value, name string) (reflect.Value, bool) {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" {
+			embedded := value.Field(i)
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if field, ok := structFieldByJSONName(embedded, name); ok {
+					return field, true
+				}
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if tag == name || (tag == "" && f.Name == name) {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
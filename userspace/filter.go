@@ -0,0 +1,414 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EventFilter is a filter expression that can be matched against
+// any Event. Fields are looked up with EventField().
+//
+//   Name == "ProcessExecuted" && Filename ^= "/tmp/"
+//   Name == "SocketState" && (DestPort == 22 || DestPort >= 8000)
+//   ContainerID && !(Comm =~ "^(runc|containerd)")
+//
+// Operators:
+//   ==  !=          equal, not equal (numeric if both sides are numbers)
+//   >  >=  <  <=    numeric comparison
+//   =~  !~          regular expression match, no match
+//   ^=  $=          has prefix, has suffix
+//   &&  ||  !  ( )  boolean logic
+//
+// A field on its own is true if the field is set to a non zero value.
+type EventFilter struct {
+	expression string
+	root       filterNode
+}
+
+// ParseEventFilter will compile a filter expression. An empty
+// expression will match every Event.
+func ParseEventFilter(expression string) (*EventFilter, error) {
+	f := &EventFilter{
+		expression: expression,
+	}
+	if strings.TrimSpace(expression) == "" {
+		return f, nil
+	}
+	tokens, err := filterTokenize(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %v", expression, err)
+	}
+	p := &filterParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %v", expression, err)
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("invalid filter %q: unexpected %q", expression, p.peek().value)
+	}
+	f.root = root
+	return f, nil
+}
+
+// MustParseEventFilter is ParseEventFilter() that will panic on
+// an invalid expression. Used for static profiles.
+func MustParseEventFilter(expression string) *EventFilter {
+	f, err := ParseEventFilter(expression)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Match will return true if the Event matches the filter.
+// A nil filter matches every Event.
+func (f *EventFilter) Match(event Event) bool {
+	if f == nil || f.root == nil {
+		return true
+	}
+	return f.root.match(event)
+}
+
+func (f *EventFilter) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.expression
+}
+
+// References will return true if the filter compares field with
+// ==, ^=, $= or =~ to a value that would match. Used to find the
+// observation points a filter depends on, such as Name == "ModuleLoaded".
+func (f *EventFilter) References(field, value string) bool {
+	if f == nil || f.root == nil {
+		return false
+	}
+	return filterReferences(f.root, field, value)
+}
+
+func filterReferences(node filterNode, field, value string) bool {
+	switch n := node.(type) {
+	case *filterAnd:
+		return filterReferences(n.left, field, value) || filterReferences(n.right, field, value)
+	case *filterOr:
+		return filterReferences(n.left, field, value) || filterReferences(n.right, field, value)
+	case *filterNot:
+		return filterReferences(n.node, field, value)
+	case *filterCompare:
+		if n.field != field {
+			return false
+		}
+		switch n.op {
+		case "==":
+			return n.value == value
+		case "^=":
+			return strings.HasPrefix(value, n.value)
+		case "$=":
+			return strings.HasSuffix(value, n.value)
+		case "=~":
+			return n.regex.MatchString(value)
+		}
+	}
+	return false
+}
+
+type filterNode interface {
+	match(event Event) bool
+}
+
+type filterAnd struct{ left, right filterNode }
+type filterOr struct{ left, right filterNode }
+type filterNot struct{ node filterNode }
+type filterExists struct{ field string }
+type filterCompare struct {
+	field  string
+	op     string
+	value  string
+	number float64
+	isNum  bool
+	regex  *regexp.Regexp
+}
+
+func (n *filterAnd) match(event Event) bool { return n.left.match(event) && n.right.match(event) }
+func (n *filterOr) match(event Event) bool  { return n.left.match(event) || n.right.match(event) }
+func (n *filterNot) match(event Event) bool { return !n.node.match(event) }
+
+func (n *filterExists) match(event Event) bool {
+	value, ok := EventField(event, n.field)
+	if !ok {
+		return false
+	}
+	return !reflect.ValueOf(value).IsZero()
+}
+
+func (n *filterCompare) match(event Event) bool {
+	value, ok := EventField(event, n.field)
+	if !ok {
+		// A missing field is only ever "not equal"
+		return n.op == "!=" || n.op == "!~"
+	}
+	actual := fmt.Sprint(value)
+	switch n.op {
+	case "=~":
+		return n.regex.MatchString(actual)
+	case "!~":
+		return !n.regex.MatchString(actual)
+	case "^=":
+		return strings.HasPrefix(actual, n.value)
+	case "$=":
+		return strings.HasSuffix(actual, n.value)
+	}
+	number, isNum := EventFieldFloat(event, n.field)
+	if n.isNum && isNum {
+		switch n.op {
+		case "==":
+			return number == n.number
+		case "!=":
+			return number != n.number
+		case ">":
+			return number > n.number
+		case ">=":
+			return number >= n.number
+		case "<":
+			return number < n.number
+		case "<=":
+			return number <= n.number
+		}
+	}
+	switch n.op {
+	case "==":
+		return actual == n.value
+	case "!=":
+		return actual != n.value
+	}
+	// Ordered comparison of a non numeric field
+	return false
+}
+
+type filterTokenKind int
+
+const (
+	filterTokenIdent filterTokenKind = iota
+	filterTokenString
+	filterTokenNumber
+	filterTokenOp
+)
+
+type filterToken struct {
+	kind  filterTokenKind
+	value string
+}
+
+var filterOperators = []string{"&&", "||", "==", "!=", ">=", "<=", "=~", "!~", "^=", "$=", ">", "<", "!", "(", ")"}
+
+func filterTokenize(expression string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(expression)
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		if unicode.IsSpace(r) {
+			i++
+			continue
+		}
+		if r == '"' || r == '\'' {
+			quote := r
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string")
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					b.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == quote {
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenString, value: b.String()})
+			continue
+		}
+		matched := false
+		for _, op := range filterOperators {
+			if strings.HasPrefix(string(runes[i:]), op) {
+				tokens = append(tokens, filterToken{kind: filterTokenOp, value: op})
+				i += len([]rune(op))
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		start := i
+		for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || strings.ContainsRune("._-/:", runes[i])) {
+			i++
+		}
+		if start == i {
+			return nil, fmt.Errorf("unexpected %q", string(r))
+		}
+		word := string(runes[start:i])
+		if _, err := strconv.ParseFloat(word, 64); err == nil {
+			tokens = append(tokens, filterToken{kind: filterTokenNumber, value: word})
+			continue
+		}
+		tokens = append(tokens, filterToken{kind: filterTokenIdent, value: word})
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() filterToken {
+	if p.done() {
+		return filterToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) isOp(op string) bool {
+	t := p.peek()
+	return !p.done() && t.kind == filterTokenOp && t.value == op
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.done() {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	if p.isOp("!") {
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &filterNot{node: node}, nil
+	}
+	if p.isOp("(") {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isOp(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return node, nil
+	}
+	field := p.peek()
+	if field.kind != filterTokenIdent {
+		return nil, fmt.Errorf("expected field, found %q", field.value)
+	}
+	p.pos++
+	op := p.peek()
+	if p.done() || op.kind != filterTokenOp || op.value == "&&" || op.value == "||" || op.value == ")" {
+		return &filterExists{field: field.value}, nil
+	}
+	switch op.value {
+	case "==", "!=", ">", ">=", "<", "<=", "=~", "!~", "^=", "$=":
+	default:
+		return nil, fmt.Errorf("unexpected %q after %s", op.value, field.value)
+	}
+	p.pos++
+	if p.done() {
+		return nil, fmt.Errorf("missing value for %s %s", field.value, op.value)
+	}
+	value := p.peek()
+	if value.kind == filterTokenOp {
+		return nil, fmt.Errorf("expected value, found %q", value.value)
+	}
+	p.pos++
+	compare := &filterCompare{
+		field: field.value,
+		op:    op.value,
+		value: value.value,
+	}
+	if value.kind == filterTokenNumber {
+		compare.number, _ = strconv.ParseFloat(value.value, 64)
+		compare.isNum = true
+	}
+	switch op.value {
+	case "=~", "!~":
+		regex, err := regexp.Compile(value.value)
+		if err != nil {
+			return nil, err
+		}
+		compare.regex = regex
+	case ">", ">=", "<", "<=":
+		if !compare.isNum {
+			return nil, fmt.Errorf("%s %s requires a number", field.value, op.value)
+		}
+	}
+	return compare, nil
+}
@@ -0,0 +1,143 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"testing"
+)
+
+func TestEventFilterMatch(t *testing.T) {
+	process := &ProcessEvent{
+		EventName: "ProcessExecuted",
+		Filename:  "/tmp/payload",
+		Comm:      "payload",
+		PID:       4242,
+		ContainerMetadata: ContainerMetadata{
+			ContainerID: "abc123",
+		},
+	}
+	socket := &SocketEvent{
+		EventName: "SocketState",
+		DestPort:  8080,
+		Comm:      "curl",
+	}
+	tests := []struct {
+		expression string
+		event      Event
+		match      bool
+	}{
+		{``, process, true},
+		{`Name == "ProcessExecuted"`, process, true},
+		{`Name != "ProcessExecuted"`, process, false},
+		{`Filename ^= "/tmp/"`, process, true},
+		{`Filename $= "load"`, process, true},
+		{`Filename ^= "/usr/"`, process, false},
+		{`Comm =~ "^pay"`, process, true},
+		{`Comm !~ "^pay"`, process, false},
+		{`PID == 4242`, process, true},
+		{`PID > 4000 && PID < 5000`, process, true},
+		{`PID >= 4243`, process, false},
+		{`PID <= 4242`, process, true},
+		{`ContainerID`, process, true},
+		{`!ContainerID`, process, false},
+		{`ContainerID`, socket, false},
+		{`Missing == "x"`, process, false},
+		{`Missing != "x"`, process, true},
+		{`Name == "SocketState" && (DestPort == 22 || DestPort >= 8000)`, socket, true},
+		{`Name == "SocketState" && (DestPort == 22 || DestPort > 9000)`, socket, false},
+		{`Name == "SocketState" || Name == "ProcessExecuted"`, process, true},
+		{`!(Comm =~ "^(runc|containerd)")`, socket, true},
+		{`Comm == 'curl'`, socket, true},
+	}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			f, err := ParseEventFilter(test.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if match := f.Match(test.event); match != test.match {
+				t.Errorf("expected %v, got %v", test.match, match)
+			}
+		})
+	}
+}
+
+func TestEventFilterParseErrors(t *testing.T) {
+	tests := []string{
+		`Name ==`,
+		`== "x"`,
+		`(Name == "x"`,
+		`Name == "x")`,
+		`Name == "unterminated`,
+		`Comm =~ "("`,
+		`PID > "x"`,
+		`Name == "x" &&`,
+		`Name @ "x"`,
+	}
+	for _, expression := range tests {
+		t.Run(expression, func(t *testing.T) {
+			_, err := ParseEventFilter(expression)
+			if err == nil {
+				t.Errorf("expected error for %q", expression)
+			}
+		})
+	}
+}
+
+func TestEventFilterNil(t *testing.T) {
+	var f *EventFilter
+	if !f.Match(&ProcessEvent{}) {
+		t.Errorf("nil filter should match every event")
+	}
+	if f.String() != "" {
+		t.Errorf("nil filter should have an empty expression")
+	}
+}
+
+func TestEventFilterReferences(t *testing.T) {
+	tests := []struct {
+		expression string
+		name       string
+		expected   bool
+	}{
+		{`Name == "ModuleLoaded"`, "ModuleLoaded", true},
+		{`Name == "ModuleLoaded"`, "ModuleUnloaded", false},
+		{`Name ^= "ProcessMemory"`, "ProcessMemoryRead", true},
+		{`Name $= "Loaded"`, "BPFProgramLoaded", true},
+		{`Name =~ "^Listener(Opened|Closed)$"`, "ListenerClosed", true},
+		{`Name =~ "^Listener(Opened|Closed)$"`, "ListenerFound", false},
+		{`PID == 1 && (Comm == "init" || Name == "Mounted")`, "Mounted", true},
+		{`!(Name == "Mounted")`, "Mounted", true},
+		{`Name != "Mounted"`, "Mounted", false},
+		{`Comm == "Mounted"`, "Mounted", false},
+		{``, "Mounted", false},
+	}
+	for _, test := range tests {
+		t.Run(test.expression+" "+test.name, func(t *testing.T) {
+			f := MustParseEventFilter(test.expression)
+			if actual := f.References("Name", test.name); actual != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, actual)
+			}
+		})
+	}
+}
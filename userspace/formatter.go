@@ -0,0 +1,72 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Version is the version of dse reported by formatters.
+// Set at build time with -ldflags "-X ...userspace.Version=v0.1.0"
+var Version = "dev"
+
+// Formatter will serialize an Event for an Output.
+type Formatter interface {
+	Format(event Event) ([]byte, error)
+}
+
+// ParseFormatter will return the Formatter for a name.
+//
+//   json   The raw Event JSON (default)
+//   ecs    Elastic Common Schema JSON
+//   cef    ArcSight Common Event Format
+//   leef   IBM QRadar Log Event Extended Format
+func ParseFormatter(name string) (Formatter, error) {
+	switch strings.ToLower(name) {
+	case "", "json":
+		return &JSONFormatter{}, nil
+	case "ecs":
+		return NewECSFormatter(), nil
+	case "cef":
+		return NewCEFFormatter(), nil
+	case "leef":
+		return NewLEEFFormatter(), nil
+	}
+	return nil, fmt.Errorf("unknown format %s", name)
+}
+
+// formatterOrDefault will return the JSONFormatter for a nil Formatter.
+func formatterOrDefault(formatter Formatter) Formatter {
+	if formatter == nil {
+		return &JSONFormatter{}
+	}
+	return formatter
+}
+
+// JSONFormatter will format an Event with Event.JSON()
+type JSONFormatter struct{}
+
+func (f *JSONFormatter) Format(event Event) ([]byte, error) {
+	return event.JSON()
+}
@@ -0,0 +1,254 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	formatterVendor  = "kris-nova"
+	formatterProduct = "dse"
+
+	// DefaultSeverity is the CEF severity (0-10) of an event
+	// that does not report its own.
+	DefaultSeverity = 3
+)
+
+// formatterExtension is a single key value pair in a CEF or LEEF line.
+type formatterExtension struct {
+	key   string
+	value string
+}
+
+// formatterField maps an Event field to its CEF and LEEF key.
+type formatterField struct {
+	cef   string
+	leef  string
+	field string
+	label string
+}
+
+// formatterFields are mapped for every event that has them. Fields
+// without a standard key use the CEF custom fields, with a label.
+var formatterFields = []formatterField{
+	{cef: "spid", leef: "srcPid", field: "PID"},
+	{cef: "sproc", leef: "srcProc", field: "Comm"},
+	{cef: "filePath", leef: "filePath", field: "Filename"},
+	{cef: "cs1", leef: "containerId", field: "ContainerID", label: "containerId"},
+	{cef: "cs2", leef: "signal", field: "SignalName", label: "signal"},
+	{cef: "cs4", leef: "podName", field: "PodName", label: "podName"},
+	{cef: "cs5", leef: "podNamespace", field: "PodNamespace", label: "podNamespace"},
+	{cef: "cs6", leef: "containerImage", field: "ContainerImage", label: "containerImage"},
+}
+
+// formatterExtensions will build the key value pairs for an Event
+// using either the CEF or LEEF keys.
+func formatterExtensions(event Event, leef bool) []formatterExtension {
+	var ext []formatterExtension
+	add := func(cefKey, leefKey, value string) {
+		key := cefKey
+		if leef {
+			key = leefKey
+		}
+		if key == "" || value == "" {
+			return
+		}
+		ext = append(ext, formatterExtension{key: key, value: value})
+	}
+	for _, f := range formatterFields {
+		value := EventFieldString(event, f.field)
+		if value == "" || value == "0" {
+			continue
+		}
+		add(f.cef, f.leef, value)
+		if f.label != "" {
+			add(f.cef+"Label", "", f.label)
+		}
+	}
+	switch e := event.(type) {
+	case *ContainerEvent:
+		add("spid", "srcPid", fmt.Sprint(e.ParentPid))
+		add("dpid", "dstPid", fmt.Sprint(e.ChildPid))
+		if e.ParentProc != nil {
+			add("sproc", "srcProc", e.ParentProc.Executable)
+		}
+		if e.ChildProc != nil {
+			add("dproc", "dstProc", e.ChildProc.Executable)
+		}
+	case *SocketEvent:
+		source, destination := e.SourceAddr, e.DestAddr
+		if e.Family == uint(unix.AF_INET6) {
+			source, destination = e.SourceAddrV6, e.DestAddrV6
+		}
+		add("src", "src", source)
+		add("dst", "dst", destination)
+		if e.SourcePort != 0 {
+			add("spt", "srcPort", fmt.Sprint(e.SourcePort))
+		}
+		if e.DestPort != 0 {
+			add("dpt", "dstPort", fmt.Sprint(e.DestPort))
+		}
+		add("proto", "proto", strings.ToUpper(ProtocolName(e.Protocol)))
+		if e.NewStateName != "" {
+			add("cs3", "tcpState", e.NewStateName)
+			add("cs3Label", "", "tcpState")
+		}
+	}
+	return ext
+}
+
+// CEFFormatter will format events as ArcSight Common Event Format lines.
+//
+//   CEF:0|kris-nova|dse|<version>|ProcessExecuted|ProcessExecuted|3|rt=... spid=42 filePath=/bin/sh
+type CEFFormatter struct {
+	hostname string
+}
+
+func NewCEFFormatter() *CEFFormatter {
+	hostname, _ := os.Hostname()
+	return &CEFFormatter{
+		hostname: hostname,
+	}
+}
+
+func (f *CEFFormatter) Format(event Event) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|%s|%s|%s|%s|%s|%d|",
+		cefHeaderEscape(formatterVendor),
+		cefHeaderEscape(formatterProduct),
+		cefHeaderEscape(Version),
+		cefHeaderEscape(formatterSignature(event)),
+		cefHeaderEscape(formatterDescription(event)),
+		EventSeverity(event))
+	ext := []formatterExtension{
+		{key: "rt", value: fmt.Sprint(EventTimestamp(event).UnixNano() / int64(time.Millisecond))},
+	}
+	if f.hostname != "" {
+		ext = append(ext, formatterExtension{key: "dvchost", value: f.hostname})
+	}
+	ext = append(ext, formatterExtensions(event, false)...)
+	for i, e := range ext {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(e.key)
+		b.WriteByte('=')
+		b.WriteString(cefExtensionEscape(e.value))
+	}
+	return []byte(b.String()), nil
+}
+
+func cefHeaderEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ").Replace(s)
+}
+
+func cefExtensionEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`).Replace(s)
+}
+
+// LEEFFormatter will format events as LEEF 1.0 lines with tab
+// delimited attributes.
+//
+//   LEEF:1.0|kris-nova|dse|<version>|ProcessExecuted|devTime=...	srcPid=42	filePath=/bin/sh
+type LEEFFormatter struct {
+	hostname string
+}
+
+func NewLEEFFormatter() *LEEFFormatter {
+	hostname, _ := os.Hostname()
+	return &LEEFFormatter{
+		hostname: hostname,
+	}
+}
+
+func (f *LEEFFormatter) Format(event Event) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "LEEF:1.0|%s|%s|%s|%s|",
+		leefHeaderEscape(formatterVendor),
+		leefHeaderEscape(formatterProduct),
+		leefHeaderEscape(Version),
+		leefHeaderEscape(formatterSignature(event)))
+	ext := []formatterExtension{
+		{key: "devTime", value: fmt.Sprint(EventTimestamp(event).UnixNano() / int64(time.Millisecond))},
+		{key: "devTimeFormat", value: "epoch"},
+		{key: "sev", value: fmt.Sprint(EventSeverity(event))},
+	}
+	if f.hostname != "" {
+		ext = append(ext, formatterExtension{key: "identHostName", value: f.hostname})
+	}
+	ext = append(ext, formatterExtensions(event, true)...)
+	for i, e := range ext {
+		if i > 0 {
+			b.WriteByte('\t')
+		}
+		b.WriteString(e.key)
+		b.WriteByte('=')
+		b.WriteString(leefValueEscape(e.value))
+	}
+	return []byte(b.String()), nil
+}
+
+func leefHeaderEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "\t", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+func leefValueEscape(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// formatterSignature is the CEF signature ID, and LEEF event ID,
+// of an Event. Alerts are identified by their rule.
+func formatterSignature(event Event) string {
+	if alert, ok := event.(*AlertEvent); ok {
+		return alert.RuleID
+	}
+	return event.Name()
+}
+
+// formatterDescription is the human readable CEF name of an Event.
+func formatterDescription(event Event) string {
+	if alert, ok := event.(*AlertEvent); ok && alert.Description != "" {
+		return alert.Description
+	}
+	return event.Name()
+}
+
+// SeverityEvent is implemented by events that report their own
+// severity on a 0-10 scale.
+type SeverityEvent interface {
+	Severity() int
+}
+
+// EventSeverity will return the severity of an Event on a 0-10 scale.
+func EventSeverity(event Event) int {
+	if s, ok := event.(SeverityEvent); ok {
+		return s.Severity()
+	}
+	return DefaultSeverity
+}
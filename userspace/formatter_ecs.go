@@ -0,0 +1,557 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// ECSVersion is the version of the Elastic Common Schema produced.
+	ECSVersion = "1.12.0"
+)
+
+// ECSFormatter will format events as Elastic Common Schema JSON.
+// The original event fields are kept under the "dse" object.
+//
+// More:
+//   https://www.elastic.co/guide/en/ecs/current/index.html
+type ECSFormatter struct {
+	hostname string
+}
+
+func NewECSFormatter() *ECSFormatter {
+	hostname, _ := os.Hostname()
+	return &ECSFormatter{
+		hostname: hostname,
+	}
+}
+
+// ecsDocument is a nested ECS document built with dotted field names.
+type ecsDocument map[string]interface{}
+
+func (d ecsDocument) set(path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	m := d
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := m[part].(ecsDocument)
+		if !ok {
+			child = ecsDocument{}
+			m[part] = child
+		}
+		m = child
+	}
+	m[parts[len(parts)-1]] = value
+}
+
+// setField will copy an Event field to the document if it is set.
+func (d ecsDocument) setField(path string, event Event, field string) {
+	value, ok := EventField(event, field)
+	if !ok {
+		return
+	}
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return
+		}
+	case int:
+		if v == 0 {
+			return
+		}
+	case uint:
+		if v == 0 {
+			return
+		}
+	case map[string]string:
+		if len(v) == 0 {
+			return
+		}
+	}
+	d.set(path, value)
+}
+
+func (f *ECSFormatter) Format(event Event) ([]byte, error) {
+	raw, err := event.JSON()
+	if err != nil {
+		return nil, err
+	}
+	var original interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	err = decoder.Decode(&original)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := ecsDocument{}
+	doc.set("@timestamp", EventTimestamp(event).UTC().Format(time.RFC3339Nano))
+	doc.set("ecs.version", ECSVersion)
+	doc.set("event.kind", "event")
+	doc.set("event.module", SyslogAppName)
+	doc.set("event.dataset", SyslogAppName+"."+event.Name())
+	doc.set("event.action", event.Name())
+	doc.set("agent.type", SyslogAppName)
+	doc.set("agent.version", Version)
+	if f.hostname != "" {
+		doc.set("host.hostname", f.hostname)
+	}
+	doc.set("dse", original)
+
+	// Fields shared by many events
+	doc.setField("process.pid", event, "PID")
+	doc.setField("process.parent.pid", event, "ParentPid")
+	doc.setField("process.parent.pid", event, "PPID")
+	if uid, ok := EventField(event, "UID"); ok {
+		doc.set("user.id", fmt.Sprint(uid))
+	}
+	doc.setField("container.id", event, "ContainerID")
+	doc.setField("container.name", event, "ContainerName")
+	doc.setField("container.image.name", event, "ContainerImage")
+	doc.setField("container.labels", event, "ContainerLabels")
+	doc.setField("kubernetes.pod.name", event, "PodName")
+	doc.setField("kubernetes.pod.uid", event, "PodUID")
+	doc.setField("kubernetes.namespace", event, "PodNamespace")
+	doc.setField("kubernetes.labels", event, "PodLabels")
+
+	switch e := event.(type) {
+	case *ProcessEvent:
+		doc.set("event.category", []string{"process"})
+		doc.set("event.type", []string{"start"})
+		if e.Filename != "" {
+			doc.set("process.executable", e.Filename)
+			doc.set("process.name", filepath.Base(e.Filename))
+		}
+	case *ContainerEvent:
+		doc.set("event.category", []string{"process"})
+		doc.set("event.type", []string{"start"})
+		doc.set("process.pid", e.ChildPid)
+		if e.ChildProc != nil {
+			doc.set("process.name", e.ChildProc.Executable)
+		}
+		if e.ParentProc != nil {
+			doc.set("process.parent.name", e.ParentProc.Executable)
+		}
+	case *AlertEvent:
+		doc.set("event.kind", "alert")
+		doc.set("event.category", []string{"intrusion_detection"})
+		doc.set("event.type", []string{"info"})
+		doc.set("event.severity", e.Severity())
+		doc.set("rule.id", e.RuleID)
+		doc.set("rule.description", e.Description)
+		if len(e.Tags) > 0 {
+			doc.set("tags", e.Tags)
+		}
+		if e.Filename != "" {
+			doc.set("process.executable", e.Filename)
+		}
+	case *FileOpenEvent:
+		doc.set("event.category", []string{"file"})
+		eventType := []string{"access"}
+		for _, flag := range e.FlagNames {
+			if flag == "O_CREAT" {
+				eventType = append(eventType, "creation")
+			}
+		}
+		doc.set("event.type", eventType)
+		doc.set("event.outcome", "success")
+		if e.Error != "" {
+			doc.set("event.outcome", "failure")
+			doc.set("error.code", e.Error)
+		}
+		doc.set("file.path", e.Filename)
+		doc.set("file.name", filepath.Base(e.Filename))
+		doc.set("file.directory", filepath.Dir(e.Filename))
+		doc.set("process.name", e.Comm)
+	case *FileChangeEvent:
+		doc.set("event.category", []string{"file"})
+		eventType := "change"
+		if e.EventName == EventNameFileDeleted {
+			eventType = "deletion"
+		}
+		doc.set("event.type", []string{eventType})
+		doc.set("event.outcome", "success")
+		if e.Error != "" {
+			doc.set("event.outcome", "failure")
+			doc.set("error.code", e.Error)
+		}
+		doc.set("file.path", e.Filename)
+		doc.set("file.name", filepath.Base(e.Filename))
+		doc.set("file.directory", filepath.Dir(e.Filename))
+		if e.NewFilename != "" {
+			doc.set("file.target_path", e.NewFilename)
+		}
+		if e.Mode != "" {
+			doc.set("file.mode", e.Mode)
+		}
+		if e.Owner != nil && *e.Owner >= 0 {
+			doc.set("file.uid", strconv.Itoa(*e.Owner))
+			doc.setField("file.owner", event, "OwnerName")
+		}
+		if e.Group != nil && *e.Group >= 0 {
+			doc.set("file.gid", strconv.Itoa(*e.Group))
+			doc.setField("file.group", event, "GroupName")
+		}
+		if e.Length != nil {
+			doc.set("file.size", *e.Length)
+		}
+		doc.set("process.name", e.Comm)
+	case *MountEvent:
+		doc.set("event.category", []string{"file"})
+		eventType := "creation"
+		switch e.EventName {
+		case EventNameUnmounted:
+			eventType = "deletion"
+		case EventNameMountPrepared:
+			eventType = "info"
+		}
+		doc.set("event.type", []string{eventType})
+		doc.set("event.outcome", "success")
+		if e.Error != "" {
+			doc.set("event.outcome", "failure")
+			doc.set("error.code", e.Error)
+		}
+		if e.Target != "" {
+			doc.set("file.path", e.Target)
+			doc.set("file.name", filepath.Base(e.Target))
+			doc.set("file.directory", filepath.Dir(e.Target))
+		}
+		doc.set("process.name", e.Comm)
+	case *PrivilegeEvent:
+		doc.set("event.category", []string{"iam"})
+		if e.EventName == EventNameNamespaceChanged {
+			doc.set("event.category", []string{"process"})
+		}
+		doc.set("event.type", []string{"change"})
+		doc.set("event.outcome", "success")
+		if e.Error != "" {
+			doc.set("event.outcome", "failure")
+			doc.set("error.code", e.Error)
+		}
+		doc.set("process.name", e.Comm)
+		if e.Filename != "" {
+			doc.set("process.executable", e.Filename)
+		}
+		doc.set("user.id", strconv.Itoa(int(e.Old.UID)))
+		doc.set("user.group.id", strconv.Itoa(int(e.Old.GID)))
+		doc.set("user.effective.id", strconv.Itoa(int(e.New.EUID)))
+		doc.set("user.effective.group.id", strconv.Itoa(int(e.New.EGID)))
+		if e.New.UID != e.Old.UID {
+			doc.set("user.changes.id", strconv.Itoa(int(e.New.UID)))
+		}
+		if e.New.GID != e.Old.GID {
+			doc.set("user.changes.group.id", strconv.Itoa(int(e.New.GID)))
+		}
+	case *ModuleEvent:
+		doc.set("event.category", []string{"driver"})
+		eventType := "start"
+		if e.EventName == EventNameModuleUnloaded {
+			eventType = "end"
+		}
+		doc.set("event.type", []string{eventType})
+		doc.set("event.outcome", "success")
+		if e.Error != "" {
+			doc.set("event.outcome", "failure")
+			doc.set("error.code", e.Error)
+		}
+		if e.Filename != "" {
+			doc.set("file.path", e.Filename)
+			doc.set("file.name", filepath.Base(e.Filename))
+		}
+		doc.set("process.name", e.Comm)
+	case *BPFEvent:
+		doc.set("event.category", []string{"driver"})
+		eventType := "info"
+		switch e.EventName {
+		case EventNameBPFProgramAttached:
+			eventType = "start"
+		case EventNameBPFProgramDetached:
+			eventType = "end"
+		}
+		doc.set("event.type", []string{eventType})
+		doc.set("event.outcome", "success")
+		if e.Error != "" {
+			doc.set("event.outcome", "failure")
+			doc.set("error.code", e.Error)
+		}
+		doc.set("process.name", e.Comm)
+	case *ProcessAccessEvent:
+		doc.set("event.category", []string{"process"})
+		eventType := []string{"access"}
+		if e.Write {
+			eventType = append(eventType, "change")
+		}
+		doc.set("event.type", eventType)
+		doc.set("event.outcome", "success")
+		if e.Error != "" {
+			doc.set("event.outcome", "failure")
+			doc.set("error.code", e.Error)
+		}
+		doc.set("process.name", e.Comm)
+		doc.set("process.target.pid", e.TargetPID)
+		if e.TargetComm != "" {
+			doc.set("process.target.name", e.TargetComm)
+		}
+		if e.TargetContainerID != "" {
+			doc.set("process.target.container.id", e.TargetContainerID)
+		}
+	case *LifecycleEvent:
+		doc.set("event.category", []string{"process"})
+		doc.set("process.name", e.Comm)
+		switch e.EventName {
+		case EventNameProcessForked:
+			doc.set("event.type", []string{"start"})
+			doc.set("process.pid", e.ChildPID)
+			doc.set("process.parent.pid", e.PID)
+		case EventNameProcessExec:
+			doc.set("event.type", []string{"start"})
+			if e.Filename != "" {
+				doc.set("process.executable", e.Filename)
+				doc.set("process.name", filepath.Base(e.Filename))
+			}
+		case EventNameProcessExited:
+			doc.set("event.type", []string{"end"})
+			doc.set("process.exit_code", e.ExitCode)
+		}
+	case *ExecDeniedEvent:
+		doc.set("event.category", []string{"process", "intrusion_detection"})
+		doc.set("event.type", []string{"start", "denied"})
+		doc.set("event.outcome", "success")
+		if e.Enforced {
+			doc.set("event.outcome", "failure")
+		}
+		doc.set("event.reason", e.Reason)
+		doc.set("process.executable", e.Filename)
+		doc.set("process.name", filepath.Base(e.Filename))
+		doc.set("file.path", e.Filename)
+		if e.Inode != 0 {
+			doc.set("file.inode", strconv.FormatUint(e.Inode, 10))
+		}
+	case *RateExceededEvent:
+		doc.set("event.kind", "alert")
+		doc.set("event.category", []string{"intrusion_detection"})
+		doc.set("event.type", []string{"info"})
+		doc.set("event.reason", fmt.Sprintf("%d events in %s over the threshold %d", e.Count, e.Window, e.Threshold))
+		doc.set("rule.name", e.Rate)
+		doc.setField("process.name", event, "Comm")
+	case *ResponseEvent:
+		doc.set("event.category", []string{"intrusion_detection"})
+		doc.set("event.type", []string{"info"})
+		doc.set("event.action", e.Action)
+		switch e.Result {
+		case ResponseResultDone:
+			doc.set("event.outcome", "success")
+		case ResponseResultFailed:
+			doc.set("event.outcome", "failure")
+		default:
+			doc.set("event.outcome", "unknown")
+		}
+		doc.set("event.reason", e.Result)
+		doc.set("rule.id", e.PolicyID)
+		doc.setField("process.name", event, "Comm")
+		doc.setField("process.pgid", event, "PGID")
+		if e.Error != "" {
+			doc.set("error.message", e.Error)
+		}
+	case *SignalEvent:
+		doc.set("event.category", []string{"process"})
+		doc.set("event.type", []string{"info"})
+	case *SocketEvent:
+		doc.set("event.category", []string{"network"})
+		eventType := []string{"connection"}
+		switch e.NewState {
+		case TCP_ESTABLISHED:
+			eventType = append(eventType, "start")
+		case TCP_CLOSE:
+			eventType = append(eventType, "end")
+		}
+		doc.set("event.type", eventType)
+		source, destination := e.SourceAddr, e.DestAddr
+		doc.set("network.type", "ipv4")
+		if e.Family == uint(unix.AF_INET6) {
+			source, destination = e.SourceAddrV6, e.DestAddrV6
+			doc.set("network.type", "ipv6")
+		}
+		if name := ProtocolName(e.Protocol); name != "" {
+			doc.set("network.transport", name)
+		}
+		doc.set("source.ip", source)
+		doc.set("source.port", e.SourcePort)
+		doc.set("destination.ip", destination)
+		doc.set("destination.port", e.DestPort)
+	case *ConnectionEvent:
+		doc.set("event.category", []string{"network"})
+		eventType := []string{"connection"}
+		if e.Error == "" && !e.InProgress {
+			eventType = append(eventType, "start")
+		}
+		doc.set("event.type", eventType)
+		if e.Error != "" {
+			doc.set("event.outcome", "failure")
+		}
+		if e.Protocol != "" {
+			doc.set("network.transport", e.Protocol)
+		}
+		if e.Family == "AF_UNIX" {
+			break
+		}
+		doc.set("network.type", "ipv4")
+		if e.Family == "AF_INET6" {
+			doc.set("network.type", "ipv6")
+		}
+		doc.set("network.direction", "egress")
+		source, sourcePort, destination, destinationPort := e.LocalAddr, e.LocalPort, e.PeerAddr, e.PeerPort
+		if e.EventName == EventNameSocketAccepted {
+			doc.set("network.direction", "ingress")
+			source, sourcePort, destination, destinationPort = e.PeerAddr, e.PeerPort, e.LocalAddr, e.LocalPort
+		}
+		doc.set("source.ip", source)
+		doc.set("source.port", sourcePort)
+		doc.set("destination.ip", destination)
+		doc.set("destination.port", destinationPort)
+	case *UDPFlowEvent:
+		doc.set("event.category", []string{"network"})
+		doc.set("event.type", []string{"connection", "info"})
+		doc.set("network.transport", "udp")
+		doc.set("network.type", "ipv4")
+		if e.Family == "AF_INET6" {
+			doc.set("network.type", "ipv6")
+		}
+		doc.set("network.bytes", e.BytesSent+e.BytesReceived)
+		doc.set("network.packets", e.PacketsSent+e.PacketsReceived)
+		doc.set("process.name", e.Comm)
+		doc.set("source.ip", e.LocalAddr)
+		doc.set("source.port", e.LocalPort)
+		doc.set("source.bytes", e.BytesSent)
+		doc.set("source.packets", e.PacketsSent)
+		if e.PeerAddr != "" {
+			doc.set("destination.ip", e.PeerAddr)
+			doc.set("destination.port", e.PeerPort)
+		}
+		doc.set("destination.bytes", e.BytesReceived)
+		doc.set("destination.packets", e.PacketsReceived)
+	case *DNSEvent:
+		doc.set("event.category", []string{"network"})
+		doc.set("event.type", []string{"protocol", "info"})
+		doc.set("network.transport", "udp")
+		doc.set("network.protocol", "dns")
+		doc.set("network.type", "ipv4")
+		if e.Family == "AF_INET6" {
+			doc.set("network.type", "ipv6")
+		}
+		doc.set("process.name", e.Comm)
+		doc.set("dns.type", "query")
+		doc.set("dns.id", strconv.Itoa(int(e.ID)))
+		doc.set("dns.question.name", e.QueryName)
+		doc.set("dns.question.type", e.QueryType)
+		if e.EventName == EventNameDNSResponse {
+			doc.set("dns.type", "answer")
+			doc.set("dns.response_code", e.RCode)
+			var answers []ecsDocument
+			for _, answer := range e.Answers {
+				answers = append(answers, ecsDocument{
+					"name": answer.Name,
+					"type": answer.Type,
+					"ttl":  answer.TTL,
+					"data": answer.Data,
+				})
+			}
+			if len(answers) > 0 {
+				doc.set("dns.answers", answers)
+			}
+		}
+		if e.Error != "" {
+			doc.set("error.message", e.Error)
+		}
+	case *ListenerEvent:
+		doc.set("event.category", []string{"network"})
+		eventType := []string{"info"}
+		switch e.EventName {
+		case EventNameListenerOpened:
+			eventType = []string{"start"}
+		case EventNameListenerClosed:
+			eventType = []string{"end"}
+		}
+		doc.set("event.type", eventType)
+		if e.Error != "" {
+			doc.set("event.outcome", "failure")
+			doc.set("error.code", e.Error)
+		}
+		doc.set("network.transport", e.Protocol)
+		doc.set("network.type", "ipv4")
+		if e.Family == "AF_INET6" {
+			doc.set("network.type", "ipv6")
+		}
+		doc.setField("process.name", event, "Comm")
+		doc.set("server.ip", e.Addr)
+		doc.set("server.port", e.Port)
+	case *TCPEvent:
+		doc.set("event.category", []string{"network"})
+		doc.set("event.type", []string{"connection", "info"})
+		if e.Reason != "" {
+			doc.set("event.reason", e.Reason)
+		}
+		doc.set("network.transport", "tcp")
+		doc.set("network.type", "ipv4")
+		if e.Family == "AF_INET6" {
+			doc.set("network.type", "ipv6")
+		}
+		doc.setField("process.name", event, "Comm")
+		doc.set("source.ip", e.SourceAddr)
+		doc.set("source.port", e.SourcePort)
+		doc.set("destination.ip", e.DestAddr)
+		doc.set("destination.port", e.DestPort)
+	}
+	return json.Marshal(doc)
+}
+
+// ProtocolName will return the lower case name of an IP protocol,
+// or an empty string if the protocol is unknown.
+func ProtocolName(protocol uint) string {
+	switch protocol {
+	case unix.IPPROTO_TCP:
+		return "tcp"
+	case unix.IPPROTO_UDP:
+		return "udp"
+	case unix.IPPROTO_ICMP:
+		return "icmp"
+	case unix.IPPROTO_ICMPV6:
+		return "ipv6-icmp"
+	case unix.IPPROTO_DCCP:
+		return "dccp"
+	case unix.IPPROTO_SCTP:
+		return "sctp"
+	case unix.IPPROTO_MPTCP:
+		return "mptcp"
+	}
+	return ""
+}
@@ -0,0 +1,384 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var formatterTime = time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+
+func formatterEvent() *ProcessEvent {
+	event := &ProcessEvent{
+		EventName: "ProcessExecuted",
+		Filename:  "/usr/bin/curl",
+		Comm:      "curl",
+		PID:       42,
+		ContainerMetadata: ContainerMetadata{
+			ContainerID: "abc123",
+			PodName:     "web|1",
+		},
+	}
+	event.setTimestamp(formatterTime)
+	return event
+}
+
+func TestParseFormatter(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"", true},
+		{"json", true},
+		{"ECS", true},
+		{"cef", true},
+		{"leef", true},
+		{"xml", false},
+	}
+	for _, test := range tests {
+		_, err := ParseFormatter(test.name)
+		if (err == nil) != test.valid {
+			t.Errorf("%q: expected valid=%v, got %v", test.name, test.valid, err)
+		}
+	}
+}
+
+func TestECSFormatter(t *testing.T) {
+	b, err := NewECSFormatter().Format(formatterEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	err = json.Unmarshal(b, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path  string
+		value interface{}
+	}{
+		{"@timestamp", "2021-12-01T10:00:00Z"},
+		{"ecs.version", ECSVersion},
+		{"event.kind", "event"},
+		{"event.action", "ProcessExecuted"},
+		{"event.category", []interface{}{"process"}},
+		{"process.pid", float64(42)},
+		{"process.executable", "/usr/bin/curl"},
+		{"process.name", "curl"},
+		{"container.id", "abc123"},
+		{"kubernetes.pod.name", "web|1"},
+		{"dse.Comm", "curl"},
+	}
+	for _, test := range tests {
+		value := ecsLookup(doc, test.path)
+		if !jsonEqual(value, test.value) {
+			t.Errorf("%s: expected %v, got %v", test.path, test.value, value)
+		}
+	}
+}
+
+func TestECSFormatterEvents(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  Event
+		fields map[string]interface{}
+	}{
+		{
+			name:  "alert",
+			event: &AlertEvent{EventName: EventNameAlert, RuleID: "shell-in-container", SeverityName: "high"},
+			fields: map[string]interface{}{
+				"event.kind":     "alert",
+				"event.category": []string{"intrusion_detection"},
+				"rule.id":        "shell-in-container",
+			},
+		},
+		{
+			name:  "mount",
+			event: &MountEvent{EventName: EventNameMounted, Syscall: "mount", PID: 42, PPID: 1, UID: 0, Comm: "mount", Source: "/dev/sda1", Target: "/mnt/host", FSType: "ext4"},
+			fields: map[string]interface{}{
+				"event.category": []string{"file"},
+				"event.type":     []string{"creation"},
+				"event.outcome":  "success",
+				"file.path":      "/mnt/host",
+				"file.name":      "host",
+				"process.name":   "mount",
+				"user.id":        "0",
+			},
+		},
+		{
+			name:  "unmount failed",
+			event: &MountEvent{EventName: EventNameUnmounted, Syscall: "umount2", Target: "/mnt", Error: "EPERM"},
+			fields: map[string]interface{}{
+				"event.type":    []string{"deletion"},
+				"event.outcome": "failure",
+				"error.code":    "EPERM",
+			},
+		},
+		{
+			name:  "setuid",
+			event: &PrivilegeEvent{EventName: EventNamePrivilegeChanged, Syscall: "setuid", Comm: "su", Old: Credentials{UID: 1000, EUID: 1000}, New: Credentials{UID: 0, EUID: 0}},
+			fields: map[string]interface{}{
+				"event.category":    []string{"iam"},
+				"event.type":        []string{"change"},
+				"process.name":      "su",
+				"user.id":           "1000",
+				"user.effective.id": "0",
+				"user.changes.id":   "0",
+			},
+		},
+		{
+			name:  "setns",
+			event: &PrivilegeEvent{EventName: EventNameNamespaceChanged, Syscall: "setns", Old: Credentials{UID: 1000}, New: Credentials{UID: 1000}},
+			fields: map[string]interface{}{
+				"event.category":  []string{"process"},
+				"user.id":         "1000",
+				"user.changes.id": nil,
+			},
+		},
+		{
+			name:  "module loaded",
+			event: &ModuleEvent{EventName: EventNameModuleLoaded, Syscall: "finit_module", Comm: "insmod", Filename: "/tmp/rootkit.ko", UID: 0},
+			fields: map[string]interface{}{
+				"event.category": []string{"driver"},
+				"event.type":     []string{"start"},
+				"file.path":      "/tmp/rootkit.ko",
+				"process.name":   "insmod",
+			},
+		},
+		{
+			name:  "module unloaded",
+			event: &ModuleEvent{EventName: EventNameModuleUnloaded, Syscall: "delete_module", Module: "rootkit"},
+			fields: map[string]interface{}{
+				"event.type": []string{"end"},
+				"file.path":  nil,
+			},
+		},
+		{
+			name:  "bpf program attached",
+			event: &BPFEvent{EventName: EventNameBPFProgramAttached, Command: "BPF_LINK_CREATE", Comm: "bpftool", UID: 0},
+			fields: map[string]interface{}{
+				"event.category": []string{"driver"},
+				"event.type":     []string{"start"},
+				"process.name":   "bpftool",
+				"user.id":        "0",
+			},
+		},
+		{
+			name:  "process traced",
+			event: &ProcessAccessEvent{EventName: EventNameProcessTraced, Syscall: "ptrace", PID: 42, Comm: "gdb", TargetPID: 880, TargetComm: "sshd", Write: true},
+			fields: map[string]interface{}{
+				"event.category":      []string{"process"},
+				"event.type":          []string{"access", "change"},
+				"process.pid":         42,
+				"process.target.pid":  880,
+				"process.target.name": "sshd",
+			},
+		},
+		{
+			name:  "process ppid and uid",
+			event: &ProcessEvent{EventName: "ProcessExecuted", Filename: "/bin/sh", PID: 42, PPID: 1, UID: 0},
+			fields: map[string]interface{}{
+				"process.pid":        42,
+				"process.parent.pid": 1,
+				"user.id":            "0",
+			},
+		},
+		{
+			name:  "process forked",
+			event: &LifecycleEvent{EventName: EventNameProcessForked, PID: 42, PPID: 1, ChildPID: 43, UID: 1000, Comm: "bash"},
+			fields: map[string]interface{}{
+				"event.category":     []string{"process"},
+				"event.type":         []string{"start"},
+				"process.pid":        43,
+				"process.parent.pid": 42,
+				"process.name":       "bash",
+				"user.id":            "1000",
+			},
+		},
+		{
+			name:  "process exited",
+			event: &LifecycleEvent{EventName: EventNameProcessExited, PID: 43, PPID: 42, Comm: "bash", ExitCode: 2},
+			fields: map[string]interface{}{
+				"event.type":         []string{"end"},
+				"process.pid":        43,
+				"process.parent.pid": 42,
+				"process.exit_code":  2,
+			},
+		},
+		{
+			name:  "exec denied",
+			event: &ExecDeniedEvent{EventName: EventNameExecDenied, PID: 42, Comm: "sh", Filename: "/tmp/x", Reason: "prefix /tmp/", Inode: 1234, Enforced: true},
+			fields: map[string]interface{}{
+				"event.category":     []string{"process", "intrusion_detection"},
+				"event.type":         []string{"start", "denied"},
+				"event.outcome":      "failure",
+				"event.reason":       "prefix /tmp/",
+				"process.executable": "/tmp/x",
+				"file.inode":         "1234",
+			},
+		},
+		{
+			name:  "rate exceeded",
+			event: &RateExceededEvent{EventName: EventNameRateExceeded, Rate: "fork_container", Count: 120, Threshold: 100, Window: "10s", PID: 42, Comm: "sh"},
+			fields: map[string]interface{}{
+				"event.kind":   "alert",
+				"event.reason": "120 events in 10s over the threshold 100",
+				"rule.name":    "fork_container",
+				"process.pid":  42,
+				"process.name": "sh",
+			},
+		},
+		{
+			name:  "response",
+			event: &ResponseEvent{EventName: EventNameResponse, PolicyID: "kill-shell", Action: ResponseActionKill, PID: 42, Result: ResponseResultDone, Comm: "sh"},
+			fields: map[string]interface{}{
+				"event.action":  "kill",
+				"event.outcome": "success",
+				"rule.id":       "kill-shell",
+				"process.pid":   42,
+			},
+		},
+		{
+			name:  "response failed",
+			event: &ResponseEvent{EventName: EventNameResponse, PolicyID: "kill-shell", Action: ResponseActionKill, PID: 42, Result: ResponseResultFailed, Error: "ESRCH"},
+			fields: map[string]interface{}{
+				"event.outcome": "failure",
+				"error.message": "ESRCH",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := NewECSFormatter().Format(test.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc map[string]interface{}
+			err = json.Unmarshal(b, &doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for path, expected := range test.fields {
+				if value := ecsLookup(doc, path); !jsonEqual(value, expected) {
+					t.Errorf("%s: expected %v, got %v", path, expected, value)
+				}
+			}
+		})
+	}
+}
+
+func TestCEFFormatter(t *testing.T) {
+	b, err := NewCEFFormatter().Format(formatterEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := string(b)
+	prefix := "CEF:0|kris-nova|dse|" + Version + "|ProcessExecuted|ProcessExecuted|3|"
+	if !strings.HasPrefix(line, prefix) {
+		t.Fatalf("expected prefix %q, got %q", prefix, line)
+	}
+	for _, expected := range []string{
+		"rt=1638352800000",
+		"spid=42",
+		"sproc=curl",
+		"filePath=/usr/bin/curl",
+		"cs1=abc123 cs1Label=containerId",
+		"cs4=web|1 cs4Label=podName",
+	} {
+		if !strings.Contains(line, expected) {
+			t.Errorf("expected %q in %q", expected, line)
+		}
+	}
+}
+
+func TestLEEFFormatter(t *testing.T) {
+	b, err := NewLEEFFormatter().Format(formatterEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := strings.Split(string(b), "\t")
+	prefix := "LEEF:1.0|kris-nova|dse|" + Version + "|ProcessExecuted|devTime=1638352800000"
+	if fields[0] != prefix {
+		t.Fatalf("expected %q, got %q", prefix, fields[0])
+	}
+	for _, expected := range []string{
+		"devTimeFormat=epoch",
+		"sev=3",
+		"srcPid=42",
+		"containerId=abc123",
+		"podName=web|1",
+	} {
+		found := false
+		for _, field := range fields {
+			if field == expected {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected %q in %v", expected, fields)
+		}
+	}
+}
+
+func TestFormatterEscape(t *testing.T) {
+	tests := []struct {
+		name     string
+		escape   func(string) string
+		value    string
+		expected string
+	}{
+		{"cef header", cefHeaderEscape, `a|b\c` + "\n", `a\|b\\c `},
+		{"cef extension", cefExtensionEscape, `a=b\c` + "\n", `a\=b\\c\n`},
+		{"leef header", leefHeaderEscape, "a|b\tc", `a\|b c`},
+		{"leef value", leefValueEscape, "a\tb\nc", "a b c"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := test.escape(test.value); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func ecsLookup(doc map[string]interface{}, path string) interface{} {
+	parts := strings.Split(path, ".")
+	var value interface{} = doc
+	for _, part := range parts {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[part]
+	}
+	return value
+}
+
+func jsonEqual(a, b interface{}) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
@@ -0,0 +1,612 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/kris-nova/logger"
+
+	"github.com/kris-nova/double-slit-experiment/system"
+
+	dsev1 "github.com/kris-nova/double-slit-experiment/proto/dse/v1"
+)
+
+type GRPCServerConfig struct {
+
+	// Address is host:port, or unix:///path for a Unix domain socket.
+	Address string
+
+	// Token is an optional bearer token. If set, every call must send
+	// the "authorization: Bearer <token>" metadata.
+	Token string
+
+	History int
+	Buffer  int
+}
+
+// GRPCServer is an EventHandler that will serve the dse.v1.EventService.
+type GRPCServer struct {
+	dsev1.UnimplementedEventServiceServer
+	config      GRPCServerConfig
+	broadcaster *EventBroadcaster
+}
+
+func NewGRPCServer(config GRPCServerConfig) *GRPCServer {
+	if config.History <= 0 {
+		config.History = DefaultEventServerHistory
+	}
+	if config.Buffer <= 0 {
+		config.Buffer = DefaultEventServerBuffer
+	}
+	return &GRPCServer{
+		config:      config,
+		broadcaster: NewEventBroadcaster(config.History),
+	}
+}
+
+// Handle will broadcast the Event to every subscriber.
+func (s *GRPCServer) Handle(event Event) {
+	s.broadcaster.Handle(event)
+}
+
+// Subscribe implements dse.v1.EventService/Subscribe
+func (s *GRPCServer) Subscribe(request *dsev1.SubscribeRequest, stream dsev1.EventService_SubscribeServer) error {
+	filter, err := ParseEventFilter(request.Filter)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	sub := s.broadcaster.Subscribe(filter, s.config.Buffer, int(request.Replay))
+	defer sub.Close()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			msg, err := EventProto(event)
+			if err != nil {
+				logger.Warning("Unable to convert event: %v", err)
+				continue
+			}
+			err = stream.Send(msg)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ListenAndServe will serve the EventService on the configured address.
+// This will block.
+func (s *GRPCServer) ListenAndServe() error {
+	network, address := "tcp", s.config.Address
+	if strings.HasPrefix(address, "unix://") {
+		network, address = "unix", strings.TrimPrefix(address, "unix://")
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %v", s.config.Address, err)
+	}
+	server := grpc.NewServer(grpc.StreamInterceptor(s.authorize))
+	dsev1.RegisterEventServiceServer(server, s)
+	logger.Info("Serving gRPC: %s", s.config.Address)
+	return server.Serve(listener)
+}
+
+func (s *GRPCServer) authorize(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if s.config.Token != "" {
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		var token string
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = strings.TrimPrefix(values[0], "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Token)) != 1 {
+			return status.Error(codes.Unauthenticated, "invalid token")
+		}
+	}
+	return handler(srv, stream)
+}
+
+// EventProto will convert an Event into its dse.v1 protobuf message.
+// Events without a typed message are sent as JSON.
+func EventProto(event Event) (*dsev1.Event, error) {
+	msg := &dsev1.Event{
+		Name: event.Name(),
+		Time: timestamppb.New(EventTimestamp(event)),
+	}
+	switch e := event.(type) {
+	case *ProcessEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_Process{Process: &dsev1.ProcessEvent{
+			Filename:          e.Filename,
+			Comm:              e.Comm,
+			Pid:               uint32(e.PID),
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+			Ppid:              uint32(e.PPID),
+			Uid:               uint32(e.UID),
+		}}
+	case *ContainerEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_Container{Container: &dsev1.ContainerEvent{
+			ParentPid:         int32(e.ParentPid),
+			ParentProc:        processProto(e.ParentProc),
+			ChildPid:          int32(e.ChildPid),
+			ChildProc:         processProto(e.ChildProc),
+			CloneFlags:        uint64(e.CloneFlags),
+			CloneFlagsByName:  e.CloneFlagsByName,
+			Tls:               uint64(e.TLS),
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+			Uid:               uint32(e.UID),
+		}}
+	case *SignalEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_Signal{Signal: &dsev1.SignalEvent{
+			Signal:     int32(e.Signal),
+			SignalName: e.SignalName,
+			Errno:      int32(e.Errno),
+			Code:       int32(e.Code),
+			Handler:    e.Handler,
+			Flags:      e.Flags,
+		}}
+	case *SocketEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_Socket{Socket: &dsev1.SocketEvent{
+			OldState:          int32(e.OldState),
+			OldStateName:      e.OldStateName,
+			NewState:          int32(e.NewState),
+			NewStateName:      e.NewStateName,
+			SourcePort:        uint32(e.SourcePort),
+			DestPort:          uint32(e.DestPort),
+			Family:            uint32(e.Family),
+			Protocol:          uint32(e.Protocol),
+			SourceAddr:        e.SourceAddr,
+			DestAddr:          e.DestAddr,
+			SourceAddrV6:      e.SourceAddrV6,
+			DestAddrV6:        e.DestAddrV6,
+			Pid:               uint32(e.PID),
+			Comm:              e.Comm,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}}
+	case *LifecycleEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_Lifecycle{Lifecycle: &dsev1.LifecycleEvent{
+			Pid:               uint32(e.PID),
+			Ppid:              uint32(e.PPID),
+			ChildPid:          uint32(e.ChildPID),
+			Uid:               uint32(e.UID),
+			Comm:              e.Comm,
+			Filename:          e.Filename,
+			ExitCode:          int32(e.ExitCode),
+			ExitSignal:        e.ExitSignal,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}}
+	case *AlertEvent:
+		alert := &dsev1.AlertEvent{
+			RuleId:            e.RuleID,
+			Description:       e.Description,
+			Severity:          e.SeverityName,
+			Tags:              e.Tags,
+			Pid:               uint32(e.PID),
+			Comm:              e.Comm,
+			Filename:          e.Filename,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}
+		for _, trigger := range e.Events {
+			triggerMsg, err := EventProto(trigger)
+			if err != nil {
+				return nil, err
+			}
+			alert.Events = append(alert.Events, triggerMsg)
+		}
+		msg.Event = &dsev1.Event_Alert{Alert: alert}
+	case *ResponseEvent:
+		response := &dsev1.ResponseEvent{
+			PolicyId:          e.PolicyID,
+			Action:            e.Action,
+			Signal:            e.Signal,
+			Target:            e.Target,
+			Pid:               int32(e.PID),
+			Pgid:              int32(e.PGID),
+			Cgroup:            e.Cgroup,
+			DryRun:            e.DryRun,
+			Result:            e.Result,
+			Error:             e.Error,
+			Comm:              e.Comm,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}
+		if e.Event != nil {
+			eventMsg, err := EventProto(e.Event)
+			if err != nil {
+				return nil, err
+			}
+			response.Event = eventMsg
+		}
+		msg.Event = &dsev1.Event_Response{Response: response}
+	case *ExecDeniedEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_ExecDenied{ExecDenied: &dsev1.ExecDeniedEvent{
+			Pid:               uint32(e.PID),
+			Uid:               uint32(e.UID),
+			Comm:              e.Comm,
+			Filename:          e.Filename,
+			Reason:            e.Reason,
+			Inode:             e.Inode,
+			Device:            e.Device,
+			CgroupId:          e.CgroupID,
+			Enforced:          e.Enforced,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}}
+	case *FileOpenEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_FileOpen{FileOpen: &dsev1.FileOpenEvent{
+			Pid:               uint32(e.PID),
+			Ppid:              uint32(e.PPID),
+			Uid:               uint32(e.UID),
+			Comm:              e.Comm,
+			Filename:          e.Filename,
+			Flags:             int32(e.Flags),
+			FlagNames:         e.FlagNames,
+			Mode:              e.Mode,
+			Fd:                int32(e.FD),
+			Error:             e.Error,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}}
+	case *FileChangeEvent:
+		change := &dsev1.FileChangeEvent{
+			Pid:               uint32(e.PID),
+			Ppid:              uint32(e.PPID),
+			Uid:               uint32(e.UID),
+			Comm:              e.Comm,
+			Filename:          e.Filename,
+			NewFilename:       e.NewFilename,
+			Mode:              e.Mode,
+			Permissions:       e.Permissions,
+			OwnerName:         e.OwnerName,
+			GroupName:         e.GroupName,
+			Length:            e.Length,
+			FlagNames:         e.FlagNames,
+			Error:             e.Error,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}
+		if e.Owner != nil {
+			owner := int32(*e.Owner)
+			change.Owner = &owner
+		}
+		if e.Group != nil {
+			group := int32(*e.Group)
+			change.Group = &group
+		}
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_FileChange{FileChange: change}
+	case *MountEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_Mount{Mount: &dsev1.MountEvent{
+			Syscall:            e.Syscall,
+			Pid:                uint32(e.PID),
+			Ppid:               uint32(e.PPID),
+			Uid:                uint32(e.UID),
+			Comm:               e.Comm,
+			Source:             e.Source,
+			Target:             e.Target,
+			FsType:             e.FSType,
+			Flags:              e.Flags,
+			FlagNames:          e.FlagNames,
+			MountNamespace:     uint64(e.MountNamespace),
+			HostMountNamespace: e.HostMountNamespace,
+			Fd:                 int32(e.FD),
+			Error:              e.Error,
+			ContainerId:        e.ContainerID,
+			ContainerMetadata:  containerMetadataProto(&e.ContainerMetadata),
+		}}
+	case *PrivilegeEvent:
+		privilege := &dsev1.PrivilegeEvent{
+			Syscall:            e.Syscall,
+			Pid:                uint32(e.PID),
+			Ppid:               uint32(e.PPID),
+			Uid:                uint32(e.UID),
+			Comm:               e.Comm,
+			Filename:           e.Filename,
+			Requested:          e.Requested,
+			Old:                credentialsProto(e.Old),
+			New:                credentialsProto(e.New),
+			Changed:            e.Changed,
+			GainedCapabilities: e.GainedCapabilities,
+			Escalated:          e.Escalated,
+			Namespaces:         e.Namespaces,
+			ChildPid:           uint32(e.ChildPID),
+			HostNamespaces:     e.HostNamespaces,
+			Error:              e.Error,
+			ContainerId:        e.ContainerID,
+			ContainerMetadata:  containerMetadataProto(&e.ContainerMetadata),
+		}
+		for _, group := range e.Groups {
+			privilege.Groups = append(privilege.Groups, int32(group))
+		}
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_Privilege{Privilege: privilege}
+	case *ModuleEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_Module{Module: &dsev1.ModuleEvent{
+			Syscall:           e.Syscall,
+			Pid:               uint32(e.PID),
+			Ppid:              uint32(e.PPID),
+			Uid:               uint32(e.UID),
+			Comm:              e.Comm,
+			Module:            e.Module,
+			Filename:          e.Filename,
+			Parameters:        e.Parameters,
+			Length:            e.Length,
+			Flags:             uint32(e.Flags),
+			FlagNames:         e.FlagNames,
+			Taints:            e.Taints,
+			Error:             e.Error,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}}
+	case *BPFEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_Bpf{Bpf: &dsev1.BPFEvent{
+			Command:           e.Command,
+			Pid:               uint32(e.PID),
+			Ppid:              uint32(e.PPID),
+			Uid:               uint32(e.UID),
+			Comm:              e.Comm,
+			ProgramType:       e.ProgramType,
+			ProgramName:       e.ProgramName,
+			AttachType:        e.AttachType,
+			Tracepoint:        e.Tracepoint,
+			Instructions:      uint32(e.Instructions),
+			ProgramFd:         int32(e.ProgramFD),
+			TargetFd:          int32(e.TargetFD),
+			Fd:                int32(e.FD),
+			Error:             e.Error,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}}
+	case *ProcessAccessEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_ProcessAccess{ProcessAccess: &dsev1.ProcessAccessEvent{
+			Syscall:            e.Syscall,
+			Request:            e.Request,
+			Pid:                uint32(e.PID),
+			Ppid:               uint32(e.PPID),
+			Uid:                uint32(e.UID),
+			Comm:               e.Comm,
+			TargetPid:          uint32(e.TargetPID),
+			TargetNamespacePid: int32(e.TargetNamespacePID),
+			TargetComm:         e.TargetComm,
+			TargetContainerId:  e.TargetContainerID,
+			Address:            e.Address,
+			Bytes:              e.Bytes,
+			Filename:           e.Filename,
+			FlagNames:          e.FlagNames,
+			Write:              e.Write,
+			CrossContainer:     e.CrossContainer,
+			CrossNamespaces:    e.CrossNamespaces,
+			Error:              e.Error,
+			ContainerId:        e.ContainerID,
+			ContainerMetadata:  containerMetadataProto(&e.ContainerMetadata),
+		}}
+	case *ConnectionEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_Connection{Connection: &dsev1.ConnectionEvent{
+			Syscall:           e.Syscall,
+			Pid:               uint32(e.PID),
+			Ppid:              uint32(e.PPID),
+			Uid:               uint32(e.UID),
+			Comm:              e.Comm,
+			Fd:                int32(e.FD),
+			Family:            e.Family,
+			Type:              e.Type,
+			Protocol:          e.Protocol,
+			LocalAddr:         e.LocalAddr,
+			LocalPort:         uint32(e.LocalPort),
+			PeerAddr:          e.PeerAddr,
+			PeerPort:          uint32(e.PeerPort),
+			LocalPath:         e.LocalPath,
+			PeerPath:          e.PeerPath,
+			PeerPid:           uint32(e.PeerPID),
+			FlagNames:         e.FlagNames,
+			InProgress:        e.InProgress,
+			Error:             e.Error,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}}
+	case *UDPFlowEvent:
+		msg.Event = &dsev1.Event_UdpFlow{UdpFlow: &dsev1.UDPFlowEvent{
+			Pid:               uint32(e.PID),
+			Comm:              e.Comm,
+			Family:            e.Family,
+			LocalAddr:         e.LocalAddr,
+			LocalPort:         uint32(e.LocalPort),
+			PeerAddr:          e.PeerAddr,
+			PeerPort:          uint32(e.PeerPort),
+			BytesSent:         e.BytesSent,
+			PacketsSent:       e.PacketsSent,
+			BytesReceived:     e.BytesReceived,
+			PacketsReceived:   e.PacketsReceived,
+			Interval:          e.Interval,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}}
+	case *DNSEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_Dns{Dns: &dsev1.DNSEvent{
+			Pid:               uint32(e.PID),
+			Ppid:              uint32(e.PPID),
+			Uid:               uint32(e.UID),
+			Comm:              e.Comm,
+			Sent:              e.Sent,
+			Family:            e.Family,
+			LocalAddr:         e.LocalAddr,
+			LocalPort:         uint32(e.LocalPort),
+			PeerAddr:          e.PeerAddr,
+			PeerPort:          uint32(e.PeerPort),
+			Id:                uint32(e.ID),
+			QueryName:         e.QueryName,
+			QueryType:         e.QueryType,
+			Rcode:             e.RCode,
+			Answers:           dnsAnswersProto(e.Answers),
+			Truncated:         e.Truncated,
+			Length:            uint32(e.Length),
+			Error:             e.Error,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}}
+	case *ListenerEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_Listener{Listener: &dsev1.ListenerEvent{
+			Syscall:           e.Syscall,
+			Pid:               uint32(e.PID),
+			Ppid:              uint32(e.PPID),
+			Uid:               uint32(e.UID),
+			Comm:              e.Comm,
+			Fd:                int32(e.FD),
+			Family:            e.Family,
+			Protocol:          e.Protocol,
+			Addr:              e.Addr,
+			Port:              uint32(e.Port),
+			Backlog:           int32(e.Backlog),
+			NetNs:             e.NetNS,
+			Error:             e.Error,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}}
+	case *TCPEvent:
+		msg.Cpu = int32(e.CPU)
+		msg.Event = &dsev1.Event_Tcp{Tcp: &dsev1.TCPEvent{
+			Pid:               uint32(e.PID),
+			Comm:              e.Comm,
+			Family:            e.Family,
+			SourceAddr:        e.SourceAddr,
+			SourcePort:        uint32(e.SourcePort),
+			DestAddr:          e.DestAddr,
+			DestPort:          uint32(e.DestPort),
+			Connection:        e.Connection,
+			State:             e.State,
+			Reason:            e.Reason,
+			NetNs:             e.NetNS,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}}
+	case *RateExceededEvent:
+		msg.Event = &dsev1.Event_RateExceeded{RateExceeded: &dsev1.RateExceededEvent{
+			Rate:              e.Rate,
+			Field:             e.Field,
+			Value:             e.Value,
+			Count:             int64(e.Count),
+			Threshold:         int64(e.Threshold),
+			Window:            e.Window,
+			Pid:               uint32(e.PID),
+			Comm:              e.Comm,
+			ContainerId:       e.ContainerID,
+			ContainerMetadata: containerMetadataProto(&e.ContainerMetadata),
+		}}
+	default:
+		b, err := event.JSON()
+		if err != nil {
+			return nil, err
+		}
+		msg.Event = &dsev1.Event_Json{Json: string(b)}
+	}
+	return msg, nil
+}
+
+func credentialsProto(c Credentials) *dsev1.Credentials {
+	return &dsev1.Credentials{
+		Uid:            uint32(c.UID),
+		Gid:            uint32(c.GID),
+		Euid:           uint32(c.EUID),
+		Egid:           uint32(c.EGID),
+		Suid:           uint32(c.SUID),
+		Sgid:           uint32(c.SGID),
+		Fsuid:          uint32(c.FSUID),
+		Fsgid:          uint32(c.FSGID),
+		CapInheritable: c.CapInheritable,
+		CapPermitted:   c.CapPermitted,
+		CapEffective:   c.CapEffective,
+	}
+}
+
+func processProto(p *system.Process) *dsev1.Process {
+	if p == nil {
+		return nil
+	}
+	return &dsev1.Process{
+		Executable: p.Executable,
+		Pid:        int32(p.Pid),
+		ParentPid:  int32(p.ParentPid),
+	}
+}
+
+func containerMetadataProto(m *ContainerMetadata) *dsev1.ContainerMetadata {
+	if m.ContainerName == "" && m.ContainerImage == "" && m.PodUID == "" {
+		return nil
+	}
+	return &dsev1.ContainerMetadata{
+		Name:           m.ContainerName,
+		Image:          m.ContainerImage,
+		ImageId:        m.ContainerImageID,
+		Labels:         m.ContainerLabels,
+		PodName:        m.PodName,
+		PodNamespace:   m.PodNamespace,
+		PodUid:         m.PodUID,
+		PodLabels:      m.PodLabels,
+		ComposeProject: m.ComposeProject,
+		ComposeService: m.ComposeService,
+	}
+}
+
+func dnsAnswersProto(answers []DNSAnswer) []*dsev1.DNSAnswer {
+	var msgs []*dsev1.DNSAnswer
+	for _, answer := range answers {
+		msgs = append(msgs, &dsev1.DNSAnswer{
+			Name: answer.Name,
+			Type: answer.Type,
+			Ttl:  answer.TTL,
+			Data: answer.Data,
+		})
+	}
+	return msgs
+}
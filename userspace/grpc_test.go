@@ -0,0 +1,187 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"testing"
+	"time"
+
+	dsev1 "github.com/kris-nova/double-slit-experiment/proto/dse/v1"
+)
+
+func TestEventProtoTime(t *testing.T) {
+	observed := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	event := &ProcessEvent{EventName: "ProcessExecuted", PID: 7}
+	event.setTimestamp(observed)
+
+	// A later dispatch never moves the time forward
+	event.setTimestamp(observed.Add(time.Hour))
+
+	msg, err := EventProto(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !msg.Time.AsTime().Equal(observed) {
+		t.Errorf("expected %v, got %v", observed, msg.Time.AsTime())
+	}
+	if msg.GetProcess().GetPid() != 7 {
+		t.Errorf("expected typed process message, got %v", msg.Event)
+	}
+}
+
+func TestEventProtoTyped(t *testing.T) {
+	tests := []struct {
+		event Event
+		check func(msg *dsev1.Event) bool
+	}{
+		{
+			event: &LifecycleEvent{EventName: EventNameProcessExited, PID: 10, ExitCode: 1, ExitSignal: "SIGKILL"},
+			check: func(msg *dsev1.Event) bool {
+				return msg.GetLifecycle().GetPid() == 10 && msg.GetLifecycle().GetExitSignal() == "SIGKILL"
+			},
+		},
+		{
+			event: &AlertEvent{EventName: EventNameAlert, RuleID: "shell-in-container", Events: []Event{&ProcessEvent{EventName: "ProcessExecuted", PID: 7}}},
+			check: func(msg *dsev1.Event) bool {
+				alert := msg.GetAlert()
+				return alert.GetRuleId() == "shell-in-container" && len(alert.GetEvents()) == 1 && alert.GetEvents()[0].GetProcess().GetPid() == 7
+			},
+		},
+		{
+			event: &ResponseEvent{EventName: EventNameResponse, PolicyID: "kill-reverse-shell", PID: 42, Result: ResponseResultDryRun, Event: &ProcessEvent{EventName: "ProcessExecuted", PID: 42}},
+			check: func(msg *dsev1.Event) bool {
+				response := msg.GetResponse()
+				return response.GetPolicyId() == "kill-reverse-shell" && response.GetResult() == ResponseResultDryRun && response.GetEvent().GetProcess().GetPid() == 42
+			},
+		},
+		{
+			event: &ExecDeniedEvent{EventName: EventNameExecDenied, PID: 42, Filename: "/tmp/payload", Reason: ExecDeniedPrefix, Enforced: true},
+			check: func(msg *dsev1.Event) bool {
+				denied := msg.GetExecDenied()
+				return denied.GetFilename() == "/tmp/payload" && denied.GetReason() == ExecDeniedPrefix && denied.GetEnforced()
+			},
+		},
+		{
+			event: &FileOpenEvent{EventName: EventNameFileOpened, PID: 42, Filename: "/etc/shadow", FlagNames: []string{"O_RDONLY"}},
+			check: func(msg *dsev1.Event) bool {
+				open := msg.GetFileOpen()
+				return open.GetFilename() == "/etc/shadow" && len(open.GetFlagNames()) == 1
+			},
+		},
+		{
+			event: &FileChangeEvent{EventName: EventNameFileModeChanged, PID: 42, Filename: "/etc/passwd", Permissions: "0666"},
+			check: func(msg *dsev1.Event) bool {
+				change := msg.GetFileChange()
+				return change.GetFilename() == "/etc/passwd" && change.GetPermissions() == "0666" && change.Owner == nil
+			},
+		},
+		{
+			event: &MountEvent{EventName: EventNameMounted, Syscall: "mount", Target: "/mnt", HostMountNamespace: true},
+			check: func(msg *dsev1.Event) bool {
+				mount := msg.GetMount()
+				return mount.GetSyscall() == "mount" && mount.GetTarget() == "/mnt" && mount.GetHostMountNamespace()
+			},
+		},
+		{
+			event: &PrivilegeEvent{EventName: EventNamePrivilegeChanged, Syscall: "setuid", Groups: []int{4, 27}, New: Credentials{EUID: 0, CapEffective: []string{"CAP_SYS_ADMIN"}}, Escalated: true},
+			check: func(msg *dsev1.Event) bool {
+				privilege := msg.GetPrivilege()
+				return privilege.GetEscalated() && len(privilege.GetGroups()) == 2 && privilege.GetNew().GetCapEffective()[0] == "CAP_SYS_ADMIN"
+			},
+		},
+		{
+			event: &ModuleEvent{EventName: EventNameModuleLoaded, Syscall: "finit_module", Module: "rootkit", Taints: []string{"O"}},
+			check: func(msg *dsev1.Event) bool {
+				module := msg.GetModule()
+				return module.GetModule() == "rootkit" && module.GetTaints()[0] == "O"
+			},
+		},
+		{
+			event: &BPFEvent{EventName: EventNameBPFProgramLoaded, Command: "BPF_PROG_LOAD", ProgramType: "BPF_PROG_TYPE_KPROBE"},
+			check: func(msg *dsev1.Event) bool {
+				bpf := msg.GetBpf()
+				return bpf.GetCommand() == "BPF_PROG_LOAD" && bpf.GetProgramType() == "BPF_PROG_TYPE_KPROBE"
+			},
+		},
+		{
+			event: &ProcessAccessEvent{EventName: EventNameProcessTraced, TargetPID: 880, CrossContainer: true, CrossNamespaces: []string{"pid"}},
+			check: func(msg *dsev1.Event) bool {
+				access := msg.GetProcessAccess()
+				return access.GetTargetPid() == 880 && access.GetCrossContainer() && access.GetCrossNamespaces()[0] == "pid"
+			},
+		},
+		{
+			event: &ConnectionEvent{EventName: EventNameSocketConnected, Syscall: "connect", PeerAddr: "10.0.0.1", PeerPort: 443, InProgress: true},
+			check: func(msg *dsev1.Event) bool {
+				connection := msg.GetConnection()
+				return connection.GetPeerAddr() == "10.0.0.1" && connection.GetPeerPort() == 443 && connection.GetInProgress()
+			},
+		},
+		{
+			event: &UDPFlowEvent{EventName: EventNameUDPFlow, PeerAddr: "10.0.0.1", PeerPort: 123, BytesSent: 48, PacketsSent: 1},
+			check: func(msg *dsev1.Event) bool {
+				flow := msg.GetUdpFlow()
+				return flow.GetPeerPort() == 123 && flow.GetBytesSent() == 48 && flow.GetPacketsSent() == 1
+			},
+		},
+		{
+			event: &DNSEvent{EventName: EventNameDNSResponse, QueryName: "example.com.", RCode: "NOERROR", Answers: []DNSAnswer{{Name: "example.com.", Type: "A", TTL: 300, Data: "93.184.216.34"}}},
+			check: func(msg *dsev1.Event) bool {
+				dns := msg.GetDns()
+				return dns.GetQueryName() == "example.com." && len(dns.GetAnswers()) == 1 && dns.GetAnswers()[0].GetData() == "93.184.216.34"
+			},
+		},
+		{
+			event: &ListenerEvent{EventName: EventNameListenerOpened, Syscall: "listen", Addr: "0.0.0.0", Port: 8080, Backlog: 128},
+			check: func(msg *dsev1.Event) bool {
+				listener := msg.GetListener()
+				return listener.GetAddr() == "0.0.0.0" && listener.GetPort() == 8080 && listener.GetBacklog() == 128
+			},
+		},
+		{
+			event: &TCPEvent{EventName: EventNameTCPPacketDropped, DestAddr: "10.0.0.1", DestPort: 443, Reason: "NO_SOCKET"},
+			check: func(msg *dsev1.Event) bool {
+				tcp := msg.GetTcp()
+				return tcp.GetDestAddr() == "10.0.0.1" && tcp.GetDestPort() == 443 && tcp.GetReason() == "NO_SOCKET"
+			},
+		},
+		{
+			event: &RateExceededEvent{EventName: EventNameRateExceeded, Rate: "exec_per_container", Count: 501, ContainerMetadata: testContainer},
+			check: func(msg *dsev1.Event) bool {
+				rate := msg.GetRateExceeded()
+				return rate.GetRate() == "exec_per_container" && rate.GetCount() == 501 && rate.GetContainerId() == "abc123"
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.event.Name(), func(t *testing.T) {
+			msg, err := EventProto(test.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg.GetJson() != "" || !test.check(msg) {
+				t.Errorf("unexpected message: %v", msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,499 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/perf"
+
+	"github.com/kris-nova/logger"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/kris-nova/double-slit-experiment/system"
+)
+
+const (
+	// EventNameExecDenied is the name of every ExecDeniedEvent.
+	EventNameExecDenied = "ExecDenied"
+
+	// LSMPath lists the active Linux Security Modules.
+	LSMPath = "/sys/kernel/security/lsm"
+)
+
+// ExecDenied reasons
+const (
+	ExecDeniedPrefix = "prefix"
+	ExecDeniedInode  = "inode"
+	ExecDeniedCgroup = "cgroup"
+
+	// ExecDeniedUnresolved is an exec() of a file whose path the
+	// kernel could not resolve, so it can not be checked against
+	// the prefixes. Only set when there are prefixes.
+	ExecDeniedUnresolved = "unresolved"
+)
+
+// execDeniedReasons are the reasons set by lsm.c
+var execDeniedReasons = map[uint32]string{
+	1: ExecDeniedPrefix,
+	2: ExecDeniedInode,
+	3: ExecDeniedCgroup,
+	4: ExecDeniedUnresolved,
+}
+
+// ErrExecGuardUnsupported is returned when the kernel can not run
+// the BPF LSM program.
+var ErrExecGuardUnsupported = errors.New("BPF LSM is not enabled, boot with lsm=...,bpf")
+
+// ExecPolicy is the set of executables denied by an ExecGuard.
+// An exec() is denied if any one of the lists match.
+type ExecPolicy struct {
+
+	// Prefixes are matched against the absolute path of the
+	// executable, with symlinks resolved, such as /tmp/ or /dev/shm/.
+	// A relative path, a symlink or /proc/self/fd/N is matched by
+	// the file it resolves to.
+	Prefixes []string
+
+	// Paths are resolved to their inode when the guard starts, so a
+	// copy is allowed, but a hard link or rename of the file is denied.
+	Paths []string
+
+	// Cgroups are cgroup v2 paths, relative to system.CgroupRoot.
+	// Every exec() in the cgroup, or any of its descendants, is
+	// denied. Only 16 levels of ancestors are checked.
+	Cgroups []string
+
+	// Audit will report every exec() that matches, without denying it.
+	Audit bool
+}
+
+// Empty will return true if the policy will never match.
+func (p ExecPolicy) Empty() bool {
+	return len(p.Prefixes) == 0 && len(p.Paths) == 0 && len(p.Cgroups) == 0
+}
+
+// execInode is the exec_inode_t key of the exec_deny_inodes map.
+// Dev is the kernel encoding of the device (major << 20 | minor).
+type execInode struct {
+	Ino uint64
+	Dev uint32
+	_   uint32
+}
+
+// execGuardConfig is the exec_guard_config_t value of the
+// exec_guard_config map.
+type execGuardConfig struct {
+	Enforce  uint32
+	Prefixes uint32
+}
+
+// execPrefix is the exec_prefix_t key of the exec_deny_prefixes map.
+type execPrefix struct {
+	Prefixlen uint32
+	Path      [128]byte
+}
+
+// ExecGuard will deny the exec() of executables in an ExecPolicy
+// with the BPF LSM bprm_check_security hook.
+//
+// When the kernel does not support BPF LSM, the guard falls back to
+// observation only. As an EventProducer, it will write an ExecDenied
+// event (with Enforced false) for every ProcessExecuted event that
+// matches the policy.
+type ExecGuard struct {
+	policy   ExecPolicy
+	inodes   map[execInode]string
+	cgroups  map[uint64]string
+	objects  gen_lsmObjects
+	link     *os.File
+	reader   *perf.Reader
+	enforced bool
+	loaded   bool
+}
+
+// NewExecGuard will resolve the policy, and try to load the BPF LSM
+// program. An error is only returned for an invalid policy.
+func NewExecGuard(policy ExecPolicy) (*ExecGuard, error) {
+	g := &ExecGuard{
+		policy:   policy,
+		inodes:   map[execInode]string{},
+		cgroups:  map[uint64]string{},
+		enforced: !policy.Audit,
+	}
+	for _, prefix := range policy.Prefixes {
+		if prefix == "" {
+			return nil, fmt.Errorf("empty exec deny prefix")
+		}
+		if len(prefix) > len(execPrefix{}.Path) {
+			return nil, fmt.Errorf("exec deny prefix %s is longer than %d bytes", prefix, len(execPrefix{}.Path))
+		}
+	}
+	for _, path := range policy.Paths {
+		inode, err := statExecInode(path)
+		if err != nil {
+			return nil, fmt.Errorf("exec deny path: %v", err)
+		}
+		g.inodes[inode] = path
+	}
+	for _, cgroup := range policy.Cgroups {
+		id, err := cgroupID(cgroup)
+		if err != nil {
+			return nil, fmt.Errorf("exec deny cgroup: %v", err)
+		}
+		g.cgroups[id] = cgroupPath(cgroup)
+	}
+	err := g.load()
+	if err != nil {
+		g.Close()
+		logger.Warning("Unable to load exec guard, observing only: %v", err)
+		return g, nil
+	}
+	g.loaded = true
+	return g, nil
+}
+
+// ExecGuardSupported will return ErrExecGuardUnsupported if "bpf" is
+// not one of the active Linux Security Modules.
+func ExecGuardSupported() error {
+	raw, err := ioutil.ReadFile(LSMPath)
+	if err != nil {
+		return fmt.Errorf("%v: %v", ErrExecGuardUnsupported, err)
+	}
+	for _, lsm := range strings.Split(strings.TrimSpace(string(raw)), ",") {
+		if lsm == "bpf" {
+			return nil
+		}
+	}
+	return ErrExecGuardUnsupported
+}
+
+// Loaded will return true if the BPF LSM program is attached,
+// otherwise the guard is only observing.
+func (g *ExecGuard) Loaded() bool {
+	return g.loaded
+}
+
+// Policy will return the ExecPolicy of the guard.
+func (g *ExecGuard) Policy() ExecPolicy {
+	return g.policy
+}
+
+func (g *ExecGuard) load() error {
+	err := ExecGuardSupported()
+	if err != nil {
+		return err
+	}
+	err = loadGen_lsmObjects(&g.objects, nil)
+	if err != nil {
+		return fmt.Errorf("unable to load BPF LSM objects: %v", err)
+	}
+	var value uint8 = 1
+	for _, prefix := range g.policy.Prefixes {
+		key := execPrefix{Prefixlen: uint32(len(prefix) * 8)}
+		copy(key.Path[:], prefix)
+		err := g.objects.ExecDenyPrefixes.Put(&key, &value)
+		if err != nil {
+			return fmt.Errorf("unable to deny prefix %s: %v", prefix, err)
+		}
+	}
+	for inode, path := range g.inodes {
+		key := inode
+		err := g.objects.ExecDenyInodes.Put(&key, &value)
+		if err != nil {
+			return fmt.Errorf("unable to deny path %s: %v", path, err)
+		}
+	}
+	for id, cgroup := range g.cgroups {
+		key := id
+		err := g.objects.ExecDenyCgroups.Put(&key, &value)
+		if err != nil {
+			return fmt.Errorf("unable to deny cgroup %s: %v", cgroup, err)
+		}
+	}
+	var zero uint32
+	config := execGuardConfig{Prefixes: uint32(len(g.policy.Prefixes))}
+	if g.enforced {
+		config.Enforce = 1
+	}
+	err = g.objects.ExecGuardConfig.Put(&zero, &config)
+	if err != nil {
+		return fmt.Errorf("unable to configure exec guard: %v", err)
+	}
+	g.link, err = attachLSM(g.objects.BprmCheckSecurity)
+	if err != nil {
+		return fmt.Errorf("unable to attach lsm/bprm_check_security: %v", err)
+	}
+	g.reader, err = perf.NewReader(g.objects.LsmEvents, os.Getpagesize())
+	if err != nil {
+		return fmt.Errorf("unable to start perf reader: %v", err)
+	}
+	return nil
+}
+
+// attachLSM will attach a BPF_PROG_TYPE_LSM program. The program is
+// detached when the returned file is closed.
+//
+// cilium/ebpf does not have a link for LSM programs yet, so this is
+// the BPF_RAW_TRACEPOINT_OPEN command with an empty name.
+func attachLSM(program *ebpf.Program) (*os.File, error) {
+	if program == nil {
+		return nil, fmt.Errorf("missing program")
+	}
+	attr := struct {
+		name   uint64
+		progFD uint32
+		_      uint32
+	}{
+		progFD: uint32(program.FD()),
+	}
+	fd, _, errno := unix.Syscall(unix.SYS_BPF, unix.BPF_RAW_TRACEPOINT_OPEN, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr))
+	if errno != 0 {
+		return nil, errno
+	}
+	return os.NewFile(fd, "lsm/bprm_check_security"), nil
+}
+
+// Start will write an ExecDenied event to the Observer for every
+// exec() the BPF LSM program matches. The Observer must be started.
+func (g *ExecGuard) Start(observer *Observer) {
+	if !g.loaded {
+		return
+	}
+	logger.Info("Loading BPF LSM: bprm_check_security (enforcing: %t)", g.enforced)
+	go func() {
+		for {
+			record, err := g.reader.Read()
+			if perf.IsClosed(err) {
+				return
+			}
+			if err != nil {
+				logger.Warning(err.Error())
+				continue
+			}
+			if record.LostSamples > 0 {
+				logger.Warning("Dropping kernel samples: %d", record.LostSamples)
+				continue
+			}
+			data, err := EventExecDenied(record)
+			if err != nil {
+				logger.Warning("Unable to read binary event: %v", err)
+				continue
+			}
+			observer.Emit(NewExecDeniedEvent(record.CPU, data))
+		}
+	}()
+}
+
+// Produce will match ProcessExecuted events against the policy when
+// the BPF LSM program is not loaded. The exec() has already happened.
+func (g *ExecGuard) Produce(event Event) []Event {
+	if g.loaded {
+		return nil
+	}
+	process, ok := event.(*ProcessEvent)
+	if !ok || process.EventName != "ProcessExecuted" {
+		return nil
+	}
+	denied := &ExecDeniedEvent{
+		EventName:         EventNameExecDenied,
+		CPU:               process.CPU,
+		PID:               process.PID,
+		UID:               process.UID,
+		Comm:              process.Comm,
+		Filename:          process.Filename,
+		ContainerMetadata: process.ContainerMetadata,
+	}
+	for _, prefix := range g.policy.Prefixes {
+		if strings.HasPrefix(process.Filename, prefix) {
+			denied.Reason = ExecDeniedPrefix
+			return []Event{denied}
+		}
+	}
+	if len(g.inodes) > 0 && filepath.IsAbs(process.Filename) {
+		inode, err := statExecInode(process.Filename)
+		if err == nil {
+			if _, ok := g.inodes[inode]; ok {
+				denied.Reason = ExecDeniedInode
+				denied.Inode = inode.Ino
+				denied.Device = inode.Dev
+				return []Event{denied}
+			}
+		}
+	}
+	if len(g.cgroups) > 0 {
+		cgroup, err := system.ProcCgroup(int(process.PID))
+		if err == nil {
+			for id, path := range g.cgroups {
+				if cgroupContains(path, cgroupPath(cgroup)) {
+					denied.Reason = ExecDeniedCgroup
+					denied.CgroupID = id
+					return []Event{denied}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Close will detach the BPF LSM program. Executables are no longer
+// denied after calling Close().
+func (g *ExecGuard) Close() error {
+	var errs []string
+	if g.reader != nil {
+		err := g.reader.Close()
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if g.link != nil {
+		err := g.link.Close()
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	err := g.objects.Close()
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("unable to close exec guard: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// statExecInode will return the inode and kernel device of a file.
+func statExecInode(path string) (execInode, error) {
+	var st unix.Stat_t
+	err := unix.Stat(path, &st)
+	if err != nil {
+		return execInode{}, fmt.Errorf("unable to stat %s: %v", path, err)
+	}
+	return execInode{
+		Ino: st.Ino,
+		Dev: kernelDev(st.Dev),
+	}, nil
+}
+
+// kernelDev will convert a userspace dev_t to the kernel encoding
+// used in struct super_block.
+func kernelDev(dev uint64) uint32 {
+	return unix.Major(dev)<<20 | unix.Minor(dev)
+}
+
+// cgroupPath will clean a cgroup path, relative to system.CgroupRoot.
+func cgroupPath(cgroup string) string {
+	cgroup = strings.TrimPrefix(cgroup, system.CgroupRoot)
+	return filepath.Clean("/" + cgroup)
+}
+
+// cgroupContains will return true if a cgroup path is the parent
+// cgroup, or one of its descendants.
+func cgroupContains(parent, cgroup string) bool {
+	return parent == "/" || cgroup == parent || strings.HasPrefix(cgroup, parent+"/")
+}
+
+// cgroupID will return the ID of a cgroup v2 path, which is the inode
+// of its directory, and what bpf_get_current_cgroup_id() returns.
+func cgroupID(cgroup string) (uint64, error) {
+	path := filepath.Join(system.CgroupRoot, cgroupPath(cgroup))
+	var st unix.Stat_t
+	err := unix.Stat(path, &st)
+	if err != nil {
+		return 0, fmt.Errorf("unable to stat %s: %v", path, err)
+	}
+	if st.Mode&syscall.S_IFMT != syscall.S_IFDIR {
+		return 0, fmt.Errorf("%s is not a cgroup", path)
+	}
+	return st.Ino, nil
+}
+
+// ExecDeniedEvent is written for every exec() that matches an
+// ExecPolicy. Enforced is false when the exec() was allowed, either
+// in audit mode, or because the kernel does not support BPF LSM.
+type ExecDeniedEvent struct {
+	CPU       int    `json:"CPU"`
+	EventName string `json:"Name"`
+	PID       uint   `json:"PID"`
+	UID       uint   `json:"UID"`
+	Comm      string `json:"Comm"`
+	Filename  string `json:"Filename"`
+	Reason    string `json:"Reason"`
+	Inode     uint64 `json:"Inode,omitempty"`
+	Device    uint32 `json:"Device,omitempty"`
+	CgroupID  uint64 `json:"CgroupID,omitempty"`
+	Enforced  bool   `json:"Enforced"`
+	ContainerMetadata
+	EventTime
+}
+
+func NewExecDeniedEvent(cpu int, data *exec_denied_data_t) *ExecDeniedEvent {
+	return &ExecDeniedEvent{
+		CPU:               cpu,
+		EventName:         EventNameExecDenied,
+		PID:               uint(data.Pid),
+		UID:               uint(data.Uid),
+		Comm:              BytesToString32(data.Comm),
+		Filename:          BytesToString(data.Filename[:]),
+		Reason:            execDeniedReasons[data.Reason],
+		Inode:             data.Ino,
+		Device:            data.Dev,
+		CgroupID:          data.Cgroup_id,
+		Enforced:          data.Enforced != 0,
+		ContainerMetadata: containerMetadataForPID(int(data.Pid)),
+	}
+}
+
+func (e *ExecDeniedEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *ExecDeniedEvent) String() string {
+	action := "denied"
+	if !e.Enforced {
+		action = "would deny"
+	}
+	return fmt.Sprintf("[%s] (%d) %s exec %s (%s)", e.Comm, e.PID, action, e.Filename, e.Reason)
+}
+
+func (e *ExecDeniedEvent) Name() string {
+	return e.EventName
+}
+
+// Severity is high for an enforced denial, and medium otherwise.
+func (e *ExecDeniedEvent) Severity() int {
+	if e.Enforced {
+		return RuleSeverities["high"]
+	}
+	return RuleSeverities["medium"]
+}
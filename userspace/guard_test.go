@@ -0,0 +1,205 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/kris-nova/double-slit-experiment/system"
+)
+
+func TestCgroupContains(t *testing.T) {
+	tests := []struct {
+		parent, cgroup string
+		contains       bool
+	}{
+		{"/kubepods.slice/pod1", "/kubepods.slice/pod1", true},
+		{"/kubepods.slice/pod1", "/kubepods.slice/pod1/cri-containerd-abc.scope", true},
+		{"/kubepods.slice/pod1", "/kubepods.slice/pod12", false},
+		{"/kubepods.slice/pod1", "/kubepods.slice", false},
+		{"/", "/system.slice/nginx.service", true},
+	}
+	for _, test := range tests {
+		if cgroupContains(test.parent, test.cgroup) != test.contains {
+			t.Errorf("expected %s contains %s to be %v", test.parent, test.cgroup, test.contains)
+		}
+	}
+}
+
+func TestKernelDev(t *testing.T) {
+	tests := []struct {
+		major, minor uint32
+		expected     uint32
+	}{
+		{8, 1, 8<<20 | 1},
+		{259, 65536, 259<<20 | 65536},
+		{0, 0, 0},
+	}
+	for _, test := range tests {
+		if actual := kernelDev(unix.Mkdev(test.major, test.minor)); actual != test.expected {
+			t.Errorf("expected %d:%d to be %#x, got %#x", test.major, test.minor, test.expected, actual)
+		}
+	}
+}
+
+func TestStatExecInode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payload")
+	err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var st unix.Stat_t
+	err = unix.Stat(path, &st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inode, err := statExecInode(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inode.Ino != st.Ino || inode.Dev != kernelDev(st.Dev) {
+		t.Errorf("expected %d on %#x, got %d on %#x", st.Ino, kernelDev(st.Dev), inode.Ino, inode.Dev)
+	}
+	_, err = statExecInode(path + ".missing")
+	if err == nil {
+		t.Errorf("expected error for a missing file")
+	}
+}
+
+func TestExecGuardProduce(t *testing.T) {
+	dir := t.TempDir()
+	denied := filepath.Join(dir, "nc")
+	err := os.WriteFile(denied, []byte("#!/bin/sh\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "renamed")
+	err = os.Link(denied, link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowed := filepath.Join(dir, "ls")
+	err = os.WriteFile(allowed, []byte("#!/bin/sh\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inode, err := statExecInode(denied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cgroup, err := system.ProcCgroup(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	self := uint(os.Getpid())
+
+	tests := []struct {
+		name   string
+		guard  *ExecGuard
+		event  Event
+		reason string
+	}{
+		{
+			name:   "prefix",
+			guard:  &ExecGuard{policy: ExecPolicy{Prefixes: []string{"/dev/shm/"}}},
+			event:  &ProcessEvent{EventName: "ProcessExecuted", PID: 42, Filename: "/dev/shm/payload"},
+			reason: ExecDeniedPrefix,
+		},
+		{
+			name:  "no prefix",
+			guard: &ExecGuard{policy: ExecPolicy{Prefixes: []string{"/dev/shm/"}}},
+			event: &ProcessEvent{EventName: "ProcessExecuted", PID: 42, Filename: "/usr/bin/ls"},
+		},
+		{
+			name:   "inode",
+			guard:  &ExecGuard{inodes: map[execInode]string{inode: denied}},
+			event:  &ProcessEvent{EventName: "ProcessExecuted", PID: 42, Filename: denied},
+			reason: ExecDeniedInode,
+		},
+		{
+			name:   "inode of a hard link",
+			guard:  &ExecGuard{inodes: map[execInode]string{inode: denied}},
+			event:  &ProcessEvent{EventName: "ProcessExecuted", PID: 42, Filename: link},
+			reason: ExecDeniedInode,
+		},
+		{
+			name:  "other inode",
+			guard: &ExecGuard{inodes: map[execInode]string{inode: denied}},
+			event: &ProcessEvent{EventName: "ProcessExecuted", PID: 42, Filename: allowed},
+		},
+		{
+			name:  "relative path",
+			guard: &ExecGuard{inodes: map[execInode]string{inode: denied}},
+			event: &ProcessEvent{EventName: "ProcessExecuted", PID: 42, Filename: "nc"},
+		},
+		{
+			name:  "not an exec",
+			guard: &ExecGuard{policy: ExecPolicy{Prefixes: []string{"/dev/shm/"}}},
+			event: &LifecycleEvent{EventName: EventNameProcessExec, PID: 42, Filename: "/dev/shm/payload"},
+		},
+		{
+			name:  "loaded",
+			guard: &ExecGuard{policy: ExecPolicy{Prefixes: []string{"/dev/shm/"}}, loaded: true},
+			event: &ProcessEvent{EventName: "ProcessExecuted", PID: 42, Filename: "/dev/shm/payload"},
+		},
+		{
+			name:   "cgroup",
+			guard:  &ExecGuard{cgroups: map[uint64]string{1: cgroupPath(cgroup)}},
+			event:  &ProcessEvent{EventName: "ProcessExecuted", PID: self, Filename: "/usr/bin/ls"},
+			reason: ExecDeniedCgroup,
+		},
+		{
+			name:  "other cgroup",
+			guard: &ExecGuard{cgroups: map[uint64]string{1: cgroupPath(cgroup) + "-other"}},
+			event: &ProcessEvent{EventName: "ProcessExecuted", PID: self, Filename: "/usr/bin/ls"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.reason == ExecDeniedCgroup && cgroup == "" {
+				t.Skip("not in a cgroup")
+			}
+			events := test.guard.Produce(test.event)
+			if test.reason == "" {
+				if len(events) != 0 {
+					t.Errorf("expected no events, got %v", events)
+				}
+				return
+			}
+			if len(events) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(events))
+			}
+			e := events[0].(*ExecDeniedEvent)
+			if e.Reason != test.reason || e.Enforced || e.PID != test.event.(*ProcessEvent).PID {
+				t.Errorf("unexpected event %+v", e)
+			}
+			if test.reason == ExecDeniedInode && (e.Inode != inode.Ino || e.Device != inode.Dev) {
+				t.Errorf("expected inode %d on %#x, got %d on %#x", inode.Ino, inode.Dev, e.Inode, e.Device)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/kris-nova/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// MetricsNamespace is prefixed to every metric exported by the Observer.
+	MetricsNamespace = "dse"
+
+	// MetricsPath is the HTTP path metrics are served on.
+	MetricsPath = "/metrics"
+
+	// MetricLabelOverflow replaces every label value once a metric
+	// has reached its label cardinality limit.
+	MetricLabelOverflow = "__overflow__"
+
+	// DefaultMaxLabelSets is the label cardinality limit used for
+	// an EventMetric that does not set one.
+	DefaultMaxLabelSets = 1000
+)
+
+type EventMetricType string
+
+const (
+	EventMetricCounter   EventMetricType = "counter"
+	EventMetricHistogram EventMetricType = "histogram"
+)
+
+// EventMetricLabel maps a field on an Event (see EventField)
+// to a Prometheus label.
+type EventMetricLabel struct {
+	Name  string
+	Field string
+}
+
+// EventMetric declares how a stream of Events becomes a
+// Prometheus counter or histogram.
+type EventMetric struct {
+
+	// Name of the metric, without the "dse_" namespace.
+	Name string
+	Help string
+	Type EventMetricType
+
+	// Events is the list of Event names this metric will observe.
+	// An empty list will observe every Event.
+	Events []string
+
+	// Select is optional, and will drop any Event that does not match.
+	Select func(event Event) bool
+
+	Labels []EventMetricLabel
+
+	// Field is the numeric Event field observed by a histogram.
+	Field   string
+	Buckets []float64
+
+	// MaxLabelSets is the number of unique label combinations this
+	// metric will track before all new combinations are recorded
+	// with the MetricLabelOverflow value.
+	MaxLabelSets int
+}
+
+type EventMetrics []*EventMetric
+
+// Metrics is an EventHandler that will turn Events into Prometheus
+// metrics, and serve them over HTTP.
+type Metrics struct {
+	registry   *prometheus.Registry
+	events     *prometheus.CounterVec
+	overflow   *prometheus.CounterVec
+	collectors []*eventMetricCollector
+}
+
+type eventMetricCollector struct {
+	definition *EventMetric
+	events     map[string]bool
+	counter    *prometheus.CounterVec
+	histogram  *prometheus.HistogramVec
+	mtx        sync.Mutex
+	labelSets  map[string]bool
+}
+
+// NewMetrics will register the agent health metrics, and a
+// metric for every EventMetric definition.
+func NewMetrics(definitions EventMetrics) (*Metrics, error) {
+	registry := prometheus.NewRegistry()
+	m := &Metrics{
+		registry: registry,
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "events_total",
+			Help:      "Total number of events observed, by event name.",
+		}, []string{"name"}),
+		overflow: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "metric_label_overflow_total",
+			Help:      "Total number of events recorded with overflow labels after a metric reached its label limit.",
+		}, []string{"metric"}),
+	}
+	for _, c := range []prometheus.Collector{
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.events,
+		m.overflow,
+	} {
+		err := registry.Register(c)
+		if err != nil {
+			return nil, fmt.Errorf("unable to register metric: %v", err)
+		}
+	}
+	for _, definition := range definitions {
+		collector, err := newEventMetricCollector(definition)
+		if err != nil {
+			return nil, err
+		}
+		err = registry.Register(collector.collector())
+		if err != nil {
+			return nil, fmt.Errorf("unable to register metric %s: %v", definition.Name, err)
+		}
+		m.collectors = append(m.collectors, collector)
+	}
+	return m, nil
+}
+
+func newEventMetricCollector(definition *EventMetric) (*eventMetricCollector, error) {
+	if definition.Name == "" {
+		return nil, fmt.Errorf("event metric missing name")
+	}
+	var labels []string
+	for _, label := range definition.Labels {
+		labels = append(labels, label.Name)
+	}
+	c := &eventMetricCollector{
+		definition: definition,
+		events:     map[string]bool{},
+		labelSets:  map[string]bool{},
+	}
+	for _, name := range definition.Events {
+		c.events[name] = true
+	}
+	switch definition.Type {
+	case EventMetricCounter, "":
+		c.counter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      definition.Name,
+			Help:      definition.Help,
+		}, labels)
+	case EventMetricHistogram:
+		if definition.Field == "" {
+			return nil, fmt.Errorf("histogram %s missing field", definition.Name)
+		}
+		buckets := definition.Buckets
+		if len(buckets) == 0 {
+			buckets = prometheus.DefBuckets
+		}
+		c.histogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: MetricsNamespace,
+			Name:      definition.Name,
+			Help:      definition.Help,
+			Buckets:   buckets,
+		}, labels)
+	default:
+		return nil, fmt.Errorf("invalid metric type %s for %s", definition.Type, definition.Name)
+	}
+	return c, nil
+}
+
+func (c *eventMetricCollector) collector() prometheus.Collector {
+	if c.histogram != nil {
+		return c.histogram
+	}
+	return c.counter
+}
+
+// labelValues will return the label values for an Event, and
+// whether the values had to be replaced with the overflow value.
+func (c *eventMetricCollector) labelValues(event Event) ([]string, bool) {
+	var values []string
+	for _, label := range c.definition.Labels {
+		values = append(values, EventFieldString(event, label.Field))
+	}
+	max := c.definition.MaxLabelSets
+	if max <= 0 {
+		max = DefaultMaxLabelSets
+	}
+	key := strings.Join(values, "\xff")
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.labelSets[key] {
+		return values, false
+	}
+	if len(c.labelSets) < max {
+		c.labelSets[key] = true
+		return values, false
+	}
+	for i := range values {
+		values[i] = MetricLabelOverflow
+	}
+	return values, true
+}
+
+func (c *eventMetricCollector) observe(event Event) bool {
+	if len(c.events) > 0 && !c.events[event.Name()] {
+		return false
+	}
+	if c.definition.Select != nil && !c.definition.Select(event) {
+		return false
+	}
+	values, overflow := c.labelValues(event)
+	if c.histogram != nil {
+		v, ok := EventFieldFloat(event, c.definition.Field)
+		if !ok {
+			return overflow
+		}
+		c.histogram.WithLabelValues(values...).Observe(v)
+		return overflow
+	}
+	c.counter.WithLabelValues(values...).Inc()
+	return overflow
+}
+
+// Register will serve the metrics of another collector, such as
+// a RateTracker, alongside the event metrics.
+func (m *Metrics) Register(collector prometheus.Collector) error {
+	err := m.registry.Register(collector)
+	if err != nil {
+		return fmt.Errorf("unable to register metric: %v", err)
+	}
+	return nil
+}
+
+// Handle will record an Event with every configured EventMetric.
+func (m *Metrics) Handle(event Event) {
+	m.events.WithLabelValues(event.Name()).Inc()
+	for _, c := range m.collectors {
+		if c.observe(event) {
+			m.overflow.WithLabelValues(c.definition.Name).Inc()
+		}
+	}
+}
+
+// Handler will return the http.Handler that serves the metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ListenAndServe will serve the metrics on MetricsPath at address.
+// This will block.
+func (m *Metrics) ListenAndServe(address string) error {
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, m.Handler())
+	logger.Info("Serving metrics: %s%s", address, MetricsPath)
+	return http.ListenAndServe(address, mux)
+}
@@ -0,0 +1,178 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"testing"
+)
+
+func TestEventMetricLabelOverflow(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxLabelSets int
+		containers   []string
+		overflows    int
+		values       map[string]float64
+	}{
+		{
+			name:         "under the limit",
+			maxLabelSets: 3,
+			containers:   []string{"a", "b", "a", "c"},
+			values:       map[string]float64{"a": 2, "b": 1, "c": 1},
+		},
+		{
+			name:         "over the limit",
+			maxLabelSets: 2,
+			containers:   []string{"a", "b", "c", "d", "a"},
+			overflows:    2,
+			values:       map[string]float64{"a": 2, "b": 1, MetricLabelOverflow: 2},
+		},
+		{
+			name:         "known label sets after the limit",
+			maxLabelSets: 1,
+			containers:   []string{"a", "b", "a", "a"},
+			overflows:    1,
+			values:       map[string]float64{"a": 3, MetricLabelOverflow: 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := NewMetrics(EventMetrics{
+				{
+					Name:         "test_total",
+					Events:       []string{"ProcessExecuted"},
+					Labels:       []EventMetricLabel{{Name: "container", Field: "ContainerID"}},
+					MaxLabelSets: test.maxLabelSets,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range test.containers {
+				m.Handle(&ProcessEvent{
+					EventName:         "ProcessExecuted",
+					ContainerMetadata: ContainerMetadata{ContainerID: id},
+				})
+			}
+			values := counterValues(t, m, "dse_test_total")
+			if len(values) != len(test.values) {
+				t.Fatalf("expected %v, got %v", test.values, values)
+			}
+			for label, expected := range test.values {
+				if values[label] != expected {
+					t.Errorf("expected %s=%v, got %v", label, expected, values[label])
+				}
+			}
+			if overflows := counterValues(t, m, "dse_metric_label_overflow_total")["test_total"]; overflows != float64(test.overflows) {
+				t.Errorf("expected %d overflows, got %v", test.overflows, overflows)
+			}
+		})
+	}
+}
+
+func TestEventMetricSelect(t *testing.T) {
+	m, err := NewMetrics(EventMetrics{
+		{
+			Name:   "selected_total",
+			Events: []string{"ProcessExecuted"},
+			Select: func(event Event) bool {
+				return EventFieldString(event, "Comm") != "ignored"
+			},
+		},
+		{
+			Name:   "pid",
+			Type:   EventMetricHistogram,
+			Field:  "PID",
+			Events: []string{"ProcessExecuted"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Handle(&ProcessEvent{EventName: "ProcessExecuted", Comm: "bash", PID: 10})
+	m.Handle(&ProcessEvent{EventName: "ProcessExecuted", Comm: "ignored", PID: 20})
+	m.Handle(&SignalEvent{EventName: "SignalDelivered"})
+	if v := counterValues(t, m, "dse_selected_total")[""]; v != 1 {
+		t.Errorf("expected 1 selected event, got %v", v)
+	}
+	gathered, err := m.registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range gathered {
+		if family.GetName() != "dse_pid" {
+			continue
+		}
+		histogram := family.GetMetric()[0].GetHistogram()
+		if histogram.GetSampleCount() != 2 || histogram.GetSampleSum() != 30 {
+			t.Errorf("expected 2 samples with sum 30, got %d with sum %v", histogram.GetSampleCount(), histogram.GetSampleSum())
+		}
+	}
+	events := counterValues(t, m, "dse_events_total")
+	if events["ProcessExecuted"] != 2 || events["SignalDelivered"] != 1 {
+		t.Errorf("expected 2 ProcessExecuted and 1 SignalDelivered, got %v", events)
+	}
+}
+
+func TestNewMetricsInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition *EventMetric
+	}{
+		{"missing name", &EventMetric{}},
+		{"histogram missing field", &EventMetric{Name: "h", Type: EventMetricHistogram}},
+		{"invalid type", &EventMetric{Name: "g", Type: "gauge"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewMetrics(EventMetrics{test.definition})
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// counterValues will return the values of a counter by its first
+// label value, or by "" for a counter without labels.
+func counterValues(t *testing.T, m *Metrics, name string) map[string]float64 {
+	t.Helper()
+	gathered, err := m.registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]float64{}
+	for _, family := range gathered {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			var label string
+			if len(metric.GetLabel()) > 0 {
+				label = metric.GetLabel()[0].GetValue()
+			}
+			values[label] += metric.GetCounter().GetValue()
+		}
+	}
+	return values
+}
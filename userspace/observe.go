@@ -18,10 +18,12 @@
 package userspace
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/cilium/ebpf"
 
@@ -37,6 +39,10 @@ import (
 type Observer struct {
 	points    ObservationPoints
 	reference ObservationReference
+	enrichers []Enricher
+	handlers  []EventHandler
+	producers []EventProducer
+	eventCh   chan Event
 }
 
 // ObservationReference will set the reference for
@@ -58,19 +64,46 @@ func NewObserver(points ObservationPoints) *Observer {
 			probe:   probe,
 			eventCh: make(chan Event),
 		},
+		eventCh: make(chan Event),
 	}
 	return observer
 }
 
+// AddHandler will register an EventHandler with the Observer.
+// Handlers are called in the order they are added, and must
+// be added before calling Start().
+func (o *Observer) AddHandler(handler EventHandler) {
+	o.handlers = append(o.handlers, handler)
+}
+
+// AddEnricher will register an Enricher that is called for every
+// Event before any EventHandler.
+func (o *Observer) AddEnricher(enricher Enricher) {
+	o.enrichers = append(o.enrichers, enricher)
+}
+
+// AddProducer will register an EventProducer with the Observer.
+// Producers must be added before calling Start().
+func (o *Observer) AddProducer(producer EventProducer) {
+	o.producers = append(o.producers, producer)
+}
+
+// Emit will dispatch an Event from outside of the ObservationPoints,
+// such as the ExecGuard with its own perf buffer. The Observer must
+// be started.
+func (o *Observer) Emit(event Event) {
+	o.reference.eventCh <- event
+}
+
 // NextEvent will return the next Event in the "queue" otherwise block.
 func (o *Observer) NextEvent() Event {
-	return <-o.reference.eventCh
+	return <-o.eventCh
 }
 
 // PrintJSONEvents will simply Print() the events in raw JSON
 func (o *Observer) PrintJSONEvents() {
 	for {
-		event := <-o.reference.eventCh
+		event := <-o.eventCh
 		b, err := event.JSON()
 		if err != nil {
 			fmt.Printf("{\"Error\": \"%v\"}\n", err)
@@ -84,7 +117,7 @@ func (o *Observer) PrintJSONEvents() {
 // logger.
 func (o *Observer) LogEvents() {
 	for {
-		event := <-o.reference.eventCh
+		event := <-o.eventCh
 		logger.Info(event.String())
 	}
 }
@@ -96,6 +129,12 @@ func (o *Observer) Start() error {
 	var loadedLinks []link.Link
 	for _, obs := range o.points {
 		obs.SetReference(o.reference)
+		if loader, ok := obs.(ObservationPointLoader); ok {
+			err := loader.Load()
+			if err != nil {
+				return fmt.Errorf("Error configuring observation point: %v", err)
+			}
+		}
 		for _, td := range obs.Tracepoints() {
 			logger.Info("Loading tracepoint: %s/%s", td.Group, td.Tracepoint)
 			link, err := link.Tracepoint(td.Group, td.Tracepoint, td.Program)
@@ -119,9 +158,40 @@ func (o *Observer) Start() error {
 	// [ Main Processor ]
 	go eventLoop(sigCh, reader, o.points, loadedLinks)
 
+	// [ Event Handlers ]
+	go o.dispatch()
+
 	return nil
 }
 
+// dispatch will pass every Event from the ObservationPoints
+// through the configured enrichers and handlers, and then on to the EventStream.
+func (o *Observer) dispatch() {
+	for {
+		o.dispatchEvent(<-o.reference.eventCh)
+	}
+}
+
+// dispatchEvent will deliver a single Event, followed by any
+// events the producers create from it.
+func (o *Observer) dispatchEvent(event Event) {
+	if e, ok := event.(interface{ setTimestamp(time.Time) }); ok {
+		e.setTimestamp(time.Now())
+	}
+	for _, enricher := range o.enrichers {
+		enricher.Enrich(event)
+	}
+	for _, handler := range o.handlers {
+		handler.Handle(event)
+	}
+	o.eventCh <- event
+	for _, producer := range o.producers {
+		for _, produced := range producer.Produce(event) {
+			o.dispatchEvent(produced)
+		}
+	}
+}
+
 func eventLoop(sigCh chan os.Signal, reader *perf.Reader, points ObservationPoints, loadedLinks []link.Link) {
 	for {
 		select {
@@ -134,12 +204,18 @@ func eventLoop(sigCh chan os.Signal, reader *perf.Reader, points ObservationPoin
 				logger.Critical("********************")
 				fmt.Println()
 				for _, l := range loadedLinks {
-					go func() {
-						err := l.Close()
+					err := l.Close()
+					if err != nil {
+						logger.Warning("Error unlinking: %s", err)
+					}
+				}
+				for _, point := range points {
+					if closer, ok := point.(ObservationPointCloser); ok {
+						err := closer.Close()
 						if err != nil {
-							logger.Warning("Error unlinking: %s", err)
+							logger.Warning("Error closing observation point: %s", err)
 						}
-					}()
+					}
 				}
 				err := reader.Close()
 				if err != nil {
@@ -154,25 +230,28 @@ func eventLoop(sigCh chan os.Signal, reader *perf.Reader, points ObservationPoin
 		event, err := reader.Read()
 		if err != nil {
 			logger.Warning(err.Error())
+			continue
 		}
 		if event.LostSamples > 0 {
 			logger.Warning("Dropping kernel samples: %d", event.LostSamples)
 			continue
 		}
-		errCh := make(chan error)
-		for _, point := range points {
-			go func() {
-				errCh <- point.Event(event)
-			}()
-		}
+		// Every point sees every record, and ignores the
+		// records of other event types.
 		var foundErrors []error
-		for i := 0; i < len(points); i++ {
-			r := <-errCh
-			if r != nil {
-				foundErrors = append(foundErrors)
+		handled := false
+		for _, point := range points {
+			err := point.Event(event)
+			if errors.Is(err, ErrEventType) {
+				continue
+			}
+			if err != nil {
+				foundErrors = append(foundErrors, err)
+				continue
 			}
+			handled = true
 		}
-		if len(foundErrors) == len(points) {
+		if !handled && len(foundErrors) > 0 {
 			logger.Warning("Unable to read binary event:")
 			for _, err := range foundErrors {
 				logger.Warning(err.Error())
@@ -184,7 +263,7 @@ func eventLoop(sigCh chan os.Signal, reader *perf.Reader, points ObservationPoin
 // EventStream will return the channel of events.
 // This is the same channel used in the other Observer methods.
 func (o *Observer) EventStream() chan Event {
-	return o.reference.eventCh
+	return o.eventCh
 }
 
 type TracepointData struct {
@@ -204,4 +283,16 @@ type ObservationPoint interface {
 	SetReference(reference ObservationReference)
 }
 
+// ObservationPointLoader is implemented by an ObservationPoint that
+// configures its BPF maps before its tracepoints are loaded.
+type ObservationPointLoader interface {
+	Load() error
+}
+
+// ObservationPointCloser is implemented by an ObservationPoint that
+// stops work it started in Load() when the Observer shuts down.
+type ObservationPointCloser interface {
+	Close() error
+}
+
 type ObservationPoints map[string]ObservationPoint
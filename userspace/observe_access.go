@@ -0,0 +1,325 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall"
+
+	"github.com/kris-nova/logger"
+
+	"github.com/kris-nova/double-slit-experiment/system"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	EventNameProcessTraced        = "ProcessTraced"
+	EventNameProcessMemoryRead    = "ProcessMemoryRead"
+	EventNameProcessMemoryWritten = "ProcessMemoryWritten"
+	EventNameProcessMemoryOpened  = "ProcessMemoryOpened"
+)
+
+// Operations of ACCESS_OP_* in bpf.c
+const (
+	accessOpPtrace   = 1
+	accessOpVMReadv  = 2
+	accessOpVMWritev = 3
+	accessOpProcMem  = 4
+)
+
+// accessSyscalls are the syscalls of the ACCESS_OP_* operations in bpf.c
+var accessSyscalls = map[uint32]string{
+	accessOpPtrace:   "ptrace",
+	accessOpVMReadv:  "process_vm_readv",
+	accessOpVMWritev: "process_vm_writev",
+	accessOpProcMem:  "openat",
+}
+
+// ptraceRequests are the ptrace() requests that are sent by the kernel.
+var ptraceRequests = map[uint64]string{
+	unix.PTRACE_TRACEME:  "PTRACE_TRACEME",
+	unix.PTRACE_POKETEXT: "PTRACE_POKETEXT",
+	unix.PTRACE_POKEDATA: "PTRACE_POKEDATA",
+	unix.PTRACE_POKEUSR:  "PTRACE_POKEUSR",
+	unix.PTRACE_SETREGS:  "PTRACE_SETREGS",
+	unix.PTRACE_ATTACH:   "PTRACE_ATTACH",
+	unix.PTRACE_SEIZE:    "PTRACE_SEIZE",
+}
+
+// accessNamespaces are the namespaces compared between a process
+// and the process it accessed.
+var accessNamespaces = []string{"pid", "mnt", "net", "user", "ipc", "uts", "cgroup"}
+
+// ProcessAccessObservationPoint will observe a process that reads or
+// writes the memory of another process.
+//
+//   ProcessTraced         ptrace() attach, seize, traceme, poke and setregs
+//   ProcessMemoryRead     process_vm_readv()
+//   ProcessMemoryWritten  process_vm_writev()
+//   ProcessMemoryOpened   openat() of /proc/<pid>/mem
+//
+// Requests such as PTRACE_PEEKDATA and PTRACE_SYSCALL are filtered in
+// the kernel, as every debugger sends thousands of them. Only absolute
+// /proc/<pid>/mem paths are matched. A process that accesses itself
+// is not sent.
+type ProcessAccessObservationPoint struct {
+	reference   ObservationReference
+	dropFilters []DropProcessAccess
+}
+
+func (p *ProcessAccessObservationPoint) Event(record perf.Record) error {
+	data, err := EventProcessAccess(record)
+	if err != nil {
+		return err
+	}
+
+	for _, drop := range p.dropFilters {
+		if drop(data) {
+			return nil
+		}
+	}
+
+	event := NewProcessAccessEvent(record.CPU, data)
+	if event.TargetPID == event.PID {
+		return nil
+	}
+	p.reference.eventCh <- event
+	return nil
+}
+
+func (p *ProcessAccessObservationPoint) Tracepoints() map[string]TracepointData {
+	probe := p.reference.probe
+	syscalls := []struct {
+		name        string
+		enter, exit *ebpf.Program
+	}{
+		{"ptrace", probe.EnterPtrace, probe.ExitPtrace},
+		{"process_vm_readv", probe.EnterProcessVmReadv, probe.ExitProcessVmReadv},
+		{"process_vm_writev", probe.EnterProcessVmWritev, probe.ExitProcessVmWritev},
+		{"openat", probe.EnterOpenatProcMem, probe.ExitOpenatProcMem},
+		{"openat2", probe.EnterOpenat2ProcMem, probe.ExitOpenat2ProcMem},
+	}
+	tracepoints := map[string]TracepointData{}
+	for _, call := range syscalls {
+		enter, exit := "sys_enter_"+call.name, "sys_exit_"+call.name
+		if !TracepointExists(BPFGroupSyscalls, enter) {
+			continue
+		}
+		tracepoints[enter] = TracepointData{
+			Group:      BPFGroupSyscalls,
+			Tracepoint: enter,
+			Program:    call.enter,
+		}
+		tracepoints[exit] = TracepointData{
+			Group:      BPFGroupSyscalls,
+			Tracepoint: exit,
+			Program:    call.exit,
+		}
+	}
+	return tracepoints
+}
+
+func (p *ProcessAccessObservationPoint) SetReference(reference ObservationReference) {
+	p.reference = reference
+}
+
+func NewProcessAccessObservationPoint(dropFilters []DropProcessAccess) *ProcessAccessObservationPoint {
+	return &ProcessAccessObservationPoint{
+		dropFilters: dropFilters,
+	}
+}
+
+// ProcessAccessEvent is sent when a process accesses the memory of
+// another process. The PID passed to the syscall is TargetNamespacePID,
+// which is in the PID namespace of the process. TargetPID is the same
+// process on the host, and is 0 if it could not be found.
+//
+// CrossContainer is true if the processes are in different containers,
+// or only one of them is in a container. CrossNamespaces are the names
+// of the namespaces that are different.
+type ProcessAccessEvent struct {
+	CPU                int      `json:"CPU"`
+	EventName          string   `json:"Name"`
+	Syscall            string   `json:"Syscall"`
+	Request            string   `json:"Request,omitempty"`
+	PID                uint     `json:"PID"`
+	PPID               uint     `json:"PPID"`
+	UID                uint     `json:"UID"`
+	Comm               string   `json:"Comm"`
+	TargetPID          uint     `json:"TargetPID"`
+	TargetNamespacePID int      `json:"TargetNamespacePID"`
+	TargetComm         string   `json:"TargetComm,omitempty"`
+	TargetContainerID  string   `json:"TargetContainerID,omitempty"`
+	Address            uint64   `json:"Address,omitempty"`
+	Bytes              int64    `json:"Bytes,omitempty"`
+	Filename           string   `json:"Filename,omitempty"`
+	FlagNames          []string `json:"FlagNames,omitempty"`
+	Write              bool     `json:"Write"`
+	CrossContainer     bool     `json:"CrossContainer"`
+	CrossNamespaces    []string `json:"CrossNamespaces,omitempty"`
+	Error              string   `json:"Error,omitempty"`
+	ContainerMetadata
+	EventTime
+}
+
+func NewProcessAccessEvent(cpu int, data *process_access_data_t) *ProcessAccessEvent {
+	e := &ProcessAccessEvent{
+		CPU:                cpu,
+		Syscall:            accessSyscalls[data.Op],
+		PID:                uint(data.Pid),
+		PPID:               uint(data.Ppid),
+		UID:                uint(data.Uid),
+		Comm:               BytesToString32(data.Comm),
+		TargetNamespacePID: int(data.Target),
+		ContainerMetadata:  containerMetadataForPID(int(data.Pid)),
+	}
+	switch data.Op {
+	case accessOpPtrace:
+		e.EventName = EventNameProcessTraced
+		e.Request = ptraceRequestName(data.Request)
+		switch data.Request {
+		case unix.PTRACE_POKETEXT, unix.PTRACE_POKEDATA, unix.PTRACE_POKEUSR:
+			e.Address = data.Addr
+			e.Write = true
+		case unix.PTRACE_SETREGS:
+			e.Write = true
+		}
+	case accessOpVMReadv:
+		e.EventName = EventNameProcessMemoryRead
+	case accessOpVMWritev:
+		e.EventName = EventNameProcessMemoryWritten
+		e.Write = true
+	case accessOpProcMem:
+		e.EventName = EventNameProcessMemoryOpened
+		e.Filename = BytesToString(data.Filename[:])
+		e.FlagNames = OpenFlagNames(int(data.Flags))
+		e.Write = data.Flags&unix.O_ACCMODE != unix.O_RDONLY
+	}
+	switch {
+	case data.Ret < 0:
+		e.Error = unix.ErrnoName(syscall.Errno(-data.Ret))
+	case data.Op == accessOpVMReadv || data.Op == accessOpVMWritev:
+		e.Bytes = data.Ret
+	}
+
+	// PTRACE_TRACEME asks to be traced by the parent
+	target := 0
+	if data.Op == accessOpPtrace && data.Request == unix.PTRACE_TRACEME {
+		target = int(data.Ppid)
+	} else if data.Target > 0 {
+		var err error
+		target, err = system.ProcFindNamespacePID(int(data.Pid), int(data.Target))
+		if err != nil {
+			logger.Debug(err.Error())
+		}
+	}
+	if target > 0 {
+		e.TargetPID = uint(target)
+		e.setTarget(target)
+	}
+	return e
+}
+
+// setTarget will look up the process that was accessed, and compare
+// its container and namespaces. The process might have terminated.
+func (e *ProcessAccessEvent) setTarget(target int) {
+	comm, err := system.ProcComm(target)
+	if err != nil {
+		logger.Debug(err.Error())
+		return
+	}
+	e.TargetComm = comm
+	e.TargetContainerID = containerMetadataForPID(target).ContainerID
+	e.CrossContainer = e.TargetContainerID != e.ContainerID
+	for _, namespace := range accessNamespaces {
+		ns, err := system.ProcNamespace(int(e.PID), namespace)
+		if err != nil {
+			continue
+		}
+		targetNs, err := system.ProcNamespace(target, namespace)
+		if err != nil {
+			continue
+		}
+		if ns != targetNs {
+			e.CrossNamespaces = append(e.CrossNamespaces, namespace)
+		}
+	}
+}
+
+func (e *ProcessAccessEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *ProcessAccessEvent) String() string {
+	detail := e.Syscall
+	if e.Request != "" {
+		detail = e.Request
+	}
+	if e.Filename != "" {
+		detail = fmt.Sprintf("%s %s", detail, e.Filename)
+	}
+	if e.Error != "" {
+		detail = fmt.Sprintf("%s: %s", detail, e.Error)
+	}
+	target := fmt.Sprintf("%d", e.TargetNamespacePID)
+	if e.TargetComm != "" {
+		target = fmt.Sprintf("[%s] (%d)", e.TargetComm, e.TargetPID)
+	}
+	return fmt.Sprintf("[%s] (%d) %s -> %s cross-container:%t namespaces:%v", e.Comm, e.PID, detail, target, e.CrossContainer, e.CrossNamespaces)
+}
+
+func (e *ProcessAccessEvent) Name() string {
+	return e.EventName
+}
+
+// Severity is critical across containers, high for a write or
+// across namespaces, and medium otherwise.
+func (e *ProcessAccessEvent) Severity() int {
+	switch {
+	case e.CrossContainer:
+		return RuleSeverities["critical"]
+	case e.Write || len(e.CrossNamespaces) > 0:
+		return RuleSeverities["high"]
+	}
+	return RuleSeverities["medium"]
+}
+
+func ptraceRequestName(request uint64) string {
+	if name, ok := ptraceRequests[request]; ok {
+		return name
+	}
+	return fmt.Sprintf("PTRACE_%d", request)
+}
+
+type DropProcessAccess func(d *process_access_data_t) bool
+
+// DropProcessAccessComm will drop every access by a process with this comm.
+func DropProcessAccessComm(comm string) DropProcessAccess {
+	return func(d *process_access_data_t) bool {
+		return BytesToString32(d.Comm) == comm
+	}
+}
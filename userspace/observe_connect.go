@@ -0,0 +1,338 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall"
+
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	EventNameSocketConnected = "SocketConnected"
+	EventNameSocketAccepted  = "SocketAccepted"
+)
+
+// sockCallSyscalls are the syscalls of the SOCK_OP_* operations in bpf.c
+var sockCallSyscalls = map[uint32]string{
+	1: "connect",
+	2: "accept",
+	3: "accept4",
+	4: "bind",
+	5: "listen",
+}
+
+var socketFamilies = map[uint16]string{
+	unix.AF_UNIX:  "AF_UNIX",
+	unix.AF_INET:  "AF_INET",
+	unix.AF_INET6: "AF_INET6",
+}
+
+var socketTypes = map[uint16]string{
+	unix.SOCK_STREAM:    "SOCK_STREAM",
+	unix.SOCK_DGRAM:     "SOCK_DGRAM",
+	unix.SOCK_RAW:       "SOCK_RAW",
+	unix.SOCK_RDM:       "SOCK_RDM",
+	unix.SOCK_SEQPACKET: "SOCK_SEQPACKET",
+	unix.SOCK_PACKET:    "SOCK_PACKET",
+}
+
+var acceptFlags = []flagName{
+	{unix.SOCK_NONBLOCK, "SOCK_NONBLOCK"},
+	{unix.SOCK_CLOEXEC, "SOCK_CLOEXEC"},
+}
+
+// ConnectObservationPoint will observe every connect() of a TCP,
+// UDP or Unix socket, including the connections that failed.
+//
+// The peer is the address passed to connect(), and the local address
+// is the address the socket was bound to. A non-blocking connect() is
+// InProgress, and its result is only seen by the SocketObservationPoint.
+type ConnectObservationPoint struct {
+	reference   ObservationReference
+	dropFilters []DropConnection
+}
+
+func (p *ConnectObservationPoint) Event(record perf.Record) error {
+	data, err := EventConnect(record)
+	if err != nil {
+		return err
+	}
+
+	for _, drop := range p.dropFilters {
+		if drop(data) {
+			return nil
+		}
+	}
+
+	p.reference.eventCh <- NewConnectionEvent(record.CPU, data)
+	return nil
+}
+
+func (p *ConnectObservationPoint) Tracepoints() map[string]TracepointData {
+	probe := p.reference.probe
+	return map[string]TracepointData{
+		"sys_enter_connect": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_enter_connect",
+			Program:    probe.EnterConnect,
+		},
+		"sys_exit_connect": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_exit_connect",
+			Program:    probe.ExitConnect,
+		},
+	}
+}
+
+func (p *ConnectObservationPoint) SetReference(reference ObservationReference) {
+	p.reference = reference
+}
+
+func NewConnectObservationPoint(dropFilters []DropConnection) *ConnectObservationPoint {
+	return &ConnectObservationPoint{
+		dropFilters: dropFilters,
+	}
+}
+
+// AcceptObservationPoint will observe every accept() and accept4()
+// of a TCP or Unix socket. A non-blocking accept() with nothing to
+// accept is dropped in the kernel.
+type AcceptObservationPoint struct {
+	reference   ObservationReference
+	dropFilters []DropConnection
+}
+
+func (p *AcceptObservationPoint) Event(record perf.Record) error {
+	data, err := EventAccept(record)
+	if err != nil {
+		return err
+	}
+
+	for _, drop := range p.dropFilters {
+		if drop(data) {
+			return nil
+		}
+	}
+
+	p.reference.eventCh <- NewConnectionEvent(record.CPU, data)
+	return nil
+}
+
+func (p *AcceptObservationPoint) Tracepoints() map[string]TracepointData {
+	probe := p.reference.probe
+	return map[string]TracepointData{
+		"sys_enter_accept": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_enter_accept",
+			Program:    probe.EnterAcceptSyscall,
+		},
+		"sys_exit_accept": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_exit_accept",
+			Program:    probe.ExitAcceptSyscall,
+		},
+		"sys_enter_accept4": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_enter_accept4",
+			Program:    probe.EnterAccept4,
+		},
+		"sys_exit_accept4": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_exit_accept4",
+			Program:    probe.ExitAccept4,
+		},
+	}
+}
+
+func (p *AcceptObservationPoint) SetReference(reference ObservationReference) {
+	p.reference = reference
+}
+
+func NewAcceptObservationPoint(dropFilters []DropConnection) *AcceptObservationPoint {
+	return &AcceptObservationPoint{
+		dropFilters: dropFilters,
+	}
+}
+
+// ConnectionEvent is sent for every connect() and accept(). FD is
+// the socket passed to connect(), or the socket returned by accept().
+//
+// Unix sockets have a path instead of an address. The path of an
+// accepted socket is the path of the listener, and PeerPID is the
+// process on the other end.
+type ConnectionEvent struct {
+	CPU        int      `json:"CPU"`
+	EventName  string   `json:"Name"`
+	Syscall    string   `json:"Syscall"`
+	PID        uint     `json:"PID"`
+	PPID       uint     `json:"PPID"`
+	UID        uint     `json:"UID"`
+	Comm       string   `json:"Comm"`
+	FD         int      `json:"FD"`
+	Family     string   `json:"Family"`
+	Type       string   `json:"Type,omitempty"`
+	Protocol   string   `json:"Protocol,omitempty"`
+	LocalAddr  string   `json:"LocalAddr,omitempty"`
+	LocalPort  uint     `json:"LocalPort,omitempty"`
+	PeerAddr   string   `json:"PeerAddr,omitempty"`
+	PeerPort   uint     `json:"PeerPort,omitempty"`
+	LocalPath  string   `json:"LocalPath,omitempty"`
+	PeerPath   string   `json:"PeerPath,omitempty"`
+	PeerPID    uint     `json:"PeerPID,omitempty"`
+	FlagNames  []string `json:"FlagNames,omitempty"`
+	InProgress bool     `json:"InProgress"`
+	Error      string   `json:"Error,omitempty"`
+	ContainerMetadata
+	EventTime
+}
+
+func NewConnectionEvent(cpu int, data *sock_call_data_t) *ConnectionEvent {
+	e := &ConnectionEvent{
+		CPU:               cpu,
+		EventName:         EventNameSocketAccepted,
+		Syscall:           sockCallSyscalls[data.Op],
+		PID:               uint(data.Pid),
+		PPID:              uint(data.Ppid),
+		UID:               uint(data.Uid),
+		Comm:              BytesToString32(data.Comm),
+		FD:                int(data.Fd),
+		Family:            socketFamilies[data.Family],
+		Type:              socketTypes[data.Sock_type],
+		Protocol:          SocketProtocolName(data.Family, data.Sock_type),
+		PeerPID:           uint(data.Peer_pid),
+		FlagNames:         flagNames(int(data.Flags), acceptFlags),
+		ContainerMetadata: containerMetadataForPID(int(data.Pid)),
+	}
+	if e.Syscall == "connect" {
+		e.EventName = EventNameSocketConnected
+	} else if data.Ret >= 0 {
+		e.FD = int(data.Ret)
+	}
+	switch data.Family {
+	case unix.AF_INET:
+		var local, peer [4]byte
+		copy(local[:], data.Laddr[:4])
+		copy(peer[:], data.Raddr[:4])
+		e.LocalAddr, e.PeerAddr = IPV4(local), IPV4(peer)
+		e.LocalPort, e.PeerPort = uint(data.Lport), uint(data.Rport)
+	case unix.AF_INET6:
+		e.LocalAddr, e.PeerAddr = IPV6(data.Laddr), IPV6(data.Raddr)
+		e.LocalPort, e.PeerPort = uint(data.Lport), uint(data.Rport)
+	case unix.AF_UNIX:
+		path := unixSocketPath(data.Path)
+		if e.Syscall == "connect" {
+			e.PeerPath = path
+		} else {
+			e.LocalPath = path
+		}
+	}
+	switch {
+	case data.Ret == -int32(unix.EINPROGRESS):
+		e.InProgress = true
+	case data.Ret < 0:
+		e.Error = unix.ErrnoName(syscall.Errno(-data.Ret))
+	}
+	return e
+}
+
+func (e *ConnectionEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *ConnectionEvent) String() string {
+	local := fmt.Sprintf("%s:%d", e.LocalAddr, e.LocalPort)
+	peer := fmt.Sprintf("%s:%d", e.PeerAddr, e.PeerPort)
+	if e.Family == "AF_UNIX" {
+		local, peer = e.LocalPath, e.PeerPath
+		if e.PeerPID != 0 {
+			peer = fmt.Sprintf("%s (%d)", peer, e.PeerPID)
+		}
+	}
+	detail := fmt.Sprintf("%s %s -> %s", e.Protocol, local, peer)
+	if e.EventName == EventNameSocketAccepted {
+		detail = fmt.Sprintf("%s %s <- %s", e.Protocol, local, peer)
+	}
+	switch {
+	case e.Error != "":
+		detail = fmt.Sprintf("%s: %s", detail, e.Error)
+	case e.InProgress:
+		detail = fmt.Sprintf("%s: in progress", detail)
+	}
+	return fmt.Sprintf("[%s] (%d) %s: %s", e.Comm, e.PID, e.Syscall, detail)
+}
+
+func (e *ConnectionEvent) Name() string {
+	return e.EventName
+}
+
+// SocketProtocolName will return the lower case name of the protocol
+// of a socket, from its family and type.
+func SocketProtocolName(family, sockType uint16) string {
+	if family == unix.AF_UNIX {
+		return "unix"
+	}
+	if family != unix.AF_INET && family != unix.AF_INET6 {
+		return ""
+	}
+	switch sockType {
+	case unix.SOCK_STREAM:
+		return "tcp"
+	case unix.SOCK_DGRAM:
+		return "udp"
+	case unix.SOCK_SEQPACKET:
+		return "sctp"
+	case unix.SOCK_RAW:
+		return "raw"
+	}
+	return ""
+}
+
+// unixSocketPath will return the path of a Unix socket. Abstract
+// sockets start with a NUL, and are shown with an @ instead.
+func unixSocketPath(path [108]byte) string {
+	if path[0] == 0 && path[1] != 0 {
+		return "@" + BytesToString(path[1:])
+	}
+	return BytesToString(path[:])
+}
+
+type DropConnection func(d *sock_call_data_t) bool
+
+// DropConnectionFamily will drop every connection of a socket family,
+// such as unix.AF_UNIX.
+func DropConnectionFamily(family uint16) DropConnection {
+	return func(d *sock_call_data_t) bool {
+		return d.Family == family
+	}
+}
+
+// DropConnectionComm will drop every connection by a process with this comm.
+func DropConnectionComm(comm string) DropConnection {
+	return func(d *sock_call_data_t) bool {
+		return BytesToString32(d.Comm) == comm
+	}
+}
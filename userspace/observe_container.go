@@ -78,7 +78,7 @@ func (c *ContainerObservationPoint) Tracepoints() map[string]TracepointData {
 		"sys_enter_clone": {
 			Group:      BPFGroupSyscalls,
 			Tracepoint: "sys_enter_clone",
-			Program:    c.reference.probe.EnterExecve,
+			Program:    c.reference.probe.EnterClone,
 		},
 	}
 }
@@ -105,20 +105,25 @@ type ContainerEvent struct {
 	CloneFlags       uint            `json:"CloneFlags"`
 	CloneFlagsByName []string        `json:"CloneFlagsByName"`
 	TLS              uint            `json:"TLS"`
+	UID              uint            `json:"UID"`
+	ContainerMetadata
+	EventTime
 }
 
 func NewContainerEvent(name string, cpu int, cloneData *clone_data_t, parentProc, childProc *system.Process) *ContainerEvent {
 	return &ContainerEvent{
-		CPU:              cpu,
-		data:             cloneData,
-		EventName:        name,
-		ParentPid:        int(cloneData.Parent_tid),
-		ParentProc:       parentProc,
-		ChildPid:         int(cloneData.Child_tid),
-		ChildProc:        childProc,
-		CloneFlags:       uint(cloneData.Clone_flags),
-		CloneFlagsByName: CloneFlagsByName(cloneData.Clone_flags),
-		TLS:              uint(cloneData.TLS),
+		CPU:               cpu,
+		data:              cloneData,
+		EventName:         name,
+		ParentPid:         int(cloneData.Parent_tid),
+		ParentProc:        parentProc,
+		ChildPid:          int(cloneData.Child_tid),
+		ChildProc:         childProc,
+		CloneFlags:        uint(cloneData.Clone_flags),
+		CloneFlagsByName:  CloneFlagsByName(cloneData.Clone_flags),
+		TLS:               uint(cloneData.TLS),
+		UID:               uint(cloneData.Uid),
+		ContainerMetadata: containerMetadataForPID(int(cloneData.Child_tid)),
 	}
 }
 
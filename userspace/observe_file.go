@@ -0,0 +1,590 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	EventNameFileOpened       = "FileOpened"
+	EventNameFileDeleted      = "FileDeleted"
+	EventNameFileRenamed      = "FileRenamed"
+	EventNameFileModeChanged  = "FileModeChanged"
+	EventNameFileOwnerChanged = "FileOwnerChanged"
+	EventNameFileTruncated    = "FileTruncated"
+)
+
+// fileChangeNames are the event names of the FILE_OP_* operations
+// in bpf.c
+var fileChangeNames = map[uint32]string{
+	1: EventNameFileDeleted,
+	2: EventNameFileRenamed,
+	3: EventNameFileModeChanged,
+	4: EventNameFileOwnerChanged,
+	5: EventNameFileTruncated,
+}
+
+// DefaultFilePrefixes are the sensitive files observed by the
+// FileOpened point unless other prefixes are configured.
+var DefaultFilePrefixes = []string{
+	"/etc/shadow",
+	"/etc/gshadow",
+	"/etc/sudoers",
+	"/etc/kubernetes/",
+	"/var/lib/kubelet/",
+	"/var/run/secrets/",
+	"/run/secrets/",
+	"/root/.kube/",
+	"/root/.ssh/",
+}
+
+// FileOpenObservationPoint will observe openat() and openat2() of
+// any path that starts with one of the prefixes. The prefixes are
+// matched in the kernel, so other opens never reach userspace.
+//
+// The prefixes are matched against the path of the file that was
+// opened, read from the fd when the syscall returns, so symlinks,
+// "." and ".." are resolved. A failed open is matched against the
+// path passed to the syscall, resolved against the working directory
+// (or the directory fd) of the task, with "." and ".." only cleaned
+// in userspace.
+type FileOpenObservationPoint struct {
+	reference   ObservationReference
+	prefixes    []string
+	dropFilters []DropFileOpen
+}
+
+// Load will push the prefixes into the file_prefixes map. Every
+// open is observed without any prefixes.
+func (p *FileOpenObservationPoint) Load() error {
+	return loadFilePrefixes(p.reference, p.prefixes)
+}
+
+// loadFilePrefixes will configure the in kernel path filter shared
+// by every file ObservationPoint.
+func loadFilePrefixes(reference ObservationReference, prefixes []string) error {
+	var value uint8 = 1
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			return fmt.Errorf("empty file prefix")
+		}
+		key := file_prefix_t{Prefixlen: uint32(len(prefix) * 8)}
+		if len(prefix) > len(key.Path) {
+			return fmt.Errorf("file prefix %s is longer than %d bytes", prefix, len(key.Path))
+		}
+		copy(key.Path[:], prefix)
+		err := reference.probe.FilePrefixes.Put(&key, &value)
+		if err != nil {
+			return fmt.Errorf("unable to filter file prefix %s: %v", prefix, err)
+		}
+	}
+	var zero, filter uint32 = 0, 0
+	if len(prefixes) > 0 {
+		filter = 1
+	}
+	err := reference.probe.FileConfig.Put(&zero, &filter)
+	if err != nil {
+		return fmt.Errorf("unable to configure file filter: %v", err)
+	}
+	return nil
+}
+
+func (p *FileOpenObservationPoint) Event(record perf.Record) error {
+	data, err := EventFileOpen(record)
+	if err != nil {
+		return err
+	}
+
+	for _, drop := range p.dropFilters {
+		if drop(data) {
+			return nil
+		}
+	}
+
+	p.reference.eventCh <- NewFileOpenEvent(EventNameFileOpened, record.CPU, data)
+	return nil
+}
+
+func (p *FileOpenObservationPoint) Tracepoints() map[string]TracepointData {
+	return map[string]TracepointData{
+		"sys_enter_openat": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_enter_openat",
+			Program:    p.reference.probe.EnterOpenat,
+		},
+		"sys_exit_openat": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_exit_openat",
+			Program:    p.reference.probe.ExitOpenat,
+		},
+		"sys_enter_openat2": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_enter_openat2",
+			Program:    p.reference.probe.EnterOpenat2,
+		},
+		"sys_exit_openat2": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_exit_openat2",
+			Program:    p.reference.probe.ExitOpenat2,
+		},
+	}
+}
+
+func (p *FileOpenObservationPoint) SetReference(reference ObservationReference) {
+	p.reference = reference
+}
+
+func NewFileOpenObservationPoint(prefixes []string, dropFilters []DropFileOpen) *FileOpenObservationPoint {
+	return &FileOpenObservationPoint{
+		prefixes:    prefixes,
+		dropFilters: dropFilters,
+	}
+}
+
+type FileOpenEvent struct {
+	CPU       int      `json:"CPU"`
+	EventName string   `json:"Name"`
+	PID       uint     `json:"PID"`
+	PPID      uint     `json:"PPID"`
+	UID       uint     `json:"UID"`
+	Comm      string   `json:"Comm"`
+	Filename  string   `json:"Filename"`
+	Flags     int      `json:"Flags"`
+	FlagNames []string `json:"FlagNames"`
+	Mode      string   `json:"Mode,omitempty"`
+	FD        int      `json:"FD"`
+	Error     string   `json:"Error,omitempty"`
+	ContainerMetadata
+	EventTime
+}
+
+func NewFileOpenEvent(name string, cpu int, data *file_open_data_t) *FileOpenEvent {
+	e := &FileOpenEvent{
+		CPU:               cpu,
+		EventName:         name,
+		PID:               uint(data.Pid),
+		PPID:              uint(data.Ppid),
+		UID:               uint(data.Uid),
+		Comm:              BytesToString32(data.Comm),
+		Filename:          cleanFilePath(BytesToString(data.Filename[:])),
+		Flags:             int(data.Flags),
+		FlagNames:         OpenFlagNames(int(data.Flags)),
+		FD:                int(data.Ret),
+		ContainerMetadata: containerMetadataForPID(int(data.Pid)),
+	}
+	if data.Flags&unix.O_CREAT != 0 || data.Flags&unix.O_TMPFILE == unix.O_TMPFILE {
+		e.Mode = fmt.Sprintf("%04o", data.Mode)
+	}
+	if data.Ret < 0 {
+		e.FD = -1
+		e.Error = unix.ErrnoName(syscall.Errno(-data.Ret))
+	}
+	return e
+}
+
+func (e *FileOpenEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *FileOpenEvent) String() string {
+	result := fmt.Sprintf("fd %d", e.FD)
+	if e.Error != "" {
+		result = e.Error
+	}
+	return fmt.Sprintf("[%s] (%d) open %s %s: %s", e.Comm, e.PID, e.Filename, strings.Join(e.FlagNames, "|"), result)
+}
+
+func (e *FileOpenEvent) Name() string {
+	return e.EventName
+}
+
+// cleanFilePath will clean "." and ".." from a path resolved in the
+// kernel. A relative path could not be resolved, and is left as is.
+func cleanFilePath(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Clean(path)
+}
+
+// openFlags are checked in order, so flags that share bits with
+// another flag (O_SYNC and O_TMPFILE) come first.
+var openFlags = []flagName{
+	{unix.O_CREAT, "O_CREAT"},
+	{unix.O_EXCL, "O_EXCL"},
+	{unix.O_NOCTTY, "O_NOCTTY"},
+	{unix.O_TRUNC, "O_TRUNC"},
+	{unix.O_APPEND, "O_APPEND"},
+	{unix.O_NONBLOCK, "O_NONBLOCK"},
+	{unix.O_SYNC, "O_SYNC"},
+	{unix.O_DSYNC, "O_DSYNC"},
+	{unix.O_ASYNC, "O_ASYNC"},
+	{unix.O_DIRECT, "O_DIRECT"},
+	{unix.O_LARGEFILE, "O_LARGEFILE"},
+	{unix.O_TMPFILE, "O_TMPFILE"},
+	{unix.O_DIRECTORY, "O_DIRECTORY"},
+	{unix.O_NOFOLLOW, "O_NOFOLLOW"},
+	{unix.O_NOATIME, "O_NOATIME"},
+	{unix.O_CLOEXEC, "O_CLOEXEC"},
+	{unix.O_PATH, "O_PATH"},
+}
+
+// OpenFlagNames will decode the flags of open() into their names.
+// The access mode is always first, and unknown bits are in hex.
+func OpenFlagNames(flags int) []string {
+	var names []string
+	switch flags & unix.O_ACCMODE {
+	case unix.O_RDONLY:
+		names = append(names, "O_RDONLY")
+	case unix.O_WRONLY:
+		names = append(names, "O_WRONLY")
+	case unix.O_RDWR:
+		names = append(names, "O_RDWR")
+	default:
+		names = append(names, fmt.Sprintf("0x%x", flags&unix.O_ACCMODE))
+	}
+	return append(names, flagNames(flags&^unix.O_ACCMODE, openFlags)...)
+}
+
+type DropFileOpen func(d *file_open_data_t) bool
+
+// DropFileOpenComm will drop every open by a process with this comm.
+func DropFileOpenComm(comm string) DropFileOpen {
+	return func(d *file_open_data_t) bool {
+		return BytesToString32(d.Comm) == comm
+	}
+}
+
+// DropFileOpenFailed will drop every open that returned an error.
+func DropFileOpenFailed(d *file_open_data_t) bool {
+	return d.Ret < 0
+}
+
+// FileChangeObservationPoint will observe files that are deleted,
+// renamed, truncated, or have their mode or owner changed. The same
+// prefixes as the FileOpenObservationPoint are matched in the kernel,
+// and a rename matches if either path does.
+//
+//   FileDeleted       unlink(), unlinkat()
+//   FileRenamed       rename(), renameat(), renameat2()
+//   FileModeChanged   chmod(), fchmodat()
+//   FileOwnerChanged  chown(), fchownat()
+//   FileTruncated     truncate()
+//
+// Changes are matched against the path passed to the syscall, as
+// there is no fd to read the path from. Symlinks, "." and ".." are
+// not resolved, so a chmod() of /tmp/../etc/shadow, or of a symlink
+// to it, does not match /etc/.
+type FileChangeObservationPoint struct {
+	reference   ObservationReference
+	prefixes    []string
+	dropFilters []DropFileChange
+}
+
+// Load will push the prefixes into the file_prefixes map, which is
+// shared with the FileOpenObservationPoint.
+func (p *FileChangeObservationPoint) Load() error {
+	return loadFilePrefixes(p.reference, p.prefixes)
+}
+
+func (p *FileChangeObservationPoint) Event(record perf.Record) error {
+	data, err := EventFileChange(record)
+	if err != nil {
+		return err
+	}
+
+	for _, drop := range p.dropFilters {
+		if drop(data) {
+			return nil
+		}
+	}
+
+	name, ok := fileChangeNames[data.Op]
+	if !ok {
+		return fmt.Errorf("unknown file change operation: %d", data.Op)
+	}
+	p.reference.eventCh <- NewFileChangeEvent(name, record.CPU, data)
+	return nil
+}
+
+// Tracepoints will only return the syscalls of this architecture, as
+// newer architectures (arm64) only have the *at() syscalls.
+func (p *FileChangeObservationPoint) Tracepoints() map[string]TracepointData {
+	probe := p.reference.probe
+	syscalls := []struct {
+		name        string
+		enter, exit *ebpf.Program
+	}{
+		{"unlink", probe.EnterUnlink, probe.ExitUnlink},
+		{"unlinkat", probe.EnterUnlinkat, probe.ExitUnlinkat},
+		{"rename", probe.EnterRename, probe.ExitRename},
+		{"renameat", probe.EnterRenameat, probe.ExitRenameat},
+		{"renameat2", probe.EnterRenameat2, probe.ExitRenameat2},
+		{"chmod", probe.EnterChmod, probe.ExitChmod},
+		{"fchmodat", probe.EnterFchmodat, probe.ExitFchmodat},
+		{"chown", probe.EnterChown, probe.ExitChown},
+		{"fchownat", probe.EnterFchownat, probe.ExitFchownat},
+		{"truncate", probe.EnterTruncate, probe.ExitTruncate},
+	}
+	tracepoints := map[string]TracepointData{}
+	for _, call := range syscalls {
+		enter, exit := "sys_enter_"+call.name, "sys_exit_"+call.name
+		if !TracepointExists(BPFGroupSyscalls, enter) {
+			continue
+		}
+		tracepoints[enter] = TracepointData{
+			Group:      BPFGroupSyscalls,
+			Tracepoint: enter,
+			Program:    call.enter,
+		}
+		tracepoints[exit] = TracepointData{
+			Group:      BPFGroupSyscalls,
+			Tracepoint: exit,
+			Program:    call.exit,
+		}
+	}
+	return tracepoints
+}
+
+func (p *FileChangeObservationPoint) SetReference(reference ObservationReference) {
+	p.reference = reference
+}
+
+func NewFileChangeObservationPoint(prefixes []string, dropFilters []DropFileChange) *FileChangeObservationPoint {
+	return &FileChangeObservationPoint{
+		prefixes:    prefixes,
+		dropFilters: dropFilters,
+	}
+}
+
+// tracefsPaths are where tracefs is mounted, newest first.
+var tracefsPaths = []string{
+	"/sys/kernel/tracing",
+	"/sys/kernel/debug/tracing",
+}
+
+// TracepointExists will return false if a tracepoint is missing from
+// tracefs. If tracefs is not mounted, the tracepoint is assumed to
+// exist, and will fail when it is loaded.
+func TracepointExists(group, name string) bool {
+	mounted := false
+	for _, path := range tracefsPaths {
+		_, err := os.Stat(filepath.Join(path, "events"))
+		if err != nil {
+			continue
+		}
+		mounted = true
+		_, err = os.Stat(filepath.Join(path, "events", group, name))
+		if err == nil {
+			return true
+		}
+	}
+	return !mounted
+}
+
+// FileChangeEvent is sent for every change to a file. The fields of
+// other operations are omitted, so Owner and Group are only set for
+// FileOwnerChanged, where -1 is unchanged.
+type FileChangeEvent struct {
+	CPU         int      `json:"CPU"`
+	EventName   string   `json:"Name"`
+	PID         uint     `json:"PID"`
+	PPID        uint     `json:"PPID"`
+	UID         uint     `json:"UID"`
+	Comm        string   `json:"Comm"`
+	Filename    string   `json:"Filename"`
+	NewFilename string   `json:"NewFilename,omitempty"`
+	Mode        string   `json:"Mode,omitempty"`
+	Permissions string   `json:"Permissions,omitempty"`
+	Owner       *int     `json:"Owner,omitempty"`
+	OwnerName   string   `json:"OwnerName,omitempty"`
+	Group       *int     `json:"Group,omitempty"`
+	GroupName   string   `json:"GroupName,omitempty"`
+	Length      *int64   `json:"Length,omitempty"`
+	FlagNames   []string `json:"FlagNames,omitempty"`
+	Error       string   `json:"Error,omitempty"`
+	ContainerMetadata
+	EventTime
+}
+
+func NewFileChangeEvent(name string, cpu int, data *file_change_data_t) *FileChangeEvent {
+	e := &FileChangeEvent{
+		CPU:               cpu,
+		EventName:         name,
+		PID:               uint(data.Pid),
+		PPID:              uint(data.Ppid),
+		UID:               uint(data.Uid),
+		Comm:              BytesToString32(data.Comm),
+		Filename:          cleanFilePath(BytesToString(data.Filename[:])),
+		ContainerMetadata: containerMetadataForPID(int(data.Pid)),
+	}
+	switch name {
+	case EventNameFileDeleted:
+		e.FlagNames = atFlagNames(int(data.Flags))
+	case EventNameFileRenamed:
+		e.NewFilename = cleanFilePath(BytesToString(data.Newname[:]))
+		e.FlagNames = renameFlagNames(int(data.Flags))
+	case EventNameFileModeChanged:
+		e.Mode = fmt.Sprintf("%04o", data.Mode&07777)
+		e.Permissions = PermissionString(data.Mode)
+	case EventNameFileOwnerChanged:
+		owner, group := int(int32(data.Owner)), int(int32(data.Group))
+		e.Owner, e.Group = &owner, &group
+		if owner >= 0 {
+			if u, err := user.LookupId(strconv.Itoa(owner)); err == nil {
+				e.OwnerName = u.Username
+			}
+		}
+		if group >= 0 {
+			if g, err := user.LookupGroupId(strconv.Itoa(group)); err == nil {
+				e.GroupName = g.Name
+			}
+		}
+		e.FlagNames = atFlagNames(int(data.Flags))
+	case EventNameFileTruncated:
+		length := data.Length
+		e.Length = &length
+	}
+	if data.Ret < 0 {
+		e.Error = unix.ErrnoName(syscall.Errno(-data.Ret))
+	}
+	return e
+}
+
+func (e *FileChangeEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *FileChangeEvent) String() string {
+	detail := e.Filename
+	switch e.EventName {
+	case EventNameFileRenamed:
+		detail = fmt.Sprintf("%s -> %s", e.Filename, e.NewFilename)
+	case EventNameFileModeChanged:
+		detail = fmt.Sprintf("%s %s (%s)", e.Filename, e.Mode, e.Permissions)
+	case EventNameFileOwnerChanged:
+		detail = fmt.Sprintf("%s %d:%d", e.Filename, *e.Owner, *e.Group)
+	case EventNameFileTruncated:
+		detail = fmt.Sprintf("%s %d bytes", e.Filename, *e.Length)
+	}
+	if e.Error != "" {
+		detail = fmt.Sprintf("%s: %s", detail, e.Error)
+	}
+	return fmt.Sprintf("[%s] (%d) %s: %s", e.Comm, e.PID, e.EventName, detail)
+}
+
+func (e *FileChangeEvent) Name() string {
+	return e.EventName
+}
+
+// PermissionString will format a mode like ls, with the setuid,
+// setgid and sticky bits (rwsr-xr-x).
+func PermissionString(mode uint32) string {
+	perm := []byte("rwxrwxrwx")
+	for i := range perm {
+		if mode&(1<<uint(8-i)) == 0 {
+			perm[i] = '-'
+		}
+	}
+	special := []struct {
+		bit   uint32
+		index int
+		set   byte
+	}{
+		{unix.S_ISUID, 2, 's'},
+		{unix.S_ISGID, 5, 's'},
+		{unix.S_ISVTX, 8, 't'},
+	}
+	for _, s := range special {
+		if mode&s.bit == 0 {
+			continue
+		}
+		if perm[s.index] == '-' {
+			perm[s.index] = s.set - 'a' + 'A'
+			continue
+		}
+		perm[s.index] = s.set
+	}
+	return string(perm)
+}
+
+// atFlagNames will decode the flags of unlinkat() and fchownat().
+func atFlagNames(flags int) []string {
+	return flagNames(flags, []flagName{
+		{unix.AT_REMOVEDIR, "AT_REMOVEDIR"},
+		{unix.AT_SYMLINK_NOFOLLOW, "AT_SYMLINK_NOFOLLOW"},
+		{unix.AT_EMPTY_PATH, "AT_EMPTY_PATH"},
+	})
+}
+
+// renameFlagNames will decode the flags of renameat2().
+func renameFlagNames(flags int) []string {
+	return flagNames(flags, []flagName{
+		{unix.RENAME_NOREPLACE, "RENAME_NOREPLACE"},
+		{unix.RENAME_EXCHANGE, "RENAME_EXCHANGE"},
+		{unix.RENAME_WHITEOUT, "RENAME_WHITEOUT"},
+	})
+}
+
+type flagName struct {
+	flag int
+	name string
+}
+
+// flagNames will decode flags into their names, in order. Unknown
+// bits are in hex.
+func flagNames(flags int, names []flagName) []string {
+	var decoded []string
+	for _, f := range names {
+		if f.flag == 0 || flags&f.flag != f.flag {
+			continue
+		}
+		decoded = append(decoded, f.name)
+		flags &^= f.flag
+	}
+	if flags != 0 {
+		decoded = append(decoded, fmt.Sprintf("0x%x", flags))
+	}
+	return decoded
+}
+
+type DropFileChange func(d *file_change_data_t) bool
+
+// DropFileChangeComm will drop every change by a process with this comm.
+func DropFileChangeComm(comm string) DropFileChange {
+	return func(d *file_change_data_t) bool {
+		return BytesToString32(d.Comm) == comm
+	}
+}
@@ -0,0 +1,193 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall"
+
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	EventNameProcessForked = "ProcessForked"
+	EventNameProcessExec   = "ProcessExec"
+	EventNameProcessExited = "ProcessExited"
+)
+
+// LifecycleObservationPoint will observe every process that is
+// forked, every successful exec() and every process that exits.
+//
+// Forks are followed with task/task_newtask, where new threads are
+// dropped in the kernel by their clone flags.
+//
+// Unlike ProcessExecuted which is sent on every call to execve(),
+// ProcessExec is only sent once the new executable has replaced
+// the process.
+type LifecycleObservationPoint struct {
+	reference   ObservationReference
+	dropFilters []DropLifecycle
+}
+
+func (p *LifecycleObservationPoint) Event(record perf.Record) error {
+	data, err := EventLifecycle(record)
+	if err != nil {
+		return err
+	}
+
+	for _, drop := range p.dropFilters {
+		if drop(data) {
+			return nil
+		}
+	}
+
+	var name string
+	switch data.Type {
+	case EventTypeFork:
+		name = EventNameProcessForked
+	case EventTypeExec:
+		name = EventNameProcessExec
+	case EventTypeExit:
+		name = EventNameProcessExited
+	}
+	p.reference.eventCh <- NewLifecycleEvent(name, record.CPU, data)
+	return nil
+}
+
+func (p *LifecycleObservationPoint) Tracepoints() map[string]TracepointData {
+	return map[string]TracepointData{
+		"task_newtask": {
+			Group:      BPFGroupTask,
+			Tracepoint: "task_newtask",
+			Program:    p.reference.probe.TaskNewtask,
+		},
+		"sched_process_exec": {
+			Group:      BPFGroupSched,
+			Tracepoint: "sched_process_exec",
+			Program:    p.reference.probe.SchedProcessExec,
+		},
+		"sched_process_exit": {
+			Group:      BPFGroupSched,
+			Tracepoint: "sched_process_exit",
+			Program:    p.reference.probe.SchedProcessExit,
+		},
+	}
+}
+
+func (p *LifecycleObservationPoint) SetReference(reference ObservationReference) {
+	p.reference = reference
+}
+
+func NewLifecycleObservationPoint(dropFilters []DropLifecycle) *LifecycleObservationPoint {
+	return &LifecycleObservationPoint{
+		dropFilters: dropFilters,
+	}
+}
+
+// LifecycleEvent is a process that was forked, replaced with exec()
+// or exited. For ProcessForked the PID is the parent, and ChildPID
+// is the new process.
+type LifecycleEvent struct {
+	CPU        int    `json:"CPU"`
+	EventName  string `json:"Name"`
+	data       *process_data_t
+	PID        uint   `json:"PID"`
+	PPID       uint   `json:"PPID"`
+	ChildPID   uint   `json:"ChildPID,omitempty"`
+	UID        uint   `json:"UID"`
+	Comm       string `json:"Comm"`
+	Filename   string `json:"Filename,omitempty"`
+	ExitCode   int    `json:"ExitCode"`
+	ExitSignal string `json:"ExitSignal,omitempty"`
+	ContainerMetadata
+	EventTime
+}
+
+func NewLifecycleEvent(name string, cpu int, data *process_data_t) *LifecycleEvent {
+
+	// The cgroup of a new child is inherited from the parent,
+	// so both look up the same container.
+	pid := int(data.Pid)
+	if data.Type == EventTypeFork {
+		pid = int(data.Child_pid)
+	}
+
+	e := &LifecycleEvent{
+		CPU:               cpu,
+		EventName:         name,
+		data:              data,
+		PID:               uint(data.Pid),
+		PPID:              uint(data.Ppid),
+		ChildPID:          uint(data.Child_pid),
+		UID:               uint(data.Uid),
+		Comm:              BytesToString(data.Comm[:]),
+		Filename:          BytesToString(data.Filename[:]),
+		ContainerMetadata: containerMetadataForPID(pid),
+	}
+	if data.Type == EventTypeExit {
+		// The exit code is encoded the same way as wait() status.
+		status := syscall.WaitStatus(data.Exit_code)
+		if status.Signaled() {
+			e.ExitSignal = unix.SignalName(status.Signal())
+		} else {
+			e.ExitCode = status.ExitStatus()
+		}
+	}
+	return e
+}
+
+func (e *LifecycleEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *LifecycleEvent) String() string {
+	switch e.data.Type {
+	case EventTypeFork:
+		return fmt.Sprintf("[%s] (%d) (CPU: %d): fork %d", e.Comm, e.PID, e.CPU, e.ChildPID)
+	case EventTypeExit:
+		return fmt.Sprintf("[%s] (%d) (CPU: %d): exit %d", e.Comm, e.PID, e.CPU, e.ExitCode)
+	}
+	return fmt.Sprintf("[%s] (%d) (CPU: %d): %s", e.Comm, e.PID, e.CPU, e.Filename)
+}
+
+func (e *LifecycleEvent) Name() string {
+	return e.EventName
+}
+
+type DropLifecycle func(d *process_data_t) bool
+
+// DropLifecycleKernelThreads will drop events from kernel threads,
+// which are all children of kthreadd (PID 2).
+func DropLifecycleKernelThreads(d *process_data_t) bool {
+	return d.Pid == 2 || d.Ppid == 2
+}
+
+// SelectLifecycleType will drop every event that is not of this type,
+// such as EventTypeFork.
+func SelectLifecycleType(eventType uint32) DropLifecycle {
+	return func(d *process_data_t) bool {
+		return d.Type != eventType
+	}
+}
@@ -0,0 +1,110 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// testLifecycleRecord will encode process data as a perf record.
+func testLifecycleRecord(t *testing.T, data process_data_t) perf.Record {
+	b := &bytes.Buffer{}
+	err := binary.Write(b, binary.LittleEndian, &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return perf.Record{RawSample: b.Bytes()}
+}
+
+func TestLifecycleObservationPointEvent(t *testing.T) {
+	// The children are never running, like a child that has
+	// already exited when the record is read.
+	tests := []struct {
+		data process_data_t
+		name string
+	}{
+		{process_data_t{Type: EventTypeFork, Pid: 42, Child_pid: 0x3ffffff0}, EventNameProcessForked},
+		{process_data_t{Type: EventTypeExec, Pid: 0x3ffffff0}, EventNameProcessExec},
+		{process_data_t{Type: EventTypeExit, Pid: 0x3ffffff0, Exit_code: 256}, EventNameProcessExited},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := NewLifecycleObservationPoint(nil)
+			p.SetReference(ObservationReference{eventCh: make(chan Event, 1)})
+			err := p.Event(testLifecycleRecord(t, test.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case event := <-p.reference.eventCh:
+				if event.Name() != test.name {
+					t.Errorf("expected %s, got %s", test.name, event.Name())
+				}
+			default:
+				t.Fatalf("expected %s, got no event", test.name)
+			}
+		})
+	}
+}
+
+func TestLifecycleForkRates(t *testing.T) {
+	tracker, err := NewRateTracker(ProfileDefaultRates())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := ProfileRates()[EventNameProcessForked].(*LifecycleObservationPoint)
+	p.SetReference(ObservationReference{eventCh: make(chan Event, 1)})
+
+	// A burst of children that exit before their fork is read,
+	// so none of them can be found in /proc.
+	var exceeded []int
+	for i := 0; i < 600; i++ {
+		child := uint32(0x3ffff000 + i)
+		for _, data := range []process_data_t{
+			{Type: EventTypeFork, Pid: 42, Tid: 42, Ppid: 1, Child_pid: child, Uid: 1000},
+			{Type: EventTypeExit, Pid: child, Tid: child, Ppid: 42, Uid: 1000},
+		} {
+			err := p.Event(testLifecycleRecord(t, data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case event := <-p.reference.eventCh:
+				for _, e := range tracker.Produce(event) {
+					rate := e.(*RateExceededEvent)
+					if rate.Rate == "fork_per_parent" && rate.Value == "42" {
+						exceeded = append(exceeded, i)
+					}
+				}
+			default:
+			}
+		}
+	}
+	if len(exceeded) == 0 || exceeded[0] != 500 {
+		t.Errorf("expected fork_per_parent exceeded at fork 500, got %v", exceeded)
+	}
+}
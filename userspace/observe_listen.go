@@ -0,0 +1,445 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kris-nova/logger"
+
+	"github.com/kris-nova/double-slit-experiment/system"
+
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	EventNameListenerOpened = "ListenerOpened"
+	EventNameListenerClosed = "ListenerClosed"
+	EventNameListenerFound  = "ListenerFound"
+
+	// DefaultListenerScanInterval is how often the listeners are checked
+	// in /proc, to find the UDP listeners that were closed.
+	DefaultListenerScanInterval = 30 * time.Second
+)
+
+// sockOpClose is SOCK_OP_CLOSE in bpf.c
+const sockOpClose = 6
+
+// listenerKey is a listener, which is unique in its network namespace.
+type listenerKey struct {
+	NetNS    uint64
+	Protocol string
+	Addr     string
+	Port     uint
+}
+
+// ListenObservationPoint will keep an inventory of every TCP and UDP
+// port that is listening, in every network namespace.
+//
+//   ListenerFound   a listener that was open when the point was loaded
+//   ListenerOpened  listen() of a TCP socket, or bind() of a UDP socket to a port
+//   ListenerClosed  a listener was closed
+//
+// The inventory is found in /proc when the point is loaded. A closed
+// TCP listener is seen in the kernel, and UDP has no state to see, so
+// the inventory is checked in /proc every interval for the UDP
+// listeners that were closed.
+type ListenObservationPoint struct {
+	reference   ObservationReference
+	dropFilters []DropListener
+	interval    time.Duration
+
+	mtx       sync.Mutex
+	listeners map[listenerKey]*listener
+}
+
+// listener is a listener in the inventory, and when it was added.
+type listener struct {
+	event *ListenerEvent
+	added time.Time
+}
+
+// Load will find the listeners that are already open, and then check
+// the inventory every interval.
+func (p *ListenObservationPoint) Load() error {
+	go func() {
+		p.scan()
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			p.rescan()
+		}
+	}()
+	return nil
+}
+
+// scan will send a ListenerFound for every listener in /proc that is
+// not in the inventory.
+func (p *ListenObservationPoint) scan() {
+	namespaces, err := system.ProcNetNamespaces()
+	if err != nil {
+		logger.Warning("Unable to find network namespaces: %v", err)
+		return
+	}
+	owners, err := system.ProcSocketOwners()
+	if err != nil {
+		logger.Warning("Unable to find socket owners: %v", err)
+		return
+	}
+	for netns, pid := range namespaces {
+		sockets, err := system.ProcNetSockets(pid)
+		if err != nil {
+			logger.Debug("Unable to read sockets of network namespace %d: %v", netns, err)
+			continue
+		}
+		for _, socket := range sockets {
+			if !socket.Listening() {
+				continue
+			}
+			e := NewListenerFoundEvent(netns, socket, owners[socket.Inode])
+			if p.drop(e) || !p.add(e) {
+				continue
+			}
+			p.reference.eventCh <- e
+		}
+	}
+}
+
+// rescan will send a ListenerClosed for every listener in the
+// inventory that is no longer in /proc. A listener added after the
+// scan started might be missing from the namespaces that were
+// already read, and is checked on the next scan.
+func (p *ListenObservationPoint) rescan() {
+	start := time.Now()
+	namespaces, err := system.ProcNetNamespaces()
+	if err != nil {
+		logger.Warning("Unable to find network namespaces: %v", err)
+		return
+	}
+	open := make(map[listenerKey]bool)
+	for netns, pid := range namespaces {
+		sockets, err := system.ProcNetSockets(pid)
+		if err != nil {
+
+			// Deliberate design: A namespace we can't read keeps its
+			// listeners, as they are likely still open.
+			logger.Debug("Unable to read sockets of network namespace %d: %v", netns, err)
+			p.mtx.Lock()
+			for key := range p.listeners {
+				if key.NetNS == netns {
+					open[key] = true
+				}
+			}
+			p.mtx.Unlock()
+			continue
+		}
+		for _, socket := range sockets {
+			if socket.Listening() {
+				open[newListenerKey(netns, socketAddr(socket.IPv6, socket.LocalAddr), socket.Protocol, uint(socket.LocalPort))] = true
+			}
+		}
+	}
+	var closed []*ListenerEvent
+	p.mtx.Lock()
+	for key, l := range p.listeners {
+		if open[key] || !l.added.Before(start) {
+			continue
+		}
+		delete(p.listeners, key)
+		closed = append(closed, l.event.closed())
+	}
+	p.mtx.Unlock()
+	for _, e := range closed {
+		p.reference.eventCh <- e
+	}
+}
+
+// add will add a listener to the inventory, and returns false if it
+// was already in the inventory.
+func (p *ListenObservationPoint) add(e *ListenerEvent) bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	key := e.key()
+	if _, ok := p.listeners[key]; ok {
+		return false
+	}
+	p.listeners[key] = &listener{
+		event: e,
+		added: time.Now(),
+	}
+	return true
+}
+
+// remove will remove a listener from the inventory.
+func (p *ListenObservationPoint) remove(e *ListenerEvent) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	delete(p.listeners, e.key())
+}
+
+func (p *ListenObservationPoint) drop(e *ListenerEvent) bool {
+	for _, drop := range p.dropFilters {
+		if drop(e) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ListenObservationPoint) Event(record perf.Record) error {
+	data, err := EventListen(record)
+	if err != nil {
+		return err
+	}
+
+	e := NewListenerEvent(record.CPU, data)
+	if p.drop(e) {
+		return nil
+	}
+	switch {
+	case e.EventName == EventNameListenerClosed:
+		p.remove(e)
+	case e.Error == "":
+		// A port listened on again, such as with SO_REUSEPORT, replaces
+		// the listener in the inventory.
+		p.remove(e)
+		p.add(e)
+	}
+
+	p.reference.eventCh <- e
+	return nil
+}
+
+func (p *ListenObservationPoint) Tracepoints() map[string]TracepointData {
+	probe := p.reference.probe
+	return map[string]TracepointData{
+		"sys_enter_bind": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_enter_bind",
+			Program:    probe.EnterBind,
+		},
+		"sys_exit_bind": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_exit_bind",
+			Program:    probe.ExitBind,
+		},
+		"sys_enter_listen": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_enter_listen",
+			Program:    probe.EnterListen,
+		},
+		"sys_exit_listen": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_exit_listen",
+			Program:    probe.ExitListenSyscall,
+		},
+		"inet_sock_listen_close": {
+			Group:      BPFGroupSock,
+			Tracepoint: "inet_sock_set_state",
+			Program:    probe.InetSockListenClose,
+		},
+	}
+}
+
+func (p *ListenObservationPoint) SetReference(reference ObservationReference) {
+	p.reference = reference
+}
+
+// NewListenObservationPoint will keep an inventory of the listeners,
+// and check it every interval. An interval of 0 is
+// DefaultListenerScanInterval.
+func NewListenObservationPoint(interval time.Duration, dropFilters []DropListener) *ListenObservationPoint {
+	if interval <= 0 {
+		interval = DefaultListenerScanInterval
+	}
+	return &ListenObservationPoint{
+		dropFilters: dropFilters,
+		interval:    interval,
+		listeners:   make(map[listenerKey]*listener),
+	}
+}
+
+// ListenerEvent is a TCP or UDP port that a process is listening on.
+// NetNS is the network namespace of the listener, as the same port can
+// be listening in every container.
+//
+// Syscall and Backlog are only known for a listener that is opened
+// while the point is loaded. A ListenerFound or a ListenerClosed of a
+// UDP listener is the process that owns the socket, found in /proc.
+type ListenerEvent struct {
+	CPU       int    `json:"CPU"`
+	EventName string `json:"Name"`
+	Syscall   string `json:"Syscall,omitempty"`
+	PID       uint   `json:"PID"`
+	PPID      uint   `json:"PPID,omitempty"`
+	UID       uint   `json:"UID"`
+	Comm      string `json:"Comm"`
+	FD        int    `json:"FD,omitempty"`
+	Family    string `json:"Family"`
+	Protocol  string `json:"Protocol"`
+	Addr      string `json:"Addr"`
+	Port      uint   `json:"Port"`
+	Backlog   int    `json:"Backlog,omitempty"`
+	NetNS     uint64 `json:"NetNS"`
+	Error     string `json:"Error,omitempty"`
+	ContainerMetadata
+	EventTime
+}
+
+func NewListenerEvent(cpu int, data *sock_call_data_t) *ListenerEvent {
+	e := &ListenerEvent{
+		CPU:               cpu,
+		EventName:         EventNameListenerOpened,
+		Syscall:           sockCallSyscalls[data.Op],
+		PID:               uint(data.Pid),
+		PPID:              uint(data.Ppid),
+		UID:               uint(data.Uid),
+		Comm:              BytesToString32(data.Comm),
+		FD:                int(data.Fd),
+		Family:            socketFamilies[data.Family],
+		Protocol:          SocketProtocolName(data.Family, data.Sock_type),
+		Addr:              inetAddr(data.Family, data.Laddr),
+		Port:              uint(data.Lport),
+		NetNS:             uint64(data.Netns),
+		ContainerMetadata: containerMetadataForPID(int(data.Pid)),
+	}
+	switch {
+	case data.Op == sockOpClose:
+		e.EventName = EventNameListenerClosed
+		e.FD = 0
+	case e.Syscall == "listen":
+		e.Backlog = int(int32(data.Flags))
+	}
+	if data.Ret < 0 {
+		e.Error = unix.ErrnoName(syscall.Errno(-data.Ret))
+	}
+	return e
+}
+
+// NewListenerFoundEvent is a listener found in /proc, with the process
+// that owns it, which is 0 if it was not found.
+func NewListenerFoundEvent(netns uint64, socket *system.Socket, pid int) *ListenerEvent {
+	e := &ListenerEvent{
+		EventName: EventNameListenerFound,
+		PID:       uint(pid),
+		UID:       uint(socket.UID),
+		Family:    "AF_INET",
+		Protocol:  socket.Protocol,
+		Addr:      socketAddr(socket.IPv6, socket.LocalAddr),
+		Port:      uint(socket.LocalPort),
+		NetNS:     netns,
+	}
+	if socket.IPv6 {
+		e.Family = "AF_INET6"
+	}
+	if pid == 0 {
+		return e
+	}
+
+	// Deliberate design: We ignore errors if we can't lookup the
+	// process. There is a non-zero chance the process has terminated.
+	comm, err := system.ProcComm(pid)
+	if err != nil {
+		logger.Debug(err.Error())
+	}
+	e.Comm = comm
+	e.ContainerMetadata = containerMetadataForPID(pid)
+	return e
+}
+
+// closed will return a ListenerClosed of a listener in the inventory.
+func (e *ListenerEvent) closed() *ListenerEvent {
+	return &ListenerEvent{
+		EventName: EventNameListenerClosed,
+		PID:       e.PID,
+		UID:       e.UID,
+		Comm:      e.Comm,
+		Family:    e.Family,
+		Protocol:  e.Protocol,
+		Addr:      e.Addr,
+		Port:      e.Port,
+		NetNS:     e.NetNS,
+		ContainerMetadata: ContainerMetadata{
+			ContainerID: e.ContainerID,
+		},
+	}
+}
+
+func (e *ListenerEvent) key() listenerKey {
+	return newListenerKey(e.NetNS, e.Addr, e.Protocol, e.Port)
+}
+
+func newListenerKey(netns uint64, addr, protocol string, port uint) listenerKey {
+	return listenerKey{
+		NetNS:    netns,
+		Protocol: protocol,
+		Addr:     addr,
+		Port:     port,
+	}
+}
+
+func (e *ListenerEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *ListenerEvent) String() string {
+	detail := fmt.Sprintf("%s %s", e.Protocol, net.JoinHostPort(e.Addr, fmt.Sprintf("%d", e.Port)))
+	if e.Backlog != 0 {
+		detail = fmt.Sprintf("%s backlog %d", detail, e.Backlog)
+	}
+	if e.Error != "" {
+		detail = fmt.Sprintf("%s: %s", detail, e.Error)
+	}
+	return fmt.Sprintf("[%s] (%d) %s: %s", e.Comm, e.PID, e.EventName, detail)
+}
+
+func (e *ListenerEvent) Name() string {
+	return e.EventName
+}
+
+// socketAddr will return an address from /proc as the probe would.
+func socketAddr(ipv6 bool, ip net.IP) string {
+	var addr [16]byte
+	copy(addr[:], ip)
+	if ipv6 {
+		return inetAddr(unix.AF_INET6, addr)
+	}
+	return inetAddr(unix.AF_INET, addr)
+}
+
+// DropListener will drop a listener, including the listeners found in
+// /proc, before it is added to the inventory.
+type DropListener func(e *ListenerEvent) bool
+
+// DropListenerLoopback will drop every listener on a loopback address,
+// which is not reachable from another host.
+func DropListenerLoopback(e *ListenerEvent) bool {
+	ip := net.ParseIP(e.Addr)
+	return ip != nil && ip.IsLoopback()
+}
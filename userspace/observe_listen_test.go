@@ -0,0 +1,92 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kris-nova/double-slit-experiment/system"
+)
+
+func TestListenObservationPointRescan(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer conn.Close()
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	netns, err := system.ProcNamespace(os.Getpid(), "net")
+	if err != nil {
+		t.Skipf("unable to read network namespace: %v", err)
+	}
+	sockets, err := system.ProcNetSockets(os.Getpid())
+	if err != nil {
+		t.Skipf("unable to read sockets: %v", err)
+	}
+	var open *ListenerEvent
+	for _, socket := range sockets {
+		if socket.Protocol == "udp" && socket.LocalPort == port {
+			open = NewListenerFoundEvent(netns, socket, os.Getpid())
+		}
+	}
+	if open == nil {
+		t.Fatalf("unable to find UDP port %d", port)
+	}
+
+	// Network namespace 1 is never a real namespace, so these are closed
+	closed := &ListenerEvent{EventName: EventNameListenerOpened, Protocol: "udp", Addr: "127.0.0.1", Port: 5353, NetNS: 1}
+	added := &ListenerEvent{EventName: EventNameListenerOpened, Protocol: "udp", Addr: "127.0.0.1", Port: 5354, NetNS: 1}
+
+	p := NewListenObservationPoint(time.Minute, nil)
+	p.SetReference(ObservationReference{eventCh: make(chan Event, 8)})
+	p.add(open)
+	p.add(closed)
+	p.add(added)
+
+	// Added while the scan was reading /proc, such as by Event
+	p.listeners[added.key()].added = time.Now().Add(time.Hour)
+	p.rescan()
+
+	var events []Event
+	for len(p.reference.eventCh) > 0 {
+		events = append(events, <-p.reference.eventCh)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	e := events[0].(*ListenerEvent)
+	if e.EventName != EventNameListenerClosed || e.key() != closed.key() {
+		t.Errorf("expected %s of port %d, got %s of port %d", EventNameListenerClosed, closed.Port, e.EventName, e.Port)
+	}
+	for _, l := range []*ListenerEvent{open, added} {
+		if _, ok := p.listeners[l.key()]; !ok {
+			t.Errorf("expected port %d in the inventory", l.Port)
+		}
+	}
+	if _, ok := p.listeners[closed.key()]; ok {
+		t.Errorf("expected port %d to be removed from the inventory", closed.Port)
+	}
+}
@@ -0,0 +1,556 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	EventNameModuleLoaded       = "ModuleLoaded"
+	EventNameModuleUnloaded     = "ModuleUnloaded"
+	EventNameBPFProgramLoaded   = "BPFProgramLoaded"
+	EventNameBPFProgramAttached = "BPFProgramAttached"
+	EventNameBPFProgramDetached = "BPFProgramDetached"
+)
+
+// moduleSyscalls are the syscalls of the MODULE_OP_* operations in bpf.c
+var moduleSyscalls = map[uint32]string{
+	1: "init_module",
+	2: "finit_module",
+	3: "delete_module",
+}
+
+// Commands of the bpf() syscall, from include/uapi/linux/bpf.h
+const (
+	bpfCmdProgLoad          = 5
+	bpfCmdProgAttach        = 8
+	bpfCmdProgDetach        = 9
+	bpfCmdRawTracepointOpen = 17
+	bpfCmdLinkCreate        = 28
+)
+
+var bpfCommands = map[uint32]string{
+	bpfCmdProgLoad:          "BPF_PROG_LOAD",
+	bpfCmdProgAttach:        "BPF_PROG_ATTACH",
+	bpfCmdProgDetach:        "BPF_PROG_DETACH",
+	bpfCmdRawTracepointOpen: "BPF_RAW_TRACEPOINT_OPEN",
+	bpfCmdLinkCreate:        "BPF_LINK_CREATE",
+}
+
+// Flags of finit_module(), from include/uapi/linux/module.h
+const (
+	moduleInitIgnoreModversions = 0x1
+	moduleInitIgnoreVermagic    = 0x2
+	moduleInitCompressedFile    = 0x4
+)
+
+// loadSelf will exclude the modules and BPF programs that
+// dse itself loads, by PID.
+func loadSelf(reference ObservationReference) error {
+	var zero uint32 = 0
+	self := uint32(os.Getpid())
+	err := reference.probe.LoadConfig.Put(&zero, &self)
+	if err != nil {
+		return fmt.Errorf("unable to exclude pid %d: %v", self, err)
+	}
+	return nil
+}
+
+// ModuleObservationPoint will observe every kernel module that is
+// loaded or unloaded.
+//
+//   ModuleLoaded    init_module(), finit_module()
+//   ModuleUnloaded  delete_module()
+//
+// The name of a loaded module is read from the module_load tracepoint,
+// so a module that fails to load might not have a name.
+type ModuleObservationPoint struct {
+	reference   ObservationReference
+	dropFilters []DropModule
+}
+
+// Load will exclude dse itself.
+func (p *ModuleObservationPoint) Load() error {
+	return loadSelf(p.reference)
+}
+
+func (p *ModuleObservationPoint) Event(record perf.Record) error {
+	data, err := EventModule(record)
+	if err != nil {
+		return err
+	}
+
+	for _, drop := range p.dropFilters {
+		if drop(data) {
+			return nil
+		}
+	}
+
+	p.reference.eventCh <- NewModuleEvent(record.CPU, data)
+	return nil
+}
+
+func (p *ModuleObservationPoint) Tracepoints() map[string]TracepointData {
+	probe := p.reference.probe
+	tracepoints := map[string]TracepointData{
+		"sys_enter_init_module": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_enter_init_module",
+			Program:    probe.EnterInitModule,
+		},
+		"sys_exit_init_module": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_exit_init_module",
+			Program:    probe.ExitInitModule,
+		},
+		"sys_enter_finit_module": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_enter_finit_module",
+			Program:    probe.EnterFinitModule,
+		},
+		"sys_exit_finit_module": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_exit_finit_module",
+			Program:    probe.ExitFinitModule,
+		},
+		"sys_enter_delete_module": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_enter_delete_module",
+			Program:    probe.EnterDeleteModule,
+		},
+		"sys_exit_delete_module": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_exit_delete_module",
+			Program:    probe.ExitDeleteModule,
+		},
+	}
+
+	// Without module_load, loaded modules are sent without a name
+	if TracepointExists(BPFGroupModule, "module_load") {
+		tracepoints["module_load"] = TracepointData{
+			Group:      BPFGroupModule,
+			Tracepoint: "module_load",
+			Program:    probe.ModuleLoad,
+		}
+	}
+	return tracepoints
+}
+
+func (p *ModuleObservationPoint) SetReference(reference ObservationReference) {
+	p.reference = reference
+}
+
+func NewModuleObservationPoint(dropFilters []DropModule) *ModuleObservationPoint {
+	return &ModuleObservationPoint{
+		dropFilters: dropFilters,
+	}
+}
+
+// ModuleEvent is sent for every module syscall. Length is the size
+// of the image passed to init_module(), and Filename is the file
+// passed to finit_module().
+type ModuleEvent struct {
+	CPU        int      `json:"CPU"`
+	EventName  string   `json:"Name"`
+	Syscall    string   `json:"Syscall"`
+	PID        uint     `json:"PID"`
+	PPID       uint     `json:"PPID"`
+	UID        uint     `json:"UID"`
+	Comm       string   `json:"Comm"`
+	Module     string   `json:"Module,omitempty"`
+	Filename   string   `json:"Filename,omitempty"`
+	Parameters string   `json:"Parameters,omitempty"`
+	Length     uint64   `json:"Length,omitempty"`
+	Flags      uint     `json:"Flags"`
+	FlagNames  []string `json:"FlagNames,omitempty"`
+	Taints     []string `json:"Taints,omitempty"`
+	Error      string   `json:"Error,omitempty"`
+	ContainerMetadata
+	EventTime
+}
+
+func NewModuleEvent(cpu int, data *module_data_t) *ModuleEvent {
+	e := &ModuleEvent{
+		CPU:               cpu,
+		EventName:         EventNameModuleLoaded,
+		Syscall:           moduleSyscalls[data.Op],
+		PID:               uint(data.Pid),
+		PPID:              uint(data.Ppid),
+		UID:               uint(data.Uid),
+		Comm:              BytesToString32(data.Comm),
+		Module:            BytesToString(data.Name[:]),
+		Filename:          cleanFilePath(BytesToString(data.Filename[:])),
+		Parameters:        BytesToString(data.Params[:]),
+		Length:            data.Len,
+		Flags:             uint(data.Flags),
+		Taints:            flagNames(int(data.Taints), moduleTaints),
+		ContainerMetadata: containerMetadataForPID(int(data.Pid)),
+	}
+	switch e.Syscall {
+	case "finit_module":
+		e.FlagNames = flagNames(int(data.Flags), finitModuleFlags)
+	case "delete_module":
+		e.EventName = EventNameModuleUnloaded
+		e.FlagNames = flagNames(int(data.Flags), deleteModuleFlags)
+	}
+	if data.Ret < 0 {
+		e.Error = unix.ErrnoName(syscall.Errno(-data.Ret))
+	}
+	return e
+}
+
+func (e *ModuleEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *ModuleEvent) String() string {
+	detail := e.Module
+	if e.Filename != "" {
+		detail = fmt.Sprintf("%s %s", detail, e.Filename)
+	}
+	if e.Error != "" {
+		detail = fmt.Sprintf("%s: %s", detail, e.Error)
+	}
+	return fmt.Sprintf("[%s] (%d) %s: %s %v", e.Comm, e.PID, e.Syscall, detail, e.FlagNames)
+}
+
+func (e *ModuleEvent) Name() string {
+	return e.EventName
+}
+
+// Severity is high for a module that was loaded, and medium otherwise.
+func (e *ModuleEvent) Severity() int {
+	if e.EventName == EventNameModuleLoaded && e.Error == "" {
+		return RuleSeverities["high"]
+	}
+	return RuleSeverities["medium"]
+}
+
+var finitModuleFlags = []flagName{
+	{moduleInitIgnoreModversions, "MODULE_INIT_IGNORE_MODVERSIONS"},
+	{moduleInitIgnoreVermagic, "MODULE_INIT_IGNORE_VERMAGIC"},
+	{moduleInitCompressedFile, "MODULE_INIT_COMPRESSED_FILE"},
+}
+
+var deleteModuleFlags = []flagName{
+	{unix.O_NONBLOCK, "O_NONBLOCK"},
+	{unix.O_TRUNC, "O_TRUNC"},
+}
+
+// moduleTaints are the taints a module can set, by the bit of the
+// TAINT_* flag in include/linux/panic.h
+var moduleTaints = []flagName{
+	{1 << 0, "TAINT_PROPRIETARY_MODULE"},
+	{1 << 1, "TAINT_FORCED_MODULE"},
+	{1 << 10, "TAINT_CRAP"},
+	{1 << 12, "TAINT_OOT_MODULE"},
+	{1 << 13, "TAINT_UNSIGNED_MODULE"},
+	{1 << 15, "TAINT_LIVEPATCH"},
+	{1 << 18, "TAINT_TEST"},
+}
+
+type DropModule func(d *module_data_t) bool
+
+// DropModuleFailed will drop every module syscall that returned an error.
+func DropModuleFailed(d *module_data_t) bool {
+	return d.Ret < 0
+}
+
+// BPFObservationPoint will observe every BPF program that is loaded,
+// attached or detached with the bpf() syscall.
+//
+//   BPFProgramLoaded    BPF_PROG_LOAD
+//   BPFProgramAttached  BPF_PROG_ATTACH, BPF_LINK_CREATE, BPF_RAW_TRACEPOINT_OPEN
+//   BPFProgramDetached  BPF_PROG_DETACH
+//
+// Every other command, such as map lookups and updates, is filtered
+// in the kernel.
+type BPFObservationPoint struct {
+	reference   ObservationReference
+	dropFilters []DropBPF
+}
+
+// Load will exclude dse itself.
+func (p *BPFObservationPoint) Load() error {
+	return loadSelf(p.reference)
+}
+
+func (p *BPFObservationPoint) Event(record perf.Record) error {
+	data, err := EventBPF(record)
+	if err != nil {
+		return err
+	}
+
+	for _, drop := range p.dropFilters {
+		if drop(data) {
+			return nil
+		}
+	}
+
+	p.reference.eventCh <- NewBPFEvent(record.CPU, data)
+	return nil
+}
+
+func (p *BPFObservationPoint) Tracepoints() map[string]TracepointData {
+	probe := p.reference.probe
+	return map[string]TracepointData{
+		"sys_enter_bpf": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_enter_bpf",
+			Program:    probe.EnterBpf,
+		},
+		"sys_exit_bpf": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_exit_bpf",
+			Program:    probe.ExitBpf,
+		},
+	}
+}
+
+func (p *BPFObservationPoint) SetReference(reference ObservationReference) {
+	p.reference = reference
+}
+
+func NewBPFObservationPoint(dropFilters []DropBPF) *BPFObservationPoint {
+	return &BPFObservationPoint{
+		dropFilters: dropFilters,
+	}
+}
+
+// BPFEvent is sent for every bpf() syscall that loads, attaches or
+// detaches a program. FD is the program, link or tracepoint file
+// descriptor that was returned.
+type BPFEvent struct {
+	CPU          int    `json:"CPU"`
+	EventName    string `json:"Name"`
+	Command      string `json:"Command"`
+	PID          uint   `json:"PID"`
+	PPID         uint   `json:"PPID"`
+	UID          uint   `json:"UID"`
+	Comm         string `json:"Comm"`
+	ProgramType  string `json:"ProgramType,omitempty"`
+	ProgramName  string `json:"ProgramName,omitempty"`
+	AttachType   string `json:"AttachType,omitempty"`
+	Tracepoint   string `json:"Tracepoint,omitempty"`
+	Instructions uint   `json:"Instructions,omitempty"`
+	ProgramFD    int    `json:"ProgramFD,omitempty"`
+	TargetFD     int    `json:"TargetFD,omitempty"`
+	FD           int    `json:"FD,omitempty"`
+	Error        string `json:"Error,omitempty"`
+	ContainerMetadata
+	EventTime
+}
+
+func NewBPFEvent(cpu int, data *bpf_data_t) *BPFEvent {
+	e := &BPFEvent{
+		CPU:               cpu,
+		EventName:         EventNameBPFProgramAttached,
+		Command:           bpfCommands[data.Cmd],
+		PID:               uint(data.Pid),
+		PPID:              uint(data.Ppid),
+		UID:               uint(data.Uid),
+		Comm:              BytesToString32(data.Comm),
+		ContainerMetadata: containerMetadataForPID(int(data.Pid)),
+	}
+	switch data.Cmd {
+	case bpfCmdProgLoad:
+		e.EventName = EventNameBPFProgramLoaded
+		e.ProgramType = BPFProgramTypeName(data.Prog_type)
+		e.ProgramName = BytesToString(data.Prog_name[:])
+		e.Instructions = uint(data.Insn_cnt)
+
+		// The expected attach type is only set for some program types
+		if data.Attach_type != 0 {
+			e.AttachType = BPFAttachTypeName(data.Attach_type)
+		}
+	case bpfCmdProgAttach, bpfCmdLinkCreate:
+		e.AttachType = BPFAttachTypeName(data.Attach_type)
+		e.ProgramFD = int(data.Prog_fd)
+		e.TargetFD = int(data.Target_fd)
+	case bpfCmdProgDetach:
+		e.EventName = EventNameBPFProgramDetached
+		e.AttachType = BPFAttachTypeName(data.Attach_type)
+		e.ProgramFD = int(data.Prog_fd)
+		e.TargetFD = int(data.Target_fd)
+	case bpfCmdRawTracepointOpen:
+		e.Tracepoint = BytesToString(data.Tracepoint[:])
+		e.ProgramFD = int(data.Prog_fd)
+	}
+	switch {
+	case data.Ret < 0:
+		e.Error = unix.ErrnoName(syscall.Errno(-data.Ret))
+	case data.Cmd != bpfCmdProgAttach && data.Cmd != bpfCmdProgDetach:
+		e.FD = int(data.Ret)
+	}
+	return e
+}
+
+func (e *BPFEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *BPFEvent) String() string {
+	detail := e.ProgramType
+	if e.ProgramName != "" {
+		detail = fmt.Sprintf("%s %s", detail, e.ProgramName)
+	}
+	if e.AttachType != "" {
+		detail = fmt.Sprintf("%s %s", detail, e.AttachType)
+	}
+	if e.Tracepoint != "" {
+		detail = fmt.Sprintf("%s %s", detail, e.Tracepoint)
+	}
+	if e.Error != "" {
+		detail = fmt.Sprintf("%s: %s", detail, e.Error)
+	}
+	return fmt.Sprintf("[%s] (%d) %s: %s", e.Comm, e.PID, e.Command, detail)
+}
+
+func (e *BPFEvent) Name() string {
+	return e.EventName
+}
+
+// Severity is medium for every BPF program.
+func (e *BPFEvent) Severity() int {
+	return RuleSeverities["medium"]
+}
+
+// BPFProgramTypeName will return the name of a BPF program type.
+// Unknown types are named by number.
+func BPFProgramTypeName(progType uint32) string {
+	if int(progType) < len(bpfProgramTypes) {
+		return bpfProgramTypes[progType]
+	}
+	return fmt.Sprintf("BPF_PROG_TYPE_%d", progType)
+}
+
+// BPFAttachTypeName will return the name of a BPF attach type.
+// Unknown types are named by number.
+func BPFAttachTypeName(attachType uint32) string {
+	if int(attachType) < len(bpfAttachTypes) {
+		return bpfAttachTypes[attachType]
+	}
+	return fmt.Sprintf("BPF_ATTACH_TYPE_%d", attachType)
+}
+
+// bpfProgramTypes are the names of enum bpf_prog_type, by value.
+var bpfProgramTypes = []string{
+	"BPF_PROG_TYPE_UNSPEC",
+	"BPF_PROG_TYPE_SOCKET_FILTER",
+	"BPF_PROG_TYPE_KPROBE",
+	"BPF_PROG_TYPE_SCHED_CLS",
+	"BPF_PROG_TYPE_SCHED_ACT",
+	"BPF_PROG_TYPE_TRACEPOINT",
+	"BPF_PROG_TYPE_XDP",
+	"BPF_PROG_TYPE_PERF_EVENT",
+	"BPF_PROG_TYPE_CGROUP_SKB",
+	"BPF_PROG_TYPE_CGROUP_SOCK",
+	"BPF_PROG_TYPE_LWT_IN",
+	"BPF_PROG_TYPE_LWT_OUT",
+	"BPF_PROG_TYPE_LWT_XMIT",
+	"BPF_PROG_TYPE_SOCK_OPS",
+	"BPF_PROG_TYPE_SK_SKB",
+	"BPF_PROG_TYPE_CGROUP_DEVICE",
+	"BPF_PROG_TYPE_SK_MSG",
+	"BPF_PROG_TYPE_RAW_TRACEPOINT",
+	"BPF_PROG_TYPE_CGROUP_SOCK_ADDR",
+	"BPF_PROG_TYPE_LWT_SEG6LOCAL",
+	"BPF_PROG_TYPE_LIRC_MODE2",
+	"BPF_PROG_TYPE_SK_REUSEPORT",
+	"BPF_PROG_TYPE_FLOW_DISSECTOR",
+	"BPF_PROG_TYPE_CGROUP_SYSCTL",
+	"BPF_PROG_TYPE_RAW_TRACEPOINT_WRITABLE",
+	"BPF_PROG_TYPE_CGROUP_SOCKOPT",
+	"BPF_PROG_TYPE_TRACING",
+	"BPF_PROG_TYPE_STRUCT_OPS",
+	"BPF_PROG_TYPE_EXT",
+	"BPF_PROG_TYPE_LSM",
+	"BPF_PROG_TYPE_SK_LOOKUP",
+	"BPF_PROG_TYPE_SYSCALL",
+	"BPF_PROG_TYPE_NETFILTER",
+}
+
+// bpfAttachTypes are the names of enum bpf_attach_type, by value.
+var bpfAttachTypes = []string{
+	"BPF_CGROUP_INET_INGRESS",
+	"BPF_CGROUP_INET_EGRESS",
+	"BPF_CGROUP_INET_SOCK_CREATE",
+	"BPF_CGROUP_SOCK_OPS",
+	"BPF_SK_SKB_STREAM_PARSER",
+	"BPF_SK_SKB_STREAM_VERDICT",
+	"BPF_CGROUP_DEVICE",
+	"BPF_SK_MSG_VERDICT",
+	"BPF_CGROUP_INET4_BIND",
+	"BPF_CGROUP_INET6_BIND",
+	"BPF_CGROUP_INET4_CONNECT",
+	"BPF_CGROUP_INET6_CONNECT",
+	"BPF_CGROUP_INET4_POST_BIND",
+	"BPF_CGROUP_INET6_POST_BIND",
+	"BPF_CGROUP_UDP4_SENDMSG",
+	"BPF_CGROUP_UDP6_SENDMSG",
+	"BPF_LIRC_MODE2",
+	"BPF_FLOW_DISSECTOR",
+	"BPF_CGROUP_SYSCTL",
+	"BPF_CGROUP_UDP4_RECVMSG",
+	"BPF_CGROUP_UDP6_RECVMSG",
+	"BPF_CGROUP_GETSOCKOPT",
+	"BPF_CGROUP_SETSOCKOPT",
+	"BPF_TRACE_RAW_TP",
+	"BPF_TRACE_FENTRY",
+	"BPF_TRACE_FEXIT",
+	"BPF_MODIFY_RETURN",
+	"BPF_LSM_MAC",
+	"BPF_TRACE_ITER",
+	"BPF_CGROUP_INET4_GETPEERNAME",
+	"BPF_CGROUP_INET6_GETPEERNAME",
+	"BPF_CGROUP_INET4_GETSOCKNAME",
+	"BPF_CGROUP_INET6_GETSOCKNAME",
+	"BPF_XDP_DEVMAP",
+	"BPF_CGROUP_INET_SOCK_RELEASE",
+	"BPF_XDP_CPUMAP",
+	"BPF_SK_LOOKUP",
+	"BPF_XDP",
+	"BPF_SK_SKB_VERDICT",
+	"BPF_SK_REUSEPORT_SELECT",
+	"BPF_SK_REUSEPORT_SELECT_OR_MIGRATE",
+	"BPF_PERF_EVENT",
+	"BPF_TRACE_KPROBE_MULTI",
+	"BPF_LSM_CGROUP",
+	"BPF_STRUCT_OPS",
+	"BPF_NETFILTER",
+}
+
+type DropBPF func(d *bpf_data_t) bool
+
+// DropBPFFailed will drop every bpf() syscall that returned an error.
+// Libraries probe for kernel features by loading programs that fail.
+func DropBPFFailed(d *bpf_data_t) bool {
+	return d.Ret < 0
+}
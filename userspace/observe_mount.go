@@ -0,0 +1,338 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall"
+
+	"github.com/kris-nova/logger"
+
+	"github.com/kris-nova/double-slit-experiment/system"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	EventNameMounted       = "Mounted"
+	EventNameUnmounted     = "Unmounted"
+	EventNameMountPrepared = "MountPrepared"
+)
+
+// mountSyscalls are the syscalls of the MOUNT_OP_* operations in bpf.c
+var mountSyscalls = map[uint32]string{
+	1: "mount",
+	2: "umount2",
+	3: "fsopen",
+	4: "fsmount",
+	5: "move_mount",
+	6: "open_tree",
+}
+
+// Flags of the new mount API, from include/uapi/linux/mount.h
+const (
+	fsopenCloexec  = 0x1
+	fsmountCloexec = 0x1
+
+	mountAttrRdonly      = 0x1
+	mountAttrNosuid      = 0x2
+	mountAttrNodev       = 0x4
+	mountAttrNoexec      = 0x8
+	mountAttrNoatime     = 0x10
+	mountAttrStrictatime = 0x20
+	mountAttrNodiratime  = 0x80
+	mountAttrIdmap       = 0x100000
+	mountAttrNosymfollow = 0x200000
+
+	moveMountFSymlinks   = 0x1
+	moveMountFAutomounts = 0x2
+	moveMountFEmptyPath  = 0x4
+	moveMountTSymlinks   = 0x10
+	moveMountTAutomounts = 0x20
+	moveMountTEmptyPath  = 0x40
+	moveMountSetGroup    = 0x100
+
+	openTreeClone = 0x1
+	atRecursive   = 0x8000
+)
+
+// MountObservationPoint will observe mount() and umount2(), and the
+// new mount API, where fsopen(), fsmount() and open_tree() prepare a
+// mount that move_mount() attaches.
+//
+//   Mounted        mount(), move_mount()
+//   Unmounted      umount2()
+//   MountPrepared  fsopen(), fsmount(), open_tree()
+//
+// Every event has the inode of the mount namespace of the process,
+// so mounts on the host can be told apart from mounts in containers.
+type MountObservationPoint struct {
+	reference   ObservationReference
+	dropFilters []DropMount
+	hostMntNs   uint64
+}
+
+// Load will find the mount namespace of the host, which is the
+// mount namespace of PID 1. Without it, no mount is on the host.
+func (p *MountObservationPoint) Load() error {
+	ns, err := system.ProcNamespace(1, "mnt")
+	if err != nil {
+		logger.Warning("Unable to find host mount namespace: %v", err)
+		return nil
+	}
+	p.hostMntNs = ns
+	return nil
+}
+
+func (p *MountObservationPoint) Event(record perf.Record) error {
+	data, err := EventMount(record)
+	if err != nil {
+		return err
+	}
+
+	for _, drop := range p.dropFilters {
+		if drop(data) {
+			return nil
+		}
+	}
+
+	event := NewMountEvent(record.CPU, data)
+	event.HostMountNamespace = p.hostMntNs != 0 && uint64(data.Mnt_ns) == p.hostMntNs
+	p.reference.eventCh <- event
+	return nil
+}
+
+// Tracepoints will only return the syscalls of this kernel, as the
+// new mount API was added in Linux 5.2.
+func (p *MountObservationPoint) Tracepoints() map[string]TracepointData {
+	probe := p.reference.probe
+	syscalls := []struct {
+		name        string
+		enter, exit *ebpf.Program
+	}{
+		{"mount", probe.EnterMount, probe.ExitMount},
+		{"umount", probe.EnterUmount, probe.ExitUmount},
+		{"fsopen", probe.EnterFsopen, probe.ExitFsopen},
+		{"fsmount", probe.EnterFsmount, probe.ExitFsmount},
+		{"move_mount", probe.EnterMoveMount, probe.ExitMoveMount},
+		{"open_tree", probe.EnterOpenTree, probe.ExitOpenTree},
+	}
+	tracepoints := map[string]TracepointData{}
+	for _, call := range syscalls {
+		enter, exit := "sys_enter_"+call.name, "sys_exit_"+call.name
+		if !TracepointExists(BPFGroupSyscalls, enter) {
+			continue
+		}
+		tracepoints[enter] = TracepointData{
+			Group:      BPFGroupSyscalls,
+			Tracepoint: enter,
+			Program:    call.enter,
+		}
+		tracepoints[exit] = TracepointData{
+			Group:      BPFGroupSyscalls,
+			Tracepoint: exit,
+			Program:    call.exit,
+		}
+	}
+	return tracepoints
+}
+
+func (p *MountObservationPoint) SetReference(reference ObservationReference) {
+	p.reference = reference
+}
+
+func NewMountObservationPoint(dropFilters []DropMount) *MountObservationPoint {
+	return &MountObservationPoint{
+		dropFilters: dropFilters,
+	}
+}
+
+// MountEvent is sent for every mount syscall. FD is the file
+// descriptor returned by fsopen(), fsmount() and open_tree().
+type MountEvent struct {
+	CPU                int      `json:"CPU"`
+	EventName          string   `json:"Name"`
+	Syscall            string   `json:"Syscall"`
+	PID                uint     `json:"PID"`
+	PPID               uint     `json:"PPID"`
+	UID                uint     `json:"UID"`
+	Comm               string   `json:"Comm"`
+	Source             string   `json:"Source,omitempty"`
+	Target             string   `json:"Target,omitempty"`
+	FSType             string   `json:"FSType,omitempty"`
+	Flags              uint64   `json:"Flags"`
+	FlagNames          []string `json:"FlagNames,omitempty"`
+	MountNamespace     uint     `json:"MountNamespace"`
+	HostMountNamespace bool     `json:"HostMountNamespace"`
+	FD                 int      `json:"FD,omitempty"`
+	Error              string   `json:"Error,omitempty"`
+	ContainerMetadata
+	EventTime
+}
+
+func NewMountEvent(cpu int, data *mount_data_t) *MountEvent {
+	e := &MountEvent{
+		CPU:               cpu,
+		Syscall:           mountSyscalls[data.Op],
+		PID:               uint(data.Pid),
+		PPID:              uint(data.Ppid),
+		UID:               uint(data.Uid),
+		Comm:              BytesToString32(data.Comm),
+		Source:            BytesToString(data.Source[:]),
+		Target:            cleanFilePath(BytesToString(data.Target[:])),
+		FSType:            BytesToString32(data.Fstype),
+		Flags:             data.Flags,
+		MountNamespace:    uint(data.Mnt_ns),
+		ContainerMetadata: containerMetadataForPID(int(data.Pid)),
+	}
+	flags := int(data.Flags)
+	switch e.Syscall {
+	case "mount":
+		e.EventName = EventNameMounted
+		if data.Flags&unix.MS_MGC_MSK == unix.MS_MGC_VAL {
+			flags = int(data.Flags &^ unix.MS_MGC_MSK)
+		}
+		e.FlagNames = flagNames(flags, mountFlags)
+	case "move_mount":
+		e.EventName = EventNameMounted
+		e.FlagNames = flagNames(flags, moveMountFlags)
+	case "umount2":
+		e.EventName = EventNameUnmounted
+		e.FlagNames = flagNames(flags, umountFlags)
+	case "fsopen":
+		e.EventName = EventNameMountPrepared
+		e.FlagNames = flagNames(flags, []flagName{{fsopenCloexec, "FSOPEN_CLOEXEC"}})
+	case "fsmount":
+		e.EventName = EventNameMountPrepared
+		e.FlagNames = append(flagNames(flags, []flagName{{fsmountCloexec, "FSMOUNT_CLOEXEC"}}),
+			flagNames(int(data.Attr), mountAttrFlags)...)
+	case "open_tree":
+		e.EventName = EventNameMountPrepared
+		e.FlagNames = flagNames(flags, openTreeFlags)
+	default:
+		e.EventName = EventNameMounted
+	}
+	switch {
+	case data.Ret < 0:
+		e.Error = unix.ErrnoName(syscall.Errno(-data.Ret))
+	case e.EventName == EventNameMountPrepared:
+		e.FD = int(data.Ret)
+	}
+	return e
+}
+
+func (e *MountEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *MountEvent) String() string {
+	namespace := "container"
+	if e.HostMountNamespace {
+		namespace = "host"
+	}
+	detail := fmt.Sprintf("%s %s %s", e.Source, e.Target, e.FSType)
+	if e.Error != "" {
+		detail = fmt.Sprintf("%s: %s", detail, e.Error)
+	}
+	return fmt.Sprintf("[%s] (%d) %s (%s mnt:%d): %s %v", e.Comm, e.PID, e.Syscall, namespace, e.MountNamespace, detail, e.FlagNames)
+}
+
+func (e *MountEvent) Name() string {
+	return e.EventName
+}
+
+var mountFlags = []flagName{
+	{unix.MS_RDONLY, "MS_RDONLY"},
+	{unix.MS_NOSUID, "MS_NOSUID"},
+	{unix.MS_NODEV, "MS_NODEV"},
+	{unix.MS_NOEXEC, "MS_NOEXEC"},
+	{unix.MS_SYNCHRONOUS, "MS_SYNCHRONOUS"},
+	{unix.MS_REMOUNT, "MS_REMOUNT"},
+	{unix.MS_MANDLOCK, "MS_MANDLOCK"},
+	{unix.MS_DIRSYNC, "MS_DIRSYNC"},
+	{unix.MS_NOSYMFOLLOW, "MS_NOSYMFOLLOW"},
+	{unix.MS_NOATIME, "MS_NOATIME"},
+	{unix.MS_NODIRATIME, "MS_NODIRATIME"},
+	{unix.MS_BIND, "MS_BIND"},
+	{unix.MS_MOVE, "MS_MOVE"},
+	{unix.MS_REC, "MS_REC"},
+	{unix.MS_SILENT, "MS_SILENT"},
+	{unix.MS_POSIXACL, "MS_POSIXACL"},
+	{unix.MS_UNBINDABLE, "MS_UNBINDABLE"},
+	{unix.MS_PRIVATE, "MS_PRIVATE"},
+	{unix.MS_SLAVE, "MS_SLAVE"},
+	{unix.MS_SHARED, "MS_SHARED"},
+	{unix.MS_RELATIME, "MS_RELATIME"},
+	{unix.MS_KERNMOUNT, "MS_KERNMOUNT"},
+	{unix.MS_I_VERSION, "MS_I_VERSION"},
+	{unix.MS_STRICTATIME, "MS_STRICTATIME"},
+	{unix.MS_LAZYTIME, "MS_LAZYTIME"},
+}
+
+var umountFlags = []flagName{
+	{unix.MNT_FORCE, "MNT_FORCE"},
+	{unix.MNT_DETACH, "MNT_DETACH"},
+	{unix.MNT_EXPIRE, "MNT_EXPIRE"},
+	{unix.UMOUNT_NOFOLLOW, "UMOUNT_NOFOLLOW"},
+}
+
+var mountAttrFlags = []flagName{
+	{mountAttrRdonly, "MOUNT_ATTR_RDONLY"},
+	{mountAttrNosuid, "MOUNT_ATTR_NOSUID"},
+	{mountAttrNodev, "MOUNT_ATTR_NODEV"},
+	{mountAttrNoexec, "MOUNT_ATTR_NOEXEC"},
+	{mountAttrNoatime, "MOUNT_ATTR_NOATIME"},
+	{mountAttrStrictatime, "MOUNT_ATTR_STRICTATIME"},
+	{mountAttrNodiratime, "MOUNT_ATTR_NODIRATIME"},
+	{mountAttrIdmap, "MOUNT_ATTR_IDMAP"},
+	{mountAttrNosymfollow, "MOUNT_ATTR_NOSYMFOLLOW"},
+}
+
+var moveMountFlags = []flagName{
+	{moveMountFSymlinks, "MOVE_MOUNT_F_SYMLINKS"},
+	{moveMountFAutomounts, "MOVE_MOUNT_F_AUTOMOUNTS"},
+	{moveMountFEmptyPath, "MOVE_MOUNT_F_EMPTY_PATH"},
+	{moveMountTSymlinks, "MOVE_MOUNT_T_SYMLINKS"},
+	{moveMountTAutomounts, "MOVE_MOUNT_T_AUTOMOUNTS"},
+	{moveMountTEmptyPath, "MOVE_MOUNT_T_EMPTY_PATH"},
+	{moveMountSetGroup, "MOVE_MOUNT_SET_GROUP"},
+}
+
+var openTreeFlags = []flagName{
+	{openTreeClone, "OPEN_TREE_CLONE"},
+	{unix.O_CLOEXEC, "OPEN_TREE_CLOEXEC"},
+	{unix.AT_SYMLINK_NOFOLLOW, "AT_SYMLINK_NOFOLLOW"},
+	{unix.AT_NO_AUTOMOUNT, "AT_NO_AUTOMOUNT"},
+	{unix.AT_EMPTY_PATH, "AT_EMPTY_PATH"},
+	{atRecursive, "AT_RECURSIVE"},
+}
+
+type DropMount func(d *mount_data_t) bool
+
+// DropMountFailed will drop every mount syscall that returned an error.
+func DropMountFailed(d *mount_data_t) bool {
+	return d.Ret < 0
+}
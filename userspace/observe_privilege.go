@@ -0,0 +1,502 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"syscall"
+
+	"github.com/kris-nova/logger"
+
+	"github.com/kris-nova/double-slit-experiment/system"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	EventNamePrivilegeChanged     = "PrivilegeChanged"
+	EventNameExecPrivilegeChanged = "ExecPrivilegeChanged"
+	EventNameNamespaceChanged     = "NamespaceChanged"
+)
+
+// privilegeSyscalls are the syscalls of the PRIV_OP_* operations in bpf.c
+var privilegeSyscalls = map[uint32]string{
+	1:  "setuid",
+	2:  "setreuid",
+	3:  "setresuid",
+	4:  "setfsuid",
+	5:  "setgid",
+	6:  "setregid",
+	7:  "setresgid",
+	8:  "setfsgid",
+	9:  "setgroups",
+	10: "capset",
+	11: "execve",
+	12: "unshare",
+	13: "setns",
+	14: "clone",
+	15: "clone3",
+}
+
+// namespaceSyscalls change the namespaces of a process, instead of
+// its credentials.
+var namespaceSyscalls = map[string]bool{
+	"unshare": true,
+	"setns":   true,
+	"clone":   true,
+	"clone3":  true,
+}
+
+// namespaceFlags are the CLONE_NEW* flags of clone(), clone3(),
+// unshare() and setns(). CLONE_NEWTIME is missing from older headers.
+var namespaceFlags = []struct {
+	flag uint64
+	name string
+}{
+	{CLONE_NEWNS, "CLONE_NEWNS"},
+	{CLONE_NEWCGROUP, "CLONE_NEWCGROUP"},
+	{CLONE_NEWUTS, "CLONE_NEWUTS"},
+	{CLONE_NEWIPC, "CLONE_NEWIPC"},
+	{CLONE_NEWUSER, "CLONE_NEWUSER"},
+	{CLONE_NEWPID, "CLONE_NEWPID"},
+	{CLONE_NEWNET, "CLONE_NEWNET"},
+	{0x00000080, "CLONE_NEWTIME"},
+}
+
+// hostNamespaces are the namespaces compared with PID 1 after setns().
+// The user namespace is left out, as most containers share it with
+// the host.
+var hostNamespaces = []string{"mnt", "pid", "net", "ipc", "uts", "cgroup"}
+
+// privilegeArgs are the number of ID arguments of each syscall.
+var privilegeArgs = map[string]int{
+	"setuid":    1,
+	"setreuid":  2,
+	"setresuid": 3,
+	"setfsuid":  1,
+	"setgid":    1,
+	"setregid":  2,
+	"setresgid": 3,
+	"setfsgid":  1,
+}
+
+// Capabilities are the names of the capabilities, by bit.
+var Capabilities = []string{
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_DAC_READ_SEARCH",
+	"CAP_FOWNER",
+	"CAP_FSETID",
+	"CAP_KILL",
+	"CAP_SETGID",
+	"CAP_SETUID",
+	"CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE",
+	"CAP_NET_BIND_SERVICE",
+	"CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN",
+	"CAP_NET_RAW",
+	"CAP_IPC_LOCK",
+	"CAP_IPC_OWNER",
+	"CAP_SYS_MODULE",
+	"CAP_SYS_RAWIO",
+	"CAP_SYS_CHROOT",
+	"CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT",
+	"CAP_SYS_ADMIN",
+	"CAP_SYS_BOOT",
+	"CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE",
+	"CAP_SYS_TIME",
+	"CAP_SYS_TTY_CONFIG",
+	"CAP_MKNOD",
+	"CAP_LEASE",
+	"CAP_AUDIT_WRITE",
+	"CAP_AUDIT_CONTROL",
+	"CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE",
+	"CAP_MAC_ADMIN",
+	"CAP_SYSLOG",
+	"CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND",
+	"CAP_AUDIT_READ",
+	"CAP_PERFMON",
+	"CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// PrivilegeObservationPoint will observe every change of credentials.
+//
+//   PrivilegeChanged      setuid(), setreuid(), setresuid(), setfsuid(),
+//                         the same gid syscalls, setgroups() and capset()
+//   ExecPrivilegeChanged  exec() of a setuid or setgid binary, or a binary
+//                         with file capabilities
+//   NamespaceChanged      unshare(), setns(), and clone() or clone3()
+//                         with a CLONE_NEW* flag
+//
+// All have the credentials before and after. ExecPrivilegeChanged
+// is only sent when the effective UID or GID changed across exec(),
+// or capabilities were gained.
+type PrivilegeObservationPoint struct {
+	reference   ObservationReference
+	dropFilters []DropPrivilege
+	hostNs      map[string]uint64
+}
+
+// Load will find the namespaces of the host, which are the namespaces
+// of PID 1. Without them, no setns() joins the host.
+func (p *PrivilegeObservationPoint) Load() error {
+	p.hostNs = map[string]uint64{}
+	for _, namespace := range hostNamespaces {
+		ns, err := system.ProcNamespace(1, namespace)
+		if err != nil {
+			logger.Warning("Unable to find host %s namespace: %v", namespace, err)
+			continue
+		}
+		p.hostNs[namespace] = ns
+	}
+	return nil
+}
+
+func (p *PrivilegeObservationPoint) Event(record perf.Record) error {
+	data, err := EventPrivilege(record)
+	if err != nil {
+		return err
+	}
+
+	for _, drop := range p.dropFilters {
+		if drop(data) {
+			return nil
+		}
+	}
+
+	event := NewPrivilegeEvent(record.CPU, data)
+	if event.Syscall == "setns" && event.Error == "" {
+		event.HostNamespaces = p.sharedHostNamespaces(int(data.Pid))
+	}
+	p.reference.eventCh <- event
+	return nil
+}
+
+// sharedHostNamespaces will return the namespaces a process shares
+// with the host.
+func (p *PrivilegeObservationPoint) sharedHostNamespaces(pid int) []string {
+	var shared []string
+	for _, namespace := range hostNamespaces {
+		hostNs, ok := p.hostNs[namespace]
+		if !ok {
+			continue
+		}
+		ns, err := system.ProcNamespace(pid, namespace)
+		if err != nil {
+			continue
+		}
+		if ns == hostNs {
+			shared = append(shared, namespace)
+		}
+	}
+	return shared
+}
+
+func (p *PrivilegeObservationPoint) Tracepoints() map[string]TracepointData {
+	probe := p.reference.probe
+	syscalls := []struct {
+		name        string
+		enter, exit *ebpf.Program
+	}{
+		{"setuid", probe.EnterSetuid, probe.ExitSetuid},
+		{"setreuid", probe.EnterSetreuid, probe.ExitSetreuid},
+		{"setresuid", probe.EnterSetresuid, probe.ExitSetresuid},
+		{"setfsuid", probe.EnterSetfsuid, probe.ExitSetfsuid},
+		{"setgid", probe.EnterSetgid, probe.ExitSetgid},
+		{"setregid", probe.EnterSetregid, probe.ExitSetregid},
+		{"setresgid", probe.EnterSetresgid, probe.ExitSetresgid},
+		{"setfsgid", probe.EnterSetfsgid, probe.ExitSetfsgid},
+		{"setgroups", probe.EnterSetgroups, probe.ExitSetgroups},
+		{"capset", probe.EnterCapset, probe.ExitCapset},
+		{"unshare", probe.EnterUnshare, probe.ExitUnshare},
+		{"setns", probe.EnterSetns, probe.ExitSetns},
+		{"clone", probe.EnterCloneNs, probe.ExitCloneNs},
+		{"clone3", probe.EnterClone3Ns, probe.ExitClone3Ns},
+	}
+	tracepoints := map[string]TracepointData{
+		"sys_enter_execve": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_enter_execve",
+			Program:    probe.EnterExecveCreds,
+		},
+		"sys_enter_execveat": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_enter_execveat",
+			Program:    probe.EnterExecveatCreds,
+		},
+		"sched_process_exec": {
+			Group:      BPFGroupSched,
+			Tracepoint: "sched_process_exec",
+			Program:    probe.ExecCredsChanged,
+		},
+	}
+	for _, call := range syscalls {
+		enter, exit := "sys_enter_"+call.name, "sys_exit_"+call.name
+		if !TracepointExists(BPFGroupSyscalls, enter) {
+			continue
+		}
+		tracepoints[enter] = TracepointData{
+			Group:      BPFGroupSyscalls,
+			Tracepoint: enter,
+			Program:    call.enter,
+		}
+		tracepoints[exit] = TracepointData{
+			Group:      BPFGroupSyscalls,
+			Tracepoint: exit,
+			Program:    call.exit,
+		}
+	}
+	return tracepoints
+}
+
+func (p *PrivilegeObservationPoint) SetReference(reference ObservationReference) {
+	p.reference = reference
+}
+
+func NewPrivilegeObservationPoint(dropFilters []DropPrivilege) *PrivilegeObservationPoint {
+	return &PrivilegeObservationPoint{
+		dropFilters: dropFilters,
+	}
+}
+
+// Credentials are the IDs and capability sets of a process.
+type Credentials struct {
+	UID            uint     `json:"UID"`
+	GID            uint     `json:"GID"`
+	EUID           uint     `json:"EUID"`
+	EGID           uint     `json:"EGID"`
+	SUID           uint     `json:"SUID"`
+	SGID           uint     `json:"SGID"`
+	FSUID          uint     `json:"FSUID"`
+	FSGID          uint     `json:"FSGID"`
+	CapInheritable []string `json:"CapInheritable"`
+	CapPermitted   []string `json:"CapPermitted"`
+	CapEffective   []string `json:"CapEffective"`
+}
+
+func newCredentials(data cred_data_t) Credentials {
+	return Credentials{
+		UID:            uint(data.Uid),
+		GID:            uint(data.Gid),
+		EUID:           uint(data.Euid),
+		EGID:           uint(data.Egid),
+		SUID:           uint(data.Suid),
+		SGID:           uint(data.Sgid),
+		FSUID:          uint(data.Fsuid),
+		FSGID:          uint(data.Fsgid),
+		CapInheritable: CapabilityNames(data.Cap_inheritable),
+		CapPermitted:   CapabilityNames(data.Cap_permitted),
+		CapEffective:   CapabilityNames(data.Cap_effective),
+	}
+}
+
+// PrivilegeEvent is sent for a change of credentials. UID is the
+// real UID of the process before the change.
+//
+// Escalated is true if the effective UID became 0, or if effective
+// capabilities were gained.
+//
+// A NamespaceChanged event has the CLONE_NEW* flags in Namespaces,
+// and the new process in ChildPID for clone(). HostNamespaces are
+// the namespaces a process shares with PID 1 after setns().
+type PrivilegeEvent struct {
+	CPU                int         `json:"CPU"`
+	EventName          string      `json:"Name"`
+	Syscall            string      `json:"Syscall"`
+	PID                uint        `json:"PID"`
+	PPID               uint        `json:"PPID"`
+	UID                uint        `json:"UID"`
+	Comm               string      `json:"Comm"`
+	Filename           string      `json:"Filename,omitempty"`
+	Requested          string      `json:"Requested,omitempty"`
+	Groups             []int       `json:"Groups,omitempty"`
+	Old                Credentials `json:"Old"`
+	New                Credentials `json:"New"`
+	Changed            []string    `json:"Changed"`
+	GainedCapabilities []string    `json:"GainedCapabilities,omitempty"`
+	Escalated          bool        `json:"Escalated"`
+	Namespaces         []string    `json:"Namespaces,omitempty"`
+	ChildPID           uint        `json:"ChildPID,omitempty"`
+	HostNamespaces     []string    `json:"HostNamespaces,omitempty"`
+	Error              string      `json:"Error,omitempty"`
+	ContainerMetadata
+	EventTime
+}
+
+func NewPrivilegeEvent(cpu int, data *priv_data_t) *PrivilegeEvent {
+	e := &PrivilegeEvent{
+		CPU:                cpu,
+		EventName:          EventNamePrivilegeChanged,
+		Syscall:            privilegeSyscalls[data.Op],
+		PID:                uint(data.Pid),
+		PPID:               uint(data.Ppid),
+		UID:                uint(data.Old.Uid),
+		Comm:               BytesToString32(data.Comm),
+		Filename:           BytesToString(data.Filename[:]),
+		Old:                newCredentials(data.Old),
+		New:                newCredentials(data.New),
+		Changed:            changedCredentials(data.Old, data.New),
+		GainedCapabilities: CapabilityNames(data.New.Cap_effective &^ data.Old.Cap_effective),
+		ContainerMetadata:  containerMetadataForPID(int(data.Pid)),
+	}
+	if e.Syscall == "execve" {
+		e.EventName = EventNameExecPrivilegeChanged
+	}
+	if namespaceSyscalls[e.Syscall] {
+		e.EventName = EventNameNamespaceChanged
+		flags := data.Args[0]
+		if e.Syscall == "setns" {
+			// setns(int fd, int nstype), where 0 allows any namespace
+			flags = data.Args[1]
+		}
+		e.Namespaces = NamespaceFlagNames(flags)
+		if (e.Syscall == "clone" || e.Syscall == "clone3") && data.Ret > 0 {
+			e.ChildPID = uint(data.Ret)
+		}
+	}
+	e.Escalated = (data.Old.Euid != 0 && data.New.Euid == 0) || len(e.GainedCapabilities) > 0
+	if n := privilegeArgs[e.Syscall]; n > 0 {
+		var args []string
+		for _, arg := range data.Args[:n] {
+			// -1 leaves an ID unchanged
+			args = append(args, fmt.Sprint(int32(uint32(arg))))
+		}
+		e.Requested = fmt.Sprintf("%s(%s)", e.Syscall, strings.Join(args, ", "))
+	}
+	if e.Syscall == "setgroups" {
+		for i := 0; i < int(data.Ngroups) && i < len(data.Groups); i++ {
+			e.Groups = append(e.Groups, int(data.Groups[i]))
+		}
+	}
+	if data.Ret < 0 {
+		e.Error = unix.ErrnoName(syscall.Errno(-data.Ret))
+	}
+	return e
+}
+
+func (e *PrivilegeEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *PrivilegeEvent) String() string {
+	detail := e.Requested
+	if e.Filename != "" {
+		detail = e.Filename
+	}
+	if detail == "" {
+		detail = e.Syscall
+	}
+	if len(e.Namespaces) > 0 {
+		detail = fmt.Sprintf("%s %v", detail, e.Namespaces)
+	}
+	if e.Error != "" {
+		detail = fmt.Sprintf("%s: %s", detail, e.Error)
+	}
+	return fmt.Sprintf("[%s] (%d) %s uid:%d->%d euid:%d->%d changed:%v", e.Comm, e.PID, detail, e.Old.UID, e.New.UID, e.Old.EUID, e.New.EUID, e.Changed)
+}
+
+func (e *PrivilegeEvent) Name() string {
+	return e.EventName
+}
+
+// Severity is high for an escalation, and low otherwise.
+func (e *PrivilegeEvent) Severity() int {
+	if e.Escalated {
+		return RuleSeverities["high"]
+	}
+	return RuleSeverities["low"]
+}
+
+// NamespaceFlagNames will decode the CLONE_NEW* flags into their names.
+func NamespaceFlagNames(flags uint64) []string {
+	var names []string
+	for _, f := range namespaceFlags {
+		if flags&f.flag != 0 {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
+
+// CapabilityNames will decode a capability set into the names of
+// the capabilities. Unknown bits are named by number.
+func CapabilityNames(caps uint64) []string {
+	var names []string
+	for bit := 0; bit < 64; bit++ {
+		if caps&(1<<uint(bit)) == 0 {
+			continue
+		}
+		if bit < len(Capabilities) {
+			names = append(names, Capabilities[bit])
+			continue
+		}
+		names = append(names, fmt.Sprintf("CAP_%d", bit))
+	}
+	return names
+}
+
+// changedCredentials will return the names of the credentials
+// that are different.
+func changedCredentials(old, new cred_data_t) []string {
+	changed := []string{}
+	for _, c := range []struct {
+		name     string
+		old, new uint64
+	}{
+		{"UID", uint64(old.Uid), uint64(new.Uid)},
+		{"GID", uint64(old.Gid), uint64(new.Gid)},
+		{"EUID", uint64(old.Euid), uint64(new.Euid)},
+		{"EGID", uint64(old.Egid), uint64(new.Egid)},
+		{"SUID", uint64(old.Suid), uint64(new.Suid)},
+		{"SGID", uint64(old.Sgid), uint64(new.Sgid)},
+		{"FSUID", uint64(old.Fsuid), uint64(new.Fsuid)},
+		{"FSGID", uint64(old.Fsgid), uint64(new.Fsgid)},
+		{"CapInheritable", old.Cap_inheritable, new.Cap_inheritable},
+		{"CapPermitted", old.Cap_permitted, new.Cap_permitted},
+		{"CapEffective", old.Cap_effective, new.Cap_effective},
+	} {
+		if c.old != c.new {
+			changed = append(changed, c.name)
+		}
+	}
+	return changed
+}
+
+type DropPrivilege func(d *priv_data_t) bool
+
+// DropPrivilegeUnchanged will drop every successful syscall that
+// did not change the credentials. Failed syscalls, and syscalls that
+// change namespaces, are kept.
+func DropPrivilegeUnchanged(d *priv_data_t) bool {
+	return d.Ret >= 0 && d.Old == d.New && !namespaceSyscalls[privilegeSyscalls[d.Op]]
+}
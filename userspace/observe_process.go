@@ -73,16 +73,23 @@ type ProcessEvent struct {
 	Filename  string         `json:"Filename"`
 	Comm      string         `json:"Comm"`
 	PID       uint           `json:"PID"`
+	PPID      uint           `json:"PPID"`
+	UID       uint           `json:"UID"`
+	ContainerMetadata
+	EventTime
 }
 
 func NewProcessEvent(name string, cpu int, execData *execve_data_t) *ProcessEvent {
 	return &ProcessEvent{
-		data:      execData,
-		CPU:       cpu,
-		EventName: name,
-		Filename:  BytesToString32(execData.Filename),
-		Comm:      BytesToString32(execData.Comm),
-		PID:       uint(execData.Pid),
+		data:              execData,
+		CPU:               cpu,
+		EventName:         name,
+		Filename:          BytesToString32(execData.Filename),
+		Comm:              BytesToString32(execData.Comm),
+		PID:               uint(execData.Pid),
+		PPID:              uint(execData.Ppid),
+		UID:               uint(execData.Uid),
+		ContainerMetadata: containerMetadataForPID(int(execData.Pid)),
 	}
 }
 
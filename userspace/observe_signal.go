@@ -20,6 +20,9 @@ package userspace
 import (
 	"encoding/json"
 	"fmt"
+	"syscall"
+
+	"golang.org/x/sys/unix"
 
 	"github.com/cilium/ebpf/perf"
 )
@@ -66,26 +69,29 @@ func NewSignalObservationPoint(dropFunctions []DropSignal) *SignalObservationPoi
 }
 
 type SignalEvent struct {
-	CPU       int            `json:"CPU"`
-	EventName string         `json:"Name"`
-	data      *signal_data_t `json:"Data"`
-	Signal    int            `json:"Signal"`
-	Errno     int            `json:"Errno"`
-	Code      int            `json:"Code"`
-	Handler   uint64         `json:"Handler"`
-	Flags     uint64         `json:"Flags"`
+	CPU        int            `json:"CPU"`
+	EventName  string         `json:"Name"`
+	data       *signal_data_t `json:"Data"`
+	Signal     int            `json:"Signal"`
+	SignalName string         `json:"SignalName"`
+	Errno      int            `json:"Errno"`
+	Code       int            `json:"Code"`
+	Handler    uint64         `json:"Handler"`
+	Flags      uint64         `json:"Flags"`
+	EventTime
 }
 
 func NewSignalEvent(name string, cpu int, signalData *signal_data_t) *SignalEvent {
 	return &SignalEvent{
-		data:      signalData,
-		EventName: name,
-		CPU:       cpu,
-		Signal:    int(signalData.Signal),
-		Errno:     int(signalData.Errno),
-		Code:      int(signalData.Code),
-		Handler:   signalData.SignalHandler,
-		Flags:     signalData.SignalFlags,
+		data:       signalData,
+		EventName:  name,
+		CPU:        cpu,
+		Signal:     int(signalData.Signal),
+		SignalName: unix.SignalName(syscall.Signal(signalData.Signal)),
+		Errno:      int(signalData.Errno),
+		Code:       int(signalData.Code),
+		Handler:    signalData.SignalHandler,
+		Flags:      signalData.SignalFlags,
 	}
 }
 
@@ -70,7 +70,9 @@ type SocketEvent struct {
 	EventName    string            `json:"Name"`
 	data         *inet_sock_data_t `json:"Data"`
 	OldState     int               `json:"OldState"`
+	OldStateName string            `json:"OldStateName"`
 	NewState     int               `json:"NewState"`
+	NewStateName string            `json:"NewStateName"`
 	SourcePort   uint              `json:"SourcePort"`
 	DestPort     uint              `json:"DestPort"`
 	Family       uint              `json:"Family"`
@@ -79,23 +81,35 @@ type SocketEvent struct {
 	DestAddr     string            `json:"DestAddr"`
 	SourceAddrV6 string            `json:"SourceAddrV6"`
 	DestAddrV6   string            `json:"DestAddrV6"`
+
+	// PID and Comm are the process that called connect() or listen()
+	// on the socket, and are empty for sockets that were accepted.
+	PID  uint   `json:"PID,omitempty"`
+	Comm string `json:"Comm,omitempty"`
+	ContainerMetadata
+	EventTime
 }
 
 func NewSocketEvent(name string, cpu int, data *inet_sock_data_t) *SocketEvent {
 	return &SocketEvent{
-		data:         data,
-		EventName:    name,
-		CPU:          cpu,
-		OldState:     int(data.OldState),
-		NewState:     int(data.NewState),
-		SourcePort:   uint(data.Sport),
-		DestPort:     uint(data.Dport),
-		Family:       uint(data.Family),
-		Protocol:     uint(data.Protocol),
-		SourceAddr:   IPV4(data.Saddr),
-		DestAddr:     IPV4(data.Daddr),
-		SourceAddrV6: IPV6(data.Saddr_v6),
-		DestAddrV6:   IPV6(data.Daddr_v6),
+		data:              data,
+		EventName:         name,
+		CPU:               cpu,
+		OldState:          int(data.OldState),
+		OldStateName:      TCPStateName(int(data.OldState)),
+		NewState:          int(data.NewState),
+		NewStateName:      TCPStateName(int(data.NewState)),
+		SourcePort:        uint(data.Sport),
+		DestPort:          uint(data.Dport),
+		Family:            uint(data.Family),
+		Protocol:          uint(data.Protocol),
+		SourceAddr:        IPV4(data.Saddr),
+		DestAddr:          IPV4(data.Daddr),
+		SourceAddrV6:      IPV6(data.Saddr_v6),
+		DestAddrV6:        IPV6(data.Daddr_v6),
+		PID:               uint(data.Pid),
+		Comm:              BytesToString(data.Comm[:]),
+		ContainerMetadata: containerMetadataForPID(int(data.Pid)),
 	}
 }
 
@@ -116,3 +130,45 @@ type DropSocket func(d *inet_sock_data_t) bool
 func DropSocketProtocolEq0(d *inet_sock_data_t) bool {
 	return d.Protocol == 0
 }
+
+// TCP socket states as reported by inet_sock_set_state
+// More:
+//
+//	include/net/tcp_states.h
+const (
+	TCP_ESTABLISHED  int = 1
+	TCP_SYN_SENT     int = 2
+	TCP_SYN_RECV     int = 3
+	TCP_FIN_WAIT1    int = 4
+	TCP_FIN_WAIT2    int = 5
+	TCP_TIME_WAIT    int = 6
+	TCP_CLOSE        int = 7
+	TCP_CLOSE_WAIT   int = 8
+	TCP_LAST_ACK     int = 9
+	TCP_LISTEN       int = 10
+	TCP_CLOSING      int = 11
+	TCP_NEW_SYN_RECV int = 12
+)
+
+var tcpStateNames = map[int]string{
+	TCP_ESTABLISHED:  "TCP_ESTABLISHED",
+	TCP_SYN_SENT:     "TCP_SYN_SENT",
+	TCP_SYN_RECV:     "TCP_SYN_RECV",
+	TCP_FIN_WAIT1:    "TCP_FIN_WAIT1",
+	TCP_FIN_WAIT2:    "TCP_FIN_WAIT2",
+	TCP_TIME_WAIT:    "TCP_TIME_WAIT",
+	TCP_CLOSE:        "TCP_CLOSE",
+	TCP_CLOSE_WAIT:   "TCP_CLOSE_WAIT",
+	TCP_LAST_ACK:     "TCP_LAST_ACK",
+	TCP_LISTEN:       "TCP_LISTEN",
+	TCP_CLOSING:      "TCP_CLOSING",
+	TCP_NEW_SYN_RECV: "TCP_NEW_SYN_RECV",
+}
+
+// TCPStateName will return the kernel name of a TCP state.
+func TCPStateName(state int) string {
+	if name, ok := tcpStateNames[state]; ok {
+		return name
+	}
+	return fmt.Sprintf("TCP_UNKNOWN(%d)", state)
+}
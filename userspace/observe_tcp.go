@@ -0,0 +1,319 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	EventNameTCPRetransmit    = "TCPRetransmit"
+	EventNameTCPResetSent     = "TCPResetSent"
+	EventNameTCPResetReceived = "TCPResetReceived"
+	EventNameTCPPacketDropped = "TCPPacketDropped"
+)
+
+// tcpOpEvents are the events of the TCP_OP_* operations in bpf.c
+var tcpOpEvents = map[uint32]string{
+	1: EventNameTCPRetransmit,
+	2: EventNameTCPResetSent,
+	3: EventNameTCPResetReceived,
+	4: EventNameTCPPacketDropped,
+}
+
+// TCPObservationPoint will observe the health of TCP connections.
+//
+//   TCPRetransmit     tcp/tcp_retransmit_skb
+//   TCPResetSent      tcp/tcp_send_reset
+//   TCPResetReceived  tcp/tcp_receive_reset
+//   TCPPacketDropped  skb/kfree_skb, for TCP packets only
+//
+// These happen in softirq context, so the process is the owner of the
+// socket, saved when it called connect(), listen() or accept(). The
+// sockets that were open before the point was loaded have no owner.
+type TCPObservationPoint struct {
+	reference   ObservationReference
+	dropFilters []DropTCP
+	dropReasons map[int]string
+}
+
+// Load will read the names of the drop reasons of this kernel, which
+// change between kernels.
+func (p *TCPObservationPoint) Load() error {
+	p.dropReasons = TracepointSymbols(BPFGroupSkb, "kfree_skb", "reason")
+	return nil
+}
+
+func (p *TCPObservationPoint) Event(record perf.Record) error {
+	data, err := EventTCP(record)
+	if err != nil {
+		return err
+	}
+
+	for _, drop := range p.dropFilters {
+		if drop(data) {
+			return nil
+		}
+	}
+
+	p.reference.eventCh <- NewTCPEvent(record.CPU, data, p.dropReasons)
+	return nil
+}
+
+func (p *TCPObservationPoint) Tracepoints() map[string]TracepointData {
+	probe := p.reference.probe
+	tracepoints := map[string]TracepointData{
+		"tcp_retransmit_skb": {
+			Group:      BPFGroupTCP,
+			Tracepoint: "tcp_retransmit_skb",
+			Program:    probe.TcpRetransmitSkb,
+		},
+		"tcp_send_reset": {
+			Group:      BPFGroupTCP,
+			Tracepoint: "tcp_send_reset",
+			Program:    probe.TcpSendReset,
+		},
+		"tcp_receive_reset": {
+			Group:      BPFGroupTCP,
+			Tracepoint: "tcp_receive_reset",
+			Program:    probe.TcpReceiveReset,
+		},
+		"tcp_sock_owner": {
+			Group:      BPFGroupSock,
+			Tracepoint: "inet_sock_set_state",
+			Program:    probe.TcpSockOwner,
+		},
+		"tcp_accept_owner": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_exit_accept",
+			Program:    probe.TcpAcceptOwner,
+		},
+		"tcp_accept4_owner": {
+			Group:      BPFGroupSyscalls,
+			Tracepoint: "sys_exit_accept4",
+			Program:    probe.TcpAccept4Owner,
+		},
+	}
+	if TracepointExists(BPFGroupSkb, "kfree_skb") {
+		tracepoints["kfree_skb"] = TracepointData{
+			Group:      BPFGroupSkb,
+			Tracepoint: "kfree_skb",
+			Program:    probe.KfreeSkb,
+		}
+	}
+	return tracepoints
+}
+
+func (p *TCPObservationPoint) SetReference(reference ObservationReference) {
+	p.reference = reference
+}
+
+func NewTCPObservationPoint(dropFilters []DropTCP) *TCPObservationPoint {
+	return &TCPObservationPoint{
+		dropFilters: dropFilters,
+	}
+}
+
+// TCPEvent is a retransmit, a reset or a dropped packet of a TCP
+// connection. The source is the local end of the socket, or the sender
+// of a dropped packet without a socket, such as a packet for a port
+// that is closed.
+//
+// Connection is the same for both directions of a connection, so the
+// events of a connection can be counted by it, such as with a rate.
+//
+// Drops are counted by connection in the kernel, and a connection sends
+// at most one TCPPacketDropped a second. Drops is the number of packets
+// dropped since the last TCPPacketDropped of the connection. Packets
+// without a socket are counted by their addresses alone, so a port scan
+// from one source is a single TCPPacketDropped a second.
+type TCPEvent struct {
+	CPU        int    `json:"CPU"`
+	EventName  string `json:"Name"`
+	PID        uint   `json:"PID,omitempty"`
+	Comm       string `json:"Comm,omitempty"`
+	Family     string `json:"Family"`
+	SourceAddr string `json:"SourceAddr"`
+	SourcePort uint   `json:"SourcePort"`
+	DestAddr   string `json:"DestAddr"`
+	DestPort   uint   `json:"DestPort"`
+	Connection string `json:"Connection"`
+	State      string `json:"State,omitempty"`
+	Reason     string `json:"Reason,omitempty"`
+	Drops      uint   `json:"Drops,omitempty"`
+	NetNS      uint64 `json:"NetNS,omitempty"`
+	ContainerMetadata
+	EventTime
+}
+
+// NewTCPEvent will name the drop reason of a TCPPacketDropped from
+// reasons. Kernels before 5.17 have no drop reason.
+func NewTCPEvent(cpu int, data *tcp_data_t, reasons map[int]string) *TCPEvent {
+	e := &TCPEvent{
+		CPU:               cpu,
+		EventName:         tcpOpEvents[data.Op],
+		PID:               uint(data.Pid),
+		Comm:              BytesToString32(data.Comm),
+		Family:            socketFamilies[data.Family],
+		SourceAddr:        inetAddr(data.Family, data.Saddr),
+		SourcePort:        uint(data.Sport),
+		DestAddr:          inetAddr(data.Family, data.Daddr),
+		DestPort:          uint(data.Dport),
+		Drops:             uint(data.Drops),
+		NetNS:             uint64(data.Netns),
+		ContainerMetadata: containerMetadataForPID(int(data.Pid)),
+	}
+	e.Connection = TCPConnection(e.SourceAddr, e.SourcePort, e.DestAddr, e.DestPort)
+	if data.State != 0 {
+		e.State = TCPStateName(int(data.State))
+	}
+	if data.Reason != 0 {
+		e.Reason = reasons[int(data.Reason)]
+		if e.Reason == "" {
+			e.Reason = fmt.Sprintf("REASON_%d", data.Reason)
+		}
+	}
+	return e
+}
+
+func (e *TCPEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *TCPEvent) String() string {
+	detail := fmt.Sprintf("%s -> %s", tcpEndpoint(e.SourceAddr, e.SourcePort), tcpEndpoint(e.DestAddr, e.DestPort))
+	if e.State != "" {
+		detail = fmt.Sprintf("%s %s", detail, e.State)
+	}
+	if e.Reason != "" {
+		detail = fmt.Sprintf("%s: %s", detail, e.Reason)
+	}
+	if e.Drops > 1 {
+		detail = fmt.Sprintf("%s (%d drops)", detail, e.Drops)
+	}
+	return fmt.Sprintf("[%s] (%d) %s: %s", e.Comm, e.PID, e.EventName, detail)
+}
+
+func (e *TCPEvent) Name() string {
+	return e.EventName
+}
+
+// TCPConnection will return the same name for both directions of a
+// connection, with the lowest endpoint first.
+//
+//   10.0.0.4:50412 10.0.0.9:443
+func TCPConnection(addr string, port uint, peerAddr string, peerPort uint) string {
+	local, peer := tcpEndpoint(addr, port), tcpEndpoint(peerAddr, peerPort)
+	if peer < local {
+		local, peer = peer, local
+	}
+	return fmt.Sprintf("%s %s", local, peer)
+}
+
+func tcpEndpoint(addr string, port uint) string {
+	return net.JoinHostPort(addr, fmt.Sprintf("%d", port))
+}
+
+// printSymbolicPattern is one value of a __print_symbolic(), such as
+// { 2, "NOT_SPECIFIED" }
+var printSymbolicPattern = regexp.MustCompile(`\{\s*(-?\w+)\s*,\s*"([^"]*)"\s*\}`)
+
+// TracepointSymbols will return the names of the values of a field of
+// a tracepoint, from the __print_symbolic() in its format in tracefs.
+// The names are empty if the tracepoint or the field is not found.
+func TracepointSymbols(group, name, field string) map[int]string {
+	for _, path := range tracefsPaths {
+		raw, err := ioutil.ReadFile(filepath.Join(path, "events", group, name, "format"))
+		if err != nil {
+			continue
+		}
+		return printSymbolic(string(raw), field)
+	}
+	return make(map[int]string)
+}
+
+// printSymbolic will parse the values of the __print_symbolic() of a
+// field in the print fmt of a tracepoint format. The end of the call
+// is its balanced closing parenthesis, as values such as
+// { (1 << 2), "NAME" } have parentheses of their own.
+func printSymbolic(format, field string) map[int]string {
+	symbols := make(map[int]string)
+	call := "__print_symbolic(REC->" + field + ","
+	start := strings.Index(format, call)
+	if start < 0 {
+		return symbols
+	}
+	format = format[start+len(call):]
+	depth, quoted := 1, false
+	for i := 0; i < len(format) && depth > 0; i++ {
+		switch {
+		case quoted && format[i] == '\\':
+			i++
+		case format[i] == '"':
+			quoted = !quoted
+		case quoted:
+		case format[i] == '(':
+			depth++
+		case format[i] == ')':
+			depth--
+			if depth == 0 {
+				format = format[:i]
+			}
+		}
+	}
+	for _, match := range printSymbolicPattern.FindAllStringSubmatch(format, -1) {
+		value, err := strconv.ParseInt(match[1], 0, 64)
+		if err != nil {
+			continue
+		}
+		symbols[int(value)] = match[2]
+	}
+	return symbols
+}
+
+type DropTCP func(d *tcp_data_t) bool
+
+// DropTCPUnowned will drop every event of a socket without an owner,
+// such as the sockets open before the point was loaded.
+func DropTCPUnowned(d *tcp_data_t) bool {
+	return d.Pid == 0
+}
+
+// DropTCPLoopback will drop every event between loopback addresses.
+func DropTCPLoopback(d *tcp_data_t) bool {
+	if d.Family == unix.AF_INET {
+		return d.Saddr[0] == 127 && d.Daddr[0] == 127
+	}
+	loopback := net.IPv6loopback
+	return net.IP(d.Saddr[:]).Equal(loopback) && net.IP(d.Daddr[:]).Equal(loopback)
+}
@@ -0,0 +1,48 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrintSymbolic(t *testing.T) {
+	format := `print fmt: "skbaddr=%p protocol=%u location=%pS reason: %s", REC->skbaddr, REC->protocol, REC->location, ` +
+		`__print_symbolic(REC->reason, { 2, "NOT_SPECIFIED" }, { 3, "NO_SOCKET" }, { (1 << 16), "SUBSYS" }, { 5, "CLOSE)" }, { 4, "PKT_TOO_SMALL" }), ` +
+		`__print_symbolic(REC->rx_sk, { 9, "OTHER" })`
+	tests := []struct {
+		field   string
+		symbols map[int]string
+	}{
+		{"reason", map[int]string{2: "NOT_SPECIFIED", 3: "NO_SOCKET", 4: "PKT_TOO_SMALL", 5: "CLOSE)"}},
+		{"rx_sk", map[int]string{9: "OTHER"}},
+		{"protocol", map[int]string{}},
+	}
+	for _, test := range tests {
+		symbols := printSymbolic(format, test.field)
+		if !reflect.DeepEqual(symbols, test.symbols) {
+			t.Errorf("expected %s symbols %v, got %v", test.field, test.symbols, symbols)
+		}
+	}
+}
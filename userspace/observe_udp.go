@@ -0,0 +1,454 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kris-nova/logger"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	EventNameUDPFlow     = "UDPFlow"
+	EventNameDNSQuery    = "DNSQuery"
+	EventNameDNSResponse = "DNSResponse"
+
+	// DefaultUDPFlowInterval is how often the UDP flow counters are sent.
+	DefaultUDPFlowInterval = 10 * time.Second
+)
+
+// Directions of UDP_SEND and UDP_RECV in bpf.c
+const (
+	udpSend = 1
+	udpRecv = 2
+)
+
+var dnsRCodes = map[dnsmessage.RCode]string{
+	dnsmessage.RCodeSuccess:        "NOERROR",
+	dnsmessage.RCodeFormatError:    "FORMERR",
+	dnsmessage.RCodeServerFailure:  "SERVFAIL",
+	dnsmessage.RCodeNameError:      "NXDOMAIN",
+	dnsmessage.RCodeNotImplemented: "NOTIMP",
+	dnsmessage.RCodeRefused:        "REFUSED",
+}
+
+// UDPObservationPoint will observe every UDP message that is sent or
+// received with sendto(), recvfrom(), sendmsg(), recvmsg(), sendmmsg()
+// or recvmmsg(), or with read() and write() on a connected socket,
+// such as the Go resolver. send() and recv() are sendto() and recvfrom().
+//
+//   UDPFlow      the bytes and packets of a flow since the last UDPFlow
+//   DNSQuery     a DNS query on port 53, sent or received
+//   DNSResponse  a DNS response on port 53, sent or received
+//
+// Messages are counted in the kernel, by process and address, and the
+// counters are sent every interval for the flows that changed. A flow
+// that did not change is removed. Only the first 4 messages of a
+// sendmmsg() or recvmmsg() are counted, and readv() and writev() are
+// not counted.
+type UDPObservationPoint struct {
+	reference   ObservationReference
+	dropFilters []DropDNS
+	interval    time.Duration
+	flows       map[udp_flow_key_t]udp_flow_t
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+// Load will start sending the flow counters every interval, until
+// the point is closed.
+func (p *UDPObservationPoint) Load() error {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.flush()
+			case <-p.done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Close will stop sending the flow counters.
+func (p *UDPObservationPoint) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.done)
+	})
+	return nil
+}
+
+// flush will send a UDPFlow for every flow that changed since the last
+// flush, and remove every flow that did not.
+func (p *UDPObservationPoint) flush() {
+	var (
+		key  udp_flow_key_t
+		flow udp_flow_t
+		idle []udp_flow_key_t
+	)
+	entries := p.reference.probe.UdpFlows.Iterate()
+	for entries.Next(&key, &flow) {
+		last := p.flows[key]
+		if flow.Tx_packets == last.Tx_packets && flow.Rx_packets == last.Rx_packets {
+			idle = append(idle, key)
+			continue
+		}
+		p.flows[key] = flow
+		p.reference.eventCh <- NewUDPFlowEvent(key, flow, last, p.interval)
+	}
+	if err := entries.Err(); err != nil {
+		logger.Warning("Unable to read UDP flows: %v", err)
+	}
+	for _, key := range idle {
+
+		// Deliberate design: A message counted between the read and the
+		// delete is lost. The flow was idle for a whole interval.
+		err := p.reference.probe.UdpFlows.Delete(&key)
+		if err != nil && err != ebpf.ErrKeyNotExist {
+			logger.Debug("Unable to remove UDP flow: %v", err)
+		}
+		delete(p.flows, key)
+	}
+}
+
+func (p *UDPObservationPoint) Event(record perf.Record) error {
+	data, err := EventDNS(record)
+	if err != nil {
+		return err
+	}
+
+	for _, drop := range p.dropFilters {
+		if drop(data) {
+			return nil
+		}
+	}
+
+	p.reference.eventCh <- NewDNSEvent(record.CPU, data)
+	return nil
+}
+
+func (p *UDPObservationPoint) Tracepoints() map[string]TracepointData {
+	probe := p.reference.probe
+	syscalls := []struct {
+		name        string
+		enter, exit *ebpf.Program
+	}{
+		{"sendto", probe.EnterSendto, probe.ExitSendto},
+		{"recvfrom", probe.EnterRecvfrom, probe.ExitRecvfrom},
+		{"sendmsg", probe.EnterSendmsg, probe.ExitSendmsg},
+		{"recvmsg", probe.EnterRecvmsg, probe.ExitRecvmsg},
+		{"sendmmsg", probe.EnterSendmmsg, probe.ExitSendmmsg},
+		{"recvmmsg", probe.EnterRecvmmsg, probe.ExitRecvmmsg},
+		{"write", probe.EnterWrite, probe.ExitWrite},
+		{"read", probe.EnterRead, probe.ExitRead},
+	}
+	tracepoints := map[string]TracepointData{}
+	for _, call := range syscalls {
+		enter, exit := "sys_enter_"+call.name, "sys_exit_"+call.name
+		tracepoints[enter] = TracepointData{
+			Group:      BPFGroupSyscalls,
+			Tracepoint: enter,
+			Program:    call.enter,
+		}
+		tracepoints[exit] = TracepointData{
+			Group:      BPFGroupSyscalls,
+			Tracepoint: exit,
+			Program:    call.exit,
+		}
+	}
+	return tracepoints
+}
+
+func (p *UDPObservationPoint) SetReference(reference ObservationReference) {
+	p.reference = reference
+}
+
+// NewUDPObservationPoint will count the UDP flows, and send them every
+// interval. An interval of 0 is DefaultUDPFlowInterval.
+func NewUDPObservationPoint(interval time.Duration, dropFilters []DropDNS) *UDPObservationPoint {
+	if interval <= 0 {
+		interval = DefaultUDPFlowInterval
+	}
+	return &UDPObservationPoint{
+		dropFilters: dropFilters,
+		interval:    interval,
+		flows:       make(map[udp_flow_key_t]udp_flow_t),
+		done:        make(chan struct{}),
+	}
+}
+
+// UDPFlowEvent is the bytes and packets of a flow of one process,
+// since the last UDPFlowEvent of the flow. PeerAddr is empty for a
+// socket that is not connected and received without an address.
+type UDPFlowEvent struct {
+	EventName       string  `json:"Name"`
+	PID             uint    `json:"PID"`
+	Comm            string  `json:"Comm"`
+	Family          string  `json:"Family"`
+	LocalAddr       string  `json:"LocalAddr"`
+	LocalPort       uint    `json:"LocalPort"`
+	PeerAddr        string  `json:"PeerAddr,omitempty"`
+	PeerPort        uint    `json:"PeerPort,omitempty"`
+	BytesSent       uint64  `json:"BytesSent"`
+	PacketsSent     uint64  `json:"PacketsSent"`
+	BytesReceived   uint64  `json:"BytesReceived"`
+	PacketsReceived uint64  `json:"PacketsReceived"`
+	Interval        float64 `json:"Interval"`
+	ContainerMetadata
+	EventTime
+}
+
+func NewUDPFlowEvent(key udp_flow_key_t, flow, last udp_flow_t, interval time.Duration) *UDPFlowEvent {
+	e := &UDPFlowEvent{
+		EventName:         EventNameUDPFlow,
+		PID:               uint(key.Pid),
+		Comm:              BytesToString32(flow.Comm),
+		Family:            socketFamilies[key.Family],
+		LocalPort:         uint(key.Lport),
+		PeerPort:          uint(key.Rport),
+		BytesSent:         flow.Tx_bytes - last.Tx_bytes,
+		PacketsSent:       flow.Tx_packets - last.Tx_packets,
+		BytesReceived:     flow.Rx_bytes - last.Rx_bytes,
+		PacketsReceived:   flow.Rx_packets - last.Rx_packets,
+		Interval:          interval.Seconds(),
+		ContainerMetadata: containerMetadataForPID(int(key.Pid)),
+	}
+	e.LocalAddr, e.PeerAddr = inetAddr(key.Family, key.Laddr), inetAddr(key.Family, key.Raddr)
+	if e.PeerPort == 0 {
+		e.PeerAddr = ""
+	}
+	return e
+}
+
+func (e *UDPFlowEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *UDPFlowEvent) String() string {
+	return fmt.Sprintf("[%s] (%d) udp %s:%d <-> %s:%d sent:%d/%d received:%d/%d", e.Comm, e.PID, e.LocalAddr, e.LocalPort, e.PeerAddr, e.PeerPort,
+		e.BytesSent, e.PacketsSent, e.BytesReceived, e.PacketsReceived)
+}
+
+func (e *UDPFlowEvent) Name() string {
+	return e.EventName
+}
+
+// DNSAnswer is one answer of a DNSResponse, such as an A record
+// and its address.
+type DNSAnswer struct {
+	Name string `json:"Name"`
+	Type string `json:"Type"`
+	TTL  uint32 `json:"TTL"`
+	Data string `json:"Data,omitempty"`
+}
+
+// DNSEvent is a DNS message on port 53. Sent is true if the process
+// sent the message, which is a query from a client, or a response
+// from a server. Only the first 512 bytes of a message are read, so
+// Error is set for the answers that could not be parsed.
+type DNSEvent struct {
+	CPU       int         `json:"CPU"`
+	EventName string      `json:"Name"`
+	PID       uint        `json:"PID"`
+	PPID      uint        `json:"PPID"`
+	UID       uint        `json:"UID"`
+	Comm      string      `json:"Comm"`
+	Sent      bool        `json:"Sent"`
+	Family    string      `json:"Family"`
+	LocalAddr string      `json:"LocalAddr"`
+	LocalPort uint        `json:"LocalPort"`
+	PeerAddr  string      `json:"PeerAddr"`
+	PeerPort  uint        `json:"PeerPort"`
+	ID        uint16      `json:"ID"`
+	QueryName string      `json:"QueryName"`
+	QueryType string      `json:"QueryType"`
+	RCode     string      `json:"RCode,omitempty"`
+	Answers   []DNSAnswer `json:"Answers,omitempty"`
+	Truncated bool        `json:"Truncated"`
+	Length    uint        `json:"Length"`
+	Error     string      `json:"Error,omitempty"`
+	ContainerMetadata
+	EventTime
+}
+
+func NewDNSEvent(cpu int, data *dns_data_t) *DNSEvent {
+	e := &DNSEvent{
+		CPU:               cpu,
+		EventName:         EventNameDNSQuery,
+		PID:               uint(data.Pid),
+		PPID:              uint(data.Ppid),
+		UID:               uint(data.Uid),
+		Comm:              BytesToString32(data.Comm),
+		Sent:              data.Direction == udpSend,
+		Family:            socketFamilies[data.Family],
+		LocalAddr:         inetAddr(data.Family, data.Laddr),
+		LocalPort:         uint(data.Lport),
+		PeerAddr:          inetAddr(data.Family, data.Raddr),
+		PeerPort:          uint(data.Rport),
+		Length:            uint(data.Len),
+		ContainerMetadata: containerMetadataForPID(int(data.Pid)),
+	}
+	length := int(data.Len)
+	if length > len(data.Payload) {
+		length = len(data.Payload)
+	}
+	err := e.parse(data.Payload[:length])
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// parse will decode a DNS message. The header and the question are
+// set even if the answers can not be parsed.
+func (e *DNSEvent) parse(message []byte) error {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(message)
+	if err != nil {
+		return err
+	}
+	e.ID = header.ID
+	e.Truncated = header.Truncated
+	if header.Response {
+		e.EventName = EventNameDNSResponse
+		e.RCode = DNSRCodeName(header.RCode)
+	}
+	question, err := parser.Question()
+	if err == nil {
+		e.QueryName = question.Name.String()
+		e.QueryType = DNSTypeName(question.Type)
+		err = parser.SkipAllQuestions()
+	}
+	if err != nil || !header.Response {
+		return err
+	}
+	for {
+		answer, err := parser.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		data, err := dnsAnswerData(&parser, answer.Type)
+		if err != nil {
+			return err
+		}
+		e.Answers = append(e.Answers, DNSAnswer{
+			Name: answer.Name.String(),
+			Type: DNSTypeName(answer.Type),
+			TTL:  answer.TTL,
+			Data: data,
+		})
+	}
+}
+
+func (e *DNSEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *DNSEvent) String() string {
+	var answers []string
+	for _, answer := range e.Answers {
+		answers = append(answers, fmt.Sprintf("%s %s", answer.Type, answer.Data))
+	}
+	detail := fmt.Sprintf("%s %s", e.QueryType, e.QueryName)
+	if e.EventName == EventNameDNSResponse {
+		detail = fmt.Sprintf("%s %s [%s]", detail, e.RCode, strings.Join(answers, ", "))
+	}
+	if e.Error != "" {
+		detail = fmt.Sprintf("%s: %s", detail, e.Error)
+	}
+	return fmt.Sprintf("[%s] (%d) %s %s:%d: %s", e.Comm, e.PID, e.EventName, e.PeerAddr, e.PeerPort, detail)
+}
+
+func (e *DNSEvent) Name() string {
+	return e.EventName
+}
+
+// dnsAnswerData will read the data of an answer as a string. Types
+// that are not decoded are skipped, and have no data.
+func dnsAnswerData(parser *dnsmessage.Parser, answerType dnsmessage.Type) (string, error) {
+	switch answerType {
+	case dnsmessage.TypeA:
+		r, err := parser.AResource()
+		return net.IP(r.A[:]).String(), err
+	case dnsmessage.TypeAAAA:
+		r, err := parser.AAAAResource()
+		return net.IP(r.AAAA[:]).String(), err
+	case dnsmessage.TypeCNAME:
+		r, err := parser.CNAMEResource()
+		return r.CNAME.String(), err
+	case dnsmessage.TypeNS:
+		r, err := parser.NSResource()
+		return r.NS.String(), err
+	case dnsmessage.TypePTR:
+		r, err := parser.PTRResource()
+		return r.PTR.String(), err
+	case dnsmessage.TypeMX:
+		r, err := parser.MXResource()
+		return fmt.Sprintf("%d %s", r.Pref, r.MX.String()), err
+	case dnsmessage.TypeSRV:
+		r, err := parser.SRVResource()
+		return fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, r.Target.String()), err
+	case dnsmessage.TypeTXT:
+		r, err := parser.TXTResource()
+		return strings.Join(r.TXT, " "), err
+	}
+	return "", parser.SkipAnswer()
+}
+
+// DNSTypeName will return the name of a DNS type, such as "AAAA".
+func DNSTypeName(t dnsmessage.Type) string {
+	return strings.TrimPrefix(t.String(), "Type")
+}
+
+// DNSRCodeName will return the name of a DNS response code, such as
+// "NXDOMAIN". Unknown codes are named by number.
+func DNSRCodeName(rcode dnsmessage.RCode) string {
+	if name, ok := dnsRCodes[rcode]; ok {
+		return name
+	}
+	return fmt.Sprintf("RCODE%d", rcode)
+}
+
+type DropDNS func(d *dns_data_t) bool
+
+// DropDNSComm will drop every DNS message of a process with this comm.
+func DropDNSComm(comm string) DropDNS {
+	return func(d *dns_data_t) bool {
+		return BytesToString32(d.Comm) == comm
+	}
+}
@@ -0,0 +1,319 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var testDNSQuestion = dnsmessage.Question{
+	Name:  dnsmessage.MustNewName("example.com."),
+	Type:  dnsmessage.TypeA,
+	Class: dnsmessage.ClassINET,
+}
+
+// testDNSMessage will build a DNS message with a single question, and
+// the answers added by the answers function.
+func testDNSMessage(t *testing.T, header dnsmessage.Header, answers func(b *dnsmessage.Builder) error) []byte {
+	b := dnsmessage.NewBuilder(nil, header)
+	b.EnableCompression()
+	err := b.StartQuestions()
+	if err == nil {
+		err = b.Question(testDNSQuestion)
+	}
+	if err == nil && answers != nil {
+		err = b.StartAnswers()
+		if err == nil {
+			err = answers(&b)
+		}
+	}
+	message, err2 := b.Finish()
+	if err == nil {
+		err = err2
+	}
+	if err != nil {
+		t.Fatalf("unable to build DNS message: %v", err)
+	}
+	return message
+}
+
+func testDNSAnswers(b *dnsmessage.Builder) error {
+	header := dnsmessage.ResourceHeader{Name: testDNSQuestion.Name, Class: dnsmessage.ClassINET, TTL: 300}
+	err := b.CNAMEResource(header, dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("www.example.com.")})
+	if err != nil {
+		return err
+	}
+	header.Name = dnsmessage.MustNewName("www.example.com.")
+	err = b.AResource(header, dnsmessage.AResource{A: [4]byte{93, 184, 216, 34}})
+	if err != nil {
+		return err
+	}
+	return b.AAAAResource(header, dnsmessage.AAAAResource{AAAA: [16]byte{0x26, 0x06, 0x28, 0x00, 15: 0x01}})
+}
+
+// testDNSPointer is a response for example.com. with a single answer.
+// The name of the answer is a compression pointer at the given offset.
+func testDNSPointer(offset byte) []byte {
+	return []byte{
+		0x12, 0x34, 0x81, 0x80, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+		7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, 0x00, 0x01, 0x00, 0x01,
+		0xc0, offset, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, 0x01, 0x2c, 0x00, 0x04, 93, 184, 216, 34,
+	}
+}
+
+func TestDNSEventParse(t *testing.T) {
+	response := testDNSMessage(t, dnsmessage.Header{ID: 7, Response: true, RecursionAvailable: true}, testDNSAnswers)
+	tests := []struct {
+		name    string
+		message []byte
+		event   DNSEvent
+		err     bool
+	}{
+		{
+			name:    "query",
+			message: testDNSMessage(t, dnsmessage.Header{ID: 7, RecursionDesired: true}, nil),
+			event:   DNSEvent{EventName: EventNameDNSQuery, ID: 7, QueryName: "example.com.", QueryType: "A"},
+		},
+		{
+			name:    "answers",
+			message: response,
+			event: DNSEvent{EventName: EventNameDNSResponse, ID: 7, QueryName: "example.com.", QueryType: "A", RCode: "NOERROR", Answers: []DNSAnswer{
+				{Name: "example.com.", Type: "CNAME", TTL: 300, Data: "www.example.com."},
+				{Name: "www.example.com.", Type: "A", TTL: 300, Data: "93.184.216.34"},
+				{Name: "www.example.com.", Type: "AAAA", TTL: 300, Data: "2606:2800::1"},
+			}},
+		},
+		{
+			name:    "nxdomain",
+			message: testDNSMessage(t, dnsmessage.Header{ID: 7, Response: true, RCode: dnsmessage.RCodeNameError}, func(b *dnsmessage.Builder) error { return nil }),
+			event:   DNSEvent{EventName: EventNameDNSResponse, ID: 7, QueryName: "example.com.", QueryType: "A", RCode: "NXDOMAIN"},
+		},
+		{
+			name:    "truncated flag",
+			message: testDNSMessage(t, dnsmessage.Header{ID: 7, Response: true, Truncated: true}, func(b *dnsmessage.Builder) error { return nil }),
+			event:   DNSEvent{EventName: EventNameDNSResponse, ID: 7, QueryName: "example.com.", QueryType: "A", RCode: "NOERROR", Truncated: true},
+		},
+		{
+			name:    "compression pointer",
+			message: testDNSPointer(12),
+			event: DNSEvent{EventName: EventNameDNSResponse, ID: 0x1234, QueryName: "example.com.", QueryType: "A", RCode: "NOERROR", Answers: []DNSAnswer{
+				{Name: "example.com.", Type: "A", TTL: 300, Data: "93.184.216.34"},
+			}},
+		},
+		{
+			name:    "pointer loop",
+			message: testDNSPointer(29),
+			event:   DNSEvent{EventName: EventNameDNSResponse, ID: 0x1234, QueryName: "example.com.", QueryType: "A", RCode: "NOERROR"},
+			err:     true,
+		},
+		{
+			name:    "truncated answers",
+			message: response[:len(response)-8],
+			event: DNSEvent{EventName: EventNameDNSResponse, ID: 7, QueryName: "example.com.", QueryType: "A", RCode: "NOERROR", Answers: []DNSAnswer{
+				{Name: "example.com.", Type: "CNAME", TTL: 300, Data: "www.example.com."},
+				{Name: "www.example.com.", Type: "A", TTL: 300, Data: "93.184.216.34"},
+			}},
+			err: true,
+		},
+		{
+			name:    "truncated question",
+			message: testDNSPointer(12)[:20],
+			event:   DNSEvent{EventName: EventNameDNSResponse, ID: 0x1234, RCode: "NOERROR"},
+			err:     true,
+		},
+		{
+			name:    "truncated header",
+			message: testDNSPointer(12)[:8],
+			event:   DNSEvent{EventName: EventNameDNSQuery},
+			err:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := DNSEvent{EventName: EventNameDNSQuery}
+			err := e.parse(test.message)
+			if (err != nil) != test.err {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+			if e.String() != test.event.String() {
+				t.Errorf("expected %q, got %q", test.event.String(), e.String())
+			}
+		})
+	}
+}
+
+func TestNewDNSEventLength(t *testing.T) {
+	message := testDNSMessage(t, dnsmessage.Header{ID: 7, RecursionDesired: true}, nil)
+	data := &dns_data_t{
+		Pid:       uint32(os.Getpid()),
+		Direction: udpSend,
+		Len:       1024,
+		Family:    2,
+		Rport:     53,
+	}
+	copy(data.Payload[:], message)
+	e := NewDNSEvent(0, data)
+	if e.QueryName != "example.com." || e.Length != 1024 || !e.Sent {
+		t.Errorf("unexpected event %s", e.String())
+	}
+}
+
+// testLoadObservationPoint will load the probe, attach the tracepoints
+// of the point, and send the events of the point to the returned channel.
+// The test is skipped when the probe can not be loaded, such as without
+// CAP_BPF.
+func testLoadObservationPoint(t *testing.T, point ObservationPoint) chan Event {
+	probe := gen_probeObjects{}
+	err := loadGen_probeObjects(&probe, nil)
+	if err != nil {
+		t.Skipf("unable to load the probe: %v", err)
+	}
+	t.Cleanup(func() { probe.Close() })
+	eventCh := make(chan Event, 64)
+	point.SetReference(ObservationReference{probe: probe, eventCh: eventCh})
+	if loader, ok := point.(ObservationPointLoader); ok {
+		err := loader.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if closer, ok := point.(ObservationPointCloser); ok {
+		t.Cleanup(func() { closer.Close() })
+	}
+	for _, td := range point.Tracepoints() {
+		l, err := link.Tracepoint(td.Group, td.Tracepoint, td.Program)
+		if err != nil {
+			t.Skipf("unable to load tracepoint %s/%s: %v", td.Group, td.Tracepoint, err)
+		}
+		t.Cleanup(func() { l.Close() })
+	}
+	reader, err := perf.NewReader(probe.Events, os.Getpagesize())
+	if err != nil {
+		t.Skipf("unable to start perf reader: %v", err)
+	}
+	t.Cleanup(func() { reader.Close() })
+	go func() {
+		for {
+			record, err := reader.Read()
+			if perf.IsClosed(err) {
+				return
+			}
+			if err != nil || record.LostSamples > 0 {
+				continue
+			}
+			_ = point.Event(record)
+		}
+	}()
+	return eventCh
+}
+
+// testDNSResponder will answer every A question with 192.0.2.1.
+func testDNSResponder(conn net.PacketConn) {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var parser dnsmessage.Parser
+		header, err := parser.Start(buf[:n])
+		if err != nil {
+			continue
+		}
+		question, err := parser.Question()
+		if err != nil {
+			continue
+		}
+		b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true, RecursionDesired: header.RecursionDesired, RecursionAvailable: true})
+		b.EnableCompression()
+		_ = b.StartQuestions()
+		_ = b.Question(question)
+		_ = b.StartAnswers()
+		if question.Type == dnsmessage.TypeA {
+			_ = b.AResource(dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: 60}, dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}})
+		}
+		response, err := b.Finish()
+		if err != nil {
+			continue
+		}
+		_, _ = conn.WriteTo(response, addr)
+	}
+}
+
+func TestDNSEventLocalResponder(t *testing.T) {
+	eventCh := testLoadObservationPoint(t, NewUDPObservationPoint(time.Hour, nil))
+
+	// Only messages to or from port 53 are decoded
+	conn, err := net.ListenPacket("udp", "127.0.0.1:53")
+	if err != nil {
+		t.Skipf("unable to listen: %v", err)
+	}
+	defer conn.Close()
+	go testDNSResponder(conn)
+
+	// The Go resolver will write() and read() a connected socket
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", conn.LocalAddr().String())
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addrs, err := resolver.LookupIPAddr(ctx, "dse.test.")
+	if err != nil {
+		t.Fatalf("unable to resolve: %v", err)
+	}
+	if len(addrs) != 1 || addrs[0].IP.String() != "192.0.2.1" {
+		t.Fatalf("unexpected addresses %v", addrs)
+	}
+
+	var query, response bool
+	deadline := time.After(5 * time.Second)
+	for !query || !response {
+		select {
+		case event := <-eventCh:
+			e, ok := event.(*DNSEvent)
+			if !ok || e.PID != uint(os.Getpid()) || e.PeerPort != 53 || e.QueryName != "dse.test." || e.QueryType != "A" {
+				continue
+			}
+			switch e.EventName {
+			case EventNameDNSQuery:
+				query = query || e.Sent
+			case EventNameDNSResponse:
+				response = response || !e.Sent && len(e.Answers) == 1 && e.Answers[0].Data == "192.0.2.1" && e.RCode == "NOERROR"
+			}
+		case <-deadline:
+			t.Fatalf("expected a sent A query and a received response for dse.test., got query %t and response %t", query, response)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kris-nova/logger"
+)
+
+// Output is a sink that Events are written to.
+type Output interface {
+	Write(event Event) error
+	Close() error
+}
+
+// Outputs will write every Event to each Output.
+type Outputs []Output
+
+func (o Outputs) Write(event Event) error {
+	var errs []string
+	for _, output := range o {
+		err := output.Write(event)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func (o Outputs) Close() error {
+	var errs []string
+	for _, output := range o {
+		err := output.Close()
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// WriteEvents will write every Event to the outputs.
+// This will block.
+func (o *Observer) WriteEvents(output Output) {
+	for {
+		event := <-o.eventCh
+		err := output.Write(event)
+		if err != nil {
+			logger.Warning("Unable to write event: %v", err)
+		}
+	}
+}
+
+// ParseOutputs will create an Output for each output spec.
+func ParseOutputs(specs []string) (Outputs, error) {
+	var outputs Outputs
+	for _, spec := range specs {
+		output, err := ParseOutput(spec)
+		if err != nil {
+			outputs.Close()
+			return nil, err
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
+
+// ParseOutput will create an Output from an output spec. Every output
+// accepts a format (see ParseFormatter) such as stdout?format=ecs
+//
+//   stdout
+//   file:///var/log/dse/events.json?max-size=100M&max-age=24h&max-backups=7&compress=true
+//   unix:///run/dse/events.sock
+//   syslog+udp://localhost:514
+//   syslog+tcp://localhost:601
+//   syslog+unix:///dev/log
+//   journald
+//   otlp+grpc://localhost:4317?tls=false&header=authorization:Bearer%20token
+//   otlp+http://localhost:4318/v1/logs
+//   otlp+https://collector.example.com:4318/v1/logs
+func ParseOutput(spec string) (Output, error) {
+	if spec == "-" {
+		spec = "stdout"
+	}
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output %s: %v", spec, err)
+	}
+	query := u.Query()
+	formatter, err := ParseFormatter(query.Get("format"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid output %s: %v", spec, err)
+	}
+	scheme := u.Scheme
+	if scheme == "" {
+		// Outputs without a target (stdout, journald)
+		scheme = u.Path
+	}
+	switch scheme {
+	case "stdout":
+		return NewStdoutOutput(formatter), nil
+	case "file":
+		config := FileOutputConfig{
+			Path:      u.Path,
+			Formatter: formatter,
+		}
+		if v := query.Get("max-size"); v != "" {
+			config.MaxSize, err = ParseByteSize(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid output %s: %v", spec, err)
+			}
+		}
+		if v := query.Get("max-age"); v != "" {
+			config.MaxAge, err = time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid output %s: %v", spec, err)
+			}
+		}
+		if v := query.Get("max-backups"); v != "" {
+			config.MaxBackups, err = strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid output %s: %v", spec, err)
+			}
+		}
+		if v := query.Get("compress"); v != "" {
+			config.Compress, err = strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid output %s: %v", spec, err)
+			}
+		}
+		return NewFileOutput(config)
+	case "unix":
+		return NewUnixSocketOutput(u.Path, formatter)
+	case "syslog+udp", "syslog+tcp":
+		return NewSyslogOutput(strings.TrimPrefix(u.Scheme, "syslog+"), u.Host, formatter)
+	case "syslog+unix":
+		return NewSyslogOutput("unixgram", u.Path, formatter)
+	case "journald":
+		path := u.Path
+		if u.Scheme == "" {
+			path = JournaldSocket
+		}
+		return NewJournaldOutput(path, formatter)
+	case "otlp+grpc", "otlp+http", "otlp+https":
+		config := OTLPOutputConfig{
+			Protocol:  OTLPProtocolGRPC,
+			Endpoint:  u.Host,
+			Headers:   map[string]string{},
+			Formatter: formatter,
+		}
+		if u.Scheme != "otlp+grpc" {
+			path := u.Path
+			if path == "" {
+				path = OTLPLogsPath
+			}
+			config.Protocol = OTLPProtocolHTTP
+			config.Endpoint = fmt.Sprintf("%s://%s%s", strings.TrimPrefix(u.Scheme, "otlp+"), u.Host, path)
+		}
+		if v := query.Get("tls"); v != "" {
+			config.TLS, err = strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid output %s: %v", spec, err)
+			}
+		}
+		for _, header := range query["header"] {
+			kv := strings.SplitN(header, ":", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid output %s: invalid header %s", spec, header)
+			}
+			config.Headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+		return NewOTLPOutput(config)
+	}
+	return nil, fmt.Errorf("invalid output %s: unknown type %s", spec, scheme)
+}
+
+// ParseByteSize will parse a size such as 512, 64K, 100M or 1G.
+func ParseByteSize(size string) (int64, error) {
+	multiplier := int64(1)
+	s := strings.TrimSuffix(strings.ToUpper(size), "B")
+	switch {
+	case strings.HasSuffix(s, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(s, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(s, "G"):
+		multiplier = 1 << 30
+	}
+	n, err := strconv.ParseInt(strings.TrimRight(s, "KMG"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %s", size)
+	}
+	return n * multiplier, nil
+}
+
+// StdoutOutput will print the events, one per line
+type StdoutOutput struct {
+	formatter Formatter
+}
+
+func NewStdoutOutput(formatter Formatter) *StdoutOutput {
+	return &StdoutOutput{
+		formatter: formatterOrDefault(formatter),
+	}
+}
+
+func (s *StdoutOutput) Write(event Event) error {
+	b, err := s.formatter.Format(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(os.Stdout, string(b))
+	return err
+}
+
+func (s *StdoutOutput) Close() error {
+	return nil
+}
@@ -0,0 +1,288 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kris-nova/logger"
+)
+
+const (
+	// rotatedTimeFormat is appended to the name of a rotated file.
+	rotatedTimeFormat = "20060102T150405.000000000"
+)
+
+type FileOutputConfig struct {
+
+	// Path of the active file. Rotated files are written
+	// next to it as <Path>.<timestamp>[.gz]
+	Path string
+
+	// MaxSize in bytes before the file is rotated. 0 disables
+	// size based rotation.
+	MaxSize int64
+
+	// MaxAge of the active file before it is rotated. 0 disables
+	// time based rotation.
+	MaxAge time.Duration
+
+	// MaxBackups is the number of rotated files to keep. 0 keeps
+	// every rotated file.
+	MaxBackups int
+
+	// Compress rotated files with gzip.
+	Compress bool
+
+	// Formatter defaults to JSON
+	Formatter Formatter
+}
+
+// FileOutput will write newline delimited events to a file,
+// rotating the file based on size and age.
+type FileOutput struct {
+	config  FileOutputConfig
+	mtx     sync.Mutex
+	file    *os.File
+	size    int64
+	opened  time.Time
+	rotated chan string
+	pending sync.WaitGroup
+	closed  bool
+}
+
+func NewFileOutput(config FileOutputConfig) (*FileOutput, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("file output missing path")
+	}
+	config.Formatter = formatterOrDefault(config.Formatter)
+	f := &FileOutput{
+		config:  config,
+		rotated: make(chan string, 16),
+	}
+	err := os.MkdirAll(filepath.Dir(config.Path), 0755)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create output directory: %v", err)
+	}
+	err = f.open()
+	if err != nil {
+		return nil, err
+	}
+	f.pending.Add(1)
+	go f.cleanup()
+	return f, nil
+}
+
+func (f *FileOutput) open() error {
+	file, err := os.OpenFile(f.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("unable to open output file: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("unable to stat output file: %v", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	f.opened = time.Now()
+	return nil
+}
+
+func (f *FileOutput) Write(event Event) error {
+	b, err := f.config.Formatter.Format(event)
+	if err != nil {
+		return err
+	}
+	return f.writeLine(b)
+}
+
+func (f *FileOutput) writeLine(b []byte) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.closed {
+		return fmt.Errorf("file output %s closed", f.config.Path)
+	}
+	if f.file == nil {
+		// A previous rotation was unable to reopen the file
+		err := f.open()
+		if err != nil {
+			return err
+		}
+	}
+	if f.shouldRotate(int64(len(b) + 1)) {
+		err := f.rotate()
+		if err != nil {
+			return err
+		}
+	}
+	n, err := f.file.Write(append(b, '\n'))
+	f.size += int64(n)
+	return err
+}
+
+func (f *FileOutput) shouldRotate(next int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.config.MaxSize > 0 && f.size+next > f.config.MaxSize {
+		return true
+	}
+	if f.config.MaxAge > 0 && time.Since(f.opened) > f.config.MaxAge {
+		return true
+	}
+	return false
+}
+
+// rotate will move the active file out of the way and open a new one.
+// Compression and pruning happen in the background, see cleanup().
+func (f *FileOutput) rotate() error {
+	err := f.file.Close()
+	if err != nil {
+		return fmt.Errorf("unable to close output file: %v", err)
+	}
+	f.file = nil
+	rotated := fmt.Sprintf("%s.%s", f.config.Path, time.Now().Format(rotatedTimeFormat))
+	err = os.Rename(f.config.Path, rotated)
+	if err != nil {
+		return fmt.Errorf("unable to rotate output file: %v", err)
+	}
+	err = f.open()
+	if err != nil {
+		// Move the rotated file back so no events are lost, and
+		// try once more before giving up on this write.
+		logger.Critical("Unable to reopen %s after rotation: %v", f.config.Path, err)
+		if rerr := os.Rename(rotated, f.config.Path); rerr != nil {
+			f.rotated <- rotated
+		}
+		if rerr := f.open(); rerr != nil {
+			return fmt.Errorf("unable to reopen output file after rotation: %v", err)
+		}
+		return nil
+	}
+	f.rotated <- rotated
+	return nil
+}
+
+// cleanup will compress and prune rotated files one at a time,
+// so a file is never pruned while it is being compressed.
+func (f *FileOutput) cleanup() {
+	defer f.pending.Done()
+	for rotated := range f.rotated {
+		if f.config.Compress {
+			err := gzipFile(rotated)
+			if err != nil {
+				logger.Warning("Unable to compress %s: %v", rotated, err)
+			}
+		}
+		err := f.prune()
+		if err != nil {
+			logger.Warning("Unable to prune %s: %v", f.config.Path, err)
+		}
+	}
+}
+
+// prune will remove the oldest rotated files past MaxBackups.
+func (f *FileOutput) prune() error {
+	if f.config.MaxBackups <= 0 {
+		return nil
+	}
+	matches, err := filepath.Glob(f.config.Path + ".*")
+	if err != nil {
+		return err
+	}
+	var rotated []string
+	for _, match := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(match, f.config.Path+"."), ".gz")
+		if _, err := time.Parse(rotatedTimeFormat, suffix); err == nil {
+			rotated = append(rotated, match)
+		}
+	}
+	if len(rotated) <= f.config.MaxBackups {
+		return nil
+	}
+	// The timestamp format sorts lexically
+	sort.Strings(rotated)
+	for _, old := range rotated[:len(rotated)-f.config.MaxBackups] {
+		err := os.Remove(old)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	_, err = io.Copy(gz, in)
+	if err != nil {
+		out.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	err = gz.Close()
+	if err != nil {
+		out.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	err = out.Close()
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (f *FileOutput) Close() error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	close(f.rotated)
+	f.pending.Wait()
+	return err
+}
@@ -0,0 +1,219 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"bufio"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileOutputRotation(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxSize    int64
+		maxBackups int
+		compress   bool
+		lines      int
+		rotated    int
+	}{
+		{
+			name:    "no rotation",
+			lines:   10,
+			rotated: 0,
+		},
+		{
+			name:    "size rotation",
+			maxSize: 25,
+			lines:   6,
+			rotated: 2,
+		},
+		{
+			name:       "pruned backups",
+			maxSize:    10,
+			maxBackups: 2,
+			lines:      8,
+			rotated:    2,
+		},
+		{
+			name:     "compressed backups",
+			maxSize:  10,
+			compress: true,
+			lines:    4,
+			rotated:  3,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "events.log")
+			f, err := NewFileOutput(FileOutputConfig{
+				Path:       path,
+				MaxSize:    test.maxSize,
+				MaxBackups: test.maxBackups,
+				Compress:   test.compress,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < test.lines; i++ {
+				err := f.writeLine([]byte("event-line"))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			err = f.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			rotated := rotatedFiles(t, path)
+			if len(rotated) != test.rotated {
+				t.Fatalf("expected %d rotated files, found %v", test.rotated, rotated)
+			}
+			for _, name := range rotated {
+				if test.compress != strings.HasSuffix(name, ".gz") {
+					t.Errorf("unexpected rotated file %s", name)
+				}
+			}
+			lines := countLines(t, path)
+			for _, name := range rotated {
+				lines += countLines(t, name)
+			}
+			if test.maxBackups == 0 && lines != test.lines {
+				t.Errorf("expected %d lines, found %d", test.lines, lines)
+			}
+		})
+	}
+}
+
+func TestFileOutputAgeRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	f, err := NewFileOutput(FileOutputConfig{
+		Path:   path,
+		MaxAge: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	err = f.writeLine([]byte("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	err = f.writeLine([]byte("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rotatedFiles(t, path)); n != 1 {
+		t.Errorf("expected 1 rotated file, found %d", n)
+	}
+}
+
+func TestFileOutputClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	f, err := NewFileOutput(FileOutputConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed reopen during rotation leaves no active file,
+	// Close() must still stop the cleanup goroutine.
+	f.mtx.Lock()
+	f.file.Close()
+	f.file = nil
+	f.mtx.Unlock()
+
+	done := make(chan error)
+	go func() {
+		done <- f.Close()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() did not return")
+	}
+	err = f.Close()
+	if err != nil {
+		t.Errorf("second Close(): %v", err)
+	}
+	err = f.writeLine([]byte("late"))
+	if err == nil {
+		t.Errorf("expected write after Close() to fail")
+	}
+}
+
+func TestFileOutputReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	f, err := NewFileOutput(FileOutputConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.mtx.Lock()
+	f.file.Close()
+	f.file = nil
+	f.mtx.Unlock()
+	err = f.writeLine([]byte("after reopen"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countLines(t, path); n != 1 {
+		t.Errorf("expected 1 line, found %d", n)
+	}
+}
+
+func rotatedFiles(t *testing.T, path string) []string {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func countLines(t *testing.T, path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner = bufio.NewScanner(gz)
+	}
+	n := 0
+	for scanner.Scan() {
+		n++
+	}
+	return n
+}
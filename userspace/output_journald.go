@@ -0,0 +1,228 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// JournaldSocket is the systemd-journald native protocol socket.
+	JournaldSocket = "/run/systemd/journal/socket"
+
+	// journaldPriority is syslog severity informational
+	journaldPriority = "6"
+
+	// journaldFieldPrefix is prefixed to every event field.
+	journaldFieldPrefix = "DSE_"
+)
+
+// JournaldOutput will send events to systemd-journald using the
+// native protocol. Every event field is sent as a structured
+// journal field (DSE_FILENAME, DSE_PARENTPROC_PID, ...) and the
+// formatted event is sent as the MESSAGE.
+//
+// More:
+//   https://systemd.io/JOURNAL_NATIVE_PROTOCOL/
+type JournaldOutput struct {
+	path      string
+	formatter Formatter
+	mtx       sync.Mutex
+	conn      *net.UnixConn
+}
+
+func NewJournaldOutput(path string, formatter Formatter) (*JournaldOutput, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to journald %s: %v", path, err)
+	}
+	return &JournaldOutput{
+		path:      path,
+		formatter: formatterOrDefault(formatter),
+		conn:      conn,
+	}, nil
+}
+
+func (j *JournaldOutput) Write(event Event) error {
+	b, err := event.JSON()
+	if err != nil {
+		return err
+	}
+	fields := map[string]string{}
+	var raw interface{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	err = decoder.Decode(&raw)
+	if err != nil {
+		return err
+	}
+	journaldFlatten(fields, journaldFieldPrefix[:len(journaldFieldPrefix)-1], raw)
+	msg, err := j.formatter.Format(event)
+	if err != nil {
+		return err
+	}
+	fields["MESSAGE"] = string(msg)
+	fields["PRIORITY"] = journaldPriority
+	fields["SYSLOG_IDENTIFIER"] = SyslogAppName
+	fields["DSE_EVENT"] = event.Name()
+
+	var keys []string
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	payload := &bytes.Buffer{}
+	for _, k := range keys {
+		journaldAppendField(payload, k, fields[k])
+	}
+	return j.send(payload.Bytes())
+}
+
+// send will write the payload to journald. Payloads that are too large
+// for a datagram are sent as a sealed memfd.
+func (j *JournaldOutput) send(payload []byte) error {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	if j.conn == nil {
+		return fmt.Errorf("journald output %s closed", j.path)
+	}
+	_, err := j.conn.Write(payload)
+	if err == nil {
+		return nil
+	}
+	if !isMessageTooLong(err) {
+		return fmt.Errorf("unable to write to journald: %v", err)
+	}
+	fd, err := unix.MemfdCreate("dse-journal", unix.MFD_ALLOW_SEALING|unix.MFD_CLOEXEC)
+	if err != nil {
+		return fmt.Errorf("unable to create memfd: %v", err)
+	}
+	defer unix.Close(fd)
+	_, err = unix.Write(fd, payload)
+	if err != nil {
+		return fmt.Errorf("unable to write memfd: %v", err)
+	}
+	_, err = unix.FcntlInt(uintptr(fd), unix.F_ADD_SEALS, unix.F_SEAL_SHRINK|unix.F_SEAL_GROW|unix.F_SEAL_WRITE|unix.F_SEAL_SEAL)
+	if err != nil {
+		return fmt.Errorf("unable to seal memfd: %v", err)
+	}
+
+	// WriteMsgUnix() refuses a connected datagram socket, so the
+	// descriptor is sent with sendmsg() on the raw socket.
+	raw, err := j.conn.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("unable to write to journald: %v", err)
+	}
+	werr := raw.Write(func(sock uintptr) bool {
+		err = unix.Sendmsg(int(sock), nil, unix.UnixRights(fd), nil, 0)
+		return err != unix.EAGAIN
+	})
+	if werr != nil {
+		err = werr
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write to journald: %v", err)
+	}
+	return nil
+}
+
+func isMessageTooLong(err error) bool {
+	if opErr, ok := err.(*net.OpError); ok {
+		err = opErr.Err
+	}
+	if sysErr, ok := err.(*os.SyscallError); ok {
+		err = sysErr.Err
+	}
+	return err == syscall.EMSGSIZE || err == syscall.ENOBUFS
+}
+
+// journaldFlatten will turn nested JSON into journal field names.
+func journaldFlatten(fields map[string]string, prefix string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			journaldFlatten(fields, prefix+"_"+journaldFieldName(k), child)
+		}
+	case []interface{}:
+		var values []string
+		for _, child := range v {
+			values = append(values, fmt.Sprint(child))
+		}
+		fields[prefix] = strings.Join(values, ",")
+	case nil:
+	default:
+		fields[prefix] = fmt.Sprint(v)
+	}
+}
+
+// journaldFieldName will make a name safe for a journal field
+// (upper case letters, digits and underscores).
+func journaldFieldName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// journaldAppendField will serialize a field. Values containing a
+// newline use the binary length prefixed form.
+func journaldAppendField(b *bytes.Buffer, key, value string) {
+	b.WriteString(key)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+func (j *JournaldOutput) Close() error {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	if j.conn == nil {
+		return nil
+	}
+	err := j.conn.Close()
+	j.conn = nil
+	return err
+}
@@ -0,0 +1,155 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestJournaldOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+	}{
+		{"datagram", "/usr/bin/curl"},
+		{"memfd", "/tmp/" + strings.Repeat("a", 1<<20)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "journal.sock")
+			conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			j, err := NewJournaldOutput(path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer j.Close()
+			event := formatterEvent()
+			event.Filename = test.filename
+			err = j.Write(event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fields := readJournaldFields(t, conn)
+			expected := map[string]string{
+				"DSE_EVENT":         "ProcessExecuted",
+				"DSE_PID":           "42",
+				"DSE_FILENAME":      test.filename,
+				"DSE_CONTAINERID":   "abc123",
+				"PRIORITY":          journaldPriority,
+				"SYSLOG_IDENTIFIER": SyslogAppName,
+			}
+			for k, v := range expected {
+				if fields[k] != v {
+					t.Errorf("expected %s=%.40q, got %.40q", k, v, fields[k])
+				}
+			}
+			if !strings.Contains(fields["MESSAGE"], `"Comm":"curl"`) {
+				t.Errorf("unexpected MESSAGE %.80q", fields["MESSAGE"])
+			}
+		})
+	}
+}
+
+func TestJournaldAppendField(t *testing.T) {
+	b := &bytes.Buffer{}
+	journaldAppendField(b, "MESSAGE", "one line")
+	journaldAppendField(b, "DSE_ARGS", "two\nlines")
+	expected := "MESSAGE=one line\nDSE_ARGS\n\x09\x00\x00\x00\x00\x00\x00\x00two\nlines\n"
+	if b.String() != expected {
+		t.Errorf("expected %q, got %q", expected, b.String())
+	}
+}
+
+func TestJournaldFieldName(t *testing.T) {
+	if name := journaldFieldName("Parent.Pid-1"); name != "PARENT_PID_1" {
+		t.Errorf("expected PARENT_PID_1, got %s", name)
+	}
+}
+
+// readJournaldFields will read a datagram, or the memfd passed in a
+// datagram, and parse the native protocol fields.
+func readJournaldFields(t *testing.T, conn *net.UnixConn) map[string]string {
+	buf := make([]byte, 1<<16)
+	oob := make([]byte, unix.CmsgSpace(4))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := buf[:n]
+	if oobn > 0 {
+		messages, err := unix.ParseSocketControlMessage(oob[:oobn])
+		if err != nil || len(messages) != 1 {
+			t.Fatalf("unable to parse control message: %v", err)
+		}
+		fds, err := unix.ParseUnixRights(&messages[0])
+		if err != nil || len(fds) != 1 {
+			t.Fatalf("unable to parse rights: %v", err)
+		}
+		f := os.NewFile(uintptr(fds[0]), "memfd")
+		defer f.Close()
+
+		// journald maps the memfd, the offset is still at the end of
+		// the payload written by the output.
+		_, err = f.Seek(0, io.SeekStart)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload, err = ioutil.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	fields := map[string]string{}
+	for len(payload) > 0 {
+		i := bytes.IndexByte(payload, '\n')
+		if i < 0 {
+			t.Fatalf("unterminated field %q", payload)
+		}
+		line := payload[:i]
+		payload = payload[i+1:]
+		if eq := bytes.IndexByte(line, '='); eq >= 0 {
+			fields[string(line[:eq])] = string(line[eq+1:])
+			continue
+		}
+		length := binary.LittleEndian.Uint64(payload)
+		fields[string(line)] = string(payload[8 : 8+length])
+		payload = payload[8+length+1:]
+	}
+	return fields
+}
@@ -0,0 +1,554 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kris-nova/logger"
+
+	"golang.org/x/sys/unix"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	collectorlogsv1 "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	logsv1 "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcev1 "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+const (
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http"
+
+	// OTLPLogsPath is the default path for OTLP/HTTP logs.
+	OTLPLogsPath = "/v1/logs"
+
+	// otlpInstrumentationName is the instrumentation scope of every LogRecord
+	otlpInstrumentationName = "github.com/kris-nova/double-slit-experiment"
+
+	DefaultOTLPBatchSize       = 512
+	DefaultOTLPBatchTimeout    = time.Second
+	DefaultOTLPQueueSize       = 8192
+	DefaultOTLPExportTimeout   = 10 * time.Second
+	DefaultOTLPMaxRetryElapsed = time.Minute
+)
+
+type OTLPOutputConfig struct {
+
+	// Protocol is OTLPProtocolGRPC or OTLPProtocolHTTP
+	Protocol string
+
+	// Endpoint is host:port for gRPC, or the full logs URL for HTTP
+	// (http://localhost:4318/v1/logs)
+	Endpoint string
+
+	// TLS enables transport security for gRPC. HTTP uses the scheme
+	// of the Endpoint.
+	TLS bool
+
+	// Headers are sent with every export (e.g. authorization)
+	Headers map[string]string
+
+	// Formatter is used for the LogRecord body, and defaults to JSON
+	Formatter Formatter
+
+	BatchSize       int
+	BatchTimeout    time.Duration
+	QueueSize       int
+	ExportTimeout   time.Duration
+	MaxRetryElapsed time.Duration
+}
+
+// OTLPOutput will export events as OpenTelemetry LogRecords over OTLP.
+//
+// Events are queued, and exported in batches. Exports that fail with
+// a retryable error are retried with exponential backoff until
+// MaxRetryElapsed, after which the batch is dropped.
+type OTLPOutput struct {
+	config   OTLPOutputConfig
+	resource *resourcev1.Resource
+	queue    chan *otlpLogRecord
+	done     chan struct{}
+	wg       sync.WaitGroup
+	once     sync.Once
+
+	grpcConn   *grpc.ClientConn
+	grpcClient collectorlogsv1.LogsServiceClient
+	httpClient *http.Client
+}
+
+func NewOTLPOutput(config OTLPOutputConfig) (*OTLPOutput, error) {
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("otlp output missing endpoint")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultOTLPBatchSize
+	}
+	if config.BatchTimeout <= 0 {
+		config.BatchTimeout = DefaultOTLPBatchTimeout
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultOTLPQueueSize
+	}
+	if config.ExportTimeout <= 0 {
+		config.ExportTimeout = DefaultOTLPExportTimeout
+	}
+	if config.MaxRetryElapsed <= 0 {
+		config.MaxRetryElapsed = DefaultOTLPMaxRetryElapsed
+	}
+	config.Formatter = formatterOrDefault(config.Formatter)
+	o := &OTLPOutput{
+		config:   config,
+		resource: otlpResource(),
+		queue:    make(chan *otlpLogRecord, config.QueueSize),
+		done:     make(chan struct{}),
+	}
+	switch config.Protocol {
+	case OTLPProtocolGRPC:
+		creds := grpc.WithInsecure()
+		if config.TLS {
+			creds = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+		}
+		conn, err := grpc.Dial(config.Endpoint, creds)
+		if err != nil {
+			return nil, fmt.Errorf("unable to dial otlp %s: %v", config.Endpoint, err)
+		}
+		o.grpcConn = conn
+		o.grpcClient = collectorlogsv1.NewLogsServiceClient(conn)
+	case OTLPProtocolHTTP:
+		o.httpClient = &http.Client{Timeout: config.ExportTimeout}
+	default:
+		return nil, fmt.Errorf("invalid otlp protocol %s", config.Protocol)
+	}
+	o.wg.Add(1)
+	go o.run()
+	return o, nil
+}
+
+// otlpResource will describe the host dse is running on.
+func otlpResource() *resourcev1.Resource {
+	attributes := []*commonv1.KeyValue{
+		otlpString("service.name", SyslogAppName),
+		otlpString("host.arch", runtime.GOARCH),
+		otlpString("os.type", runtime.GOOS),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		attributes = append(attributes, otlpString("host.name", hostname))
+	}
+	var uname unix.Utsname
+	if err := unix.Uname(&uname); err == nil {
+		attributes = append(attributes, otlpString("os.version", unix.ByteSliceToString(uname.Release[:])))
+	}
+	return &resourcev1.Resource{Attributes: attributes}
+}
+
+type otlpAttribute struct {
+	Key   string
+	Field string
+}
+
+// otlpAttributes maps Event fields to OpenTelemetry semantic
+// convention attribute names on the LogRecord.
+var otlpAttributes = []otlpAttribute{
+	{Key: "process.pid", Field: "PID"},
+	{Key: "process.parent_pid", Field: "ParentPid"},
+	{Key: "process.parent_pid", Field: "PPID"},
+	{Key: "process.executable.path", Field: "Filename"},
+	{Key: "process.executable.name", Field: "Comm"},
+	{Key: "network.local.port", Field: "SourcePort"},
+	{Key: "network.peer.port", Field: "DestPort"},
+	{Key: "network.local.port", Field: "LocalPort"},
+	{Key: "network.peer.port", Field: "PeerPort"},
+	{Key: "rule.id", Field: "RuleID"},
+}
+
+// otlpResourceAttributes maps Event fields to the attributes of the
+// container and pod resource that produced the Event.
+var otlpResourceAttributes = []otlpAttribute{
+	{Key: "container.id", Field: "ContainerID"},
+	{Key: "container.name", Field: "ContainerName"},
+	{Key: "container.image.name", Field: "ContainerImage"},
+	{Key: "container.image.id", Field: "ContainerImageID"},
+	{Key: "k8s.pod.name", Field: "PodName"},
+	{Key: "k8s.namespace.name", Field: "PodNamespace"},
+	{Key: "k8s.pod.uid", Field: "PodUID"},
+}
+
+// otlpLogRecord is a LogRecord waiting to be exported, with the
+// resource attributes of its container and pod.
+type otlpLogRecord struct {
+	record     *logsv1.LogRecord
+	attributes []*commonv1.KeyValue
+	key        string
+}
+
+// otlpSeverity will map an EventSeverity() to an OpenTelemetry severity.
+func otlpSeverity(severity int) (logsv1.SeverityNumber, string) {
+	switch {
+	case severity >= 10:
+		return logsv1.SeverityNumber_SEVERITY_NUMBER_FATAL, "FATAL"
+	case severity >= 8:
+		return logsv1.SeverityNumber_SEVERITY_NUMBER_ERROR, "ERROR"
+	case severity >= 5:
+		return logsv1.SeverityNumber_SEVERITY_NUMBER_WARN, "WARN"
+	}
+	return logsv1.SeverityNumber_SEVERITY_NUMBER_INFO, "INFO"
+}
+
+// EventLogRecord will map an Event to an OpenTelemetry LogRecord.
+// The body of the record is the formatted event. Container and pod
+// attributes belong to the resource, see EventResourceAttributes().
+func EventLogRecord(event Event, formatter Formatter) (*logsv1.LogRecord, error) {
+	b, err := formatterOrDefault(formatter).Format(event)
+	if err != nil {
+		return nil, err
+	}
+	severityNumber, severityText := otlpSeverity(EventSeverity(event))
+	record := &logsv1.LogRecord{
+		TimeUnixNano:   uint64(EventTimestamp(event).UnixNano()),
+		SeverityNumber: severityNumber,
+		SeverityText:   severityText,
+		Name:           event.Name(),
+		Body:           &commonv1.AnyValue{Value: &commonv1.AnyValue_StringValue{StringValue: string(b)}},
+		Attributes: []*commonv1.KeyValue{
+			otlpString("event.name", event.Name()),
+		},
+	}
+	for _, a := range otlpAttributes {
+		value, ok := EventField(event, a.Field)
+		if !ok {
+			continue
+		}
+		if kv := otlpValue(a.Key, value); kv != nil {
+			record.Attributes = append(record.Attributes, kv)
+		}
+	}
+	if local, peer, ok := otlpNetworkAddresses(event); ok {
+		record.Attributes = append(record.Attributes,
+			otlpString("network.local.address", local),
+			otlpString("network.peer.address", peer),
+		)
+	}
+	return record, nil
+}
+
+// otlpNetworkAddresses will return the local and peer address of the
+// socket of an Event. The source of a TCPEvent is the local end.
+func otlpNetworkAddresses(event Event) (string, string, bool) {
+	switch e := event.(type) {
+	case *SocketEvent:
+		if e.Family == uint(unix.AF_INET6) {
+			return e.SourceAddrV6, e.DestAddrV6, true
+		}
+		return e.SourceAddr, e.DestAddr, true
+	case *ConnectionEvent:
+		if e.LocalAddr == "" && e.PeerAddr == "" {
+			return "", "", false
+		}
+		return e.LocalAddr, e.PeerAddr, true
+	case *TCPEvent:
+		return e.SourceAddr, e.DestAddr, true
+	}
+	return "", "", false
+}
+
+// EventResourceAttributes will return the attributes of the container
+// and pod that produced an Event, which are added to the attributes
+// of the host resource.
+func EventResourceAttributes(event Event) []*commonv1.KeyValue {
+	var attributes []*commonv1.KeyValue
+	for _, a := range otlpResourceAttributes {
+		value, ok := EventField(event, a.Field)
+		if !ok {
+			continue
+		}
+		if kv := otlpValue(a.Key, value); kv != nil {
+			attributes = append(attributes, kv)
+		}
+	}
+	if enrichable, ok := event.(ContainerEnrichable); ok {
+		metadata := enrichable.Container()
+		for _, key := range sortedKeys(metadata.ContainerLabels) {
+			attributes = append(attributes, otlpString("container.label."+key, metadata.ContainerLabels[key]))
+		}
+		for _, key := range sortedKeys(metadata.PodLabels) {
+			attributes = append(attributes, otlpString("k8s.pod.label."+key, metadata.PodLabels[key]))
+		}
+	}
+	return attributes
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// otlpResourceLogs will group a batch of records into one ResourceLogs
+// for each container and pod, in the order they were first seen.
+func otlpResourceLogs(host *resourcev1.Resource, batch []*otlpLogRecord) []*logsv1.ResourceLogs {
+	var resourceLogs []*logsv1.ResourceLogs
+	groups := map[string]*logsv1.InstrumentationLibraryLogs{}
+	for _, r := range batch {
+		group, ok := groups[r.key]
+		if !ok {
+			resource := host
+			if len(r.attributes) > 0 {
+				resource = &resourcev1.Resource{Attributes: append(append([]*commonv1.KeyValue{}, host.Attributes...), r.attributes...)}
+			}
+			group = &logsv1.InstrumentationLibraryLogs{
+				InstrumentationLibrary: &commonv1.InstrumentationLibrary{Name: otlpInstrumentationName},
+			}
+			groups[r.key] = group
+			resourceLogs = append(resourceLogs, &logsv1.ResourceLogs{
+				Resource:                   resource,
+				InstrumentationLibraryLogs: []*logsv1.InstrumentationLibraryLogs{group},
+			})
+		}
+		group.Logs = append(group.Logs, r.record)
+	}
+	return resourceLogs
+}
+
+func otlpString(key, value string) *commonv1.KeyValue {
+	return &commonv1.KeyValue{Key: key, Value: &commonv1.AnyValue{Value: &commonv1.AnyValue_StringValue{StringValue: value}}}
+}
+
+// otlpValue will return an attribute for a field value. Empty strings
+// and zero numbers are unknown, such as the PID of a softirq or the
+// port of a unix socket, and have no attribute.
+func otlpValue(key string, value interface{}) *commonv1.KeyValue {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return otlpString(key, v)
+	case bool:
+		return &commonv1.KeyValue{Key: key, Value: &commonv1.AnyValue{Value: &commonv1.AnyValue_BoolValue{BoolValue: v}}}
+	case int:
+		if v == 0 {
+			return nil
+		}
+		return &commonv1.KeyValue{Key: key, Value: &commonv1.AnyValue{Value: &commonv1.AnyValue_IntValue{IntValue: int64(v)}}}
+	case uint:
+		if v == 0 {
+			return nil
+		}
+		return &commonv1.KeyValue{Key: key, Value: &commonv1.AnyValue{Value: &commonv1.AnyValue_IntValue{IntValue: int64(v)}}}
+	}
+	return otlpString(key, fmt.Sprint(value))
+}
+
+// Write will queue the Event for export. Events are dropped
+// if the queue is full.
+func (o *OTLPOutput) Write(event Event) error {
+	record, err := EventLogRecord(event, o.config.Formatter)
+	if err != nil {
+		return err
+	}
+	attributes := EventResourceAttributes(event)
+	var key strings.Builder
+	for _, kv := range attributes {
+		fmt.Fprintf(&key, "%s=%s\xff", kv.Key, kv.Value.GetStringValue())
+	}
+	select {
+	case o.queue <- &otlpLogRecord{record: record, attributes: attributes, key: key.String()}:
+		return nil
+	default:
+		return fmt.Errorf("otlp queue full, dropping event")
+	}
+}
+
+func (o *OTLPOutput) run() {
+	defer o.wg.Done()
+	ticker := time.NewTicker(o.config.BatchTimeout)
+	defer ticker.Stop()
+	var batch []*otlpLogRecord
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		err := o.exportWithRetry(batch)
+		if err != nil {
+			logger.Warning("Dropping %d events: %v", len(batch), err)
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case record := <-o.queue:
+			batch = append(batch, record)
+			if len(batch) >= o.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-o.done:
+			for {
+				select {
+				case record := <-o.queue:
+					batch = append(batch, record)
+					if len(batch) >= o.config.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// otlpRetryableError will wrap errors that are worth retrying, with
+// an optional server provided delay.
+type otlpRetryableError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *otlpRetryableError) Error() string {
+	return e.err.Error()
+}
+
+func (o *OTLPOutput) exportWithRetry(batch []*otlpLogRecord) error {
+	request := &collectorlogsv1.ExportLogsServiceRequest{
+		ResourceLogs: otlpResourceLogs(o.resource, batch),
+	}
+	start := time.Now()
+	backoff := 500 * time.Millisecond
+	for {
+		err := o.export(request)
+		if err == nil {
+			return nil
+		}
+		retryable, ok := err.(*otlpRetryableError)
+		if !ok {
+			return err
+		}
+		delay := backoff
+		if retryable.delay > 0 {
+			delay = retryable.delay
+		}
+		if time.Since(start)+delay > o.config.MaxRetryElapsed {
+			return fmt.Errorf("giving up after %s: %v", time.Since(start).Round(time.Second), err)
+		}
+		logger.Debug("Retrying otlp export in %s: %v", delay, err)
+		select {
+		case <-time.After(delay):
+		case <-o.done:
+			// Shutting down, make one last attempt
+			return o.export(request)
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (o *OTLPOutput) export(request *collectorlogsv1.ExportLogsServiceRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), o.config.ExportTimeout)
+	defer cancel()
+	if o.grpcClient != nil {
+		if len(o.config.Headers) > 0 {
+			ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.config.Headers))
+		}
+		_, err := o.grpcClient.Export(ctx, request)
+		if err == nil {
+			return nil
+		}
+		switch status.Code(err) {
+		case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted,
+			codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+			return &otlpRetryableError{err: err}
+		}
+		return err
+	}
+
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range o.config.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return &otlpRetryableError{err: err}
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("otlp export: %s", resp.Status)
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		retryable := &otlpRetryableError{err: err}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryable.delay = time.Duration(seconds) * time.Second
+		}
+		return retryable
+	}
+	return err
+}
+
+// Close will flush any queued events and close the connection.
+func (o *OTLPOutput) Close() error {
+	o.once.Do(func() {
+		close(o.done)
+	})
+	o.wg.Wait()
+	if o.grpcConn != nil {
+		return o.grpcConn.Close()
+	}
+	return nil
+}
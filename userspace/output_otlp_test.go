@@ -0,0 +1,183 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcev1 "go.opentelemetry.io/proto/otlp/resource/v1"
+	"golang.org/x/sys/unix"
+)
+
+func TestOTLPResourceLogs(t *testing.T) {
+	host := &resourcev1.Resource{Attributes: []*commonv1.KeyValue{otlpString("host.name", "node-1")}}
+	events := []*ProcessEvent{
+		{EventName: "ProcessExecuted", PID: 1},
+		{EventName: "ProcessExecuted", PID: 2, ContainerMetadata: ContainerMetadata{ContainerID: "aaa", PodName: "web"}},
+		{EventName: "ProcessExecuted", PID: 3, ContainerMetadata: ContainerMetadata{ContainerID: "bbb"}},
+		{EventName: "ProcessExecuted", PID: 4, ContainerMetadata: ContainerMetadata{ContainerID: "aaa", PodName: "web"}},
+		{EventName: "ProcessExecuted", PID: 5},
+	}
+	var batch []*otlpLogRecord
+	for _, event := range events {
+		record, err := EventLogRecord(event, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, kv := range record.Attributes {
+			if kv.Key == "container.id" || kv.Key == "k8s.pod.name" {
+				t.Errorf("unexpected record attribute %s", kv.Key)
+			}
+		}
+		attributes := EventResourceAttributes(event)
+		key := ""
+		for _, kv := range attributes {
+			key += kv.Key + "=" + kv.Value.GetStringValue() + ";"
+		}
+		batch = append(batch, &otlpLogRecord{record: record, attributes: attributes, key: key})
+	}
+
+	tests := []struct {
+		pids       []int64
+		attributes map[string]string
+	}{
+		{pids: []int64{1, 5}, attributes: map[string]string{"host.name": "node-1"}},
+		{pids: []int64{2, 4}, attributes: map[string]string{"host.name": "node-1", "container.id": "aaa", "k8s.pod.name": "web"}},
+		{pids: []int64{3}, attributes: map[string]string{"host.name": "node-1", "container.id": "bbb"}},
+	}
+	resourceLogs := otlpResourceLogs(host, batch)
+	if len(resourceLogs) != len(tests) {
+		t.Fatalf("expected %d resources, got %d", len(tests), len(resourceLogs))
+	}
+	for i, test := range tests {
+		attributes := map[string]string{}
+		for _, kv := range resourceLogs[i].Resource.Attributes {
+			attributes[kv.Key] = kv.Value.GetStringValue()
+		}
+		if len(attributes) != len(test.attributes) {
+			t.Errorf("resource %d: expected %v, got %v", i, test.attributes, attributes)
+		}
+		for key, value := range test.attributes {
+			if attributes[key] != value {
+				t.Errorf("resource %d: expected %s=%s, got %q", i, key, value, attributes[key])
+			}
+		}
+		logs := resourceLogs[i].InstrumentationLibraryLogs[0].Logs
+		if len(logs) != len(test.pids) {
+			t.Fatalf("resource %d: expected %d records, got %d", i, len(test.pids), len(logs))
+		}
+		for j, record := range logs {
+			for _, kv := range record.Attributes {
+				if kv.Key == "process.pid" && kv.Value.GetIntValue() != test.pids[j] {
+					t.Errorf("resource %d: expected pid %d, got %d", i, test.pids[j], kv.Value.GetIntValue())
+				}
+			}
+		}
+	}
+	if len(host.Attributes) != 1 {
+		t.Errorf("host resource was modified: %v", host.Attributes)
+	}
+}
+
+func TestEventLogRecordTime(t *testing.T) {
+	observed := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	event := &ProcessEvent{EventName: "ProcessExecuted"}
+	event.setTimestamp(observed)
+	record, err := EventLogRecord(event, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.TimeUnixNano != uint64(observed.UnixNano()) {
+		t.Errorf("expected %d, got %d", observed.UnixNano(), record.TimeUnixNano)
+	}
+}
+
+func TestEventLogRecordAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      Event
+		attributes map[string]string
+	}{
+		{
+			name:       "process ppid",
+			event:      &ProcessEvent{EventName: "ProcessExecuted", PID: 42, PPID: 1},
+			attributes: map[string]string{"process.pid": "42", "process.parent_pid": "1"},
+		},
+		{
+			name:  "socket",
+			event: &SocketEvent{EventName: "SocketOpened", Family: uint(unix.AF_INET), SourceAddr: "10.0.0.2", SourcePort: 34567, DestAddr: "10.0.0.1", DestPort: 443},
+			attributes: map[string]string{
+				"network.local.address": "10.0.0.2", "network.local.port": "34567",
+				"network.peer.address": "10.0.0.1", "network.peer.port": "443",
+			},
+		},
+		{
+			name:  "connection",
+			event: &ConnectionEvent{EventName: EventNameSocketConnected, PID: 42, PPID: 1, LocalAddr: "10.0.0.2", LocalPort: 34567, PeerAddr: "10.0.0.1", PeerPort: 443},
+			attributes: map[string]string{
+				"process.pid": "42", "process.parent_pid": "1",
+				"network.local.address": "10.0.0.2", "network.local.port": "34567",
+				"network.peer.address": "10.0.0.1", "network.peer.port": "443",
+			},
+		},
+		{
+			name:       "unix connection",
+			event:      &ConnectionEvent{EventName: EventNameSocketConnected, PID: 42, PeerPath: "/run/docker.sock"},
+			attributes: map[string]string{"process.pid": "42"},
+		},
+		{
+			name:  "tcp",
+			event: &TCPEvent{EventName: EventNameTCPRetransmit, SourceAddr: "10.0.0.2", SourcePort: 34567, DestAddr: "10.0.0.1", DestPort: 443},
+			attributes: map[string]string{
+				"network.local.address": "10.0.0.2", "network.local.port": "34567",
+				"network.peer.address": "10.0.0.1", "network.peer.port": "443",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			record, err := EventLogRecord(test.event, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			attributes := map[string]string{}
+			for _, kv := range record.Attributes {
+				if kv.Key == "event.name" || kv.Key == "process.executable.name" {
+					continue
+				}
+				if _, ok := kv.Value.Value.(*commonv1.AnyValue_IntValue); ok {
+					attributes[kv.Key] = fmt.Sprint(kv.Value.GetIntValue())
+					continue
+				}
+				attributes[kv.Key] = kv.Value.GetStringValue()
+			}
+			if !reflect.DeepEqual(attributes, test.attributes) {
+				t.Errorf("expected %v, got %v", test.attributes, attributes)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SyslogAppName is the APP-NAME of every syslog message.
+	SyslogAppName = "dse"
+
+	// syslogPriority is facility daemon (3) and severity informational (6)
+	syslogPriority = 3*8 + 6
+
+	// syslogNil is the RFC 5424 NILVALUE
+	syslogNil = "-"
+)
+
+// SyslogOutput will send events as RFC 5424 syslog messages
+// over udp, tcp or a unix datagram socket. The event name is
+// used as the MSGID and the formatted event as the MSG.
+//
+// TCP messages are framed with octet counting (RFC 6587).
+type SyslogOutput struct {
+	network   string
+	formatter Formatter
+	address   string
+	hostname  string
+	mtx       sync.Mutex
+	conn      net.Conn
+}
+
+func NewSyslogOutput(network, address string, formatter Formatter) (*SyslogOutput, error) {
+	switch network {
+	case "udp", "tcp", "unixgram":
+	default:
+		return nil, fmt.Errorf("invalid syslog network %s", network)
+	}
+	if address == "" {
+		return nil, fmt.Errorf("syslog output missing address")
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = syslogNil
+	}
+	s := &SyslogOutput{
+		network:   network,
+		formatter: formatterOrDefault(formatter),
+		address:   address,
+		hostname:  hostname,
+	}
+	err = s.connect()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SyslogOutput) connect() error {
+	conn, err := net.DialTimeout(s.network, s.address, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("unable to connect to syslog %s://%s: %v", s.network, s.address, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// syslogMessage will format an RFC 5424 message. The TIMESTAMP is
+// the time of the event.
+//
+//   <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (s *SyslogOutput) syslogMessage(event Event, msg []byte) []byte {
+	header := fmt.Sprintf("<%d>1 %s %s %s %d %s %s ",
+		syslogPriority,
+		EventTimestamp(event).Format(time.RFC3339Nano),
+		syslogHeaderValue(s.hostname, 255),
+		SyslogAppName,
+		os.Getpid(),
+		syslogHeaderValue(event.Name(), 32),
+		syslogNil)
+	return append([]byte(header), msg...)
+}
+
+// syslogHeaderValue will make a value safe for an RFC 5424
+// header field (printable US-ASCII, no spaces).
+func syslogHeaderValue(value string, max int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if value == "" {
+		return syslogNil
+	}
+	if len(value) > max {
+		value = value[:max]
+	}
+	return value
+}
+
+func (s *SyslogOutput) Write(event Event) error {
+	b, err := s.formatter.Format(event)
+	if err != nil {
+		return err
+	}
+	msg := s.syslogMessage(event, b)
+	if s.network == "tcp" {
+		msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.conn == nil {
+		err := s.connect()
+		if err != nil {
+			return err
+		}
+	}
+	_, err = s.conn.Write(msg)
+	if err != nil {
+		// Reconnect on the next event
+		s.conn.Close()
+		s.conn = nil
+		return fmt.Errorf("unable to write to syslog %s://%s: %v", s.network, s.address, err)
+	}
+	return nil
+}
+
+func (s *SyslogOutput) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,155 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogOutput(t *testing.T) {
+	tests := []struct {
+		network string
+		listen  func(t *testing.T) (address string, read func() string)
+	}{
+		{"udp", listenSyslogPacket("udp", "127.0.0.1:0")},
+		{"unixgram", listenSyslogPacket("unixgram", "")},
+		{"tcp", listenSyslogTCP},
+	}
+	event := formatterEvent()
+	msg, err := (&JSONFormatter{}).Format(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		t.Run(test.network, func(t *testing.T) {
+			address, read := test.listen(t)
+			s, err := NewSyslogOutput(test.network, address, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+			s.hostname = "node 1"
+			err = s.Write(event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := fmt.Sprintf("<30>1 2021-12-01T10:00:00Z node_1 dse %d ProcessExecuted - %s", os.Getpid(), msg)
+			if actual := read(); actual != expected {
+				t.Errorf("expected %q, got %q", expected, actual)
+			}
+		})
+	}
+}
+
+func TestSyslogHeaderValue(t *testing.T) {
+	tests := []struct {
+		value, expected string
+	}{
+		{"ProcessExecuted", "ProcessExecuted"},
+		{"node 1\n", "node_1_"},
+		{"", syslogNil},
+		{strings.Repeat("a", 40), strings.Repeat("a", 32)},
+	}
+	for _, test := range tests {
+		if actual := syslogHeaderValue(test.value, 32); actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+}
+
+func TestNewSyslogOutputInvalid(t *testing.T) {
+	_, err := NewSyslogOutput("sctp", "127.0.0.1:514", nil)
+	if err == nil {
+		t.Errorf("expected invalid network error")
+	}
+	_, err = NewSyslogOutput("udp", "", nil)
+	if err == nil {
+		t.Errorf("expected missing address error")
+	}
+}
+
+// listenSyslogPacket will listen for datagrams. An empty address
+// listens on a unix socket in a temporary directory.
+func listenSyslogPacket(network, address string) func(t *testing.T) (string, func() string) {
+	return func(t *testing.T) (string, func() string) {
+		if address == "" {
+			address = filepath.Join(t.TempDir(), "syslog.sock")
+		}
+		conn, err := net.ListenPacket(network, address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn.LocalAddr().String(), func() string {
+			buf := make([]byte, 65536)
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return string(buf[:n])
+		}
+	}
+}
+
+// listenSyslogTCP will listen for a single connection, and read
+// messages framed with octet counting.
+func listenSyslogTCP(t *testing.T) (string, func() string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	return listener.Addr().String(), func() string {
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		r := bufio.NewReader(conn)
+		length, err := r.ReadString(' ')
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, n)
+		_, err = io.ReadFull(r, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf)
+	}
+}
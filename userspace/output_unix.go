@@ -0,0 +1,138 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/kris-nova/logger"
+)
+
+const (
+	// unixSocketClientBuffer is the number of events buffered for
+	// each client before events are dropped for that client.
+	unixSocketClientBuffer = 1024
+)
+
+// UnixSocketOutput will listen on a Unix domain socket, and stream
+// newline delimited events to every connected client.
+//
+//   socat - UNIX-CONNECT:/run/dse/events.sock
+type UnixSocketOutput struct {
+	path      string
+	formatter Formatter
+	listener  net.Listener
+	mtx       sync.Mutex
+	clients   map[net.Conn]chan []byte
+}
+
+func NewUnixSocketOutput(path string, formatter Formatter) (*UnixSocketOutput, error) {
+	if path == "" {
+		return nil, fmt.Errorf("unix output missing path")
+	}
+
+	// Remove a stale socket from a previous run
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen on %s: %v", path, err)
+	}
+	u := &UnixSocketOutput{
+		path:      path,
+		formatter: formatterOrDefault(formatter),
+		listener:  listener,
+		clients:   map[net.Conn]chan []byte{},
+	}
+	go u.accept()
+	return u, nil
+}
+
+func (u *UnixSocketOutput) accept() {
+	for {
+		conn, err := u.listener.Accept()
+		if err != nil {
+			return
+		}
+		ch := make(chan []byte, unixSocketClientBuffer)
+		u.mtx.Lock()
+		u.clients[conn] = ch
+		u.mtx.Unlock()
+		logger.Debug("Unix socket client connected: %s", u.path)
+		go u.stream(conn, ch)
+	}
+}
+
+func (u *UnixSocketOutput) stream(conn net.Conn, ch chan []byte) {
+	defer u.remove(conn)
+	for b := range ch {
+		_, err := conn.Write(b)
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (u *UnixSocketOutput) remove(conn net.Conn) {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	if ch, ok := u.clients[conn]; ok {
+		close(ch)
+		delete(u.clients, conn)
+	}
+	conn.Close()
+}
+
+func (u *UnixSocketOutput) Write(event Event) error {
+	b, err := u.formatter.Format(event)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	for conn, ch := range u.clients {
+		select {
+		case ch <- b:
+		default:
+			logger.Warning("Dropping event for slow unix socket client: %s", conn.RemoteAddr())
+		}
+	}
+	return nil
+}
+
+func (u *UnixSocketOutput) Close() error {
+	err := u.listener.Close()
+	u.mtx.Lock()
+	for conn, ch := range u.clients {
+		close(ch)
+		delete(u.clients, conn)
+		conn.Close()
+	}
+	u.mtx.Unlock()
+	return err
+}
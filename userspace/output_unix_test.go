@@ -0,0 +1,139 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUnixSocketOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.sock")
+
+	// A stale socket from a previous run is replaced
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	u, err := NewUnixSocketOutput(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var clients []*bufio.Reader
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		clients = append(clients, bufio.NewReader(conn))
+	}
+	waitUnixSocketClients(t, u, 2)
+
+	event := formatterEvent()
+	err = u.Write(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := (&JSONFormatter{}).Format(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, client := range clients {
+		line, err := client.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != string(expected)+"\n" {
+			t.Errorf("expected %q, got %q", expected, line)
+		}
+	}
+
+	err = u.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, client := range clients {
+		_, err := client.ReadString('\n')
+		if err != io.EOF {
+			t.Errorf("expected EOF after Close(), got %v", err)
+		}
+	}
+}
+
+func TestUnixSocketOutputClientClosed(t *testing.T) {
+	u, err := NewUnixSocketOutput(filepath.Join(t.TempDir(), "events.sock"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer u.Close()
+	conn, err := net.Dial("unix", u.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitUnixSocketClients(t, u, 1)
+	conn.Close()
+
+	// The client is removed once a write to it fails
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		err = u.Write(formatterEvent())
+		if err != nil {
+			t.Fatal(err)
+		}
+		u.mtx.Lock()
+		n := len(u.clients)
+		u.mtx.Unlock()
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("closed client was not removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func waitUnixSocketClients(t *testing.T, u *UnixSocketOutput, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		u.mtx.Lock()
+		connected := len(u.clients)
+		u.mtx.Unlock()
+		if connected == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d clients, found %d", n, connected)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -17,6 +17,8 @@
 
 package userspace
 
+import "time"
+
 func ProfileSignalsOnly() ObservationPoints {
 	return ObservationPoints{
 		"SignalDelivered": NewSignalObservationPoint([]DropSignal{}),
@@ -62,3 +64,399 @@ func ProfileDefault() ObservationPoints {
 		}),
 	}
 }
+
+// ProfileProcessTree is used to follow every process
+// that is forked, executed and exited.
+func ProfileProcessTree() ObservationPoints {
+	return ObservationPoints{
+		"ProcessLifecycle": NewLifecycleObservationPoint([]DropLifecycle{
+
+			// Drop all kernel threads
+			DropLifecycleKernelThreads,
+		}),
+	}
+}
+
+// ProfileRates is added to the default profile when rates are
+// tracked. Forks are followed with task_newtask, which sees every
+// fork(), vfork() and clone() of a process, but not of a thread.
+func ProfileRates() ObservationPoints {
+	return ObservationPoints{
+		"ProcessForked": NewLifecycleObservationPoint([]DropLifecycle{
+
+			// Select only forks
+			SelectLifecycleType(EventTypeFork),
+
+			// Drop all kernel threads
+			DropLifecycleKernelThreads,
+		}),
+	}
+}
+
+// ProfileFiles will observe opens and changes of any path that
+// starts with one of the prefixes, or DefaultFilePrefixes.
+func ProfileFiles(prefixes []string) ObservationPoints {
+	if len(prefixes) == 0 {
+		prefixes = DefaultFilePrefixes
+	}
+	return ObservationPoints{
+		EventNameFileOpened: NewFileOpenObservationPoint(prefixes, []DropFileOpen{}),
+		"FileChanged":       NewFileChangeObservationPoint(prefixes, []DropFileChange{}),
+	}
+}
+
+// ProfileMounts will observe every mount and unmount.
+func ProfileMounts() ObservationPoints {
+	return ObservationPoints{
+		"Mount": NewMountObservationPoint([]DropMount{}),
+	}
+}
+
+// ProfilePrivileges will observe every change of credentials,
+// and every exec() of a setuid binary.
+func ProfilePrivileges() ObservationPoints {
+	return ObservationPoints{
+		"Privilege": NewPrivilegeObservationPoint([]DropPrivilege{
+
+			// Drop all credential syscalls that changed nothing
+			DropPrivilegeUnchanged,
+		}),
+	}
+}
+
+// ProfileLoads will observe every kernel module and BPF
+// program that is loaded, other than by dse itself.
+func ProfileLoads() ObservationPoints {
+	return ObservationPoints{
+		"Module": NewModuleObservationPoint([]DropModule{}),
+		"BPF": NewBPFObservationPoint([]DropBPF{
+
+			// Drop all feature probes that failed
+			DropBPFFailed,
+		}),
+	}
+}
+
+// ProfileProcessAccess will observe every process that traces,
+// or reads or writes the memory of, another process.
+func ProfileProcessAccess() ObservationPoints {
+	return ObservationPoints{
+		"ProcessAccess": NewProcessAccessObservationPoint([]DropProcessAccess{}),
+	}
+}
+
+// ProfileConnections will observe every connect() and accept()
+// with the process that called it.
+func ProfileConnections() ObservationPoints {
+	return ObservationPoints{
+		"Connect": NewConnectObservationPoint([]DropConnection{}),
+		"Accept":  NewAcceptObservationPoint([]DropConnection{}),
+	}
+}
+
+// ProfileUDP will count the UDP flows of every process, and send them
+// every interval, with every DNS query and response on port 53.
+func ProfileUDP(interval time.Duration) ObservationPoints {
+	return ObservationPoints{
+		"UDP": NewUDPObservationPoint(interval, []DropDNS{}),
+	}
+}
+
+// ProfileListeners will keep an inventory of every TCP and UDP port
+// listening, starting with the ports already listening.
+func ProfileListeners() ObservationPoints {
+	return ObservationPoints{
+		"Listen": NewListenObservationPoint(DefaultListenerScanInterval, []DropListener{}),
+	}
+}
+
+// ProfileTCP will observe the retransmits, resets and dropped packets
+// of every TCP connection.
+func ProfileTCP() ObservationPoints {
+	return ObservationPoints{
+		"TCP": NewTCPObservationPoint([]DropTCP{}),
+	}
+}
+
+// ProfileRules will return the observation points that are off by
+// default, but produce an event one of the enabled rules references.
+// Points that need a setting, such as the file prefixes, are not
+// included.
+func ProfileRules(rules Rules) ObservationPoints {
+	profiles := []struct {
+		events  []string
+		profile func() ObservationPoints
+	}{
+		{
+			events:  []string{EventNameMounted, EventNameUnmounted, EventNameMountPrepared},
+			profile: ProfileMounts,
+		},
+		{
+			events:  []string{EventNamePrivilegeChanged, EventNameExecPrivilegeChanged, EventNameNamespaceChanged},
+			profile: ProfilePrivileges,
+		},
+		{
+			events:  []string{EventNameModuleLoaded, EventNameModuleUnloaded, EventNameBPFProgramLoaded, EventNameBPFProgramAttached, EventNameBPFProgramDetached},
+			profile: ProfileLoads,
+		},
+		{
+			events:  []string{EventNameProcessTraced, EventNameProcessMemoryRead, EventNameProcessMemoryWritten, EventNameProcessMemoryOpened},
+			profile: ProfileProcessAccess,
+		},
+		{
+			events:  []string{EventNameSocketConnected, EventNameSocketAccepted},
+			profile: ProfileConnections,
+		},
+		{
+			events:  []string{EventNameListenerOpened, EventNameListenerClosed, EventNameListenerFound},
+			profile: ProfileListeners,
+		},
+		{
+			events:  []string{EventNameTCPRetransmit, EventNameTCPResetSent, EventNameTCPResetReceived, EventNameTCPPacketDropped},
+			profile: ProfileTCP,
+		},
+	}
+	points := ObservationPoints{}
+	for _, p := range profiles {
+		if !rules.References(p.events...) {
+			continue
+		}
+		for name, point := range p.profile() {
+			points[name] = point
+		}
+	}
+	return points
+}
+
+// ProfileDefaultRates are the thresholds for fork bombs and
+// exec storms, by container, parent process and UID, and for
+// unhealthy TCP connections.
+func ProfileDefaultRates() EventRates {
+	return EventRates{
+		{
+			Name:      "fork_per_container",
+			Help:      "Processes forked in a container.",
+			Events:    []string{EventNameProcessForked},
+			By:        "ContainerID",
+			Window:    10 * time.Second,
+			Threshold: 1000,
+			Gauge:     true,
+		},
+		{
+			Name:      "fork_per_parent",
+			Help:      "Processes forked by a single parent process.",
+			Events:    []string{EventNameProcessForked},
+			By:        "PID",
+			Window:    10 * time.Second,
+			Threshold: 500,
+		},
+		{
+			Name:      "fork_per_uid",
+			Help:      "Processes forked by a user.",
+			Events:    []string{EventNameProcessForked},
+			By:        "UID",
+			Window:    10 * time.Second,
+			Threshold: 2000,
+			Gauge:     true,
+		},
+		{
+			Name:      "exec_per_container",
+			Help:      "Processes executed in a container.",
+			Events:    []string{"ProcessExecuted"},
+			By:        "ContainerID",
+			Window:    10 * time.Second,
+			Threshold: 500,
+			Gauge:     true,
+		},
+		{
+			Name:      "exec_per_parent",
+			Help:      "Processes executed by the children of a single parent process.",
+			Events:    []string{"ProcessExecuted"},
+			By:        "PPID",
+			Window:    10 * time.Second,
+			Threshold: 200,
+		},
+		{
+			Name:      "exec_per_uid",
+			Help:      "Processes executed by a user.",
+			Events:    []string{"ProcessExecuted"},
+			By:        "UID",
+			Window:    10 * time.Second,
+			Threshold: 1000,
+			Gauge:     true,
+		},
+		{
+			Name:      "tcp_retransmit_per_connection",
+			Help:      "TCP segments retransmitted on a single connection.",
+			Events:    []string{EventNameTCPRetransmit},
+			By:        "Connection",
+			Window:    10 * time.Second,
+			Threshold: 100,
+		},
+		{
+			Name:      "tcp_drop_per_connection",
+			Help:      "TCP packets dropped on a single connection.",
+			Events:    []string{EventNameTCPPacketDropped},
+			By:        "Connection",
+			Count:     "Drops",
+			Window:    10 * time.Second,
+			Threshold: 100,
+		},
+	}
+}
+
+func ProfileDefaultMetrics() EventMetrics {
+	return EventMetrics{
+		{
+			Name:   "exec_total",
+			Help:   "Total number of processes executed, by executable and container.",
+			Events: []string{"ProcessExecuted"},
+			Labels: []EventMetricLabel{
+				{Name: "executable", Field: "Filename"},
+				{Name: "container", Field: "ContainerID"},
+			},
+		},
+		{
+			Name:   "tcp_connections_opened_total",
+			Help:   "Total number of TCP connections established, by remote port.",
+			Events: []string{"SocketState"},
+			Select: func(event Event) bool {
+				return EventFieldString(event, "NewStateName") == "TCP_ESTABLISHED"
+			},
+			Labels: []EventMetricLabel{
+				{Name: "remote_port", Field: "DestPort"},
+			},
+		},
+		{
+			Name:   "tcp_connections_closed_total",
+			Help:   "Total number of TCP connections closed, by remote port.",
+			Events: []string{"SocketState"},
+			Select: func(event Event) bool {
+				return EventFieldString(event, "NewStateName") == "TCP_CLOSE"
+			},
+			Labels: []EventMetricLabel{
+				{Name: "remote_port", Field: "DestPort"},
+			},
+		},
+		{
+			Name:   "signals_delivered_total",
+			Help:   "Total number of signals delivered, by signal name.",
+			Events: []string{"SignalDelivered"},
+			Labels: []EventMetricLabel{
+				{Name: "signal", Field: "SignalName"},
+			},
+		},
+		{
+			// Container starts per minute:
+			//   rate(dse_container_starts_total[1m]) * 60
+			Name:   "container_starts_total",
+			Help:   "Total number of container starts.",
+			Events: []string{"Container"},
+		},
+		{
+			Name:   "alerts_total",
+			Help:   "Total number of alerts, by rule and severity.",
+			Events: []string{EventNameAlert},
+			Labels: []EventMetricLabel{
+				{Name: "rule", Field: "RuleID"},
+				{Name: "severity", Field: "Severity"},
+			},
+		},
+	}
+}
+
+// ProfileDefaultRules are the starter rules loaded with
+// --rules default. They can be replaced, or disabled, by ID.
+func ProfileDefaultRules() Rules {
+	return MustCompileRules(Rules{
+		{
+			ID:          "shell-in-container",
+			Description: "A shell was executed inside a container",
+			Severity:    "high",
+			Condition:   `Name == "ProcessExecuted" && ContainerID && Filename =~ "/(ba|da|z|k|c|tc|a)?sh$"`,
+			Tags:        []string{"container", "shell"},
+		},
+		{
+			ID:          "exec-from-tmp",
+			Description: "A process was executed from a world writable directory",
+			Severity:    "high",
+			Condition:   `Name == "ProcessExecuted" && Filename =~ "^/(tmp|var/tmp|dev/shm)/"`,
+			Tags:        []string{"process"},
+		},
+		{
+			ID:          "network-tool-in-container",
+			Description: "A network tool was executed inside a container",
+			Severity:    "medium",
+			Condition:   `Name == "ProcessExecuted" && ContainerID && Filename =~ "/(nc|ncat|netcat|socat|nmap|tcpdump)$"`,
+			Tags:        []string{"container", "network"},
+		},
+		{
+			ID:          "package-manager-in-container",
+			Description: "A package manager was executed inside a running container",
+			Severity:    "low",
+			Condition:   `Name == "ProcessExecuted" && ContainerID && Filename =~ "/(apt|apt-get|dpkg|yum|dnf|rpm|apk|pip3?)$"`,
+			Tags:        []string{"container", "drift"},
+		},
+		{
+			ID:          "container-listen-new-port",
+			Description: "A container process is listening on a new port",
+			Severity:    "medium",
+			Condition:   `Name == "SocketState" && NewStateName == "TCP_LISTEN" && ContainerID`,
+			Unique:      []string{"ContainerID", "SourcePort"},
+			Tags:        []string{"container", "network"},
+		},
+		{
+			ID:          "setuid-exec-in-container",
+			Description: "A setuid binary, or a binary with file capabilities, escalated privileges inside a container",
+			Severity:    "high",
+			Condition:   `Name == "ExecPrivilegeChanged" && Escalated && ContainerID`,
+			Tags:        []string{"container", "privilege"},
+		},
+		{
+			ID:          "module-loaded-in-container",
+			Description: "A kernel module was loaded from inside a container",
+			Severity:    "critical",
+			Condition:   `Name == "ModuleLoaded" && !Error && ContainerID`,
+			Tags:        []string{"container", "kernel"},
+		},
+		{
+			ID:          "process-access-across-containers",
+			Description: "A process traced, or read or wrote the memory of, a process in another container or on the host",
+			Severity:    "critical",
+			Condition:   `(Name == "ProcessTraced" || Name ^= "ProcessMemory") && !Error && CrossContainer`,
+			Tags:        []string{"container", "escape", "credentials"},
+		},
+		{
+			ID:          "reverse-shell",
+			Description: "A shell opened an outbound TCP connection",
+			Severity:    "critical",
+			Sequence: []string{
+				`Name == "ProcessExecuted" && Filename =~ "/(ba|da|z|k|c|tc|a)?sh$"`,
+				`Name == "SocketState" && OldStateName == "TCP_SYN_SENT" && NewStateName == "TCP_ESTABLISHED"`,
+			},
+			By:     []string{"PID"},
+			Within: "10s",
+			Tags:   []string{"process", "network"},
+		},
+		{
+			ID:          "userns-setns-host",
+			Description: "A container process created a user namespace, then joined a namespace of the host",
+			Severity:    "critical",
+			Sequence: []string{
+				`Name == "NamespaceChanged" && Namespaces =~ "CLONE_NEWUSER" && !Error && ContainerID`,
+				`Name == "NamespaceChanged" && Syscall == "setns" && !Error && HostNamespaces`,
+			},
+			By:     []string{"ChildPID|PID", "ContainerID"},
+			Within: "10s",
+			Tags:   []string{"container", "privilege"},
+		},
+	})
+}
+
+// ProfileDefaultOutputs are the outputs used when none are
+// passed with --output. See ParseOutput() for the syntax.
+func ProfileDefaultOutputs() []string {
+	return []string{
+		"stdout",
+	}
+}
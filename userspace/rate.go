@@ -0,0 +1,365 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kris-nova/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// EventNameRateExceeded is the name of every RateExceededEvent.
+	EventNameRateExceeded = "RateExceeded"
+
+	// DefaultRateWindow is the window of an EventRate that does not set one.
+	DefaultRateWindow = 10 * time.Second
+
+	// DefaultMaxRateKeys is the number of keys an EventRate will
+	// track before new keys are ignored.
+	DefaultMaxRateKeys = 10000
+
+	// rateBuckets is the number of buckets a window is split into.
+	// The sliding window moves one bucket at a time.
+	rateBuckets = 10
+)
+
+// EventRate declares a threshold on how many events are seen for
+// each value of a field, within a sliding window.
+type EventRate struct {
+
+	// Name of the rate, such as "exec_per_container".
+	Name string
+	Help string
+
+	// Events is the list of Event names counted by this rate.
+	Events []string
+
+	// By is the field (see EventField) events are counted by.
+	// Events where the field is empty are not counted.
+	By string
+
+	// Count is the numeric field with the number of occurrences an
+	// event stands for, such as the Drops of a TCPPacketDropped. Events
+	// without it count once.
+	Count string
+
+	Window    time.Duration
+	Threshold int
+
+	// Gauge will export the count of every key as a Prometheus gauge.
+	Gauge bool
+
+	// MaxKeys is the number of keys tracked before new keys are ignored.
+	MaxKeys int
+}
+
+type EventRates []*EventRate
+
+// Set will change the threshold, and optionally the window, of the
+// EventRate with the same name.
+//
+//   exec_per_container=500
+//   fork_per_uid=2000/5s
+func (rates EventRates) Set(spec string) error {
+	parts := strings.SplitN(spec, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid rate %q, expected name=threshold[/window]", spec)
+	}
+	var rate *EventRate
+	for _, r := range rates {
+		if r.Name == parts[0] {
+			rate = r
+		}
+	}
+	if rate == nil {
+		return fmt.Errorf("unknown rate %q", parts[0])
+	}
+	value := strings.SplitN(parts[1], "/", 2)
+	threshold, err := strconv.Atoi(value[0])
+	if err != nil || threshold <= 0 {
+		return fmt.Errorf("invalid threshold in rate %q", spec)
+	}
+	rate.Threshold = threshold
+	if len(value) == 2 {
+		window, err := time.ParseDuration(value[1])
+		if err != nil || window <= 0 {
+			return fmt.Errorf("invalid window in rate %q", spec)
+		}
+		rate.Window = window
+	}
+	return nil
+}
+
+// RateTracker is an EventProducer that will count events for every
+// EventRate, and produce a RateExceededEvent when a key goes over
+// the threshold. A key will not produce another RateExceededEvent
+// until it has dropped back under the threshold.
+type RateTracker struct {
+	rates []*rateCounter
+	gauge *prometheus.Desc
+}
+
+// rateCounter are the sliding windows of a single EventRate by key.
+type rateCounter struct {
+	definition *EventRate
+	events     map[string]bool
+	bucket     time.Duration
+	mtx        sync.Mutex
+	windows    map[string]*rateWindow
+	pruned     time.Time
+}
+
+// rateWindow is a ring of buckets that counts events in the window.
+type rateWindow struct {
+	counts   [rateBuckets]int
+	total    int
+	current  int
+	start    time.Time
+	exceeded bool
+}
+
+// NewRateTracker will create a RateTracker for every EventRate.
+func NewRateTracker(rates EventRates) (*RateTracker, error) {
+	t := &RateTracker{
+		gauge: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricsNamespace, "", "event_rate"),
+			"Number of events within the window of a rate, by key.",
+			[]string{"rate", "key"}, nil),
+	}
+	names := map[string]bool{}
+	for _, rate := range rates {
+		if rate.Name == "" {
+			return nil, fmt.Errorf("event rate missing name")
+		}
+		if names[rate.Name] {
+			return nil, fmt.Errorf("duplicate event rate %s", rate.Name)
+		}
+		names[rate.Name] = true
+		if rate.By == "" {
+			return nil, fmt.Errorf("event rate %s missing by field", rate.Name)
+		}
+		if rate.Threshold <= 0 {
+			return nil, fmt.Errorf("event rate %s missing threshold", rate.Name)
+		}
+		if rate.Window <= 0 {
+			rate.Window = DefaultRateWindow
+		}
+		c := &rateCounter{
+			definition: rate,
+			events:     map[string]bool{},
+			bucket:     rate.Window / rateBuckets,
+			windows:    map[string]*rateWindow{},
+			pruned:     time.Now(),
+		}
+		for _, name := range rate.Events {
+			c.events[name] = true
+		}
+		t.rates = append(t.rates, c)
+	}
+	return t, nil
+}
+
+// Produce implements EventProducer.
+func (t *RateTracker) Produce(event Event) []Event {
+	var events []Event
+	now := time.Now()
+	for _, c := range t.rates {
+		if e := c.observe(event, now); e != nil {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// observe will count the Event, and return a RateExceededEvent if
+// the Event took its key over the threshold.
+func (c *rateCounter) observe(event Event, now time.Time) Event {
+	if len(c.events) > 0 && !c.events[event.Name()] {
+		return nil
+	}
+	key := EventFieldString(event, c.definition.By)
+	if key == "" {
+		return nil
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if now.Sub(c.pruned) > c.definition.Window {
+		c.prune(now)
+	}
+	w, ok := c.windows[key]
+	if !ok {
+		max := c.definition.MaxKeys
+		if max <= 0 {
+			max = DefaultMaxRateKeys
+		}
+		if len(c.windows) >= max {
+			logger.Debug("Rate %s is tracking %d keys, ignoring new keys", c.definition.Name, len(c.windows))
+			return nil
+		}
+		w = &rateWindow{start: now}
+		c.windows[key] = w
+	}
+	n := 1
+	if c.definition.Count != "" {
+		if count, ok := EventFieldFloat(event, c.definition.Count); ok && count > 1 {
+			n = int(count)
+		}
+	}
+	w.advance(now, c.bucket)
+	w.counts[w.current] += n
+	w.total += n
+	if w.total <= c.definition.Threshold {
+		w.exceeded = false
+		return nil
+	}
+	if w.exceeded {
+		return nil
+	}
+	w.exceeded = true
+	return NewRateExceededEvent(c.definition, key, w.total, event)
+}
+
+// prune will forget every key without events in the window.
+func (c *rateCounter) prune(now time.Time) {
+	for key, w := range c.windows {
+		w.advance(now, c.bucket)
+		if w.total == 0 {
+			delete(c.windows, key)
+		}
+	}
+	c.pruned = now
+}
+
+// advance will move the window forward to now, and drop the
+// buckets that fell out of the window.
+func (w *rateWindow) advance(now time.Time, bucket time.Duration) {
+	if bucket <= 0 {
+		bucket = time.Nanosecond
+	}
+	steps := int(now.Sub(w.start) / bucket)
+	if steps <= 0 {
+		return
+	}
+	if steps > rateBuckets {
+		steps = rateBuckets
+	}
+	for i := 0; i < steps; i++ {
+		w.current = (w.current + 1) % rateBuckets
+		w.total -= w.counts[w.current]
+		w.counts[w.current] = 0
+	}
+	w.start = w.start.Add(now.Sub(w.start) / bucket * bucket)
+	if w.total < 0 {
+		w.total = 0
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (t *RateTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- t.gauge
+}
+
+// Collect implements prometheus.Collector, with a gauge for every
+// key of every EventRate that has Gauge set.
+func (t *RateTracker) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	for _, c := range t.rates {
+		if !c.definition.Gauge {
+			continue
+		}
+		c.mtx.Lock()
+		for key, w := range c.windows {
+			w.advance(now, c.bucket)
+			if w.total == 0 {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(t.gauge, prometheus.GaugeValue, float64(w.total), c.definition.Name, key)
+		}
+		c.mtx.Unlock()
+	}
+}
+
+// RateExceededEvent is produced by a RateTracker when a key of an
+// EventRate goes over the threshold.
+type RateExceededEvent struct {
+	EventName string `json:"Name"`
+	Rate      string `json:"Rate"`
+	Field     string `json:"Field"`
+	Value     string `json:"Value"`
+	Count     int    `json:"Count"`
+	Threshold int    `json:"Threshold"`
+	Window    string `json:"Window"`
+	PID       uint   `json:"PID,omitempty"`
+	Comm      string `json:"Comm,omitempty"`
+	ContainerMetadata
+	EventTime
+}
+
+// NewRateExceededEvent will create a RateExceededEvent for the key
+// of an EventRate. The process of the Event that went over the
+// threshold is copied, and the container when the rate is counted
+// by container.
+func NewRateExceededEvent(rate *EventRate, key string, count int, event Event) *RateExceededEvent {
+	e := &RateExceededEvent{
+		EventName: EventNameRateExceeded,
+		Rate:      rate.Name,
+		Field:     rate.By,
+		Value:     key,
+		Count:     count,
+		Threshold: rate.Threshold,
+		Window:    rate.Window.String(),
+		Comm:      EventFieldString(event, "Comm"),
+	}
+	if pid, ok := EventFieldFloat(event, "PID"); ok {
+		e.PID = uint(pid)
+	}
+	if c, ok := event.(ContainerEnrichable); ok && rate.By == "ContainerID" {
+		e.ContainerMetadata = *c.Container()
+	}
+	return e
+}
+
+func (e *RateExceededEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *RateExceededEvent) String() string {
+	return fmt.Sprintf("%s %s=%s: %d events in %s (threshold %d)", e.Rate, e.Field, e.Value, e.Count, e.Window, e.Threshold)
+}
+
+func (e *RateExceededEvent) Name() string {
+	return e.EventName
+}
+
+// Severity implements SeverityEvent.
+func (e *RateExceededEvent) Severity() int {
+	return RuleSeverities["high"]
+}
@@ -0,0 +1,216 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateWindow(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	tests := []struct {
+		name     string
+		offsets  []time.Duration
+		exceeded []int
+	}{
+		{
+			name:     "under the threshold",
+			offsets:  []time.Duration{0, time.Second, 2 * time.Second},
+			exceeded: nil,
+		},
+		{
+			name:     "over the threshold once",
+			offsets:  []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second},
+			exceeded: []int{3},
+		},
+		{
+			name: "slides out of the window",
+			// 0s and 1s have left the 10s window by 12s
+			offsets:  []time.Duration{0, time.Second, 2 * time.Second, 12 * time.Second},
+			exceeded: nil,
+		},
+		{
+			name: "exceeded again after dropping under",
+			// 11s drops to 3 events, under the threshold
+			offsets:  []time.Duration{0, 0, 0, 0, 11 * time.Second, 11 * time.Second, 11 * time.Second, 11 * time.Second},
+			exceeded: []int{3, 7},
+		},
+		{
+			name:     "expired window",
+			offsets:  []time.Duration{0, 0, 0, time.Minute, time.Minute, time.Minute, time.Minute},
+			exceeded: []int{6},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracker, err := NewRateTracker(EventRates{{
+				Name:      "exec_per_container",
+				Events:    []string{"ProcessExecuted"},
+				By:        "ContainerID",
+				Window:    10 * time.Second,
+				Threshold: 3,
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var exceeded []int
+			for i, offset := range test.offsets {
+				event := &ProcessEvent{EventName: "ProcessExecuted", PID: uint(i), ContainerMetadata: testContainer}
+				if e := tracker.rates[0].observe(event, start.Add(offset)); e != nil {
+					exceeded = append(exceeded, i)
+				}
+			}
+			if len(exceeded) != len(test.exceeded) {
+				t.Fatalf("expected exceeded at %v, got %v", test.exceeded, exceeded)
+			}
+			for i := range exceeded {
+				if exceeded[i] != test.exceeded[i] {
+					t.Fatalf("expected exceeded at %v, got %v", test.exceeded, exceeded)
+				}
+			}
+		})
+	}
+}
+
+func TestRateKeys(t *testing.T) {
+	tracker, err := NewRateTracker(EventRates{{
+		Name:      "exec_per_container",
+		By:        "ContainerID",
+		Threshold: 1,
+		MaxKeys:   2,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := tracker.rates[0]
+	now := time.Now()
+	for _, id := range []string{"a", "b", "c", ""} {
+		c.observe(&ProcessEvent{EventName: "ProcessExecuted", ContainerMetadata: ContainerMetadata{ContainerID: id}}, now)
+	}
+	if len(c.windows) != 2 || c.windows["c"] != nil {
+		t.Errorf("expected keys a and b, got %d keys", len(c.windows))
+	}
+
+	// Keys without events in the window are pruned
+	c.observe(&ProcessEvent{EventName: "ProcessExecuted", ContainerMetadata: ContainerMetadata{ContainerID: "c"}}, now.Add(time.Minute))
+	if len(c.windows) != 1 || c.windows["c"] == nil {
+		t.Errorf("expected key c after pruning, got %d keys", len(c.windows))
+	}
+}
+
+func TestRateCount(t *testing.T) {
+	tracker, err := NewRateTracker(EventRates{{
+		Name:      "tcp_drop_per_connection",
+		Events:    []string{EventNameTCPPacketDropped},
+		By:        "Connection",
+		Count:     "Drops",
+		Threshold: 100,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := tracker.rates[0]
+	now := time.Now()
+	tests := []struct {
+		drops    uint
+		total    int
+		exceeded bool
+	}{
+		// Without Drops the event counts once
+		{0, 1, false},
+		{1, 2, false},
+		{98, 100, false},
+		{40, 140, true},
+	}
+	for _, test := range tests {
+		event := &TCPEvent{EventName: EventNameTCPPacketDropped, Connection: "10.0.0.4:50412 10.0.0.9:443", Drops: test.drops}
+		e := c.observe(event, now)
+		if total := c.windows[event.Connection].total; total != test.total {
+			t.Errorf("expected total %d after %d drops, got %d", test.total, test.drops, total)
+		}
+		if (e != nil) != test.exceeded {
+			t.Errorf("expected exceeded %v after %d drops, got %v", test.exceeded, test.drops, e != nil)
+		}
+	}
+}
+
+func TestRateExceededEvent(t *testing.T) {
+	tracker, err := NewRateTracker(EventRates{{
+		Name:      "exec_per_container",
+		By:        "ContainerID",
+		Threshold: 1,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []Event
+	for i := 0; i < 2; i++ {
+		events = append(events, tracker.Produce(&ProcessEvent{EventName: "ProcessExecuted", PID: 42, Comm: "sh", ContainerMetadata: testContainer})...)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	e := events[0].(*RateExceededEvent)
+	if e.Rate != "exec_per_container" || e.Value != "abc123" || e.Count != 2 || e.Window != DefaultRateWindow.String() {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if e.PID != 42 || e.Comm != "sh" || e.ContainerID != "abc123" {
+		t.Errorf("process and container were not copied: %+v", e)
+	}
+}
+
+func TestEventRatesSet(t *testing.T) {
+	tests := []struct {
+		spec      string
+		threshold int
+		window    time.Duration
+		err       string
+	}{
+		{spec: "exec=500", threshold: 500, window: 10 * time.Second},
+		{spec: "exec=20/5s", threshold: 20, window: 5 * time.Second},
+		{spec: "exec", err: "expected name=threshold"},
+		{spec: "fork=1", err: "unknown rate"},
+		{spec: "exec=0", err: "invalid threshold"},
+		{spec: "exec=1/soon", err: "invalid window"},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			rates := EventRates{{Name: "exec", By: "ContainerID", Threshold: 1, Window: 10 * time.Second}}
+			err := rates.Set(test.spec)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rates[0].Threshold != test.threshold || rates[0].Window != test.window {
+				t.Errorf("expected %d/%s, got %d/%s", test.threshold, test.window, rates[0].Threshold, rates[0].Window)
+			}
+		})
+	}
+}
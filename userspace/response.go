@@ -0,0 +1,571 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kris-nova/logger"
+
+	"golang.org/x/sys/unix"
+	"gopkg.in/yaml.v2"
+
+	"github.com/kris-nova/double-slit-experiment/system"
+)
+
+const (
+	// EventNameResponse is the name of every ResponseEvent.
+	EventNameResponse = "Response"
+
+	// DefaultResponseRate is the most actions a Responder will take
+	// across every policy, when no rate is configured.
+	DefaultResponseRate = "10/1m"
+
+	// responseCommLen is the length of the comm of a process
+	// (TASK_COMM_LEN), without the trailing NUL.
+	responseCommLen = 15
+)
+
+// Response actions
+const (
+	ResponseActionKill   = "kill"
+	ResponseActionStop   = "stop"
+	ResponseActionSignal = "signal"
+	ResponseActionFreeze = "freeze"
+	ResponseActionAudit  = "audit"
+)
+
+// Response targets
+const (
+	ResponseTargetProcess = "process"
+	ResponseTargetGroup   = "group"
+)
+
+// Response results
+const (
+	ResponseResultDone        = "done"
+	ResponseResultDryRun      = "dry-run"
+	ResponseResultAllowed     = "allowed"
+	ResponseResultProtected   = "protected"
+	ResponseResultRateLimited = "rate-limited"
+	ResponseResultFailed      = "failed"
+)
+
+// ResponsePolicy will act on the process, or container, of every
+// Event that matches. Alerts are events, so a policy can act on a
+// Rule with its RuleID.
+//
+//   - id: kill-reverse-shell
+//     match: Name == "Alert" && RuleID == "reverse-shell"
+//     action: kill
+//     target: group
+//     rate: 5/1m
+//
+// Actions:
+//   kill     SIGKILL the target
+//   stop     SIGSTOP the target
+//   signal   send the configured signal to the target
+//   freeze   freeze the cgroup of the process with the cgroup v2 freezer
+//   audit    only write the Response event
+type ResponsePolicy struct {
+	ID     string `yaml:"id" json:"id"`
+	Match  string `yaml:"match" json:"match"`
+	Action string `yaml:"action" json:"action"`
+
+	// Signal is the name of the signal for the signal action (SIGTERM).
+	Signal string `yaml:"signal,omitempty" json:"signal,omitempty"`
+
+	// Target is the process (default), or its process group.
+	Target string `yaml:"target,omitempty" json:"target,omitempty"`
+
+	// PID is the Event field with the PID to act on (default PID).
+	PID string `yaml:"pid,omitempty" json:"pid,omitempty"`
+
+	// Rate is the most actions this policy will take in a window (5/1m).
+	Rate string `yaml:"rate,omitempty" json:"rate,omitempty"`
+
+	Disabled bool `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+
+	filter  *EventFilter
+	signal  syscall.Signal
+	limiter *responseLimiter
+}
+
+type ResponsePolicies []*ResponsePolicy
+
+// Compile will validate the ResponsePolicy, and parse its match.
+func (p *ResponsePolicy) Compile() error {
+	if p.ID == "" {
+		return fmt.Errorf("response policy without an id")
+	}
+	if p.Disabled {
+		return nil
+	}
+	if strings.TrimSpace(p.Match) == "" {
+		return fmt.Errorf("response policy %s: empty match", p.ID)
+	}
+	filter, err := ParseEventFilter(p.Match)
+	if err != nil {
+		return fmt.Errorf("response policy %s: %v", p.ID, err)
+	}
+	p.filter = filter
+	switch p.Action {
+	case ResponseActionKill:
+		p.signal = unix.SIGKILL
+	case ResponseActionStop:
+		p.signal = unix.SIGSTOP
+	case ResponseActionSignal:
+		p.signal = unix.SignalNum(p.Signal)
+		if p.signal == 0 {
+			return fmt.Errorf("response policy %s: unknown signal %q", p.ID, p.Signal)
+		}
+	case ResponseActionFreeze, ResponseActionAudit:
+	default:
+		return fmt.Errorf("response policy %s: unknown action %q", p.ID, p.Action)
+	}
+	switch p.Target {
+	case "":
+		p.Target = ResponseTargetProcess
+	case ResponseTargetProcess, ResponseTargetGroup:
+	default:
+		return fmt.Errorf("response policy %s: unknown target %q", p.ID, p.Target)
+	}
+	if p.PID == "" {
+		p.PID = "PID"
+	}
+	if p.Rate != "" {
+		p.limiter, err = newResponseLimiter(p.Rate)
+		if err != nil {
+			return fmt.Errorf("response policy %s: %v", p.ID, err)
+		}
+	}
+	return nil
+}
+
+// ParseResponsePolicies will parse and compile a YAML (or JSON)
+// list of response policies.
+func ParseResponsePolicies(data []byte) (ResponsePolicies, error) {
+	var policies ResponsePolicies
+	err := yaml.UnmarshalStrict(data, &policies)
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range policies {
+		err := policy.Compile()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return policies, nil
+}
+
+// LoadResponsePolicies will load the policies from every file, or
+// directory of files, in order.
+func LoadResponsePolicies(paths ...string) (ResponsePolicies, error) {
+	var policies ResponsePolicies
+	for _, path := range paths {
+		files, err := configFiles(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load response policies: %v", err)
+		}
+		for _, file := range files {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("unable to load response policies: %v", err)
+			}
+			loaded, err := ParseResponsePolicies(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid response policies in %s: %v", file, err)
+			}
+			logger.Debug("Loaded %d response policies: %s", len(loaded), file)
+			policies = append(policies, loaded...)
+		}
+	}
+	return policies, nil
+}
+
+type ResponderConfig struct {
+
+	// DryRun will write the Response event for every action,
+	// without taking the action.
+	DryRun bool
+
+	// Allow is a filter of events that are never acted on.
+	Allow string
+
+	// Rate is the most actions taken across every policy in a
+	// window (10/1m). Defaults to DefaultResponseRate.
+	Rate string
+}
+
+// Responder is an EventProducer that will act on every Event that
+// matches a ResponsePolicy, and produce a ResponseEvent as the
+// audit record of the action.
+//
+// The Responder will never act on itself, PID 1, kernel threads,
+// or the cgroup it is running in.
+type Responder struct {
+	config   ResponderConfig
+	policies ResponsePolicies
+	allow    *EventFilter
+	limiter  *responseLimiter
+	self     int
+	group    int
+	cgroup   string
+}
+
+// NewResponder will create a Responder for the enabled policies.
+// The policies must be compiled.
+func NewResponder(config ResponderConfig, policies ResponsePolicies) (*Responder, error) {
+	if config.Rate == "" {
+		config.Rate = DefaultResponseRate
+	}
+	limiter, err := newResponseLimiter(config.Rate)
+	if err != nil {
+		return nil, err
+	}
+	var allow *EventFilter
+	if config.Allow != "" {
+		allow, err = ParseEventFilter(config.Allow)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r := &Responder{
+		config:  config,
+		allow:   allow,
+		limiter: limiter,
+		self:    os.Getpid(),
+		group:   syscall.Getpgrp(),
+	}
+	r.cgroup, err = system.ProcCgroup(r.self)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find own cgroup: %v", err)
+	}
+	for _, policy := range policies {
+		if !policy.Disabled {
+			r.policies = append(r.policies, policy)
+		}
+	}
+	return r, nil
+}
+
+// Policies will return the policies the Responder acts on.
+func (r *Responder) Policies() ResponsePolicies {
+	return r.policies
+}
+
+// Produce implements EventProducer.
+func (r *Responder) Produce(event Event) []Event {
+	if _, ok := event.(*ResponseEvent); ok {
+		return nil
+	}
+	var events []Event
+	for _, policy := range r.policies {
+		if !policy.filter.Match(event) {
+			continue
+		}
+		response := r.respond(policy, event)
+		if response.Error != "" {
+			logger.Warning("Response %s %s: %s", policy.ID, response.Result, response.Error)
+		}
+		events = append(events, response)
+	}
+	return events
+}
+
+// respond will check the safety guards, and take the action of
+// the policy on the target of the Event.
+func (r *Responder) respond(policy *ResponsePolicy, event Event) *ResponseEvent {
+	response := NewResponseEvent(policy, event, r.config.DryRun)
+	if pid, ok := EventFieldFloat(event, policy.PID); ok {
+		response.PID = int(pid)
+	}
+	if r.allow != nil && r.allow.Match(event) {
+		response.Result = ResponseResultAllowed
+		return response
+	}
+	if policy.Action == ResponseActionAudit {
+		response.Result = ResponseResultDone
+		return response
+	}
+	err := r.protect(policy, event, response)
+	if err != nil {
+		response.Result = ResponseResultProtected
+		response.Error = err.Error()
+		return response
+	}
+	if !responseAllow(policy.limiter, r.limiter) {
+		response.Result = ResponseResultRateLimited
+		return response
+	}
+	if r.config.DryRun {
+		response.Result = ResponseResultDryRun
+		return response
+	}
+	switch policy.Action {
+	case ResponseActionKill, ResponseActionStop, ResponseActionSignal:
+		pid := response.PID
+		if policy.Target == ResponseTargetGroup {
+			pid = -response.PGID
+		}
+		err = syscall.Kill(pid, policy.signal)
+	case ResponseActionFreeze:
+		err = system.FreezeCgroup(response.Cgroup)
+	}
+	if err != nil {
+		response.Result = ResponseResultFailed
+		response.Error = err.Error()
+		return response
+	}
+	response.Result = ResponseResultDone
+	return response
+}
+
+// protect will return an error if the target of a response is
+// dse itself, PID 1, a kernel thread, or a process that has been
+// replaced since the Event, by its comm or its start time.
+// The process group and cgroup of the target are filled in.
+func (r *Responder) protect(policy *ResponsePolicy, event Event, response *ResponseEvent) error {
+	pid := response.PID
+	if pid <= 1 {
+		return fmt.Errorf("invalid PID %d", pid)
+	}
+	if pid == r.self {
+		return fmt.Errorf("PID %d is dse", pid)
+	}
+	tgid, err := system.ProcTgid(pid)
+	if err != nil {
+		return fmt.Errorf("PID %d: %v", pid, err)
+	}
+	if tgid == r.self {
+		return fmt.Errorf("PID %d is a thread of dse", pid)
+	}
+	proc, err := system.ProcPIDLookup(pid)
+	if err != nil || proc == nil {
+		return fmt.Errorf("PID %d: process not found", pid)
+	}
+	if pid == 2 || proc.ParentPid == 2 {
+		return fmt.Errorf("PID %d is a kernel thread", pid)
+	}
+
+	// The PID may have been reused since the Event
+	if comm := responseComm(event); comm != "" {
+		actual, err := system.ProcComm(pid)
+		if err != nil {
+			return fmt.Errorf("PID %d: %v", pid, err)
+		}
+		if actual != comm {
+			return fmt.Errorf("PID %d is now %s, not %s", pid, actual, comm)
+		}
+	}
+	started, err := system.ProcStartTime(pid)
+	if err != nil {
+		return fmt.Errorf("PID %d: %v", pid, err)
+	}
+	if at := EventTimestamp(event); started.After(at) {
+		return fmt.Errorf("PID %d started at %s, after the event at %s", pid, started.Format(time.RFC3339Nano), at.Format(time.RFC3339Nano))
+	}
+
+	switch {
+	case policy.Action == ResponseActionFreeze:
+		cgroup, err := system.ProcCgroup(pid)
+		if err != nil {
+			return fmt.Errorf("PID %d: %v", pid, err)
+		}
+		if cgroup == "" || cgroup == "/" {
+			return fmt.Errorf("PID %d is in the root cgroup", pid)
+		}
+		if cgroup == r.cgroup || strings.HasPrefix(r.cgroup, strings.TrimSuffix(cgroup, "/")+"/") {
+			return fmt.Errorf("cgroup %s contains dse", cgroup)
+		}
+		response.Cgroup = cgroup
+	case policy.Target == ResponseTargetGroup:
+		pgid, err := syscall.Getpgid(pid)
+		if err != nil {
+			return fmt.Errorf("PID %d: %v", pid, err)
+		}
+		if pgid <= 1 || pgid == r.group {
+			return fmt.Errorf("process group %d is protected", pgid)
+		}
+		response.PGID = pgid
+	}
+	return nil
+}
+
+// responseComm will return the comm the target of a response is
+// expected to have. The Comm of a ProcessExecuted event is read
+// before the exec(), so the basename of the file is used instead,
+// as the kernel does. An alert is checked against its first event.
+func responseComm(event Event) string {
+	if alert, ok := event.(*AlertEvent); ok && len(alert.Events) > 0 {
+		event = alert.Events[0]
+	}
+	if event.Name() != "ProcessExecuted" {
+		return EventFieldString(event, "Comm")
+	}
+	filename := EventFieldString(event, "Filename")
+	if filename == "" {
+		return ""
+	}
+	comm := filepath.Base(filename)
+	if len(comm) > responseCommLen {
+		comm = comm[:responseCommLen]
+	}
+	return comm
+}
+
+// responseLimiter will allow at most max actions in every window.
+type responseLimiter struct {
+	mtx    sync.Mutex
+	max    int
+	window time.Duration
+	start  time.Time
+	count  int
+}
+
+// newResponseLimiter will parse a rate such as 10/1m.
+func newResponseLimiter(rate string) (*responseLimiter, error) {
+	parts := strings.SplitN(rate, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid rate %q, expected count/window", rate)
+	}
+	max, err := strconv.Atoi(parts[0])
+	if err != nil || max <= 0 {
+		return nil, fmt.Errorf("invalid count in rate %q", rate)
+	}
+	window, err := time.ParseDuration(parts[1])
+	if err != nil || window <= 0 {
+		return nil, fmt.Errorf("invalid window in rate %q", rate)
+	}
+	return &responseLimiter{
+		max:    max,
+		window: window,
+	}, nil
+}
+
+// responseAllow will take an action from every limiter, or from none
+// of them when any limiter is full. A nil limiter allows everything.
+// Limiters are locked in order, so a policy is always locked before
+// the global limiter.
+func responseAllow(limiters ...*responseLimiter) bool {
+	var locked []*responseLimiter
+	for _, l := range limiters {
+		if l == nil {
+			continue
+		}
+		l.mtx.Lock()
+		defer l.mtx.Unlock()
+		locked = append(locked, l)
+	}
+	now := time.Now()
+	for _, l := range locked {
+		if now.Sub(l.start) > l.window {
+			l.start = now
+			l.count = 0
+		}
+		if l.count >= l.max {
+			return false
+		}
+	}
+	for _, l := range locked {
+		l.count++
+	}
+	return true
+}
+
+// ResponseEvent is the audit record of a Responder acting, or
+// deciding not to act, on an Event.
+type ResponseEvent struct {
+	EventName string    `json:"Name"`
+	Time      time.Time `json:"Time"`
+	PolicyID  string    `json:"PolicyID"`
+	Action    string    `json:"Action"`
+	Signal    string    `json:"Signal,omitempty"`
+	Target    string    `json:"Target"`
+	PID       int       `json:"PID"`
+	PGID      int       `json:"PGID,omitempty"`
+	Cgroup    string    `json:"Cgroup,omitempty"`
+	DryRun    bool      `json:"DryRun"`
+	Result    string    `json:"Result"`
+	Error     string    `json:"Error,omitempty"`
+	Comm      string    `json:"Comm,omitempty"`
+	Event     Event     `json:"Event"`
+	ContainerMetadata
+}
+
+// Timestamp is the time of the response.
+func (e *ResponseEvent) Timestamp() time.Time {
+	return e.Time
+}
+
+// NewResponseEvent will create a ResponseEvent for a policy, and
+// the Event it matched.
+func NewResponseEvent(policy *ResponsePolicy, event Event, dryRun bool) *ResponseEvent {
+	response := &ResponseEvent{
+		EventName: EventNameResponse,
+		Time:      time.Now(),
+		PolicyID:  policy.ID,
+		Action:    policy.Action,
+		Target:    policy.Target,
+		DryRun:    dryRun,
+		Comm:      EventFieldString(event, "Comm"),
+		Event:     event,
+	}
+	if policy.signal != 0 {
+		response.Signal = unix.SignalName(policy.signal)
+	}
+	if c, ok := event.(ContainerEnrichable); ok {
+		response.ContainerMetadata = *c.Container()
+	}
+	return response
+}
+
+func (e *ResponseEvent) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *ResponseEvent) String() string {
+	return fmt.Sprintf("%s %s %s %d: %s", e.PolicyID, e.Action, e.Target, e.PID, e.Result)
+}
+
+func (e *ResponseEvent) Name() string {
+	return e.EventName
+}
+
+// Severity implements SeverityEvent.
+func (e *ResponseEvent) Severity() int {
+	if e.Result == ResponseResultDone {
+		return RuleSeverities["high"]
+	}
+	return RuleSeverities["medium"]
+}
@@ -0,0 +1,149 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseAllow(t *testing.T) {
+	policy, err := newResponseLimiter("1/1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	global, err := newResponseLimiter("2/1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := newResponseLimiter("5/1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !responseAllow(policy, global) {
+		t.Fatalf("expected the first action to be allowed")
+	}
+
+	// A full policy limiter does not take from the global limiter
+	for i := 0; i < 3; i++ {
+		if responseAllow(policy, global) {
+			t.Fatalf("expected the policy limiter to be full")
+		}
+	}
+	if global.count != 1 {
+		t.Errorf("expected 1 action from the global limiter, got %d", global.count)
+	}
+
+	// A full global limiter does not take from the policy limiter
+	if !responseAllow(other, global) {
+		t.Fatalf("expected the second action to be allowed")
+	}
+	if responseAllow(other, global) {
+		t.Fatalf("expected the global limiter to be full")
+	}
+	if other.count != 1 {
+		t.Errorf("expected 1 action from the other policy limiter, got %d", other.count)
+	}
+	if !responseAllow(nil) {
+		t.Errorf("expected no limiter to allow every action")
+	}
+}
+
+func TestResponseProtectStartTime(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skip(err)
+	}
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+
+	policies, err := ParseResponsePolicies([]byte("- id: kill-sleep\n  match: Filename $= \"/sleep\"\n  action: kill\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewResponder(ResponderConfig{DryRun: true}, policies)
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// The Comm of an exec() is the comm of the process before
+	// the exec(), such as the shell that ran it.
+	executed := func(filename string, at time.Time) *ProcessEvent {
+		event := &ProcessEvent{EventName: "ProcessExecuted", PID: uint(cmd.Process.Pid), Comm: "bash", Filename: filename}
+		event.setTimestamp(at)
+		return event
+	}
+	alert := func(event Event) Event {
+		return NewAlertEvent(&Rule{ID: "sleep"}, event)
+	}
+	later := time.Now().Add(time.Minute)
+	tests := []struct {
+		name  string
+		event Event
+		err   string
+	}{
+		{"exec after start", executed(cmd.Path, later), ""},
+		{"alert on exec after start", alert(executed(cmd.Path, later)), ""},
+		{"exec before start", executed(cmd.Path, time.Now().Add(-time.Hour)), "after the event"},
+		{"exec of another file", executed("/usr/bin/cat", later), "is now sleep, not cat"},
+		{"alert on exec of another file", alert(executed("/usr/bin/cat", later)), "is now sleep, not cat"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := r.respond(policies[0], test.event)
+			if test.err == "" {
+				if response.Result != ResponseResultDryRun {
+					t.Errorf("expected %s, got %s: %s", ResponseResultDryRun, response.Result, response.Error)
+				}
+				return
+			}
+			if response.Result != ResponseResultProtected || !strings.Contains(response.Error, test.err) {
+				t.Errorf("expected protected with %q, got %s: %s", test.err, response.Result, response.Error)
+			}
+		})
+	}
+}
+
+func TestResponseComm(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    Event
+		expected string
+	}{
+		{"exec", &ProcessEvent{EventName: "ProcessExecuted", Comm: "bash", Filename: "/usr/bin/sleep"}, "sleep"},
+		{"long exec", &ProcessEvent{EventName: "ProcessExecuted", Comm: "bash", Filename: "/usr/bin/systemd-tmpfiles"}, "systemd-tmpfile"},
+		{"exec without a file", &ProcessEvent{EventName: "ProcessExecuted", Comm: "bash"}, ""},
+		{"alert", NewAlertEvent(&Rule{ID: "exec"}, &ProcessEvent{EventName: "ProcessExecuted", Comm: "bash", Filename: "/bin/nc"}), "nc"},
+		{"exec after the exec", &LifecycleEvent{EventName: EventNameProcessExec, Comm: "sleep", Filename: "/usr/bin/sleep"}, "sleep"},
+		{"other", &FileOpenEvent{EventName: EventNameFileOpened, Comm: "cat", Filename: "/etc/shadow"}, "cat"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := responseComm(test.event); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,524 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kris-nova/logger"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// EventNameAlert is the name of every AlertEvent.
+	EventNameAlert = "Alert"
+
+	// RulesDefault can be passed to LoadRules() in place of a path
+	// to load ProfileDefaultRules().
+	RulesDefault = "default"
+
+	// DefaultRuleMaxUnique is the number of unique keys remembered
+	// for a Rule that does not set one. Once reached, the least
+	// recently seen key is forgotten and the Rule will alert on
+	// it again.
+	DefaultRuleMaxUnique = 10000
+
+	// DefaultRuleWithin is the window of a sequence Rule that
+	// does not set one.
+	DefaultRuleWithin = 10 * time.Second
+)
+
+// DefaultRuleBy are the "by" fields of a sequence Rule that does not
+// set any. An empty ContainerID is the host.
+var DefaultRuleBy = []string{"PID", "ContainerID"}
+
+// RuleSeverities map the severity of a Rule to the 0-10 scale
+// used by EventSeverity().
+var RuleSeverities = map[string]int{
+	"info":     1,
+	"low":      3,
+	"medium":   5,
+	"high":     8,
+	"critical": 10,
+}
+
+// Rule is a declarative detection that is evaluated against every
+// Event. The condition is an EventFilter expression.
+//
+//   - id: shell-in-container
+//     description: A shell was executed inside a container
+//     severity: high
+//     condition: Name == "ProcessExecuted" && ContainerID && Filename =~ "/(ba|da|z|k)?sh$"
+//     tags: [container, shell]
+//
+// A Rule with unique fields will only alert the first time it sees
+// each combination of their values.
+//
+//   unique: [ContainerID, SourcePort]
+//
+// A Rule with a sequence, instead of a condition, will alert once
+// events match every condition of the sequence in order, within
+// the window, with the same values for the "by" fields. The alert
+// has every event of the sequence. The default "by" fields are PID
+// and ContainerID. A "by" field may list alternatives, such as
+// ChildPID|PID, and the first one that is set is used.
+//
+//   - id: reverse-shell
+//     description: A shell opened an outbound TCP connection
+//     severity: critical
+//     by: [PID]
+//     within: 10s
+//     sequence:
+//       - Name == "ProcessExecuted" && Filename =~ "/(ba|da|z|k|c|tc|a)?sh$"
+//       - Name == "SocketState" && OldStateName == "TCP_SYN_SENT" && NewStateName == "TCP_ESTABLISHED"
+type Rule struct {
+	ID          string   `yaml:"id" json:"id"`
+	Description string   `yaml:"description" json:"description"`
+	Severity    string   `yaml:"severity" json:"severity"`
+	Condition   string   `yaml:"condition,omitempty" json:"condition,omitempty"`
+	Sequence    []string `yaml:"sequence,omitempty" json:"sequence,omitempty"`
+	By          []string `yaml:"by,omitempty" json:"by,omitempty"`
+	Within      string   `yaml:"within,omitempty" json:"within,omitempty"`
+	Unique      []string `yaml:"unique,omitempty" json:"unique,omitempty"`
+	MaxUnique   int      `yaml:"maxUnique,omitempty" json:"maxUnique,omitempty"`
+	Tags        []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+
+	// Disabled will turn off a Rule with the same ID that was loaded
+	// earlier, such as one of the default rules.
+	Disabled bool `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+
+	filter   *EventFilter
+	sequence []*EventFilter
+	by       []string
+	within   time.Duration
+}
+
+// Rules are evaluated in order.
+type Rules []*Rule
+
+// Compile will validate the Rule, and parse its condition.
+func (r *Rule) Compile() error {
+	if r.ID == "" {
+		return fmt.Errorf("rule without an id")
+	}
+	if r.Disabled {
+		return nil
+	}
+	if _, ok := RuleSeverities[r.Severity]; !ok {
+		return fmt.Errorf("rule %s: unknown severity %q", r.ID, r.Severity)
+	}
+	if len(r.Sequence) > 0 {
+		return r.compileSequence()
+	}
+	if strings.TrimSpace(r.Condition) == "" {
+		return fmt.Errorf("rule %s: empty condition", r.ID)
+	}
+	filter, err := ParseEventFilter(r.Condition)
+	if err != nil {
+		return fmt.Errorf("rule %s: %v", r.ID, err)
+	}
+	r.filter = filter
+	return nil
+}
+
+func (r *Rule) compileSequence() error {
+	if r.Condition != "" {
+		return fmt.Errorf("rule %s: has both a condition and a sequence", r.ID)
+	}
+	if len(r.Sequence) < 2 {
+		return fmt.Errorf("rule %s: a sequence needs at least 2 conditions", r.ID)
+	}
+	r.by = DefaultRuleBy
+	if len(r.By) > 0 {
+		r.by = r.By
+	}
+	for _, field := range r.by {
+		for _, alternative := range strings.Split(field, "|") {
+			if strings.TrimSpace(alternative) == "" {
+				return fmt.Errorf("rule %s: invalid by field %q", r.ID, field)
+			}
+		}
+	}
+	r.within = DefaultRuleWithin
+	if r.Within != "" {
+		within, err := time.ParseDuration(r.Within)
+		if err != nil || within <= 0 {
+			return fmt.Errorf("rule %s: invalid within %q", r.ID, r.Within)
+		}
+		r.within = within
+	}
+	r.sequence = nil
+	for i, condition := range r.Sequence {
+		if strings.TrimSpace(condition) == "" {
+			return fmt.Errorf("rule %s: empty sequence condition %d", r.ID, i)
+		}
+		filter, err := ParseEventFilter(condition)
+		if err != nil {
+			return fmt.Errorf("rule %s: %v", r.ID, err)
+		}
+		r.sequence = append(r.sequence, filter)
+	}
+	return nil
+}
+
+// Match will return true if the Event matches the condition
+// of a compiled Rule.
+func (r *Rule) Match(event Event) bool {
+	if r.Disabled || r.filter == nil {
+		return false
+	}
+	return r.filter.Match(event)
+}
+
+// References will return true if the condition, or any step of
+// the sequence, of a compiled Rule can match an Event with name.
+func (r *Rule) References(name string) bool {
+	if r.Disabled {
+		return false
+	}
+	if r.filter.References("Name", name) {
+		return true
+	}
+	for _, filter := range r.sequence {
+		if filter.References("Name", name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MustCompileRules will compile every Rule, and panic on an
+// invalid Rule. Used for static profiles.
+func MustCompileRules(rules Rules) Rules {
+	for _, rule := range rules {
+		err := rule.Compile()
+		if err != nil {
+			panic(err)
+		}
+	}
+	return rules
+}
+
+// ParseRules will parse and compile a YAML (or JSON) list of rules.
+func ParseRules(data []byte) (Rules, error) {
+	var rules Rules
+	err := yaml.UnmarshalStrict(data, &rules)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		err := rule.Compile()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// LoadRules will load the rules from every path in order. A path
+// may be a file, a directory of *.yaml, *.yml and *.json files,
+// or RulesDefault.
+//
+// A Rule with the same ID as a Rule loaded earlier will replace it.
+func LoadRules(paths ...string) (Rules, error) {
+	var rules Rules
+	for _, path := range paths {
+		if path == RulesDefault {
+			rules = rules.Merge(ProfileDefaultRules())
+			continue
+		}
+		files, err := configFiles(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load rules: %v", err)
+		}
+		for _, file := range files {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("unable to load rules: %v", err)
+			}
+			loaded, err := ParseRules(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rules in %s: %v", file, err)
+			}
+			logger.Debug("Loaded %d rules: %s", len(loaded), file)
+			rules = rules.Merge(loaded)
+		}
+	}
+	return rules, nil
+}
+
+// configFiles will return the path of a file, or the *.yaml, *.yml
+// and *.json files in a directory in order.
+func configFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Merge will return the rules with every Rule in from added, or
+// replacing the Rule with the same ID.
+func (rules Rules) Merge(from Rules) Rules {
+	merged := append(Rules{}, rules...)
+	for _, rule := range from {
+		replaced := false
+		for i, existing := range merged {
+			if existing.ID == rule.ID {
+				merged[i] = rule
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, rule)
+		}
+	}
+	return merged
+}
+
+// Enabled will return the rules that are not disabled.
+func (rules Rules) Enabled() Rules {
+	var enabled Rules
+	for _, rule := range rules {
+		if !rule.Disabled {
+			enabled = append(enabled, rule)
+		}
+	}
+	return enabled
+}
+
+// References will return true if an enabled Rule references
+// any of the event names.
+func (rules Rules) References(names ...string) bool {
+	for _, rule := range rules {
+		for _, name := range names {
+			if rule.References(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// YAML will marshal the rules in the same format they are loaded from.
+func (rules Rules) YAML() ([]byte, error) {
+	return yaml.Marshal(rules)
+}
+
+// RuleEngine is an EventProducer that will evaluate every Rule
+// against every Event, and produce an AlertEvent for each match.
+type RuleEngine struct {
+	mtx       sync.Mutex
+	rules     Rules
+	unique    map[string]*ruleUnique
+	sequences map[string]*ruleSequences
+}
+
+// ruleUnique is the set of unique keys seen by a Rule, with the
+// most recently seen key at the front.
+type ruleUnique struct {
+	keys  map[string]*list.Element
+	order *list.List
+}
+
+// NewRuleEngine will create a RuleEngine for the enabled rules.
+// The rules must be compiled.
+func NewRuleEngine(rules Rules) *RuleEngine {
+	return &RuleEngine{
+		rules:     rules.Enabled(),
+		unique:    map[string]*ruleUnique{},
+		sequences: map[string]*ruleSequences{},
+	}
+}
+
+// Rules will return the rules evaluated by the RuleEngine.
+func (e *RuleEngine) Rules() Rules {
+	return e.rules
+}
+
+// Produce implements EventProducer.
+func (e *RuleEngine) Produce(event Event) []Event {
+	var events []Event
+	for _, alert := range e.Evaluate(event) {
+		events = append(events, alert)
+	}
+	return events
+}
+
+// Evaluate will return an AlertEvent for every Rule that matches
+// the Event. Alerts are never evaluated, so a Rule can not alert
+// on its own alerts.
+func (e *RuleEngine) Evaluate(event Event) []*AlertEvent {
+	if _, ok := event.(*AlertEvent); ok {
+		return nil
+	}
+	var alerts []*AlertEvent
+	for _, rule := range e.rules {
+		var events []Event
+		if len(rule.sequence) > 0 {
+			events = e.correlate(rule, event)
+		} else if rule.Match(event) {
+			events = []Event{event}
+		}
+		if len(events) == 0 {
+			continue
+		}
+		if len(rule.Unique) > 0 && !e.first(rule, events[0]) {
+			continue
+		}
+		alerts = append(alerts, NewAlertEvent(rule, events...))
+	}
+	return alerts
+}
+
+// first will return true the first time the values of the unique
+// fields of the Rule are seen. Once the Rule has seen MaxUnique keys,
+// the least recently seen key is forgotten to make room.
+func (e *RuleEngine) first(rule *Rule, event Event) bool {
+	values := make([]string, len(rule.Unique))
+	for i, field := range rule.Unique {
+		values[i] = EventFieldString(event, field)
+	}
+	key := strings.Join(values, "\x00")
+
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	seen, ok := e.unique[rule.ID]
+	if !ok {
+		seen = &ruleUnique{
+			keys:  map[string]*list.Element{},
+			order: list.New(),
+		}
+		e.unique[rule.ID] = seen
+	}
+	if element, ok := seen.keys[key]; ok {
+		seen.order.MoveToFront(element)
+		return false
+	}
+	max := rule.MaxUnique
+	if max <= 0 {
+		max = DefaultRuleMaxUnique
+	}
+	for seen.order.Len() >= max {
+		oldest := seen.order.Back()
+		seen.order.Remove(oldest)
+		delete(seen.keys, oldest.Value.(string))
+	}
+	seen.keys[key] = seen.order.PushFront(key)
+	return true
+}
+
+// AlertEvent is produced by a RuleEngine when a Rule matches.
+// The process and container of the first triggering Event are
+// copied to the alert, so alerts can be filtered like any Event.
+type AlertEvent struct {
+	EventName    string    `json:"Name"`
+	Time         time.Time `json:"Time"`
+	RuleID       string    `json:"RuleID"`
+	Description  string    `json:"Description"`
+	SeverityName string    `json:"Severity"`
+	Tags         []string  `json:"Tags,omitempty"`
+	PID          uint      `json:"PID,omitempty"`
+	Comm         string    `json:"Comm,omitempty"`
+	Filename     string    `json:"Filename,omitempty"`
+	Events       []Event   `json:"Events"`
+	ContainerMetadata
+}
+
+// Timestamp is the time the alert was raised.
+func (e *AlertEvent) Timestamp() time.Time {
+	return e.Time
+}
+
+// NewAlertEvent will create an AlertEvent for a Rule, and the
+// events that triggered it.
+func NewAlertEvent(rule *Rule, events ...Event) *AlertEvent {
+	alert := &AlertEvent{
+		EventName:    EventNameAlert,
+		Time:         time.Now(),
+		RuleID:       rule.ID,
+		Description:  rule.Description,
+		SeverityName: rule.Severity,
+		Tags:         rule.Tags,
+		Events:       events,
+	}
+	if len(events) == 0 {
+		return alert
+	}
+	trigger := events[0]
+	if pid, ok := EventFieldFloat(trigger, "PID"); ok {
+		alert.PID = uint(pid)
+	}
+	alert.Comm = EventFieldString(trigger, "Comm")
+	alert.Filename = EventFieldString(trigger, "Filename")
+	if c, ok := trigger.(ContainerEnrichable); ok {
+		alert.ContainerMetadata = *c.Container()
+	}
+	return alert
+}
+
+func (a *AlertEvent) JSON() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+func (a *AlertEvent) String() string {
+	return fmt.Sprintf("[%s] %s: %s", a.SeverityName, a.RuleID, a.Description)
+}
+
+func (a *AlertEvent) Name() string {
+	return a.EventName
+}
+
+// Severity implements SeverityEvent.
+func (a *AlertEvent) Severity() int {
+	if severity, ok := RuleSeverities[a.SeverityName]; ok {
+		return severity
+	}
+	return DefaultSeverity
+}
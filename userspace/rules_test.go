@@ -0,0 +1,302 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var testContainer = ContainerMetadata{ContainerID: "abc123"}
+
+func TestDefaultRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []Event
+		alerts []string
+	}{
+		{
+			name:   "shell in container",
+			events: []Event{&ProcessEvent{EventName: "ProcessExecuted", Filename: "/bin/bash", ContainerMetadata: testContainer}},
+			alerts: []string{"shell-in-container"},
+		},
+		{
+			name:   "shell on the host",
+			events: []Event{&ProcessEvent{EventName: "ProcessExecuted", Filename: "/bin/bash"}},
+		},
+		{
+			name:   "exec from tmp",
+			events: []Event{&ProcessEvent{EventName: "ProcessExecuted", Filename: "/dev/shm/payload"}},
+			alerts: []string{"exec-from-tmp"},
+		},
+		{
+			name:   "network tool in container",
+			events: []Event{&ProcessEvent{EventName: "ProcessExecuted", Filename: "/usr/bin/ncat", ContainerMetadata: testContainer}},
+			alerts: []string{"network-tool-in-container"},
+		},
+		{
+			name:   "package manager in container",
+			events: []Event{&ProcessEvent{EventName: "ProcessExecuted", Filename: "/usr/bin/apt-get", ContainerMetadata: testContainer}},
+			alerts: []string{"package-manager-in-container"},
+		},
+		{
+			name: "container listen new port",
+			events: []Event{
+				&SocketEvent{EventName: "SocketState", NewStateName: "TCP_LISTEN", SourcePort: 8080, ContainerMetadata: testContainer},
+				&SocketEvent{EventName: "SocketState", NewStateName: "TCP_LISTEN", SourcePort: 8080, ContainerMetadata: testContainer},
+				&SocketEvent{EventName: "SocketState", NewStateName: "TCP_LISTEN", SourcePort: 9090, ContainerMetadata: testContainer},
+			},
+			alerts: []string{"container-listen-new-port", "container-listen-new-port"},
+		},
+		{
+			name:   "setuid exec in container",
+			events: []Event{&PrivilegeEvent{EventName: "ExecPrivilegeChanged", Escalated: true, ContainerMetadata: testContainer}},
+			alerts: []string{"setuid-exec-in-container"},
+		},
+		{
+			name:   "module loaded in container",
+			events: []Event{&ModuleEvent{EventName: EventNameModuleLoaded, ContainerMetadata: testContainer}},
+			alerts: []string{"module-loaded-in-container"},
+		},
+		{
+			name:   "module load failed in container",
+			events: []Event{&ModuleEvent{EventName: EventNameModuleLoaded, Error: "EPERM", ContainerMetadata: testContainer}},
+		},
+		{
+			name:   "process access across containers",
+			events: []Event{&ProcessAccessEvent{EventName: "ProcessTraced", CrossContainer: true}},
+			alerts: []string{"process-access-across-containers"},
+		},
+		{
+			name: "reverse shell",
+			events: []Event{
+				&ProcessEvent{EventName: "ProcessExecuted", Filename: "/bin/sh", PID: 42},
+				&SocketEvent{EventName: "SocketState", OldStateName: "TCP_SYN_SENT", NewStateName: "TCP_ESTABLISHED", PID: 42},
+			},
+			alerts: []string{"reverse-shell"},
+		},
+		{
+			name: "reverse shell from another process",
+			events: []Event{
+				&ProcessEvent{EventName: "ProcessExecuted", Filename: "/bin/sh", PID: 42},
+				&SocketEvent{EventName: "SocketState", OldStateName: "TCP_SYN_SENT", NewStateName: "TCP_ESTABLISHED", PID: 43},
+			},
+		},
+		{
+			name: "user namespace then setns to the host",
+			events: []Event{
+				&PrivilegeEvent{EventName: EventNameNamespaceChanged, Syscall: "clone", PID: 42, ChildPID: 43, Namespaces: []string{"CLONE_NEWUSER", "CLONE_NEWNS"}, ContainerMetadata: testContainer},
+				&PrivilegeEvent{EventName: EventNameNamespaceChanged, Syscall: "setns", PID: 43, HostNamespaces: []string{"mnt"}, ContainerMetadata: testContainer},
+			},
+			alerts: []string{"userns-setns-host"},
+		},
+		{
+			name: "user namespace then setns in the container",
+			events: []Event{
+				&PrivilegeEvent{EventName: EventNameNamespaceChanged, Syscall: "unshare", PID: 42, Namespaces: []string{"CLONE_NEWUSER"}, ContainerMetadata: testContainer},
+				&PrivilegeEvent{EventName: EventNameNamespaceChanged, Syscall: "setns", PID: 42, ContainerMetadata: testContainer},
+			},
+		},
+		{
+			name: "user namespace on the host",
+			events: []Event{
+				&PrivilegeEvent{EventName: EventNameNamespaceChanged, Syscall: "unshare", PID: 42, Namespaces: []string{"CLONE_NEWUSER"}},
+				&PrivilegeEvent{EventName: EventNameNamespaceChanged, Syscall: "setns", PID: 42, HostNamespaces: []string{"mnt"}},
+			},
+		},
+		{
+			name:   "file opened",
+			events: []Event{&FileOpenEvent{EventName: EventNameFileOpened, Filename: "/tmp/payload", ContainerMetadata: testContainer}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine := NewRuleEngine(ProfileDefaultRules())
+			var alerts []string
+			for _, event := range test.events {
+				for _, alert := range engine.Evaluate(event) {
+					alerts = append(alerts, alert.RuleID)
+				}
+			}
+			if strings.Join(alerts, ",") != strings.Join(test.alerts, ",") {
+				t.Errorf("expected %v, got %v", test.alerts, alerts)
+			}
+		})
+	}
+}
+
+func TestAlertEvent(t *testing.T) {
+	engine := NewRuleEngine(ProfileDefaultRules())
+	trigger := &ProcessEvent{EventName: "ProcessExecuted", Filename: "/bin/bash", Comm: "bash", PID: 7, ContainerMetadata: ContainerMetadata{ContainerID: "abc123", PodName: "web-0"}}
+	alerts := engine.Produce(trigger)
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %d", len(alerts))
+	}
+	alert := alerts[0].(*AlertEvent)
+	if alert.PID != 7 || alert.Comm != "bash" || alert.Filename != "/bin/bash" || alert.PodName != "web-0" {
+		t.Errorf("trigger was not copied to the alert: %+v", alert)
+	}
+	if alert.Severity() != RuleSeverities["high"] {
+		t.Errorf("expected high severity, got %d", alert.Severity())
+	}
+
+	// Alerts are never evaluated
+	if n := len(engine.Produce(alert)); n != 0 {
+		t.Errorf("expected no alerts for an alert, got %d", n)
+	}
+}
+
+func TestRuleUniqueEviction(t *testing.T) {
+	rules := MustCompileRules(Rules{{
+		ID:        "unique",
+		Severity:  "low",
+		Condition: `Name == "ProcessExecuted"`,
+		Unique:    []string{"Filename"},
+		MaxUnique: 2,
+	}})
+	engine := NewRuleEngine(rules)
+	tests := []struct {
+		filename string
+		alert    bool
+	}{
+		{"/a", true},
+		{"/b", true},
+		{"/a", false},
+		// /b is the least recently seen, and is forgotten
+		{"/c", true},
+		{"/a", false},
+		{"/c", false},
+		{"/b", true},
+	}
+	for i, test := range tests {
+		alerts := engine.Evaluate(&ProcessEvent{EventName: "ProcessExecuted", Filename: test.filename})
+		if (len(alerts) == 1) != test.alert {
+			t.Errorf("%d %s: expected alert=%v, got %d alerts", i, test.filename, test.alert, len(alerts))
+		}
+	}
+}
+
+func TestLoadRulesDisabled(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "rules.yaml"), []byte(`
+- id: shell-in-container
+  disabled: true
+- id: custom
+  description: A custom rule
+  severity: info
+  condition: Name == "ProcessExecuted" && Comm == "custom"
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadRules(RulesDefault, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := NewRuleEngine(rules)
+	for _, rule := range engine.Rules() {
+		if rule.ID == "shell-in-container" {
+			t.Errorf("disabled rule is evaluated")
+		}
+	}
+	if n := len(engine.Evaluate(&ProcessEvent{EventName: "ProcessExecuted", Filename: "/bin/bash", ContainerMetadata: testContainer})); n != 0 {
+		t.Errorf("expected no alerts from a disabled rule, got %d", n)
+	}
+	if n := len(engine.Evaluate(&ProcessEvent{EventName: "ProcessExecuted", Comm: "custom"})); n != 1 {
+		t.Errorf("expected 1 alert from a custom rule, got %d", n)
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		err   string
+	}{
+		{"invalid yaml", "- id: [", "yaml"},
+		{"unknown field", "- id: x\n  severity: low\n  condition: PID\n  unknown: 1", "unknown"},
+		{"missing id", "- severity: low\n  condition: PID", "without an id"},
+		{"unknown severity", "- id: x\n  severity: urgent\n  condition: PID", "unknown severity"},
+		{"empty condition", "- id: x\n  severity: low", "empty condition"},
+		{"invalid condition", "- id: x\n  severity: low\n  condition: PID ==", "invalid filter"},
+		{"condition and sequence", "- id: x\n  severity: low\n  condition: PID\n  by: [PID]\n  sequence: [PID, PID]", "both"},
+		{"short sequence", "- id: x\n  severity: low\n  by: [PID]\n  sequence: [PID]", "at least 2"},
+		{"invalid within", "- id: x\n  severity: low\n  by: [PID]\n  within: soon\n  sequence: [PID, PID]", "invalid within"},
+		{"invalid by", "- id: x\n  severity: low\n  by: [ChildPID|]\n  sequence: [PID, PID]", "invalid by"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseRules([]byte(test.rules))
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestProfileRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    Rules
+		expected []string
+	}{
+		{
+			name:     "default rules",
+			rules:    ProfileDefaultRules(),
+			expected: []string{"BPF", "Module", "Privilege", "ProcessAccess"},
+		},
+		{
+			name: "sequence",
+			rules: MustCompileRules(Rules{{
+				ID:       "reset-after-connect",
+				Severity: "low",
+				Sequence: []string{`Name == "SocketConnected"`, `Name ^= "TCPReset"`},
+				By:       []string{"PID"},
+				Within:   "1s",
+			}}),
+			expected: []string{"Accept", "Connect", "TCP"},
+		},
+		{
+			name: "disabled",
+			rules: MustCompileRules(Rules{
+				{ID: "mounts", Severity: "low", Condition: `Name == "Mounted"`, Disabled: true},
+				{ID: "shell", Severity: "low", Condition: `Name == "ProcessExecuted"`},
+			}),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actual []string
+			for name := range ProfileRules(test.rules) {
+				actual = append(actual, name)
+			}
+			sort.Strings(actual)
+			if strings.Join(actual, ",") != strings.Join(test.expected, ",") {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
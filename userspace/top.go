@@ -0,0 +1,201 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/sys/unix"
+)
+
+// TopSnapshot is a summary of recent events, sorted for display
+// by `dse top`.
+type TopSnapshot struct {
+	Events          int
+	Executables     []*TopCount
+	Talkers         []*TopCount
+	Signals         []*SignalEvent
+	ContainerStarts []*ContainerEvent
+	Containers      []*TopContainer
+}
+
+// TopCount is a single row in a ranked pane.
+type TopCount struct {
+	Key    string
+	Count  int
+	Detail string
+}
+
+// TopContainer is every process and socket seen in a single container.
+type TopContainer struct {
+	ContainerMetadata
+	Events    int
+	Processes []*TopProcess
+	Sockets   []*SocketEvent
+}
+
+// TopProcess is a process seen in a container.
+type TopProcess struct {
+	PID      uint
+	Comm     string
+	Filename string
+	Events   int
+}
+
+// NewTopSnapshot will summarize events, oldest first, that match the filter.
+// Lists of recent events are newest first, and limited to limit entries.
+func NewTopSnapshot(events []Event, filter *EventFilter, limit int) *TopSnapshot {
+	s := &TopSnapshot{}
+	executables := make(map[string]*TopCount)
+	talkers := make(map[string]*TopCount)
+	containers := make(map[string]*TopContainer)
+	processes := make(map[string]map[uint]*TopProcess)
+
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		if !filter.Match(event) {
+			continue
+		}
+		s.Events++
+
+		switch e := event.(type) {
+		case *ProcessEvent:
+			topIncrement(executables, e.Filename, e.Comm)
+		case *LifecycleEvent:
+			if e.EventName == EventNameProcessExec {
+				topIncrement(executables, e.Filename, e.Comm)
+			}
+		case *SocketEvent:
+			if e.NewState == TCP_ESTABLISHED {
+				peer := e.DestAddr
+				if e.Family == uint(unix.AF_INET6) {
+					peer = e.DestAddrV6
+				}
+				topIncrement(talkers, peer, fmt.Sprintf("%d", e.DestPort))
+			}
+		case *SignalEvent:
+			if len(s.Signals) < limit {
+				s.Signals = append(s.Signals, e)
+			}
+		case *ContainerEvent:
+			if len(s.ContainerStarts) < limit {
+				s.ContainerStarts = append(s.ContainerStarts, e)
+			}
+		}
+
+		enrichable, ok := event.(ContainerEnrichable)
+		if !ok || enrichable.Container().ContainerID == "" {
+			continue
+		}
+		metadata := enrichable.Container()
+		container, ok := containers[metadata.ContainerID]
+		if !ok {
+			container = &TopContainer{ContainerMetadata: ContainerMetadata{ContainerID: metadata.ContainerID}}
+			containers[metadata.ContainerID] = container
+			processes[metadata.ContainerID] = make(map[uint]*TopProcess)
+		}
+		container.Events++
+		container.Merge(metadata)
+		if socket, ok := event.(*SocketEvent); ok {
+			if len(container.Sockets) < limit {
+				container.Sockets = append(container.Sockets, socket)
+			}
+		}
+		pid, _ := EventFieldFloat(event, "PID")
+		if pid == 0 {
+			continue
+		}
+		process, ok := processes[metadata.ContainerID][uint(pid)]
+		if !ok {
+			process = &TopProcess{PID: uint(pid)}
+			processes[metadata.ContainerID][uint(pid)] = process
+			container.Processes = append(container.Processes, process)
+		}
+		process.Events++
+
+		// Events are newest first, so keep the first name seen.
+		if process.Comm == "" {
+			process.Comm = EventFieldString(event, "Comm")
+		}
+		if process.Filename == "" {
+			process.Filename = EventFieldString(event, "Filename")
+		}
+	}
+
+	s.Executables = topSorted(executables)
+	s.Talkers = topSorted(talkers)
+	for _, container := range containers {
+		sort.Slice(container.Processes, func(i, j int) bool {
+			if container.Processes[i].Events == container.Processes[j].Events {
+				return container.Processes[i].PID < container.Processes[j].PID
+			}
+			return container.Processes[i].Events > container.Processes[j].Events
+		})
+		s.Containers = append(s.Containers, container)
+	}
+	sort.Slice(s.Containers, func(i, j int) bool {
+		if s.Containers[i].Events == s.Containers[j].Events {
+			return s.Containers[i].ContainerID < s.Containers[j].ContainerID
+		}
+		return s.Containers[i].Events > s.Containers[j].Events
+	})
+	return s
+}
+
+// Container will find a container in the snapshot by ID.
+func (s *TopSnapshot) Container(id string) *TopContainer {
+	for _, container := range s.Containers {
+		if container.ContainerID == id {
+			return container
+		}
+	}
+	return nil
+}
+
+func topIncrement(counts map[string]*TopCount, key, detail string) {
+	if key == "" {
+		return
+	}
+	count, ok := counts[key]
+	if !ok {
+		// Events are newest first, so the detail is the most recent.
+		count = &TopCount{Key: key, Detail: detail}
+		counts[key] = count
+	}
+	count.Count++
+}
+
+func topSorted(counts map[string]*TopCount) []*TopCount {
+	var sorted []*TopCount
+	for _, count := range counts {
+		sorted = append(sorted, count)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count == sorted[j].Count {
+			return sorted[i].Key < sorted[j].Key
+		}
+		return sorted[i].Count > sorted[j].Count
+	})
+	return sorted
+}
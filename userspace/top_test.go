@@ -0,0 +1,173 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+
+package userspace
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// topEvents are oldest first, like the events kept by `dse top`.
+func topEvents() []Event {
+	web := ContainerMetadata{ContainerID: "web", ContainerName: "nginx"}
+	db := ContainerMetadata{ContainerID: "db"}
+	return []Event{
+		&ProcessEvent{EventName: "ProcessExecuted", PID: 10, Comm: "sh", Filename: "/bin/sh", ContainerMetadata: web},
+		&ProcessEvent{EventName: "ProcessExecuted", PID: 11, Comm: "curl", Filename: "/usr/bin/curl", ContainerMetadata: web},
+		&LifecycleEvent{EventName: EventNameProcessExec, PID: 12, Comm: "curl", Filename: "/usr/bin/curl", ContainerMetadata: web},
+		&LifecycleEvent{EventName: EventNameProcessExited, PID: 12, Comm: "curl", ContainerMetadata: web},
+		&SocketEvent{EventName: "SocketOpened", NewState: TCP_ESTABLISHED, Family: uint(unix.AF_INET), DestAddr: "10.0.0.1", DestPort: 443, PID: 11, ContainerMetadata: web},
+		&SocketEvent{EventName: "SocketOpened", NewState: TCP_ESTABLISHED, Family: uint(unix.AF_INET6), DestAddrV6: "fd00::1", DestPort: 5432, PID: 20, ContainerMetadata: db},
+		&SocketEvent{EventName: "SocketOpened", NewState: TCP_ESTABLISHED, Family: uint(unix.AF_INET), DestAddr: "10.0.0.1", DestPort: 8443, PID: 11, ContainerMetadata: web},
+		&SocketEvent{EventName: "SocketClosed", NewState: TCP_CLOSE, Family: uint(unix.AF_INET), DestAddr: "10.0.0.2", DestPort: 80},
+		&SignalEvent{EventName: "SignalKill", Signal: 9},
+		&SignalEvent{EventName: "SignalKill", Signal: 15},
+		&ContainerEvent{EventName: "ContainerStarted", ChildPid: 20, ContainerMetadata: db},
+		&ProcessEvent{EventName: "ProcessExecuted", PID: 20, Comm: "postgres", Filename: "/usr/bin/postgres", ContainerMetadata: ContainerMetadata{ContainerID: "db", ContainerName: "postgres"}},
+	}
+}
+
+func TestNewTopSnapshot(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      string
+		limit       int
+		events      int
+		executables []string
+		talkers     []string
+		signals     int
+		containers  []string
+		processes   map[string][]string
+	}{
+		{
+			name:        "all",
+			limit:       10,
+			events:      12,
+			executables: []string{"/usr/bin/curl=2 curl", "/bin/sh=1 sh", "/usr/bin/postgres=1 postgres"},
+			talkers:     []string{"10.0.0.1=2 8443", "fd00::1=1 5432"},
+			signals:     2,
+			containers:  []string{"web=6 nginx", "db=3 postgres"},
+			processes: map[string][]string{
+				"web": {"11=3 curl /usr/bin/curl", "12=2 curl /usr/bin/curl", "10=1 sh /bin/sh"},
+				"db":  {"20=2 postgres /usr/bin/postgres"},
+			},
+		},
+		{
+			name:        "limit",
+			limit:       1,
+			events:      12,
+			executables: []string{"/usr/bin/curl=2 curl", "/bin/sh=1 sh", "/usr/bin/postgres=1 postgres"},
+			talkers:     []string{"10.0.0.1=2 8443", "fd00::1=1 5432"},
+			signals:     1,
+			containers:  []string{"web=6 nginx", "db=3 postgres"},
+			processes: map[string][]string{
+				"web": {"11=3 curl /usr/bin/curl", "12=2 curl /usr/bin/curl", "10=1 sh /bin/sh"},
+				"db":  {"20=2 postgres /usr/bin/postgres"},
+			},
+		},
+		{
+			name:        "filter",
+			filter:      `ContainerID == "db"`,
+			limit:       10,
+			events:      3,
+			executables: []string{"/usr/bin/postgres=1 postgres"},
+			talkers:     []string{"fd00::1=1 5432"},
+			containers:  []string{"db=3 postgres"},
+			processes: map[string][]string{
+				"db": {"20=2 postgres /usr/bin/postgres"},
+			},
+		},
+		{
+			name:   "no match",
+			filter: `ContainerID == "cache"`,
+			limit:  10,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var filter *EventFilter
+			if test.filter != "" {
+				var err error
+				filter, err = ParseEventFilter(test.filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			s := NewTopSnapshot(topEvents(), filter, test.limit)
+			if s.Events != test.events {
+				t.Errorf("expected %d events, got %d", test.events, s.Events)
+			}
+			assertTopCounts(t, "executables", s.Executables, test.executables)
+			assertTopCounts(t, "talkers", s.Talkers, test.talkers)
+			if len(s.Signals) != test.signals {
+				t.Errorf("expected %d signals, got %d", test.signals, len(s.Signals))
+			}
+			var containers []string
+			for _, container := range s.Containers {
+				containers = append(containers, fmt.Sprintf("%s=%d %s", container.ContainerID, container.Events, container.ContainerName))
+				var processes []string
+				for _, process := range container.Processes {
+					processes = append(processes, fmt.Sprintf("%d=%d %s %s", process.PID, process.Events, process.Comm, process.Filename))
+				}
+				if !reflect.DeepEqual(processes, test.processes[container.ContainerID]) {
+					t.Errorf("container %s: expected processes %v, got %v", container.ContainerID, test.processes[container.ContainerID], processes)
+				}
+			}
+			if !reflect.DeepEqual(containers, test.containers) {
+				t.Errorf("expected containers %v, got %v", test.containers, containers)
+			}
+		})
+	}
+}
+
+func TestTopSnapshotNewestFirst(t *testing.T) {
+	s := NewTopSnapshot(topEvents(), nil, 10)
+	if s.Signals[0].Signal != 15 {
+		t.Errorf("expected the newest signal first, got %d", s.Signals[0].Signal)
+	}
+	if len(s.ContainerStarts) != 1 || s.ContainerStarts[0].ChildPid != 20 {
+		t.Errorf("unexpected container starts %v", s.ContainerStarts)
+	}
+	web := s.Container("web")
+	if web == nil || len(web.Sockets) != 2 || web.Sockets[0].DestPort != 8443 {
+		t.Errorf("expected the newest socket first in web, got %+v", web)
+	}
+	if s.Container("cache") != nil {
+		t.Errorf("expected no container cache")
+	}
+}
+
+func assertTopCounts(t *testing.T, pane string, counts []*TopCount, expected []string) {
+	t.Helper()
+	var actual []string
+	for _, count := range counts {
+		actual = append(actual, fmt.Sprintf("%s=%d %s", count.Key, count.Count, count.Detail))
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("%s: expected %v, got %v", pane, expected, actual)
+	}
+}
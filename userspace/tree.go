@@ -0,0 +1,395 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kris-nova/double-slit-experiment/system"
+)
+
+// ProcessTreeHost is the label of the group of processes
+// that are not in a container.
+const ProcessTreeHost = "host"
+
+// ProcessTree is a live tree of processes, grouped by container.
+// The tree is seeded from /proc, and kept up to date with the
+// events from a LifecycleObservationPoint.
+type ProcessTree struct {
+	mu        sync.Mutex
+	processes map[uint]*ProcessTreeNode
+	version   uint64
+}
+
+// ProcessTreeNode is a single process in a ProcessTreeSnapshot.
+type ProcessTreeNode struct {
+	PID      uint   `json:"PID"`
+	PPID     uint   `json:"PPID"`
+	Comm     string `json:"Comm"`
+	Filename string `json:"Filename,omitempty"`
+	ContainerMetadata
+	Children []*ProcessTreeNode `json:"Children,omitempty"`
+}
+
+// ProcessTreeGroup is every process in a single container,
+// or on the host.
+type ProcessTreeGroup struct {
+	Label string `json:"Label"`
+	ContainerMetadata
+	Processes []*ProcessTreeNode `json:"Processes"`
+}
+
+// ProcessTreeSnapshot is a copy of the ProcessTree at a point in time.
+type ProcessTreeSnapshot struct {
+	Time   time.Time           `json:"Time"`
+	Groups []*ProcessTreeGroup `json:"Groups"`
+}
+
+func NewProcessTree() *ProcessTree {
+	return &ProcessTree{
+		processes: make(map[uint]*ProcessTreeNode),
+	}
+}
+
+// Seed will add every process currently in /proc to the tree.
+func (t *ProcessTree) Seed() error {
+	processes, err := system.ProcList()
+	if err != nil {
+		return fmt.Errorf("unable to list processes: %v", err)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, p := range processes {
+		// Kernel threads
+		if p.Pid == 2 || p.ParentPid == 2 {
+			continue
+		}
+
+		// Deliberate design: We ignore errors looking up the details.
+		// There is a non-zero chance the process has terminated.
+		filename, _ := system.ProcExecutablePath(p.Pid)
+		containerID, _ := system.ProcContainerID(p.Pid)
+		t.processes[uint(p.Pid)] = &ProcessTreeNode{
+			PID:      uint(p.Pid),
+			PPID:     uint(p.ParentPid),
+			Comm:     p.Executable,
+			Filename: filename,
+			ContainerMetadata: ContainerMetadata{
+				ContainerID: containerID,
+			},
+		}
+	}
+	t.version++
+	return nil
+}
+
+// Handle will update the tree for every LifecycleEvent.
+func (t *ProcessTree) Handle(event Event) {
+	e, ok := event.(*LifecycleEvent)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch e.EventName {
+	case EventNameProcessForked:
+		child := &ProcessTreeNode{
+			PID:               e.ChildPID,
+			PPID:              e.PID,
+			Comm:              e.Comm,
+			ContainerMetadata: e.ContainerMetadata,
+		}
+		if parent, ok := t.processes[e.PID]; ok {
+			child.Filename = parent.Filename
+		}
+		t.processes[e.ChildPID] = child
+	case EventNameProcessExec:
+		node, ok := t.processes[e.PID]
+		if !ok {
+			node = &ProcessTreeNode{PID: e.PID}
+			t.processes[e.PID] = node
+		}
+		node.PPID = e.PPID
+		node.Comm = e.Comm
+		node.Filename = e.Filename
+		node.ContainerMetadata = e.ContainerMetadata
+	case EventNameProcessExited:
+		if _, ok := t.processes[e.PID]; !ok {
+			return
+		}
+		delete(t.processes, e.PID)
+	default:
+		return
+	}
+	t.version++
+}
+
+// Prune will remove every process that is no longer in /proc.
+// Exits can be missed when the kernel drops samples.
+func (t *ProcessTree) Prune() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for pid := range t.processes {
+		if _, err := os.Stat(fmt.Sprintf("/proc/%d", pid)); os.IsNotExist(err) {
+			delete(t.processes, pid)
+			t.version++
+		}
+	}
+}
+
+// Version is incremented every time the tree changes.
+func (t *ProcessTree) Version() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.version
+}
+
+// Snapshot will copy the tree. Only the groups that match
+// are included, a nil match includes every group.
+func (t *ProcessTree) Snapshot(match func(group *ProcessTreeGroup) bool) *ProcessTreeSnapshot {
+	t.mu.Lock()
+	nodes := make(map[uint]*ProcessTreeNode, len(t.processes))
+	for pid, node := range t.processes {
+		n := *node
+		n.Children = nil
+		nodes[pid] = &n
+	}
+	t.mu.Unlock()
+
+	groups := make(map[string]*ProcessTreeGroup)
+	for _, node := range nodes {
+		group, ok := groups[node.ContainerID]
+		if !ok {
+			group = &ProcessTreeGroup{
+				ContainerMetadata: ContainerMetadata{ContainerID: node.ContainerID},
+			}
+			groups[node.ContainerID] = group
+		}
+
+		// Seeded processes are never enriched, so use
+		// the metadata from any enriched process.
+		group.Merge(&node.ContainerMetadata)
+
+		parent, ok := nodes[node.PPID]
+		if ok && parent.ContainerID == node.ContainerID && parent != node {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		group.Processes = append(group.Processes, node)
+	}
+
+	snapshot := &ProcessTreeSnapshot{Time: time.Now()}
+	for _, group := range groups {
+		group.Label = processTreeGroupLabel(group)
+		if match != nil && !match(group) {
+			continue
+		}
+		sortProcessTreeNodes(group.Processes)
+		snapshot.Groups = append(snapshot.Groups, group)
+	}
+
+	// The host is always first
+	sort.Slice(snapshot.Groups, func(i, j int) bool {
+		a, b := snapshot.Groups[i], snapshot.Groups[j]
+		if a.ContainerID == "" || b.ContainerID == "" {
+			return a.ContainerID == ""
+		}
+		return a.Label < b.Label
+	})
+	return snapshot
+}
+
+func sortProcessTreeNodes(nodes []*ProcessTreeNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].PID < nodes[j].PID
+	})
+	for _, node := range nodes {
+		sortProcessTreeNodes(node.Children)
+	}
+}
+
+func processTreeGroupLabel(group *ProcessTreeGroup) string {
+	if group.ContainerID == "" {
+		return ProcessTreeHost
+	}
+	id := group.ContainerID
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	label := "container " + id
+	if group.ContainerName != "" {
+		label += " " + group.ContainerName
+	}
+	if group.PodName != "" {
+		label += fmt.Sprintf(" (%s/%s)", group.PodNamespace, group.PodName)
+	}
+	return label
+}
+
+// MatchProcessTreeGroup will match groups by a container ID prefix,
+// container name, pod name or compose project.
+func MatchProcessTreeGroup(query string) func(group *ProcessTreeGroup) bool {
+	return func(group *ProcessTreeGroup) bool {
+		if query == ProcessTreeHost {
+			return group.ContainerID == ""
+		}
+		if group.ContainerID == "" {
+			return false
+		}
+		return strings.HasPrefix(group.ContainerID, query) ||
+			group.ContainerName == query ||
+			group.PodName == query ||
+			group.ComposeProject == query
+	}
+}
+
+// Count will return the number of processes in the snapshot.
+func (s *ProcessTreeSnapshot) Count() int {
+	var count func(nodes []*ProcessTreeNode) int
+	count = func(nodes []*ProcessTreeNode) int {
+		n := len(nodes)
+		for _, node := range nodes {
+			n += count(node.Children)
+		}
+		return n
+	}
+	total := 0
+	for _, group := range s.Groups {
+		total += count(group.Processes)
+	}
+	return total
+}
+
+// WriteText will write the snapshot as an indented tree.
+//
+//   host
+//   └── 1 systemd /usr/lib/systemd/systemd
+//       └── 812 sshd /usr/sbin/sshd
+func (s *ProcessTreeSnapshot) WriteText(w io.Writer) error {
+	var write func(nodes []*ProcessTreeNode, prefix string) error
+	write = func(nodes []*ProcessTreeNode, prefix string) error {
+		for i, node := range nodes {
+			branch, indent := "├── ", "│   "
+			if i == len(nodes)-1 {
+				branch, indent = "└── ", "    "
+			}
+			line := fmt.Sprintf("%s%s%d %s", prefix, branch, node.PID, node.Comm)
+			if node.Filename != "" && filepath.Base(node.Filename) != node.Comm {
+				line += " " + node.Filename
+			}
+			_, err := fmt.Fprintln(w, line)
+			if err != nil {
+				return err
+			}
+			err = write(node.Children, prefix+indent)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, group := range s.Groups {
+		_, err := fmt.Fprintln(w, group.Label)
+		if err != nil {
+			return err
+		}
+		err = write(group.Processes, "")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON will write the snapshot as indented JSON.
+func (s *ProcessTreeSnapshot) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// WriteDOT will write the snapshot as a Graphviz digraph, with a
+// cluster for every group.
+//
+//   dse tree --once dot | dot -Tsvg > tree.svg
+func (s *ProcessTreeSnapshot) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph dse {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	var edges []string
+	var write func(nodes []*ProcessTreeNode)
+	write = func(nodes []*ProcessTreeNode) {
+		for _, node := range nodes {
+			label := fmt.Sprintf("%d %s", node.PID, dotEscape(node.Comm))
+			if node.Filename != "" {
+				label += "\\n" + dotEscape(node.Filename)
+			}
+			fmt.Fprintf(&b, "    p%d [label=\"%s\"];\n", node.PID, label)
+			for _, child := range node.Children {
+				edges = append(edges, fmt.Sprintf("  p%d -> p%d;\n", node.PID, child.PID))
+			}
+			write(node.Children)
+		}
+	}
+	for i, group := range s.Groups {
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&b, "    label=\"%s\";\n", dotEscape(group.Label))
+		write(group.Processes)
+		b.WriteString("  }\n")
+	}
+	for _, edge := range edges {
+		b.WriteString(edge)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Write will write the snapshot in a format: text, json or dot.
+func (s *ProcessTreeSnapshot) Write(w io.Writer, format string) error {
+	switch format {
+	case "", "text":
+		return s.WriteText(w)
+	case "json":
+		return s.WriteJSON(w)
+	case "dot":
+		return s.WriteDOT(w)
+	}
+	return fmt.Errorf("unknown process tree format %q, expected text, json or dot", format)
+}
+
+// dotEscape will escape a string for a quoted DOT ID.
+func dotEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return s
+}
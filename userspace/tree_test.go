@@ -0,0 +1,149 @@
+//
+// Copyright © 2021 Kris Nóva <kris@nivenly.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//    ███╗   ██╗ ██████╗ ██╗   ██╗ █████╗
+//    ████╗  ██║██╔═████╗██║   ██║██╔══██╗
+//    ██╔██╗ ██║██║██╔██║██║   ██║███████║
+//    ██║╚██╗██║████╔╝██║╚██╗ ██╔╝██╔══██║
+//    ██║ ╚████║╚██████╔╝ ╚████╔╝ ██║  ██║
+//    ╚═╝  ╚═══╝ ╚═════╝   ╚═══╝  ╚═╝  ╚═╝
+
+package userspace
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// testProcessTree is a host shell that starts a container, which
+// forks and execs nginx and then a worker that exits.
+func testProcessTree() *ProcessTree {
+	web := ContainerMetadata{ContainerID: "0123456789abcdef", ContainerName: "web", PodName: "web-0", PodNamespace: "default"}
+	tree := NewProcessTree()
+	for _, event := range []*LifecycleEvent{
+		{EventName: EventNameProcessExec, PID: 1, Comm: "systemd", Filename: "/usr/lib/systemd/systemd"},
+		{EventName: EventNameProcessForked, PID: 1, ChildPID: 10, Comm: "systemd"},
+		{EventName: EventNameProcessExec, PID: 10, PPID: 1, Comm: "bash", Filename: "/bin/bash"},
+		{EventName: EventNameProcessForked, PID: 10, ChildPID: 20, Comm: "bash", ContainerMetadata: web},
+		{EventName: EventNameProcessExec, PID: 20, PPID: 10, Comm: "nginx", Filename: "/usr/sbin/nginx-debug", ContainerMetadata: web},
+		{EventName: EventNameProcessForked, PID: 20, ChildPID: 21, Comm: "nginx", ContainerMetadata: web},
+		{EventName: EventNameProcessForked, PID: 20, ChildPID: 22, Comm: "nginx", ContainerMetadata: web},
+		{EventName: EventNameProcessExited, PID: 22},
+	} {
+		tree.Handle(event)
+	}
+	return tree
+}
+
+func TestProcessTreeSnapshot(t *testing.T) {
+	snapshot := testProcessTree().Snapshot(nil)
+	var b bytes.Buffer
+	err := snapshot.WriteText(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"host",
+		"└── 1 systemd",
+		"    └── 10 bash",
+		"container 0123456789ab web (default/web-0)",
+		"└── 20 nginx /usr/sbin/nginx-debug",
+		"    └── 21 nginx /usr/sbin/nginx-debug",
+		"",
+	}, "\n")
+	if b.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+	if snapshot.Count() != 4 {
+		t.Errorf("expected 4 processes, got %d", snapshot.Count())
+	}
+}
+
+func TestProcessTreeVersion(t *testing.T) {
+	tree := testProcessTree()
+	version := tree.Version()
+	tests := []struct {
+		name    string
+		event   Event
+		changed bool
+	}{
+		{"exit of an unknown process", &LifecycleEvent{EventName: EventNameProcessExited, PID: 999}, false},
+		{"other events", &ProcessEvent{EventName: "ProcessExecuted", PID: 30}, false},
+		{"fork", &LifecycleEvent{EventName: EventNameProcessForked, PID: 10, ChildPID: 30}, true},
+		{"exit", &LifecycleEvent{EventName: EventNameProcessExited, PID: 30}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tree.Handle(test.event)
+			if changed := tree.Version() != version; changed != test.changed {
+				t.Errorf("expected changed=%v", test.changed)
+			}
+			version = tree.Version()
+		})
+	}
+}
+
+func TestMatchProcessTreeGroup(t *testing.T) {
+	tree := testProcessTree()
+	tests := []struct {
+		query  string
+		labels []string
+	}{
+		{"host", []string{"host"}},
+		{"0123", []string{"container 0123456789ab web (default/web-0)"}},
+		{"web", []string{"container 0123456789ab web (default/web-0)"}},
+		{"web-0", []string{"container 0123456789ab web (default/web-0)"}},
+		{"missing", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			snapshot := tree.Snapshot(MatchProcessTreeGroup(test.query))
+			var labels []string
+			for _, group := range snapshot.Groups {
+				labels = append(labels, group.Label)
+			}
+			if strings.Join(labels, ",") != strings.Join(test.labels, ",") {
+				t.Errorf("expected %v, got %v", test.labels, labels)
+			}
+		})
+	}
+}
+
+func TestProcessTreeWrite(t *testing.T) {
+	snapshot := testProcessTree().Snapshot(nil)
+	tests := []struct {
+		format   string
+		contains string
+		valid    bool
+	}{
+		{"text", "└── 20 nginx", true},
+		{"json", `"Label": "host"`, true},
+		{"dot", "p20 -> p21;", true},
+		{"yaml", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var b bytes.Buffer
+			err := snapshot.Write(&b, test.format)
+			if (err == nil) != test.valid {
+				t.Fatalf("expected valid=%v, got %v", test.valid, err)
+			}
+			if !strings.Contains(b.String(), test.contains) {
+				t.Errorf("expected %q in:\n%s", test.contains, b.String())
+			}
+		})
+	}
+}